@@ -0,0 +1,37 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package achcsv reads spreadsheet (CSV) rows into ACH files and flattens ACH files back into rows.
+//
+// Which column holds each entry field is described by a Mapping, commonly read from a JSON file.
+// Rows are grouped into batches by company and effective date.
+//
+// Build a file from a payroll spreadsheet
+//     mapping, err := achcsv.ReadMappingFile("payroll-mapping.json")
+//     if err != nil {
+//         log.Fatalf("problem reading mapping: %v", err)
+//     }
+//     file, err := achcsv.Read(fd, fh, mapping)
+//     if err != nil {
+//         log.Fatalf("problem reading CSV: %v", err) // errors reference CSV row numbers
+//     }
+//
+// Flatten a file into rows
+//     if err := achcsv.Write(os.Stdout, file); err != nil {
+//         log.Fatalf("problem writing CSV: %v", err)
+//     }
+package achcsv
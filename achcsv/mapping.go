@@ -0,0 +1,240 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+// Mapping describes which CSV column holds each field of an entry and its batch.
+type Mapping struct {
+	// HeaderRow is true when the first row of the CSV names its columns
+	HeaderRow bool `json:"headerRow"`
+	// Columns locate each field within a row
+	Columns Columns `json:"columns"`
+	// AmountInCents reads amounts as whole cents (125000) rather than dollars (1,250.00)
+	AmountInCents bool `json:"amountInCents,omitempty"`
+	// EffectiveDateFormat is the time layout of effective dates. When empty 2006-01-02 is used.
+	EffectiveDateFormat string `json:"effectiveDateFormat,omitempty"`
+	// TransactionTypes maps values of the transaction type column onto transaction codes, in
+	// addition to numeric codes and the names credit, debit, savings credit and savings debit.
+	TransactionTypes map[string]int `json:"transactionTypes,omitempty"`
+	// Defaults are used for batch fields which have no column (or an empty cell)
+	Defaults Defaults `json:"defaults"`
+}
+
+// Columns name the column holding each field. When the CSV has a header row a column is
+// referenced by its name (case insensitive), otherwise by its position starting at 1.
+// RoutingNumber, AccountNumber, Amount, Name and TransactionType are required.
+type Columns struct {
+	RoutingNumber        string `json:"routingNumber"`
+	AccountNumber        string `json:"accountNumber"`
+	Amount               string `json:"amount"`
+	Name                 string `json:"name"`
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+	TransactionType      string `json:"transactionType"`
+	// Addenda is written as the entry's Addenda05 payment related information
+	Addenda string `json:"addenda,omitempty"`
+
+	CompanyName             string `json:"companyName,omitempty"`
+	CompanyIdentification   string `json:"companyIdentification,omitempty"`
+	StandardEntryClassCode  string `json:"standardEntryClassCode,omitempty"`
+	CompanyEntryDescription string `json:"companyEntryDescription,omitempty"`
+	EffectiveDate           string `json:"effectiveDate,omitempty"`
+	ODFIIdentification      string `json:"ODFIIdentification,omitempty"`
+}
+
+// Defaults are the batch values used when a row does not provide them
+type Defaults struct {
+	CompanyName             string `json:"companyName,omitempty"`
+	CompanyIdentification   string `json:"companyIdentification,omitempty"`
+	StandardEntryClassCode  string `json:"standardEntryClassCode,omitempty"`
+	CompanyEntryDescription string `json:"companyEntryDescription,omitempty"`
+	// EffectiveDate is in the Mapping's EffectiveDateFormat
+	EffectiveDate      string `json:"effectiveDate,omitempty"`
+	ODFIIdentification string `json:"ODFIIdentification,omitempty"`
+}
+
+// ReadMappingFile reads a JSON encoded Mapping
+func ReadMappingFile(path string) (*Mapping, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var m Mapping
+	if err := json.NewDecoder(fd).Decode(&m); err != nil {
+		return nil, fmt.Errorf("reading mapping %s: %v", path, err)
+	}
+	return &m, nil
+}
+
+// ExportMapping reads the rows written by Write back into a file
+func ExportMapping() *Mapping {
+	return &Mapping{
+		HeaderRow: true,
+		Columns: Columns{
+			RoutingNumber:           colRoutingNumber,
+			AccountNumber:           colAccountNumber,
+			Amount:                  colAmount,
+			Name:                    colName,
+			IdentificationNumber:    colIdentificationNumber,
+			TransactionType:         colTransactionCode,
+			Addenda:                 colAddenda,
+			CompanyName:             colCompanyName,
+			CompanyIdentification:   colCompanyIdentification,
+			StandardEntryClassCode:  colStandardEntryClassCode,
+			CompanyEntryDescription: colCompanyEntryDescription,
+			EffectiveDate:           colEffectiveDate,
+			ODFIIdentification:      colODFIIdentification,
+		},
+	}
+}
+
+func (m *Mapping) effectiveDateFormat() string {
+	if m.EffectiveDateFormat != "" {
+		return m.EffectiveDateFormat
+	}
+	return "2006-01-02"
+}
+
+// defaultTransactionTypes are the transaction type names understood by every Mapping
+var defaultTransactionTypes = map[string]int{
+	"credit":          ach.CheckingCredit,
+	"debit":           ach.CheckingDebit,
+	"checking credit": ach.CheckingCredit,
+	"checking debit":  ach.CheckingDebit,
+	"savings credit":  ach.SavingsCredit,
+	"savings debit":   ach.SavingsDebit,
+}
+
+// transactionCode returns the transaction code of a transaction type cell
+func (m *Mapping) transactionCode(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if code, ok := m.TransactionTypes[s]; ok {
+		return code, nil
+	}
+	if code, ok := defaultTransactionTypes[strings.ToLower(s)]; ok {
+		return code, nil
+	}
+	if code, err := strconv.Atoi(s); err == nil {
+		return code, nil
+	}
+	return 0, fmt.Errorf("unknown transaction type %q", s)
+}
+
+// amount returns the cents of an amount cell
+func (m *Mapping) amount(s string) (int, error) {
+	s = strings.NewReplacer("$", "", ",", "").Replace(strings.TrimSpace(s))
+	if s == "" {
+		return 0, errors.New("missing amount")
+	}
+	if m.AmountInCents {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		return n, nil
+	}
+	whole, frac := s, ""
+	if idx := strings.Index(s, "."); idx >= 0 {
+		whole, frac = s[:idx], s[idx+1:]
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	dollars, err := strconv.Atoi(whole)
+	if err != nil || dollars < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	cents, err := strconv.Atoi(frac)
+	if err != nil || cents < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return dollars*100 + cents, nil
+}
+
+// columnIndexes are the zero based positions of each mapped column, -1 when unmapped
+type columnIndexes struct {
+	routingNumber, accountNumber, amount, name, identificationNumber, transactionType, addenda               int
+	companyName, companyIdentification, standardEntryClassCode, companyEntryDescription, effectiveDate, odfi int
+}
+
+// indexes resolves the Mapping's columns against the header row (nil without one)
+func (m *Mapping) indexes(header []string) (*columnIndexes, error) {
+	resolve := func(field, column string, required bool) (int, error) {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			if required {
+				return -1, fmt.Errorf("%s column: %w", field, ach.ErrFieldRequired)
+			}
+			return -1, nil
+		}
+		if m.HeaderRow {
+			for i := range header {
+				if strings.EqualFold(strings.TrimSpace(header[i]), column) {
+					return i, nil
+				}
+			}
+			return -1, fmt.Errorf("%s column %q not found in header row", field, column)
+		}
+		n, err := strconv.Atoi(column)
+		if err != nil || n < 1 {
+			return -1, fmt.Errorf("%s column %q is not a column number", field, column)
+		}
+		return n - 1, nil
+	}
+
+	var idx columnIndexes
+	cols := []struct {
+		field, column string
+		required      bool
+		dst           *int
+	}{
+		{"routingNumber", m.Columns.RoutingNumber, true, &idx.routingNumber},
+		{"accountNumber", m.Columns.AccountNumber, true, &idx.accountNumber},
+		{"amount", m.Columns.Amount, true, &idx.amount},
+		{"name", m.Columns.Name, true, &idx.name},
+		{"identificationNumber", m.Columns.IdentificationNumber, false, &idx.identificationNumber},
+		{"transactionType", m.Columns.TransactionType, true, &idx.transactionType},
+		{"addenda", m.Columns.Addenda, false, &idx.addenda},
+		{"companyName", m.Columns.CompanyName, false, &idx.companyName},
+		{"companyIdentification", m.Columns.CompanyIdentification, false, &idx.companyIdentification},
+		{"standardEntryClassCode", m.Columns.StandardEntryClassCode, false, &idx.standardEntryClassCode},
+		{"companyEntryDescription", m.Columns.CompanyEntryDescription, false, &idx.companyEntryDescription},
+		{"effectiveDate", m.Columns.EffectiveDate, false, &idx.effectiveDate},
+		{"ODFIIdentification", m.Columns.ODFIIdentification, false, &idx.odfi},
+	}
+	for _, c := range cols {
+		i, err := resolve(c.field, c.column, c.required)
+		if err != nil {
+			return nil, err
+		}
+		*c.dst = i
+	}
+	return &idx, nil
+}
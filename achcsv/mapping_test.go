@@ -0,0 +1,132 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func readMapping(t *testing.T) *Mapping {
+	t.Helper()
+	m, err := ReadMappingFile(filepath.Join("..", "test", "testdata", "payroll-mapping.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestReadMappingFile(t *testing.T) {
+	m := readMapping(t)
+	if !m.HeaderRow || m.Columns.Amount != "Pay" || m.TransactionTypes["clawback"] != ach.CheckingDebit {
+		t.Errorf("unexpected mapping: %#v", m)
+	}
+	if m.Defaults.StandardEntryClassCode != ach.PPD {
+		t.Errorf("Defaults: %#v", m.Defaults)
+	}
+
+	if _, err := ReadMappingFile(filepath.Join("..", "test", "testdata", "missing.json")); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ReadMappingFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMapping__amount(t *testing.T) {
+	m := &Mapping{}
+	cases := map[string]int{"1250": 125000, "$1,250.00": 125000, "980.5": 98050, "0.07": 7, " 12.34 ": 1234}
+	for s, expected := range cases {
+		got, err := m.amount(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if got != expected {
+			t.Errorf("%q: got %d expected %d", s, got, expected)
+		}
+	}
+	for _, s := range []string{"", "-1.00", "1.005", "abc", "1.x"} {
+		if _, err := m.amount(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+
+	m.AmountInCents = true
+	if got, err := m.amount("125000"); err != nil || got != 125000 {
+		t.Errorf("got %d: %v", got, err)
+	}
+	if _, err := m.amount("1250.00"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMapping__transactionCode(t *testing.T) {
+	m := &Mapping{TransactionTypes: map[string]int{"PAY": ach.CheckingCredit}}
+	cases := map[string]int{"PAY": 22, "Credit": 22, "debit": 27, "Savings Credit": 32, "37": 37}
+	for s, expected := range cases {
+		got, err := m.transactionCode(s)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if got != expected {
+			t.Errorf("%q: got %d expected %d", s, got, expected)
+		}
+	}
+	if _, err := m.transactionCode("refund"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMapping__indexes(t *testing.T) {
+	m := readMapping(t)
+	header := []string{"Employee", "Routing", "Account", "Pay", "type", "Employee ID", "Memo", "Pay Date"}
+	idx, err := m.indexes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.name != 0 || idx.transactionType != 4 || idx.effectiveDate != 7 || idx.companyName != -1 {
+		t.Errorf("unexpected indexes: %#v", idx)
+	}
+
+	// header names must be found
+	if _, err := m.indexes(header[:3]); err == nil {
+		t.Error("expected error")
+	}
+	// required columns
+	m.Columns.Amount = ""
+	if _, err := m.indexes(header); !errors.Is(err, ach.ErrFieldRequired) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// without a header row columns are numbered
+	m = &Mapping{Columns: Columns{RoutingNumber: "1", AccountNumber: "2", Amount: "3", Name: "4", TransactionType: "5"}}
+	idx, err = m.indexes(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.routingNumber != 0 || idx.transactionType != 4 || idx.addenda != -1 {
+		t.Errorf("unexpected indexes: %#v", idx)
+	}
+	m.Columns.Name = "Employee"
+	if _, err := m.indexes(nil); err == nil {
+		t.Error("expected error")
+	}
+}
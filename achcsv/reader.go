@@ -0,0 +1,244 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/base"
+)
+
+// RowError is an error found in a row of the CSV
+type RowError struct {
+	// Row is the line number of the row, counting the header row
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// group is the entries of one batch and the rows they were read from
+type group struct {
+	header  *ach.BatchHeader
+	entries []*ach.EntryDetail
+	rows    []int
+}
+
+// Read builds an ACH file from CSV rows according to the Mapping. Rows are grouped into a
+// batch for each company and effective date, Create() is called on every batch and on the
+// file. Every invalid row is returned in a base.ErrorList of *RowError.
+func Read(r io.Reader, fh ach.FileHeader, m *Mapping) (*ach.File, error) {
+	if m == nil {
+		return nil, errors.New("nil Mapping")
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var header []string
+	first := 0
+	if m.HeaderRow {
+		if len(records) == 0 {
+			return nil, errors.New("missing header row")
+		}
+		header, first = records[0], 1
+	}
+	idx, err := m.indexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs base.ErrorList
+	var groups []*group
+	byKey := make(map[string]*group)
+	for i := first; i < len(records); i++ {
+		row := i + 1
+		if blank(records[i]) {
+			continue
+		}
+		bh, ed, err := m.parseRow(idx, records[i])
+		if err != nil {
+			errs.Add(&RowError{Row: row, Err: err})
+			continue
+		}
+		key := strings.Join([]string{bh.CompanyName, bh.CompanyIdentification, bh.StandardEntryClassCode,
+			bh.CompanyEntryDescription, bh.EffectiveEntryDate, bh.ODFIIdentification}, "|")
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{header: bh}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.entries = append(g.entries, ed)
+		g.rows = append(g.rows, row)
+	}
+	if !errs.Empty() {
+		return nil, errs
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("no rows")
+	}
+
+	file := ach.NewFile().SetHeader(fh)
+	for _, g := range groups {
+		batch, err := g.batch()
+		if err != nil {
+			return nil, err
+		}
+		file.AddBatch(batch)
+	}
+	if err := file.Create(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// batch creates the group's batch, with a ServiceClassCode matching its entries
+func (g *group) batch() (ach.Batcher, error) {
+	credits, debits := 0, 0
+	for _, ed := range g.entries {
+		if ed.CreditOrDebit() == "C" {
+			credits++
+		} else {
+			debits++
+		}
+	}
+	switch {
+	case debits == 0:
+		g.header.ServiceClassCode = ach.CreditsOnly
+	case credits == 0:
+		g.header.ServiceClassCode = ach.DebitsOnly
+	default:
+		g.header.ServiceClassCode = ach.MixedDebitsAndCredits
+	}
+
+	batch, err := ach.NewBatch(g.header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", g.describe(), err)
+	}
+	for i, ed := range g.entries {
+		ed.SetTraceNumber(g.header.ODFIIdentification, i+1)
+		batch.AddEntry(ed)
+	}
+	if err := batch.Create(); err != nil {
+		return nil, fmt.Errorf("%s: %w", g.describe(), err)
+	}
+	return batch, nil
+}
+
+// describe names the group's batch and rows for error messages
+func (g *group) describe() string {
+	rows := make([]string, len(g.rows))
+	for i := range g.rows {
+		rows[i] = fmt.Sprintf("%d", g.rows[i])
+	}
+	return fmt.Sprintf("batch %s %s (rows %s)", g.header.CompanyName, g.header.EffectiveEntryDate, strings.Join(rows, ", "))
+}
+
+// parseRow reads the batch header and entry of a row
+func (m *Mapping) parseRow(idx *columnIndexes, record []string) (*ach.BatchHeader, *ach.EntryDetail, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	orDefault := func(i int, def string) string {
+		if s := cell(i); s != "" {
+			return s
+		}
+		return def
+	}
+
+	bh := ach.NewBatchHeader()
+	bh.CompanyName = orDefault(idx.companyName, m.Defaults.CompanyName)
+	bh.CompanyIdentification = orDefault(idx.companyIdentification, m.Defaults.CompanyIdentification)
+	bh.StandardEntryClassCode = strings.ToUpper(orDefault(idx.standardEntryClassCode, m.Defaults.StandardEntryClassCode))
+	bh.CompanyEntryDescription = orDefault(idx.companyEntryDescription, m.Defaults.CompanyEntryDescription)
+	if odfi := orDefault(idx.odfi, m.Defaults.ODFIIdentification); len(odfi) > 8 {
+		bh.ODFIIdentification = odfi[:8]
+	} else {
+		bh.ODFIIdentification = odfi
+	}
+	if date := orDefault(idx.effectiveDate, m.Defaults.EffectiveDate); date != "" {
+		t, err := time.Parse(m.effectiveDateFormat(), date)
+		if err != nil {
+			return nil, nil, fmt.Errorf("effective date %q is not in the format %s", date, m.effectiveDateFormat())
+		}
+		bh.EffectiveEntryDate = t.Format("060102")
+	}
+
+	ed := ach.NewEntryDetail()
+	code, err := m.transactionCode(cell(idx.transactionType))
+	if err != nil {
+		return nil, nil, err
+	}
+	ed.TransactionCode = code
+	routing := cell(idx.routingNumber)
+	if err := ach.CheckRoutingNumber(routing); err != nil {
+		return nil, nil, fmt.Errorf("routing number %q: %w", routing, err)
+	}
+	ed.SetRDFI(routing)
+	ed.DFIAccountNumber = cell(idx.accountNumber)
+	if ed.Amount, err = m.amount(cell(idx.amount)); err != nil {
+		return nil, nil, err
+	}
+	ed.IndividualName = cell(idx.name)
+	ed.IdentificationNumber = cell(idx.identificationNumber)
+	ed.Category = ach.CategoryForward
+	if info := cell(idx.addenda); info != "" {
+		addenda05 := ach.NewAddenda05()
+		addenda05.PaymentRelatedInformation = info
+		ed.AddAddenda05(addenda05)
+		ed.AddendaRecordIndicator = 1
+	}
+
+	// validate the entry now, so the error refers to its row
+	ed.SetTraceNumber(bh.ODFIIdentification, 1)
+	if err := ed.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return bh, ed, nil
+}
+
+// blank returns true for rows without any values
+func blank(record []string) bool {
+	for i := range record {
+		if strings.TrimSpace(record[i]) != "" {
+			return false
+		}
+	}
+	return true
+}
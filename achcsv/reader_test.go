@@ -0,0 +1,140 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/base"
+)
+
+func mockFileHeader() ach.FileHeader {
+	fh := ach.NewFileHeader()
+	fh.ImmediateDestination = "231380104"
+	fh.ImmediateOrigin = "121042882"
+	fh.FileCreationDate = "191120"
+	fh.ImmediateDestinationName = "Federal Reserve Bank"
+	fh.ImmediateOriginName = "My Bank Name"
+	return fh
+}
+
+// validPayroll is test/testdata/payroll.csv without the invalid row
+const validPayroll = `Employee,Routing,Account,Pay,Type,Employee ID,Memo,Pay Date
+Jane Doe,231380104,744-5678-99,"$1,250.00",checking,E-1001,November salary,2019-11-22
+John Smith,121042882,123456789,980.5,savings,E-1002,,2019-11-22
+Ann Lee,231380104,556677,45.10,checking,E-1003,Expense refund,2019-11-25
+`
+
+func TestRead(t *testing.T) {
+	file, err := Read(strings.NewReader(validPayroll), mockFileHeader(), readMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(file.Batches); n != 2 {
+		t.Fatalf("got %d batches", n)
+	}
+
+	bh := file.Batches[0].GetHeader()
+	if bh.CompanyName != "Acme Corporation" || bh.EffectiveEntryDate != "191122" || bh.ODFIIdentification != "12104288" || bh.ServiceClassCode != ach.CreditsOnly {
+		t.Errorf("BatchHeader: %#v", bh)
+	}
+	entries := file.Batches[0].GetEntries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if ed := entries[0]; ed.Amount != 125000 || ed.DFIAccountNumber != "744-5678-99" || ed.IdentificationNumber != "E-1001" || ed.Addenda05[0].PaymentRelatedInformation != "November salary" {
+		t.Errorf("entry 0: %#v", ed)
+	}
+	if ed := entries[1]; ed.TransactionCode != ach.SavingsCredit || ed.Amount != 98050 || ed.AddendaRecordIndicator != 0 || ed.TraceNumber != "121042880000002" {
+		t.Errorf("entry 1: %#v", ed)
+	}
+	if bh := file.Batches[1].GetHeader(); bh.EffectiveEntryDate != "191125" {
+		t.Errorf("EffectiveEntryDate=%s", bh.EffectiveEntryDate)
+	}
+}
+
+func TestRead__rowErrors(t *testing.T) {
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "payroll.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	// the clawback row is a debit, which is fine, so break two other rows
+	m := readMapping(t)
+	delete(m.TransactionTypes, "clawback")
+	csv := strings.Replace(validPayroll, "121042882,123456789", "121042881,123456789", 1) + "Mark Payer,121042882,8765432,12.00,clawback,E-1004,,2019-11-25\n"
+
+	_, err = Read(strings.NewReader(csv), mockFileHeader(), m)
+	var errs base.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var rowErr *RowError
+	if !errors.As(errs[0], &rowErr) || rowErr.Row != 3 {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+	if !errors.As(errs[1], &rowErr) || rowErr.Row != 5 || !strings.Contains(rowErr.Error(), "row 5: unknown transaction type") {
+		t.Errorf("unexpected error: %v", errs[1])
+	}
+
+	// the test file mixes a debit into the second batch
+	file, err := Read(fd, mockFileHeader(), readMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scc := file.Batches[1].GetHeader().ServiceClassCode; scc != ach.MixedDebitsAndCredits {
+		t.Errorf("ServiceClassCode=%d", scc)
+	}
+}
+
+func TestRead__errors(t *testing.T) {
+	if _, err := Read(strings.NewReader(validPayroll), mockFileHeader(), nil); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := Read(strings.NewReader(""), mockFileHeader(), readMapping(t)); err == nil {
+		t.Error("expected error")
+	}
+	header := strings.SplitN(validPayroll, "\n", 2)[0] + "\n"
+	if _, err := Read(strings.NewReader(header), mockFileHeader(), readMapping(t)); err == nil {
+		t.Error("expected error")
+	}
+
+	// batch errors name the rows of the batch
+	m := readMapping(t)
+	m.Defaults.StandardEntryClassCode = "XYZ"
+	_, err := Read(strings.NewReader(validPayroll), mockFileHeader(), m)
+	if err == nil || !strings.Contains(err.Error(), "rows 2, 3") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	m = readMapping(t)
+	m.EffectiveDateFormat = "01/02/2006"
+	_, err = Read(strings.NewReader(validPayroll), mockFileHeader(), m)
+	if err == nil || !strings.Contains(err.Error(), "row 2: effective date") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
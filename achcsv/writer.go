@@ -0,0 +1,117 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// column names written by Write
+const (
+	colCompanyName             = "Company Name"
+	colCompanyIdentification   = "Company Identification"
+	colStandardEntryClassCode  = "SEC Code"
+	colCompanyEntryDescription = "Entry Description"
+	colEffectiveDate           = "Effective Date"
+	colODFIIdentification      = "ODFI"
+	colBatchNumber             = "Batch Number"
+	colTransactionCode         = "Transaction Code"
+	colRoutingNumber           = "Routing Number"
+	colAccountNumber           = "Account Number"
+	colAmount                  = "Amount"
+	colName                    = "Name"
+	colIdentificationNumber    = "Identification Number"
+	colTraceNumber             = "Trace Number"
+	colAddenda                 = "Addenda"
+)
+
+// Header is the header row written by Write
+var Header = []string{
+	colCompanyName, colCompanyIdentification, colStandardEntryClassCode, colCompanyEntryDescription,
+	colEffectiveDate, colODFIIdentification, colBatchNumber, colTransactionCode, colRoutingNumber,
+	colAccountNumber, colAmount, colName, colIdentificationNumber, colTraceNumber, colAddenda,
+}
+
+// Write flattens every EntryDetail of the file into a CSV row with its batch header fields
+// and addenda text, after a Header row. Amounts are written in dollars and effective dates
+// as 2006-01-02, so ExportMapping reads the rows back. IAT and ADV entries are not written.
+func Write(w io.Writer, file *ach.File) error {
+	if file == nil {
+		return errors.New("nil File")
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+	for _, batch := range file.Batches {
+		bh := batch.GetHeader()
+		effectiveDate := bh.EffectiveEntryDate
+		if t, err := time.Parse("060102", effectiveDate); err == nil {
+			effectiveDate = t.Format("2006-01-02")
+		}
+		for _, ed := range batch.GetEntries() {
+			row := []string{
+				strings.TrimSpace(bh.CompanyName),
+				strings.TrimSpace(bh.CompanyIdentification),
+				bh.StandardEntryClassCode,
+				strings.TrimSpace(bh.CompanyEntryDescription),
+				effectiveDate,
+				bh.ODFIIdentification,
+				fmt.Sprintf("%d", bh.BatchNumber),
+				fmt.Sprintf("%d", ed.TransactionCode),
+				ed.RDFIIdentificationField() + ed.CheckDigit,
+				strings.TrimSpace(ed.DFIAccountNumber),
+				fmt.Sprintf("%d.%02d", ed.Amount/100, ed.Amount%100),
+				strings.TrimSpace(ed.IndividualName),
+				strings.TrimSpace(ed.IdentificationNumber),
+				ed.TraceNumber,
+				addendaText(ed),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// addendaText joins the text of an entry's addenda records
+func addendaText(ed *ach.EntryDetail) string {
+	var parts []string
+	if ed.Addenda02 != nil {
+		parts = append(parts, strings.TrimSpace(ed.Addenda02.TerminalLocation+" "+ed.Addenda02.TerminalCity+" "+ed.Addenda02.TerminalState))
+	}
+	for _, addenda05 := range ed.Addenda05 {
+		parts = append(parts, strings.TrimSpace(addenda05.PaymentRelatedInformation))
+	}
+	if ed.Addenda98 != nil {
+		parts = append(parts, strings.TrimSpace(ed.Addenda98.ChangeCode+" "+ed.Addenda98.CorrectedData))
+	}
+	if ed.Addenda99 != nil {
+		parts = append(parts, strings.TrimSpace(ed.Addenda99.ReturnCode+" "+ed.Addenda99.AddendaInformation))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,87 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achcsv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func TestWrite(t *testing.T) {
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &file); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+len(file.Batches[0].GetEntries()) {
+		t.Fatalf("got %d rows", len(rows))
+	}
+	ed := file.Batches[0].GetEntries()[0]
+	row := rows[1]
+	if row[8] != "231380104" || row[10] != "1000000.00" || row[13] != ed.TraceNumber {
+		t.Errorf("unexpected row: %v", row)
+	}
+
+	// the rows read back into the same entries
+	read, err := Read(&buf, file.Header, ExportMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ed := range file.Batches[0].GetEntries() {
+		if other := read.Batches[0].GetEntries()[i]; ed.String() != other.String() {
+			t.Errorf("entry %d:\n%s\n%s", i, ed.String(), other.String())
+		}
+	}
+	if file.Batches[0].GetHeader().String() != read.Batches[0].GetHeader().String() {
+		t.Errorf("BatchHeader:\n%s\n%s", file.Batches[0].GetHeader().String(), read.Batches[0].GetHeader().String())
+	}
+
+	if err := Write(&buf, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestWrite__addendaText(t *testing.T) {
+	ed := ach.NewEntryDetail()
+	addenda05 := ach.NewAddenda05()
+	addenda05.PaymentRelatedInformation = "Invoice 1 "
+	ed.AddAddenda05(addenda05)
+	ed.Addenda99 = ach.NewAddenda99()
+	ed.Addenda99.ReturnCode = "R01"
+	if text := addendaText(ed); text != "Invoice 1 R01" {
+		t.Errorf("got %q", text)
+	}
+}
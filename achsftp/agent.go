@@ -0,0 +1,396 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achsftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/server"
+	"github.com/ourly/base"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// dialTimeout is how long connecting to an ODFI's SFTP server may take
+const dialTimeout = 30 * time.Second
+
+// ErrUnknownODFI is returned for a routing number without an ODFIConfig
+var ErrUnknownODFI = errors.New("achsftp: unknown ODFI")
+
+// Agent uploads the pending files of a Repository to ODFIs and downloads their inbound files.
+// Files are pending until uploaded, files downloaded from an ODFI are never uploaded. Both are
+// recorded in the server.FileMetadata of the Repository so they survive restarts. An Agent is
+// safe for concurrent use.
+type Agent struct {
+	cfg    *Config
+	repo   server.Repository
+	logger log.Logger
+
+	mu      sync.Mutex
+	uploads bool
+}
+
+// NewAgent returns an Agent exchanging the files of repo with the ODFIs of cfg.
+func NewAgent(cfg *Config, repo server.Repository, logger log.Logger) (*Agent, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &Agent{
+		cfg:     cfg,
+		repo:    repo,
+		logger:  logger,
+		uploads: true,
+	}, nil
+}
+
+// session is a connection to an ODFI's SFTP server
+type session struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+func (s *session) Close() error {
+	s.Client.Close()
+	return s.conn.Close()
+}
+
+func (a *Agent) dial(odfi *ODFIConfig) (*session, error) {
+	var auth []ssh.AuthMethod
+	if odfi.PrivateKeyFile != "" {
+		bs, err := ioutil.ReadFile(odfi.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(bs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", odfi.PrivateKeyFile, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if odfi.Password != "" {
+		auth = append(auth, ssh.Password(odfi.Password))
+	}
+
+	conn, err := ssh.Dial("tcp", odfi.Address, &ssh.ClientConfig{
+		User:            odfi.Username,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(odfi.hostKey),
+		Timeout:         dialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("achsftp: connecting to %s: %v", odfi.Address, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("achsftp: starting SFTP with %s: %v", odfi.Address, err)
+	}
+	return &session{Client: client, conn: conn}, nil
+}
+
+func (a *Agent) odfi(routingNumber string) (*ODFIConfig, error) {
+	odfi, ok := a.cfg.ODFI(routingNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownODFI, routingNumber)
+	}
+	return odfi, nil
+}
+
+// Pending returns the files of the Repository destined for routingNumber which haven't been
+// uploaded, oldest first.
+func (a *Agent) Pending(routingNumber string) []*ach.File {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return server.PendingFiles(a.repo, routingNumber)
+}
+
+// Upload uploads the pending files of the ODFI with routingNumber and returns their remote
+// paths. Files which fail to upload stay pending.
+func (a *Agent) Upload(routingNumber string) ([]string, error) {
+	odfi, err := a.odfi(routingNumber)
+	if err != nil {
+		return nil, err
+	}
+	pending := a.Pending(routingNumber)
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	s, err := a.dial(odfi)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	var paths []string
+	var errs base.ErrorList
+	for _, file := range pending {
+		path, err := a.upload(s, odfi, file)
+		if err != nil {
+			errs.Add(fmt.Errorf("file %s: %v", file.ID, err))
+			continue
+		}
+		if err := a.repo.UpdateFileMetadata(file.ID, func(meta *server.FileMetadata) { meta.Sent = path }); err != nil {
+			errs.Add(fmt.Errorf("file %s: uploaded to %s: %v", file.ID, path, err))
+		}
+		paths = append(paths, path)
+	}
+	return paths, errs.Err()
+}
+
+// UploadFile uploads file to the ODFI with routingNumber, whether or not it is pending, and
+// returns its remote path. The upload isn't recorded in the Repository, callers mark the files
+// file was built from as sent.
+func (a *Agent) UploadFile(routingNumber string, file *ach.File) (string, error) {
+	odfi, err := a.odfi(routingNumber)
+	if err != nil {
+		return "", err
+	}
+	s, err := a.dial(odfi)
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+	return a.upload(s, odfi, file)
+}
+
+// upload writes file under a temporary name which is renamed once complete, so the ODFI
+// never picks up a partial file.
+func (a *Agent) upload(s *session, odfi *ODFIConfig, file *ach.File) (string, error) {
+	if err := file.Create(); err != nil {
+		return "", err
+	}
+	name, err := odfi.Filename(file)
+	if err != nil {
+		return "", err
+	}
+	path := pathJoin(odfi.OutboundPath, name)
+	partial := path + ".part"
+
+	fd, err := s.Create(partial)
+	if err != nil {
+		return "", err
+	}
+	if err := ach.NewWriter(fd).Write(file); err != nil {
+		fd.Close()
+		s.Remove(partial)
+		return "", err
+	}
+	if err := fd.Close(); err != nil {
+		s.Remove(partial)
+		return "", err
+	}
+	if err := s.Rename(partial, path); err != nil {
+		s.Remove(partial)
+		return "", fmt.Errorf("renaming %s: %v", partial, err)
+	}
+
+	a.logger.Log("sftp", "upload", "odfi", odfi.RoutingNumber, "fileID", file.ID, "path", path)
+	return path, nil
+}
+
+// Download reads each file in the inbound directory of the ODFI with routingNumber, moves it
+// into the archive directory and stores it in the Repository. Files which can't be parsed or
+// archived are left in place, without being stored, and returned as errors.
+func (a *Agent) Download(routingNumber string) ([]*ach.File, error) {
+	odfi, err := a.odfi(routingNumber)
+	if err != nil {
+		return nil, err
+	}
+	if odfi.InboundPath == "" {
+		return nil, nil
+	}
+	s, err := a.dial(odfi)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	infos, err := s.ReadDir(odfi.InboundPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", odfi.InboundPath, err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	var files []*ach.File
+	var errs base.ErrorList
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		path := pathJoin(odfi.InboundPath, info.Name())
+		file, err := a.download(s, path)
+		if err != nil {
+			errs.Add(fmt.Errorf("%s: %v", path, err))
+			continue
+		}
+		// archive before storing, a file left in the inbound directory is read again
+		archived := pathJoin(odfi.ArchivePath, info.Name())
+		if err := s.Rename(path, archived); err != nil {
+			errs.Add(fmt.Errorf("archiving %s: %v", path, err))
+			continue
+		}
+		if err := a.store(file); err != nil {
+			errs.Add(fmt.Errorf("storing %s: %v", archived, err))
+			continue
+		}
+		a.logger.Log("sftp", "download", "odfi", odfi.RoutingNumber, "fileID", file.ID, "path", path, "archived", archived)
+		files = append(files, file)
+	}
+	return files, errs.Err()
+}
+
+func (a *Agent) download(s *session, path string) (*ach.File, error) {
+	fd, err := s.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		return nil, err
+	}
+	file.ID = base.ID()
+	return &file, nil
+}
+
+// store records a downloaded file in the Repository as received
+func (a *Agent) store(file *ach.File) error {
+	// Pending holds mu, so it never sees the file before it's marked received
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.repo.StoreFile(file); err != nil {
+		return err
+	}
+	return a.repo.UpdateFileMetadata(file.ID, func(meta *server.FileMetadata) { meta.Received = true })
+}
+
+// SetUploads enables or disables uploading pending files in Sync, which is disabled when files
+// are released at cutoffs with UploadFile instead.
+func (a *Agent) SetUploads(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.uploads = enabled
+}
+
+// Sync uploads the pending files of every ODFI, unless disabled with SetUploads, and downloads
+// their inbound files.
+func (a *Agent) Sync() error {
+	var errs base.ErrorList
+	for _, odfi := range a.cfg.ODFIs {
+		if err := a.sync(odfi.RoutingNumber, true, true); err != nil {
+			errs.Add(err)
+		}
+	}
+	return errs.Err()
+}
+
+func (a *Agent) sync(routingNumber string, upload, download bool) error {
+	a.mu.Lock()
+	upload = upload && a.uploads
+	a.mu.Unlock()
+
+	var errs base.ErrorList
+	if upload {
+		if _, err := a.Upload(routingNumber); err != nil {
+			errs.Add(fmt.Errorf("ODFI %s: upload: %v", routingNumber, err))
+		}
+	}
+	if download {
+		if _, err := a.Download(routingNumber); err != nil {
+			errs.Add(fmt.Errorf("ODFI %s: download: %v", routingNumber, err))
+		}
+	}
+	return errs.Err()
+}
+
+// Run downloads inbound files every interval until ctx is done, logging any errors. Pending
+// files are uploaded at each cutoff of their ODFI, or every interval for ODFIs without cutoffs.
+func (a *Agent) Run(ctx context.Context, interval time.Duration) {
+	state := newRunState(a.cfg, time.Now())
+	for {
+		wake := a.tick(state, time.Now(), interval)
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// runState tracks when Run next syncs and the next cutoff of each ODFI with cutoffs
+type runState struct {
+	sync    time.Time
+	cutoffs map[string]time.Time
+}
+
+func newRunState(cfg *Config, now time.Time) *runState {
+	state := &runState{sync: now, cutoffs: make(map[string]time.Time)}
+	for i := range cfg.ODFIs {
+		if next, ok := cfg.ODFIs[i].NextCutoff(now); ok {
+			state.cutoffs[cfg.ODFIs[i].RoutingNumber] = next
+		}
+	}
+	return state
+}
+
+// tick syncs every ODFI which is due at now and returns when the next one is due
+func (a *Agent) tick(state *runState, now time.Time, interval time.Duration) time.Time {
+	syncing := !now.Before(state.sync)
+	if syncing {
+		state.sync = now.Add(interval)
+	}
+	for i := range a.cfg.ODFIs {
+		odfi := &a.cfg.ODFIs[i]
+		upload := syncing
+		if cutoff, ok := state.cutoffs[odfi.RoutingNumber]; ok {
+			upload = !now.Before(cutoff)
+			if upload {
+				state.cutoffs[odfi.RoutingNumber], _ = odfi.NextCutoff(now)
+			}
+		}
+		if !upload && !syncing {
+			continue
+		}
+		if err := a.sync(odfi.RoutingNumber, upload, syncing); err != nil {
+			a.logger.Log("sftp", "sync", "error", err)
+		}
+	}
+
+	wake := state.sync
+	for _, cutoff := range state.cutoffs {
+		if cutoff.Before(wake) {
+			wake = cutoff
+		}
+	}
+	return wake
+}
@@ -0,0 +1,437 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achsftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/server"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an in-process SFTP server serving the local filesystem
+type testServer struct {
+	addr    string
+	hostKey string
+	dir     string
+
+	listener net.Listener
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "ach" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	dir, err := ioutil.TempDir("", "achsftp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{"outbound", "inbound", "archive"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &testServer{
+		addr:     l.Addr().String(),
+		hostKey:  string(ssh.MarshalAuthorizedKey(signer.PublicKey())),
+		dir:      dir,
+		listener: l,
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn, cfg)
+		}
+	}()
+	return srv
+}
+
+func (srv *testServer) serve(conn net.Conn, cfg *ssh.ServerConfig) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for ch := range chans {
+		if ch.ChannelType() != "session" {
+			ch.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := ch.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// the payload is the length prefixed subsystem name
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					if s, err := sftp.NewServer(channel); err == nil {
+						s.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func (srv *testServer) Close() {
+	srv.listener.Close()
+	os.RemoveAll(srv.dir)
+}
+
+func (srv *testServer) config() *Config {
+	return &Config{
+		ODFIs: []ODFIConfig{{
+			RoutingNumber: "231380104",
+			Address:       srv.addr,
+			Username:      "ach",
+			Password:      "secret",
+			HostPublicKey: srv.hostKey,
+			OutboundPath:  filepath.Join(srv.dir, "outbound"),
+			InboundPath:   filepath.Join(srv.dir, "inbound"),
+			ArchivePath:   filepath.Join(srv.dir, "archive") + "/",
+		}},
+	}
+}
+
+func (srv *testServer) list(t *testing.T, sub string) []string {
+	t.Helper()
+	infos, err := ioutil.ReadDir(filepath.Join(srv.dir, sub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func storeFile(t *testing.T, repo server.Repository, id, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.ID = id
+	if err := repo.StoreFile(&file); err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestAgent__Upload(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	storeFile(t, repo, "ppd", "ppd-debit.ach")
+	storeFile(t, repo, "mixed", "ppd-mixedDebitCredit.ach")
+	storeFile(t, repo, "web", "web-debit.ach") // for another ODFI
+
+	agent, err := NewAgent(srv.config(), repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(agent.Pending("231380104")); n != 2 {
+		t.Fatalf("got %d pending files", n)
+	}
+
+	paths, err := agent.Upload("231380104")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("uploaded %v", paths)
+	}
+	expected := []string{"231380104-190624-0000-A.ach", "231380104-190718-1055-A.ach"}
+	if names := srv.list(t, "outbound"); strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected uploads: %v", names)
+	}
+	fd, err := os.Open(filepath.Join(srv.dir, "outbound", expected[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if _, err := ach.NewReader(fd).Read(); err != nil {
+		t.Errorf("uploaded file: %v", err)
+	}
+
+	// uploaded files are no longer pending, even for a restarted Agent
+	if paths, err := agent.Upload("231380104"); err != nil || len(paths) != 0 {
+		t.Errorf("uploaded %v again: %v", paths, err)
+	}
+	restarted, err := NewAgent(srv.config(), repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := restarted.Pending("231380104"); len(pending) != 0 {
+		t.Errorf("got %d pending files after restart", len(pending))
+	}
+	for _, file := range repo.FindAllFiles() {
+		meta, err := repo.FindFileMetadata(file.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Header.ImmediateDestination == "231380104" && !strings.HasPrefix(meta.Sent, filepath.Join(srv.dir, "outbound", "231380104-")) {
+			t.Errorf("file %s sent to %q", file.ID, meta.Sent)
+		}
+	}
+	if _, err := agent.Upload("031300012"); err == nil {
+		t.Error("expected error for an unknown ODFI")
+	}
+}
+
+func TestAgent__Download(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "return-WEB.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range map[string][]byte{"return.ach": bs, "broken.ach": []byte("101 short\n")} {
+		if err := ioutil.WriteFile(filepath.Join(srv.dir, "inbound", name), contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(srv.dir, "inbound", "subdirectory"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	agent, err := NewAgent(srv.config(), repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := agent.Download("231380104")
+	if err == nil || !strings.Contains(err.Error(), "broken.ach") {
+		t.Errorf("expected error for broken.ach: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("downloaded %d files", len(files))
+	}
+	if _, err := repo.FindFile(files[0].ID); err != nil {
+		t.Error(err)
+	}
+	if names := srv.list(t, "archive"); len(names) != 1 || names[0] != "return.ach" {
+		t.Errorf("unexpected archive: %v", names)
+	}
+	if names := srv.list(t, "inbound"); strings.Join(names, ",") != "broken.ach,subdirectory" {
+		t.Errorf("unexpected inbound: %v", names)
+	}
+
+	// downloaded files are never uploaded
+	files[0].Header.ImmediateDestination = "231380104"
+	if n := len(agent.Pending("231380104")); n != 0 {
+		t.Errorf("got %d pending files", n)
+	}
+}
+
+func TestAgent__DownloadArchiveError(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "return-WEB.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(srv.dir, "inbound", "return.ach"), bs, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// a non-empty directory of the same name can't be replaced by the rename
+	if err := os.MkdirAll(filepath.Join(srv.dir, "archive", "return.ach", "earlier"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	agent, err := NewAgent(srv.config(), repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		files, err := agent.Download("231380104")
+		if err == nil || !strings.Contains(err.Error(), "archiving") {
+			t.Errorf("expected archive error: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("downloaded %d files", len(files))
+		}
+	}
+	// the file is left in place and never stored
+	if n := len(repo.FindAllFiles()); n != 0 {
+		t.Errorf("stored %d files", n)
+	}
+	if names := srv.list(t, "inbound"); strings.Join(names, ",") != "return.ach" {
+		t.Errorf("unexpected inbound: %v", names)
+	}
+}
+
+func TestAgent__Sync(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	storeFile(t, repo, "ppd", "ppd-debit.ach")
+	agent, err := NewAgent(srv.config(), repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if names := srv.list(t, "outbound"); len(names) != 1 {
+		t.Errorf("unexpected uploads: %v", names)
+	}
+
+	storeFile(t, repo, "mixed", "ppd-mixedDebitCredit.ach")
+	agent.SetUploads(false)
+	if err := agent.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if names := srv.list(t, "outbound"); len(names) != 1 {
+		t.Errorf("unexpected uploads: %v", names)
+	}
+}
+
+func TestAgent__tick(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	storeFile(t, repo, "ppd", "ppd-debit.ach")
+	cfg := srv.config()
+	cfg.ODFIs[0].Cutoffs = []string{"10:30"}
+	agent, err := NewAgent(cfg, repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(v string) time.Time {
+		t.Helper()
+		when, err := time.Parse("2006-01-02 15:04", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return when
+	}
+	state := newRunState(agent.cfg, at("2019-06-03 09:00"))
+
+	// files are downloaded every interval but only uploaded at the cutoff
+	if wake := agent.tick(state, at("2019-06-03 09:00"), time.Hour); !wake.Equal(at("2019-06-03 10:00")) {
+		t.Errorf("woke at %v", wake)
+	}
+	if wake := agent.tick(state, at("2019-06-03 10:00"), time.Hour); !wake.Equal(at("2019-06-03 10:30")) {
+		t.Errorf("woke at %v", wake)
+	}
+	if names := srv.list(t, "outbound"); len(names) != 0 {
+		t.Errorf("uploaded before the cutoff: %v", names)
+	}
+	if wake := agent.tick(state, at("2019-06-03 10:30"), time.Hour); !wake.Equal(at("2019-06-03 11:00")) {
+		t.Errorf("woke at %v", wake)
+	}
+	if names := srv.list(t, "outbound"); len(names) != 1 {
+		t.Errorf("unexpected uploads: %v", names)
+	}
+	if next := state.cutoffs["231380104"]; !next.Equal(at("2019-06-04 10:30")) {
+		t.Errorf("next cutoff %v", next)
+	}
+}
+
+func TestAgent__ConnectionErrors(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	repo := server.NewRepositoryInMemory(0, nil)
+	file := storeFile(t, repo, "ppd", "ppd-debit.ach")
+
+	cfg := srv.config()
+	cfg.ODFIs[0].Password = "wrong"
+	agent, err := NewAgent(cfg, repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agent.UploadFile("231380104", file); err == nil {
+		t.Error("expected authentication error")
+	}
+
+	// a server with another host key
+	other := newTestServer(t)
+	defer other.Close()
+	cfg = srv.config()
+	cfg.ODFIs[0].HostPublicKey = other.hostKey
+	agent, err = NewAgent(cfg, repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agent.Upload("231380104"); err == nil || !strings.Contains(err.Error(), "host key") {
+		t.Errorf("expected host key error: %v", err)
+	}
+	if n := len(agent.Pending("231380104")); n != 1 {
+		t.Errorf("got %d pending files", n)
+	}
+}
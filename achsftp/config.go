@@ -0,0 +1,215 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achsftp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"text/template"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/internal/cutoffs"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultOutboundFilename is the template of uploaded file names when an ODFI has none
+const DefaultOutboundFilename = "{{ .RoutingNumber }}-{{ .FileCreationDate }}-{{ .FileCreationTime }}-{{ .FileIDModifier }}.ach"
+
+// Config is the SFTP configuration of every ODFI files are exchanged with
+type Config struct {
+	ODFIs []ODFIConfig `json:"odfis"`
+}
+
+// ODFIConfig describes how files are exchanged with one ODFI
+type ODFIConfig struct {
+	// RoutingNumber is the ImmediateDestination of files sent to the ODFI
+	RoutingNumber string `json:"routingNumber"`
+
+	// Address is the host:port of the ODFI's SFTP server
+	Address string `json:"address"`
+	// Username authenticates with Password or the key in PrivateKeyFile
+	Username       string `json:"username"`
+	Password       string `json:"password,omitempty"`
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+	// HostPublicKey is the server's public key in authorized_keys format, which the server
+	// must present when connecting
+	HostPublicKey string `json:"hostPublicKey"`
+
+	// OutboundPath is the remote directory files are uploaded to
+	OutboundPath string `json:"outboundPath"`
+	// OutboundFilename is a text/template of uploaded file names, see FilenameData
+	OutboundFilename string `json:"outboundFilename,omitempty"`
+	// InboundPath is the remote directory files are downloaded from
+	InboundPath string `json:"inboundPath,omitempty"`
+	// ArchivePath is the remote directory downloaded files are moved into
+	ArchivePath string `json:"archivePath,omitempty"`
+
+	// Cutoffs are the times of day, as "15:04", Agent.Run uploads pending files at. Files
+	// are uploaded every interval when empty.
+	Cutoffs []string `json:"cutoffs,omitempty"`
+	// Timezone of the Cutoffs, UTC when empty
+	Timezone string `json:"timezone,omitempty"`
+
+	filename *template.Template
+	hostKey  ssh.PublicKey
+	cutoffs  *cutoffs.Schedule
+}
+
+// FilenameData is the data OutboundFilename templates are executed with
+type FilenameData struct {
+	// RoutingNumber is the ImmediateDestination of the file
+	RoutingNumber string
+	// FileCreationDate as YYMMDD and FileCreationTime as HHmm from the FileHeader
+	FileCreationDate string
+	FileCreationTime string
+	// FileIDModifier from the FileHeader
+	FileIDModifier string
+	// ID of the file in the Repository
+	ID string
+}
+
+// ReadConfig reads a JSON Config from path and validates it.
+func ReadConfig(path string) (*Config, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(bs, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks every ODFIConfig and parses their templates and cutoffs.
+func (cfg *Config) Validate() error {
+	if len(cfg.ODFIs) == 0 {
+		return errors.New("no ODFIs configured")
+	}
+	seen := make(map[string]bool)
+	for i := range cfg.ODFIs {
+		odfi := &cfg.ODFIs[i]
+		if err := odfi.validate(); err != nil {
+			return fmt.Errorf("ODFI %s: %v", odfi.RoutingNumber, err)
+		}
+		if seen[odfi.RoutingNumber] {
+			return fmt.Errorf("ODFI %s: configured twice", odfi.RoutingNumber)
+		}
+		seen[odfi.RoutingNumber] = true
+	}
+	return nil
+}
+
+// ODFI returns the configuration of the ODFI with routingNumber
+func (cfg *Config) ODFI(routingNumber string) (*ODFIConfig, bool) {
+	for i := range cfg.ODFIs {
+		if cfg.ODFIs[i].RoutingNumber == routingNumber {
+			return &cfg.ODFIs[i], true
+		}
+	}
+	return nil, false
+}
+
+func (odfi *ODFIConfig) validate() error {
+	if len(odfi.RoutingNumber) != 9 {
+		return errors.New("routingNumber must be 9 digits")
+	}
+	if odfi.Address == "" || odfi.Username == "" {
+		return errors.New("missing address or username")
+	}
+	if odfi.Password == "" && odfi.PrivateKeyFile == "" {
+		return errors.New("missing password or privateKeyFile")
+	}
+	if odfi.HostPublicKey == "" {
+		return errors.New("missing hostPublicKey")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(odfi.HostPublicKey))
+	if err != nil {
+		return fmt.Errorf("hostPublicKey: %v", err)
+	}
+	odfi.hostKey = hostKey
+	if odfi.OutboundPath == "" {
+		return errors.New("missing outboundPath")
+	}
+	if odfi.InboundPath != "" && odfi.ArchivePath == "" {
+		return errors.New("inboundPath requires an archivePath")
+	}
+
+	tmpl := odfi.OutboundFilename
+	if tmpl == "" {
+		tmpl = DefaultOutboundFilename
+	}
+	filename, err := template.New(odfi.RoutingNumber).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("outboundFilename: %v", err)
+	}
+	odfi.filename = filename
+
+	location := time.UTC
+	if odfi.Timezone != "" {
+		if location, err = time.LoadLocation(odfi.Timezone); err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+	}
+	if odfi.cutoffs, err = cutoffs.Parse(odfi.Cutoffs, location); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Filename returns the name file is uploaded to the ODFI as
+func (odfi *ODFIConfig) Filename(file *ach.File) (string, error) {
+	if odfi.filename == nil {
+		if err := odfi.validate(); err != nil {
+			return "", err
+		}
+	}
+	var buf bytes.Buffer
+	err := odfi.filename.Execute(&buf, FilenameData{
+		RoutingNumber:    odfi.RoutingNumber,
+		FileCreationDate: file.Header.FileCreationDate,
+		FileCreationTime: file.Header.FileCreationTime,
+		FileIDModifier:   file.Header.FileIDModifier,
+		ID:               file.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NextCutoff returns the first cutoff after t, or false if the ODFI has no cutoffs
+func (odfi *ODFIConfig) NextCutoff(t time.Time) (time.Time, bool) {
+	return odfi.cutoffs.Next(t)
+}
+
+// pathJoin joins a remote directory and file name with forward slashes
+func pathJoin(dir, name string) string {
+	if dir == "" || dir[len(dir)-1] == '/' {
+		return dir + name
+	}
+	return dir + "/" + name
+}
@@ -0,0 +1,177 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package achsftp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// testHostPublicKey is the public key of an SSH server which is never dialed
+const testHostPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILNZ8ebB1006gU/iAg66P1PNRo+uHksuaJwxrGOB1l5U"
+
+func TestConfig__Read(t *testing.T) {
+	dir, err := ioutil.TempDir("", "achsftp-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sftp.json")
+	contents := `{"odfis": [{
+		"routingNumber": "231380104",
+		"address": "localhost:2222",
+		"username": "ach",
+		"password": "secret",
+		"hostPublicKey": "%s",
+		"outboundPath": "/upload",
+		"outboundFilename": "{{ .FileCreationDate }}-{{ .ID }}.ach",
+		"cutoffs": ["16:45", "10:30"],
+		"timezone": "America/New_York"
+	}]}`
+	contents = fmt.Sprintf(contents, testHostPublicKey)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	odfi, ok := cfg.ODFI("231380104")
+	if !ok {
+		t.Fatal("ODFI not found")
+	}
+
+	file := ach.NewFile()
+	file.ID = "abc"
+	file.Header.FileCreationDate = "191021"
+	name, err := odfi.Filename(file)
+	if err != nil || name != "191021-abc.ach" {
+		t.Errorf("got %q: %v", name, err)
+	}
+	if pathJoin(odfi.OutboundPath, name) != "/upload/191021-abc.ach" {
+		t.Errorf("unexpected path")
+	}
+
+	if _, ok := cfg.ODFI("031300012"); ok {
+		t.Error("unexpected ODFI")
+	}
+	if _, err := ReadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestConfig__Validate(t *testing.T) {
+	valid := func() ODFIConfig {
+		return ODFIConfig{
+			RoutingNumber: "231380104",
+			Address:       "localhost:22",
+			Username:      "ach",
+			Password:      "secret",
+			HostPublicKey: testHostPublicKey,
+			OutboundPath:  "/upload/",
+		}
+	}
+	cfg := &Config{ODFIs: []ODFIConfig{valid()}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func(*ODFIConfig){
+		"routingNumber":         func(o *ODFIConfig) { o.RoutingNumber = "1234" },
+		"username":              func(o *ODFIConfig) { o.Username = "" },
+		"password":              func(o *ODFIConfig) { o.Password = "" },
+		"missing hostPublicKey": func(o *ODFIConfig) { o.HostPublicKey = "" },
+		"hostPublicKey:":        func(o *ODFIConfig) { o.HostPublicKey = "ssh-rsa invalid" },
+		"outboundPath":          func(o *ODFIConfig) { o.OutboundPath = "" },
+		"archivePath":           func(o *ODFIConfig) { o.InboundPath = "/download" },
+		"outboundFilename":      func(o *ODFIConfig) { o.OutboundFilename = "{{ .Missing" },
+		"timezone":              func(o *ODFIConfig) { o.Timezone = "Mars/Olympus_Mons" },
+		"15:04":                 func(o *ODFIConfig) { o.Cutoffs = []string{"4pm"} },
+	}
+	for expected, modify := range cases {
+		odfi := valid()
+		modify(&odfi)
+		cfg := &Config{ODFIs: []ODFIConfig{odfi}}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: unexpected error: %v", expected, err)
+		}
+	}
+
+	if err := (&Config{}).Validate(); err == nil {
+		t.Error("expected error without ODFIs")
+	}
+	if err := (&Config{ODFIs: []ODFIConfig{valid(), valid()}}).Validate(); err == nil {
+		t.Error("expected error for a duplicate ODFI")
+	}
+
+	// unknown template fields are reported when executed
+	odfi := valid()
+	odfi.OutboundFilename = "{{ .Unknown }}.ach"
+	if _, err := odfi.Filename(ach.NewFile()); err == nil {
+		t.Error("expected template error")
+	}
+}
+
+func TestConfig__NextCutoff(t *testing.T) {
+	odfi := ODFIConfig{
+		RoutingNumber: "231380104",
+		Address:       "localhost:22",
+		Username:      "ach",
+		Password:      "secret",
+		HostPublicKey: testHostPublicKey,
+		OutboundPath:  "/upload/",
+		Cutoffs:       []string{"16:45", "10:30"},
+		Timezone:      "America/New_York",
+	}
+	if err := odfi.validate(); err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+
+	cases := []struct {
+		now, expected time.Time
+	}{
+		{time.Date(2019, 10, 21, 8, 0, 0, 0, ny), time.Date(2019, 10, 21, 10, 30, 0, 0, ny)},
+		{time.Date(2019, 10, 21, 10, 30, 0, 0, ny), time.Date(2019, 10, 21, 16, 45, 0, 0, ny)},
+		{time.Date(2019, 10, 21, 22, 0, 0, 0, time.UTC), time.Date(2019, 10, 22, 10, 30, 0, 0, ny)},
+		// daylight saving time ends on November 3rd
+		{time.Date(2019, 11, 2, 20, 0, 0, 0, ny), time.Date(2019, 11, 3, 10, 30, 0, 0, ny)},
+	}
+	for _, tc := range cases {
+		next, ok := odfi.NextCutoff(tc.now)
+		if !ok || !next.Equal(tc.expected) {
+			t.Errorf("%v: got %v, expected %v", tc.now, next, tc.expected)
+		}
+	}
+
+	odfi.Cutoffs = nil
+	if err := odfi.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := odfi.NextCutoff(time.Now()); ok {
+		t.Error("expected no cutoff")
+	}
+}
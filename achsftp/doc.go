@@ -0,0 +1,54 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package achsftp exchanges ACH files with ODFIs over SFTP.
+//
+// An Agent uploads the pending files of a server.Repository to each configured ODFI and
+// downloads inbound files such as returns and NOCs, which are parsed, moved into an archive
+// directory on the ODFI's server and then stored in the Repository. Both are recorded in
+// the server.FileMetadata of each file, so a restarted Agent doesn't upload files again.
+//
+//     cfg, err := achsftp.ReadConfig("sftp.json")
+//     if err != nil {
+//         log.Fatal(err)
+//     }
+//     agent, err := achsftp.NewAgent(cfg, repo, logger)
+//     if err != nil {
+//         log.Fatal(err)
+//     }
+//     go agent.Run(ctx, 10*time.Minute)
+//
+// Run downloads inbound files every interval and uploads pending files at the cutoffs of each
+// ODFI, or every interval for ODFIs without cutoffs. The configuration is JSON with one entry
+// for every ODFI:
+//
+//     {
+//       "odfis": [{
+//         "routingNumber": "231380104",
+//         "address": "sftp.odfi.example.com:22",
+//         "username": "originator",
+//         "privateKeyFile": "/etc/ach/id_rsa",
+//         "hostPublicKey": "ssh-rsa AAAA...",
+//         "outboundPath": "/inbound/",
+//         "outboundFilename": "{{ .RoutingNumber }}-{{ .FileCreationDate }}-{{ .FileIDModifier }}.ach",
+//         "inboundPath": "/outbound/",
+//         "archivePath": "/outbound/archive/",
+//         "cutoffs": ["10:30", "14:45", "16:45", "20:00"],
+//         "timezone": "America/New_York"
+//       }]
+//     }
+package achsftp
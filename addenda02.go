@@ -18,8 +18,12 @@
 package ach
 
 import (
+	"fmt"
 	"strings"
+	"time"
 	"unicode/utf8"
+
+	"github.com/ourly/ach/internal/usabbrev"
 )
 
 // Addenda02 is a Addendumer addenda which provides business transaction information for Addenda Type
@@ -180,6 +184,9 @@ func (addenda02 *Addenda02) Validate() error {
 	if err := addenda02.isAlphanumeric(addenda02.TerminalState); err != nil {
 		return fieldError("TerminalState", err, addenda02.TerminalState)
 	}
+	if !usabbrev.Valid(strings.TrimSpace(addenda02.TerminalState)) {
+		return fieldError("TerminalState", ErrValidState, addenda02.TerminalState)
+	}
 	return nil
 }
 
@@ -222,7 +229,7 @@ func (addenda02 *Addenda02) ReferenceInformationOneField() string {
 
 // ReferenceInformationTwoField returns a space padded ReferenceInformationTwo string
 func (addenda02 *Addenda02) ReferenceInformationTwoField() string {
-	return addenda02.alphaField(addenda02.ReferenceInformationOne, 3)
+	return addenda02.alphaField(addenda02.ReferenceInformationTwo, 3)
 }
 
 // TerminalIdentificationCodeField returns a space padded TerminalIdentificationCode string
@@ -264,3 +271,110 @@ func (addenda02 *Addenda02) TerminalStateField() string {
 func (addenda02 *Addenda02) TraceNumberField() string {
 	return addenda02.stringField(addenda02.TraceNumber, 15)
 }
+
+// TransactionDateTime returns TransactionDate resolved to a full date. TransactionDate only
+// holds MMDD, so the year is the latest one which places the transaction on or before
+// effectiveEntryDate (usually the batch's EffectiveEntryDate).
+func (addenda02 *Addenda02) TransactionDateTime(effectiveEntryDate time.Time) (time.Time, error) {
+	mmdd := addenda02.TransactionDateField()
+	if _, err := time.Parse("0102", mmdd); err != nil {
+		return time.Time{}, fieldError("TransactionDate", ErrValidDate, addenda02.TransactionDate)
+	}
+	effective := time.Date(effectiveEntryDate.Year(), effectiveEntryDate.Month(), effectiveEntryDate.Day(), 0, 0, 0, 0, effectiveEntryDate.Location())
+	// February 29th can be up to eight years back (e.g. 1896 to 1904)
+	for year := effective.Year(); year >= effective.Year()-8; year-- {
+		t, err := time.ParseInLocation("20060102", fmt.Sprintf("%04d%s", year, mmdd), effective.Location())
+		if err == nil && !t.After(effective) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fieldError("TransactionDate", ErrValidDate, addenda02.TransactionDate)
+}
+
+// SetTransactionDate writes the month and day of t as TransactionDate (MMDD).
+func (addenda02 *Addenda02) SetTransactionDate(t time.Time) {
+	addenda02.TransactionDate = t.Format("0102")
+}
+
+// AuthorizationCode returns AuthorizationCodeOrExpireDate as the code a card authorization
+// center furnished to the merchant.
+func (addenda02 *Addenda02) AuthorizationCode() string {
+	return strings.TrimSpace(addenda02.AuthorizationCodeOrExpireDate)
+}
+
+// CardExpirationDate returns AuthorizationCodeOrExpireDate read as a card expiration date
+// (MMYY). The returned time is the first day of the month the card expires.
+func (addenda02 *Addenda02) CardExpirationDate() (time.Time, error) {
+	t, err := time.Parse("0106", strings.TrimSpace(addenda02.AuthorizationCodeOrExpireDate))
+	if err != nil {
+		return time.Time{}, fieldError("AuthorizationCodeOrExpireDate", ErrValidDate, addenda02.AuthorizationCodeOrExpireDate)
+	}
+	return t, nil
+}
+
+// SetCardExpirationDate writes the month and year of t as AuthorizationCodeOrExpireDate (MMYY).
+func (addenda02 *Addenda02) SetCardExpirationDate(t time.Time) {
+	addenda02.AuthorizationCodeOrExpireDate = t.Format("0106")
+}
+
+// Terminal describes the electronic terminal where a card transaction was originated.
+type Terminal struct {
+	// IdentificationCode uniquely identifies the terminal, up to 6 characters.
+	IdentificationCode string `json:"identificationCode"`
+	// Location is the street address or intersection of the terminal, up to 27 characters.
+	Location string `json:"location"`
+	// City of the terminal, up to 15 characters.
+	City string `json:"city"`
+	// State is the two letter US state or territory of the terminal.
+	State string `json:"state"`
+}
+
+// Validate checks the Terminal fits in an Addenda02 record and its State is a US state or territory.
+func (t Terminal) Validate() error {
+	v := new(validator)
+	fields := []struct {
+		name, value string
+		max         int
+	}{
+		{"IdentificationCode", t.IdentificationCode, 6},
+		{"Location", t.Location, 27},
+		{"City", t.City, 15},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			return fieldError("Terminal"+f.name, ErrFieldRequired)
+		}
+		if len(f.value) > f.max {
+			return fieldError("Terminal"+f.name, NewErrValidFieldLength(f.max), f.value)
+		}
+		if err := v.isAlphanumeric(f.value); err != nil {
+			return fieldError("Terminal"+f.name, err, f.value)
+		}
+	}
+	if !usabbrev.Valid(t.State) {
+		return fieldError("TerminalState", ErrValidState, t.State)
+	}
+	return nil
+}
+
+// Terminal returns the terminal fields of the Addenda02.
+func (addenda02 *Addenda02) Terminal() Terminal {
+	return Terminal{
+		IdentificationCode: strings.TrimSpace(addenda02.TerminalIdentificationCode),
+		Location:           strings.TrimSpace(addenda02.TerminalLocation),
+		City:               strings.TrimSpace(addenda02.TerminalCity),
+		State:              strings.TrimSpace(addenda02.TerminalState),
+	}
+}
+
+// SetTerminal validates t and writes it into the Addenda02's terminal fields.
+func (addenda02 *Addenda02) SetTerminal(t Terminal) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	addenda02.TerminalIdentificationCode = t.IdentificationCode
+	addenda02.TerminalLocation = t.Location
+	addenda02.TerminalCity = t.City
+	addenda02.TerminalState = t.State
+	return nil
+}
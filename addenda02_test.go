@@ -18,7 +18,9 @@
 package ach
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/ourly/base"
 )
@@ -651,6 +653,29 @@ func BenchmarkTerminalStateAlphaNumeric(b *testing.B) {
 	}
 }
 
+// testTerminalStateValid validates TerminalState is a US state or territory
+func testTerminalStateValid(t testing.TB) {
+	addenda02 := mockAddenda02()
+	addenda02.TerminalState = "ZZ"
+	err := addenda02.Validate()
+	if !base.Match(err, ErrValidState) {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestTerminalStateValid tests validating TerminalState is a US state or territory
+func TestTerminalStateValid(t *testing.T) {
+	testTerminalStateValid(t)
+}
+
+// BenchmarkTerminalStateValid benchmarks validating TerminalState is a US state or territory
+func BenchmarkTerminalStateValid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		testTerminalStateValid(b)
+	}
+}
+
 // TestAddenda02RuneCountInString validates RuneCountInString
 func TestAddenda02RuneCountInString(t *testing.T) {
 	addenda02 := NewAddenda02()
@@ -661,3 +686,99 @@ func TestAddenda02RuneCountInString(t *testing.T) {
 		t.Error("Parsed with an invalid RuneCountInString not equal to 94")
 	}
 }
+
+func TestAddenda02__ReferenceInformationTwoField(t *testing.T) {
+	addenda02 := mockAddenda02()
+	addenda02.ReferenceInformationTwo = "XYZ"
+	if v := addenda02.ReferenceInformationTwoField(); v != "XYZ" {
+		t.Errorf("ReferenceInformationTwoField=%q", v)
+	}
+}
+
+func TestAddenda02__TransactionDateTime(t *testing.T) {
+	cases := []struct {
+		mmdd      string
+		effective time.Time
+		expected  time.Time
+	}{
+		{"0612", time.Date(2019, time.June, 14, 0, 0, 0, 0, time.UTC), time.Date(2019, time.June, 12, 0, 0, 0, 0, time.UTC)},
+		{"0614", time.Date(2019, time.June, 14, 10, 30, 0, 0, time.UTC), time.Date(2019, time.June, 14, 0, 0, 0, 0, time.UTC)},
+		// transactions settle after they occur, so December is last year's
+		{"1231", time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC), time.Date(2019, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{"0229", time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0229", time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		addenda02 := mockAddenda02()
+		addenda02.TransactionDate = tc.mmdd
+		got, err := addenda02.TransactionDateTime(tc.effective)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.mmdd, err)
+		}
+		if !got.Equal(tc.expected) {
+			t.Errorf("%s on %s: got %s", tc.mmdd, tc.effective, got)
+		}
+	}
+
+	addenda02 := mockAddenda02()
+	addenda02.TransactionDate = "1332"
+	if _, err := addenda02.TransactionDateTime(time.Now()); !errors.Is(err, ErrValidDate) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	addenda02.SetTransactionDate(time.Date(2019, time.July, 4, 0, 0, 0, 0, time.UTC))
+	if addenda02.TransactionDate != "0704" {
+		t.Errorf("TransactionDate=%s", addenda02.TransactionDate)
+	}
+}
+
+func TestAddenda02__AuthorizationCodeOrExpireDate(t *testing.T) {
+	addenda02 := mockAddenda02()
+	if v := addenda02.AuthorizationCode(); v != "123456" {
+		t.Errorf("AuthorizationCode=%s", v)
+	}
+	if _, err := addenda02.CardExpirationDate(); !errors.Is(err, ErrValidDate) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	addenda02.SetCardExpirationDate(time.Date(2022, time.July, 31, 0, 0, 0, 0, time.UTC))
+	if addenda02.AuthorizationCodeOrExpireDate != "0722" {
+		t.Errorf("AuthorizationCodeOrExpireDate=%s", addenda02.AuthorizationCodeOrExpireDate)
+	}
+	exp, err := addenda02.CardExpirationDate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Year() != 2022 || exp.Month() != time.July {
+		t.Errorf("CardExpirationDate=%s", exp)
+	}
+}
+
+func TestAddenda02__Terminal(t *testing.T) {
+	addenda02 := mockAddenda02()
+	terminal := addenda02.Terminal()
+	expected := Terminal{IdentificationCode: "TERM02", Location: "Target Store 0049", City: "PHILADELPHIA", State: "PA"}
+	if terminal != expected {
+		t.Errorf("Terminal=%#v", terminal)
+	}
+
+	terminal.State = "ZZ"
+	if err := addenda02.SetTerminal(terminal); !errors.Is(err, ErrValidState) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	terminal.State = "NJ"
+	terminal.City = "A City Name Too Long"
+	if err := addenda02.SetTerminal(terminal); err == nil {
+		t.Error("expected error")
+	}
+	terminal.City = "CAMDEN"
+	if err := addenda02.SetTerminal(terminal); err != nil {
+		t.Fatal(err)
+	}
+	if addenda02.TerminalCity != "CAMDEN" || addenda02.TerminalState != "NJ" {
+		t.Errorf("TerminalCity=%s TerminalState=%s", addenda02.TerminalCity, addenda02.TerminalState)
+	}
+	if err := (Terminal{}).Validate(); !errors.Is(err, ErrFieldRequired) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// advMaxBatchEntries is the largest ADV SequenceNumber and therefore the most
+	// ADV Entry Detail Records a single batch can hold.
+	advMaxBatchEntries = 9999
+	// advMaxAmount is the largest amount the 12 digit ADVEntryDetail Amount can hold
+	advMaxAmount = 999999999999
+)
+
+var (
+	// ErrADVPostingTransactionCode is returned when an ADVPosting's TransactionCode
+	// isn't an ADV code or disagrees with Debit
+	ErrADVPostingTransactionCode = errors.New("transaction code is not an ADV accounting entry in the posting's direction")
+	// ErrADVNoPostings is returned when an ADV file is built without any postings
+	ErrADVNoPostings = errors.New("an ADV file requires at least one posting")
+)
+
+// ADVPosting is a single settlement posting (for example a line from a settlement
+// report) which is reported as an Automated Accounting Advice (ADV) entry.
+type ADVPosting struct {
+	// RoutingNumber is the 9 digit routing number of the DFI whose account was posted
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is the posted account
+	AccountNumber string `json:"accountNumber"`
+	// Name is the account holder or a description of the posting
+	Name string `json:"name"`
+	// Amount of the posting in cents
+	Amount int `json:"amount"`
+	// Debit is true when the account was debited and false when it was credited
+	Debit bool `json:"debit"`
+	// TransactionCode is the ADV accounting entry code (81 through 88). When zero
+	// CreditSummary or DebitSummary is used.
+	TransactionCode int `json:"transactionCode,omitempty"`
+	// Date the posting settled. Postings are batched by Date.
+	Date time.Time `json:"date"`
+	// FileIdentification optionally identifies the file the posting settled from
+	FileIdentification string `json:"fileIdentification,omitempty"`
+}
+
+// transactionCode returns the ADV transaction code of the posting
+func (p *ADVPosting) transactionCode() int {
+	if p.TransactionCode != 0 {
+		return p.TransactionCode
+	}
+	if p.Debit {
+		return DebitSummary
+	}
+	return CreditSummary
+}
+
+// Validate checks the ADVPosting has the information required to build an ADVEntryDetail
+func (p *ADVPosting) Validate() error {
+	if err := CheckRoutingNumber(p.RoutingNumber); err != nil {
+		return fieldError("RoutingNumber", err, p.RoutingNumber)
+	}
+	if p.AccountNumber == "" {
+		return fieldError("AccountNumber", ErrFieldRequired)
+	}
+	if p.Name == "" {
+		return fieldError("Name", ErrFieldRequired)
+	}
+	if p.Amount < 0 {
+		return fieldError("Amount", ErrNegativeAmount, p.Amount)
+	}
+	if p.Amount > advMaxAmount {
+		return fieldError("Amount", NewErrValidFieldLength(12), p.Amount)
+	}
+	if p.Date.IsZero() {
+		return fieldError("Date", ErrFieldRequired)
+	}
+	switch code := p.transactionCode(); code {
+	case CreditForDebitsOriginated, CreditForCreditsReceived, CreditForCreditsRejected, CreditSummary:
+		if p.Debit {
+			return fieldError("TransactionCode", ErrADVPostingTransactionCode, code)
+		}
+	case DebitForCreditsOriginated, DebitForDebitsReceived, DebitForDebitsRejectedBatches, DebitSummary:
+		if !p.Debit {
+			return fieldError("TransactionCode", ErrADVPostingTransactionCode, code)
+		}
+	default:
+		return fieldError("TransactionCode", ErrADVPostingTransactionCode, code)
+	}
+	return nil
+}
+
+// ADVEntryDetail returns an ADVEntryDetail for the posting. The ACHOperatorRoutingNumber
+// is the 8 digit identification of the ACH Operator and adviceRoutingNumber is the routing
+// number of the DFI receiving the advice. The SequenceNumber is set by the batch.
+func (p *ADVPosting) ADVEntryDetail(achOperatorRoutingNumber, adviceRoutingNumber string) (*ADVEntryDetail, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	ed := NewADVEntryDetail()
+	ed.TransactionCode = p.transactionCode()
+	ed.SetRDFI(p.RoutingNumber)
+	ed.DFIAccountNumber = p.AccountNumber
+	ed.Amount = p.Amount
+	ed.AdviceRoutingNumber = adviceRoutingNumber
+	ed.FileIdentification = p.FileIdentification
+	ed.IndividualName = p.Name
+	ed.ACHOperatorRoutingNumber = achOperatorRoutingNumber
+	ed.JulianDay = p.Date.YearDay()
+	return ed, nil
+}
+
+// NewADVFileFromPostings builds a complete ADV File reporting each posting.
+//
+// The FileHeader identifies the ACH Operator (ImmediateOrigin) and the DFI receiving
+// the advices (ImmediateDestination). ImmediateOrigin provides each entry's
+// ACHOperatorRoutingNumber and ImmediateDestination its AdviceRoutingNumber.
+//
+// A batch is created from a copy of bh for each posting date (in ascending order) with
+// the posting date as EffectiveEntryDate. Batches are split when they reach the maximum
+// number of ADV entries. Create() is called on each batch and the File.
+func NewADVFileFromPostings(fh FileHeader, bh *BatchHeader, postings []*ADVPosting) (*File, error) {
+	if len(postings) == 0 {
+		return nil, ErrADVNoPostings
+	}
+	if bh == nil {
+		return nil, errors.New("nil BatchHeader")
+	}
+	operator := strings.TrimSpace(fh.ImmediateOrigin)
+	if len(operator) == 10 {
+		operator = operator[1:]
+	}
+	if err := CheckRoutingNumber(operator); err != nil {
+		return nil, fieldError("ImmediateOrigin", err, fh.ImmediateOrigin)
+	}
+	advice := strings.TrimSpace(fh.ImmediateDestination)
+	if err := CheckRoutingNumber(advice); err != nil {
+		return nil, fieldError("ImmediateDestination", err, fh.ImmediateDestination)
+	}
+
+	// group entries by posting date
+	byDate := make(map[string][]*ADVEntryDetail)
+	for i, p := range postings {
+		if p == nil {
+			return nil, fmt.Errorf("posting %d: nil ADVPosting", i)
+		}
+		ed, err := p.ADVEntryDetail(operator[:8], advice)
+		if err != nil {
+			return nil, fmt.Errorf("posting %d: %w", i, err)
+		}
+		date := p.Date.Format("060102")
+		byDate[date] = append(byDate[date], ed)
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	file := NewFile()
+	file.SetHeader(fh)
+	for _, date := range dates {
+		entries := byDate[date]
+		for len(entries) > 0 {
+			n := len(entries)
+			if n > advMaxBatchEntries {
+				n = advMaxBatchEntries
+			}
+			header := *bh
+			header.ServiceClassCode = AutomatedAccountingAdvices
+			header.StandardEntryClassCode = ADV
+			header.OriginatorStatusCode = 0
+			header.EffectiveEntryDate = date
+
+			batch := NewBatchADV(&header)
+			for _, ed := range entries[:n] {
+				batch.AddADVEntry(ed)
+			}
+			if err := batch.Create(); err != nil {
+				return nil, fmt.Errorf("batch %d: %w", len(file.Batches)+1, err)
+			}
+			file.AddBatch(batch)
+			entries = entries[n:]
+		}
+	}
+	if err := file.Create(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
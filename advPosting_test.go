@@ -0,0 +1,177 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockADVPostings creates credit and debit postings settled over two days
+func mockADVPostings() []*ADVPosting {
+	day1 := time.Date(2019, time.February, 19, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	return []*ADVPosting{
+		{RoutingNumber: "231380104", AccountNumber: "744-5678-99", Name: "Settlement", Amount: 50000, Date: day2},
+		{RoutingNumber: "231380104", AccountNumber: "744-5678-99", Name: "Settlement", Amount: 12500, Debit: true, Date: day1},
+		{RoutingNumber: "121042882", AccountNumber: "123456", Name: "Received", Amount: 100, TransactionCode: CreditForCreditsReceived, Date: day1, FileIdentification: "11131"},
+	}
+}
+
+func TestADVPosting__Validate(t *testing.T) {
+	cases := []struct {
+		field  string
+		modify func(p *ADVPosting)
+		err    error
+	}{
+		{"RoutingNumber", func(p *ADVPosting) { p.RoutingNumber = "231380105" }, nil},
+		{"AccountNumber", func(p *ADVPosting) { p.AccountNumber = "" }, ErrFieldRequired},
+		{"Name", func(p *ADVPosting) { p.Name = "" }, ErrFieldRequired},
+		{"Amount", func(p *ADVPosting) { p.Amount = -1 }, ErrNegativeAmount},
+		{"Amount", func(p *ADVPosting) { p.Amount = advMaxAmount + 1 }, nil},
+		{"Date", func(p *ADVPosting) { p.Date = time.Time{} }, ErrFieldRequired},
+		{"TransactionCode", func(p *ADVPosting) { p.TransactionCode = CheckingCredit }, ErrADVPostingTransactionCode},
+		{"TransactionCode", func(p *ADVPosting) { p.TransactionCode = DebitForDebitsReceived }, ErrADVPostingTransactionCode},
+		{"TransactionCode", func(p *ADVPosting) { p.Debit, p.TransactionCode = true, CreditSummary }, ErrADVPostingTransactionCode},
+	}
+	for _, tc := range cases {
+		p := mockADVPostings()[0]
+		tc.modify(p)
+		err := p.Validate()
+		if err == nil || !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("%s: unexpected error: %v", tc.field, err)
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.field, err)
+		}
+	}
+}
+
+func TestADVPosting__ADVEntryDetail(t *testing.T) {
+	ed, err := mockADVPostings()[1].ADVEntryDetail("12104288", "231380104")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.TransactionCode != DebitSummary || ed.JulianDay != 50 || ed.RDFIIdentification != "23138010" || ed.CheckDigit != "4" {
+		t.Errorf("unexpected entry: %#v", ed)
+	}
+	ed.SequenceNumber = 1
+	if err := ed.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewADVFileFromPostings(t *testing.T) {
+	file, err := NewADVFileFromPostings(mockFileHeader(), mockBatchADVHeader(), mockADVPostings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !file.IsADV() || len(file.Batches) != 2 {
+		t.Fatalf("IsADV=%v batches=%d", file.IsADV(), len(file.Batches))
+	}
+
+	// the earliest posting date is the first batch
+	first, second := file.Batches[0], file.Batches[1]
+	if first.GetHeader().EffectiveEntryDate != "190219" || second.GetHeader().EffectiveEntryDate != "190220" {
+		t.Errorf("EffectiveEntryDate=%s,%s", first.GetHeader().EffectiveEntryDate, second.GetHeader().EffectiveEntryDate)
+	}
+	entries := first.GetADVEntries()
+	if len(entries) != 2 || entries[0].SequenceNumber != 1 || entries[1].SequenceNumber != 2 {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	if entries[1].JulianDay != 50 || entries[1].ACHOperatorRoutingNumber != "12104288" || entries[1].AdviceRoutingNumber != "231380104" {
+		t.Errorf("unexpected entry: %#v", entries[1])
+	}
+	if ctrl := first.GetADVControl(); ctrl.TotalDebitEntryDollarAmount != 12500 || ctrl.TotalCreditEntryDollarAmount != 100 {
+		t.Errorf("debit=%d credit=%d", ctrl.TotalDebitEntryDollarAmount, ctrl.TotalCreditEntryDollarAmount)
+	}
+	if file.ADVControl.BatchCount != 2 || file.ADVControl.EntryAddendaCount != 3 || file.ADVControl.TotalCreditEntryDollarAmountInFile != 50100 {
+		t.Errorf("unexpected ADVFileControl: %#v", file.ADVControl)
+	}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip through the Writer and Reader
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := NewReader(strings.NewReader(buf.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := read.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := NewWriter(&out).Write(&read); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != out.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", buf.String(), out.String())
+	}
+}
+
+func TestNewADVFileFromPostings__split(t *testing.T) {
+	var postings []*ADVPosting
+	for i := 0; i < advMaxBatchEntries+2; i++ {
+		postings = append(postings, mockADVPostings()[0])
+	}
+	file, err := NewADVFileFromPostings(mockFileHeader(), mockBatchADVHeader(), postings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Batches) != 2 {
+		t.Fatalf("got %d batches", len(file.Batches))
+	}
+	entries := file.Batches[0].GetADVEntries()
+	if len(entries) != advMaxBatchEntries || entries[advMaxBatchEntries-1].SequenceNumber != 9999 {
+		t.Errorf("first batch has %d entries", len(entries))
+	}
+	if n := len(file.Batches[1].GetADVEntries()); n != 2 {
+		t.Errorf("second batch has %d entries", n)
+	}
+	if file.Batches[1].GetHeader().BatchNumber != 2 || file.ADVControl.EntryAddendaCount != advMaxBatchEntries+2 {
+		t.Errorf("BatchNumber=%d EntryAddendaCount=%d", file.Batches[1].GetHeader().BatchNumber, file.ADVControl.EntryAddendaCount)
+	}
+}
+
+func TestNewADVFileFromPostings__errors(t *testing.T) {
+	if _, err := NewADVFileFromPostings(mockFileHeader(), mockBatchADVHeader(), nil); err != ErrADVNoPostings {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewADVFileFromPostings(mockFileHeader(), nil, mockADVPostings()); err == nil {
+		t.Error("expected error")
+	}
+
+	fh := mockFileHeader()
+	fh.ImmediateOrigin = "12104288"
+	if _, err := NewADVFileFromPostings(fh, mockBatchADVHeader(), mockADVPostings()); err == nil || !strings.Contains(err.Error(), "ImmediateOrigin") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	postings := mockADVPostings()
+	postings[2].AccountNumber = ""
+	if _, err := NewADVFileFromPostings(mockFileHeader(), mockBatchADVHeader(), postings); !errors.Is(err, ErrFieldRequired) || !strings.Contains(err.Error(), "posting 2") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
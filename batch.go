@@ -410,14 +410,14 @@ func (batch *Batch) build() error {
 			if entry.Addenda99 != nil {
 				entryCount++
 			}
+			if seq > 9999 {
+				return batch.Error("SequenceNumber", ErrBatchADVCount)
+			}
+
 			// Set Sequence Number
 			batch.ADVEntries[i].SequenceNumber = seq
 
 			seq++
-
-			if seq > 9999 {
-				return batch.Error("SequenceNumber", ErrBatchADVCount)
-			}
 		}
 		// build a BatchADVControl record
 		bcADV := NewADVBatchControl()
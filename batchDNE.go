@@ -18,7 +18,10 @@
 package ach
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // BatchDNE is a batch file that handles SEC code Death Notification Entry (DNE)
@@ -93,7 +96,7 @@ func (batch *BatchDNE) Create() error {
 	return batch.Validate()
 }
 
-// details returns the Date of Death (YYMMDD), Customer SSN (9 digits), and Amount ($$$$.cc)
+// details returns the Date of Death (MMDDYY), Customer SSN (9 digits), and Amount ($$$$.cc)
 // from the Addenda05 record. This method assumes the addenda05 PaymentRelatedInformation is valid.
 func (batch *BatchDNE) details() (string, string, string) {
 	if batch == nil || len(batch.Entries) == 0 {
@@ -109,7 +112,7 @@ func (batch *BatchDNE) details() (string, string, string) {
 	return line[18:24], line[37:46], strings.TrimSuffix(line[54:], `\`)
 }
 
-// DateOfDeath returns the YYMMDD string from Addenda05's PaymentRelatedInformation
+// DateOfDeath returns the MMDDYY string from Addenda05's PaymentRelatedInformation
 func (batch *BatchDNE) DateOfDeath() string {
 	date, _, _ := batch.details()
 	return date
@@ -126,3 +129,107 @@ func (batch *BatchDNE) Amount() string {
 	_, _, amount := batch.details()
 	return amount
 }
+
+// dneDateFormat is the MMDDYY layout of the date of death in a DNE Addenda05
+const dneDateFormat = "010206"
+
+// DNEDetails holds the typed contents of a DNE Addenda05's PaymentRelatedInformation.
+type DNEDetails struct {
+	// DateOfDeath is the date the beneficiary died. Only the year, month and day are written.
+	DateOfDeath time.Time
+
+	// CustomerSSN is the beneficiary's nine digit Social Security Number, all zeros if there is no SSN.
+	CustomerSSN string
+
+	// Amount is the beneficiary payment in cents.
+	Amount int
+}
+
+// ParseDNEDetails returns the DNEDetails held in a DNE Addenda05 record.
+func ParseDNEDetails(addenda05 *Addenda05) (*DNEDetails, error) {
+	if addenda05 == nil {
+		return nil, fieldError("Addenda05", ErrFieldInclusion)
+	}
+	line := strings.TrimSuffix(addenda05.PaymentRelatedInformation, `\`) // PaymentRelatedInformation is terminated by '\'
+	parts := strings.Split(line, "*")
+	if len(parts) != 6 || strings.TrimSpace(parts[0]) != "DATE OF DEATH" || parts[2] != "CUSTOMERSSN" || parts[4] != "AMOUNT" {
+		return nil, fmt.Errorf("DNE: unable to parse Addenda05 (%s) PaymentRelatedInformation", addenda05.ID)
+	}
+
+	date, err := time.Parse(dneDateFormat, parts[1])
+	if err != nil {
+		return nil, fieldError("DateOfDeath", ErrValidDate, parts[1])
+	}
+	amount, err := parseDNEAmount(parts[5])
+	if err != nil {
+		return nil, fieldError("Amount", err, parts[5])
+	}
+	details := &DNEDetails{
+		DateOfDeath: date,
+		CustomerSSN: parts[3],
+		Amount:      amount,
+	}
+	if err := details.Validate(); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// parseDNEAmount reads a dollars and cents amount ($$$$.cc) into cents.
+func parseDNEAmount(s string) (int, error) {
+	idx := strings.Index(s, ".")
+	if idx < 1 || len(s)-idx != 3 {
+		return 0, ErrNonNumeric
+	}
+	dollars, err := strconv.Atoi(s[:idx])
+	if err != nil {
+		return 0, ErrNonNumeric
+	}
+	cents, err := strconv.Atoi(s[idx+1:])
+	if err != nil || dollars < 0 || cents < 0 {
+		return 0, ErrNonNumeric
+	}
+	return dollars*100 + cents, nil
+}
+
+// Validate checks the DNEDetails can be written to an Addenda05 record.
+func (details *DNEDetails) Validate() error {
+	if details.DateOfDeath.IsZero() {
+		return fieldError("DateOfDeath", ErrFieldRequired)
+	}
+	if err := new(validator).isTaxIdentificationNumber(details.CustomerSSN); err != nil {
+		return fieldError("CustomerSSN", err)
+	}
+	if details.Amount < 0 {
+		return fieldError("Amount", ErrNegativeAmount, details.Amount)
+	}
+	return nil
+}
+
+// PaymentRelatedInformation returns the '*' delimited and '\' terminated payload of a DNE Addenda05.
+func (details *DNEDetails) PaymentRelatedInformation() string {
+	return fmt.Sprintf(`    DATE OF DEATH*%s*CUSTOMERSSN*%s*AMOUNT*%04d.%02d\`,
+		details.DateOfDeath.Format(dneDateFormat), details.CustomerSSN, details.Amount/100, details.Amount%100)
+}
+
+// Addenda05 validates the details and returns an Addenda05 record holding them.
+func (details *DNEDetails) Addenda05() (*Addenda05, error) {
+	if err := details.Validate(); err != nil {
+		return nil, err
+	}
+	payload := details.PaymentRelatedInformation()
+	if n := len(payload); n > 80 {
+		return nil, fieldError("PaymentRelatedInformation", NewErrValidFieldLength(80), n)
+	}
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = payload
+	return addenda05, nil
+}
+
+// Details returns the DNEDetails parsed from the batch's Addenda05 record.
+func (batch *BatchDNE) Details() (*DNEDetails, error) {
+	if batch == nil || len(batch.Entries) == 0 || len(batch.Entries[0].Addenda05) != 1 {
+		return nil, fieldError("Addenda05", ErrFieldInclusion)
+	}
+	return ParseDNEDetails(batch.Entries[0].Addenda05[0])
+}
@@ -18,6 +18,7 @@
 package ach
 
 import (
+	"errors"
 	"log"
 	"testing"
 	"time"
@@ -296,3 +297,76 @@ func TestBatchDNE__nil(t *testing.T) {
 		t.Errorf("got non-empty details from nil BatchDNE: date=%q ssn=%q amount=%q", date, ssn, amount)
 	}
 }
+
+func TestDNEDetails__Addenda05(t *testing.T) {
+	details := &DNEDetails{
+		DateOfDeath: time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC),
+		CustomerSSN: "123456789",
+		Amount:      12345,
+	}
+	addenda05, err := details.Addenda05()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := addenda05.PaymentRelatedInformation; v != `    DATE OF DEATH*010218*CUSTOMERSSN*123456789*AMOUNT*0123.45\` {
+		t.Errorf("PaymentRelatedInformation: %s", v)
+	}
+
+	entry := mockDNEEntryDetail()
+	entry.Addenda05[0] = addenda05
+	batch := NewBatchDNE(mockBatchDNEHeader())
+	batch.AddEntry(entry)
+	if err := batch.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if batch.DateOfDeath() != "010218" || batch.CustomerSSN() != "123456789" || batch.Amount() != "0123.45" {
+		t.Errorf("DateOfDeath=%s CustomerSSN=%s Amount=%s", batch.DateOfDeath(), batch.CustomerSSN(), batch.Amount())
+	}
+	parsed, err := batch.Details()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.DateOfDeath.Equal(details.DateOfDeath) || parsed.CustomerSSN != details.CustomerSSN || parsed.Amount != details.Amount {
+		t.Errorf("expected %#v got %#v", details, parsed)
+	}
+}
+
+func TestDNEDetails__Validate(t *testing.T) {
+	// no SSN is written as zeros
+	details := &DNEDetails{DateOfDeath: time.Now(), CustomerSSN: "000000000"}
+	if err := details.Validate(); err != nil {
+		t.Error(err)
+	}
+	details.CustomerSSN = "12345"
+	if err := details.Validate(); !errors.Is(err, ErrValidTaxIdentificationNumber) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	details = &DNEDetails{CustomerSSN: "000000000"}
+	if _, err := details.Addenda05(); !errors.Is(err, ErrFieldRequired) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseDNEDetails__errors(t *testing.T) {
+	// the mock holds placeholders rather than values
+	if _, err := mockBatchDNE().Details(); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := (&BatchDNE{}).Details(); !errors.Is(err, ErrFieldInclusion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = `    DATE OF DEATH*133118*CUSTOMERSSN*123456789*AMOUNT*0000.00\`
+	if _, err := ParseDNEDetails(addenda05); !errors.Is(err, ErrValidDate) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	addenda05.PaymentRelatedInformation = `    DATE OF DEATH*010218*CUSTOMERSSN*123456789*AMOUNT*12.3\`
+	if _, err := ParseDNEDetails(addenda05); !errors.Is(err, ErrNonNumeric) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	addenda05.PaymentRelatedInformation = `010218*123456789\`
+	if _, err := ParseDNEDetails(addenda05); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -116,6 +116,11 @@ type ENRPaymentInformation struct {
 	// IndividualName is the account holders full name.
 	IndividualName string
 
+	// Surname and FirstName are the account holders names as written to the Addenda05. When
+	// either is set they're written as-is, otherwise IndividualName is split at its last word.
+	Surname   string
+	FirstName string
+
 	// EnrolleeClassificationCode (also called Representative Payee Indicator) returns a code from a specific Addenda05 record.
 	// These codes represent:
 	//  0: (no)  - Initiated by beneficiary
@@ -133,7 +138,8 @@ func (info *ENRPaymentInformation) String() string {
 // ParsePaymentInformation returns an ENRPaymentInformation for a given Addenda05 record. The information is parsed from the addenda's
 // PaymentRelatedInformation field.
 //
-// The returned information is not validated for correctness.
+// Surname and FirstName are filled in from the addenda so multi-word surnames round-trip
+// through Addenda05. The returned information is not validated, see Validate.
 func (batch *BatchENR) ParsePaymentInformation(addenda05 *Addenda05) (*ENRPaymentInformation, error) {
 	parts := strings.Split(strings.TrimSuffix(addenda05.PaymentRelatedInformation, `\`), "*") // PaymentRelatedInformation is terminated by '\'
 	if len(parts) != 8 {
@@ -155,7 +161,89 @@ func (batch *BatchENR) ParsePaymentInformation(addenda05 *Addenda05) (*ENRPaymen
 		CheckDigit:                 parts[2],
 		DFIAccountNumber:           parts[3],
 		IndividualIdentification:   parts[4],
-		IndividualName:             fmt.Sprintf("%s %s", parts[6], parts[5]),
+		IndividualName:             strings.TrimSpace(fmt.Sprintf("%s %s", parts[6], parts[5])),
+		Surname:                    parts[5],
+		FirstName:                  parts[6],
 		EnrolleeClassificationCode: enrolleeCode,
 	}, nil
 }
+
+// Validate checks each sub-field of the payment information against the formats required
+// for an ENR Addenda05 record.
+func (info *ENRPaymentInformation) Validate() error {
+	v := new(validator)
+	switch info.TransactionCode {
+	case CheckingCredit, CheckingDebit, SavingsCredit, SavingsDebit:
+	default:
+		return fieldError("TransactionCode", ErrTransactionCode, info.TransactionCode)
+	}
+	if err := CheckRoutingNumber(info.RDFIIdentification + info.CheckDigit); err != nil {
+		return fieldError("RDFIIdentification", err, info.RDFIIdentification+info.CheckDigit)
+	}
+	if info.DFIAccountNumber == "" {
+		return fieldError("DFIAccountNumber", ErrFieldRequired)
+	}
+	if err := info.isPaymentField(v, info.DFIAccountNumber); err != nil {
+		return fieldError("DFIAccountNumber", err, info.DFIAccountNumber)
+	}
+	if err := v.isTaxIdentificationNumber(info.IndividualIdentification); err != nil {
+		return fieldError("IndividualIdentification", err)
+	}
+	if strings.TrimSpace(info.IndividualName) == "" {
+		return fieldError("IndividualName", ErrFieldRequired)
+	}
+	if err := info.isPaymentField(v, info.IndividualName); err != nil {
+		return fieldError("IndividualName", err, info.IndividualName)
+	}
+	if err := info.isPaymentField(v, info.Surname); err != nil {
+		return fieldError("Surname", err, info.Surname)
+	}
+	if err := info.isPaymentField(v, info.FirstName); err != nil {
+		return fieldError("FirstName", err, info.FirstName)
+	}
+	if info.EnrolleeClassificationCode != 0 && info.EnrolleeClassificationCode != 1 {
+		return fieldError("EnrolleeClassificationCode", ErrEnrolleeClassificationCode, info.EnrolleeClassificationCode)
+	}
+	return nil
+}
+
+// isPaymentField checks s is alphanumeric and free of the '*' and '\' delimiters used
+// in the Addenda05 PaymentRelatedInformation.
+func (info *ENRPaymentInformation) isPaymentField(v *validator, s string) error {
+	if strings.ContainsAny(s, `*\`) {
+		return ErrNonAlphanumeric
+	}
+	return v.isAlphanumeric(s)
+}
+
+// names returns the surname and first name written to the Addenda05. Without Surname or
+// FirstName the surname is taken as the last word of IndividualName.
+func (info *ENRPaymentInformation) names() (string, string) {
+	if info.Surname != "" || info.FirstName != "" {
+		return strings.TrimSpace(info.Surname), strings.TrimSpace(info.FirstName)
+	}
+	name := strings.TrimSpace(info.IndividualName)
+	if idx := strings.LastIndex(name, " "); idx > 0 {
+		return name[idx+1:], strings.TrimSpace(name[:idx])
+	}
+	return name, ""
+}
+
+// Addenda05 validates the payment information and returns an Addenda05 record whose
+// PaymentRelatedInformation holds the '*' delimited and '\' terminated payload read by
+// ParsePaymentInformation.
+func (info *ENRPaymentInformation) Addenda05() (*Addenda05, error) {
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+	surname, first := info.names()
+	payload := fmt.Sprintf(`%d*%s*%s*%s*%s*%s*%s*%d\`,
+		info.TransactionCode, info.RDFIIdentification, info.CheckDigit, info.DFIAccountNumber,
+		info.IndividualIdentification, strings.ToUpper(surname), strings.ToUpper(first), info.EnrolleeClassificationCode)
+	if n := len(payload); n > 80 {
+		return nil, fieldError("PaymentRelatedInformation", NewErrValidFieldLength(80), n)
+	}
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = payload
+	return addenda05, nil
+}
@@ -18,7 +18,9 @@
 package ach
 
 import (
+	"errors"
 	"log"
+	"reflect"
 	"testing"
 
 	"github.com/ourly/base"
@@ -289,6 +291,9 @@ func TestBatchENR__PaymentInformation(t *testing.T) {
 	if v := info.IndividualName; v != "JOHN DOE" {
 		t.Errorf("IndividualName: %s", v)
 	}
+	if info.Surname != "DOE" || info.FirstName != "JOHN" {
+		t.Errorf("Surname=%q FirstName=%q", info.Surname, info.FirstName)
+	}
 	if v := info.EnrolleeClassificationCode; v != 1 {
 		t.Errorf("EnrolleeClassificationCode: %d", v)
 	}
@@ -314,3 +319,97 @@ func TestBatchENRAddenda02(t *testing.T) {
 		t.Errorf("%T: %s", err, err)
 	}
 }
+
+// mockENRPaymentInformation creates valid ENR payment information
+func mockENRPaymentInformation() *ENRPaymentInformation {
+	return &ENRPaymentInformation{
+		TransactionCode:            CheckingCredit,
+		RDFIIdentification:         "12200004",
+		CheckDigit:                 "3",
+		DFIAccountNumber:           "123987654321",
+		IndividualIdentification:   "777777777",
+		IndividualName:             "JOHN DOE",
+		Surname:                    "DOE",
+		FirstName:                  "JOHN",
+		EnrolleeClassificationCode: 1,
+	}
+}
+
+func TestENRPaymentInformation__Addenda05(t *testing.T) {
+	info := mockENRPaymentInformation()
+	addenda05, err := info.Addenda05()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := addenda05.PaymentRelatedInformation; v != `22*12200004*3*123987654321*777777777*DOE*JOHN*1\` {
+		t.Errorf("PaymentRelatedInformation: %s", v)
+	}
+
+	entry := mockENREntryDetail()
+	entry.Addenda05[0] = addenda05
+	batch := NewBatchENR(mockBatchENRHeader())
+	batch.AddEntry(entry)
+	if err := batch.Create(); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := batch.ParsePaymentInformation(batch.GetEntries()[0].Addenda05[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info, parsed) {
+		t.Errorf("expected %v got %v", info, parsed)
+	}
+
+	// multi-word surnames round-trip
+	info.IndividualName, info.Surname = "JOHN VAN DYKE", "VAN DYKE"
+	if addenda05, err = info.Addenda05(); err != nil {
+		t.Fatal(err)
+	}
+	if v := addenda05.PaymentRelatedInformation; v != `22*12200004*3*123987654321*777777777*VAN DYKE*JOHN*1\` {
+		t.Errorf("PaymentRelatedInformation: %s", v)
+	}
+	if parsed, err = batch.ParsePaymentInformation(addenda05); err != nil || !reflect.DeepEqual(info, parsed) {
+		t.Errorf("expected %v got %v: %v", info, parsed, err)
+	}
+
+	// single names are written as the surname
+	info.IndividualName, info.Surname, info.FirstName = "Cher", "", ""
+	if addenda05, err = info.Addenda05(); err != nil {
+		t.Fatal(err)
+	}
+	if v := addenda05.PaymentRelatedInformation; v != `22*12200004*3*123987654321*777777777*CHER**1\` {
+		t.Errorf("PaymentRelatedInformation: %s", v)
+	}
+	if parsed, err = batch.ParsePaymentInformation(addenda05); err != nil || parsed.IndividualName != "CHER" {
+		t.Errorf("IndividualName=%q err=%v", parsed.IndividualName, err)
+	}
+}
+
+func TestENRPaymentInformation__Validate(t *testing.T) {
+	cases := []struct {
+		field  string
+		modify func(info *ENRPaymentInformation)
+		err    error
+	}{
+		{"TransactionCode", func(info *ENRPaymentInformation) { info.TransactionCode = CheckingReturnNOCCredit }, ErrTransactionCode},
+		{"DFIAccountNumber", func(info *ENRPaymentInformation) { info.DFIAccountNumber = "" }, ErrFieldRequired},
+		{"DFIAccountNumber", func(info *ENRPaymentInformation) { info.DFIAccountNumber = "1234*5678" }, ErrNonAlphanumeric},
+		{"IndividualIdentification", func(info *ENRPaymentInformation) { info.IndividualIdentification = "777-77-7777" }, ErrValidTaxIdentificationNumber},
+		{"IndividualName", func(info *ENRPaymentInformation) { info.IndividualName = " " }, ErrFieldRequired},
+		{"Surname", func(info *ENRPaymentInformation) { info.Surname = "VAN*DYKE" }, ErrNonAlphanumeric},
+		{"EnrolleeClassificationCode", func(info *ENRPaymentInformation) { info.EnrolleeClassificationCode = 2 }, ErrEnrolleeClassificationCode},
+	}
+	for _, tc := range cases {
+		info := mockENRPaymentInformation()
+		tc.modify(info)
+		if err := info.Validate(); !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.field, err)
+		}
+	}
+
+	info := mockENRPaymentInformation()
+	info.CheckDigit = "4"
+	if _, err := info.Addenda05(); err == nil {
+		t.Error("expected routing number checksum error")
+	}
+}
@@ -0,0 +1,206 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCardSettlementDebitOnly is returned when a CardSettlement's TransactionCode is not a debit,
+// as POS and SHR entries can only debit the cardholder's account
+var ErrCardSettlementDebitOnly = errors.New("POS and SHR settlements must be debits")
+
+// CardSettlement is a single card-network settlement record (for example a line from a
+// network settlement report) which is reported as a POS or SHR entry with an Addenda02.
+type CardSettlement struct {
+	// RoutingNumber is the 9 digit routing number of the cardholder's DFI
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is the cardholder's account
+	AccountNumber string `json:"accountNumber"`
+	// TransactionCode of the cardholder's account. When zero CheckingDebit is used.
+	TransactionCode int `json:"transactionCode,omitempty"`
+	// Amount of the settlement in cents
+	Amount int `json:"amount"`
+	// CardTransactionType is written as the entry's DiscretionaryData. When empty "01"
+	// (purchase of goods or services) is used.
+	CardTransactionType string `json:"cardTransactionType,omitempty"`
+
+	// IndividualName is the cardholder's name, used for POS entries
+	IndividualName string `json:"individualName,omitempty"`
+	// IdentificationNumber identifies the cardholder to the merchant, used for POS entries
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+
+	// CardNumber is the card account number, used for SHR entries
+	CardNumber string `json:"cardNumber,omitempty"`
+	// CardExpiration is the month the card expires, used for SHR entries
+	CardExpiration time.Time `json:"cardExpiration,omitempty"`
+	// DocumentReferenceNumber is the 11 digit reference of the transaction, used for SHR entries
+	DocumentReferenceNumber string `json:"documentReferenceNumber,omitempty"`
+
+	// Terminal where the card transaction was originated
+	Terminal Terminal `json:"terminal"`
+	// TransactionSerialNumber is assigned by the terminal
+	TransactionSerialNumber string `json:"transactionSerialNumber"`
+	// TransactionDate is when the card transaction occurred
+	TransactionDate time.Time `json:"transactionDate"`
+	// AuthorizationCode furnished to the merchant by the card authorization center
+	AuthorizationCode string `json:"authorizationCode,omitempty"`
+	// ReferenceInformationOne and ReferenceInformationTwo are optional merchant references
+	ReferenceInformationOne string `json:"referenceInformationOne,omitempty"`
+	ReferenceInformationTwo string `json:"referenceInformationTwo,omitempty"`
+}
+
+// transactionCode returns the transaction code of the settlement
+func (s *CardSettlement) transactionCode() int {
+	if s.TransactionCode != 0 {
+		return s.TransactionCode
+	}
+	return CheckingDebit
+}
+
+// cardTransactionType returns the card transaction type of the settlement
+func (s *CardSettlement) cardTransactionType() string {
+	if s.CardTransactionType != "" {
+		return s.CardTransactionType
+	}
+	return "01"
+}
+
+// Validate checks the CardSettlement has the information required to build a POS or SHR entry
+func (s *CardSettlement) Validate() error {
+	v := new(validator)
+	if err := CheckRoutingNumber(s.RoutingNumber); err != nil {
+		return fieldError("RoutingNumber", err, s.RoutingNumber)
+	}
+	if s.AccountNumber == "" {
+		return fieldError("AccountNumber", ErrFieldRequired)
+	}
+	if err := v.isTransactionCode(s.transactionCode()); err != nil {
+		return fieldError("TransactionCode", err, s.transactionCode())
+	}
+	if (&EntryDetail{TransactionCode: s.transactionCode()}).CreditOrDebit() != "D" {
+		return fieldError("TransactionCode", ErrCardSettlementDebitOnly, s.transactionCode())
+	}
+	if s.Amount < 0 {
+		return fieldError("Amount", ErrNegativeAmount, s.Amount)
+	}
+	if err := v.isCardTransactionType(s.cardTransactionType()); err != nil {
+		return fieldError("CardTransactionType", err, s.CardTransactionType)
+	}
+	if s.TransactionSerialNumber == "" {
+		return fieldError("TransactionSerialNumber", ErrFieldRequired)
+	}
+	if s.TransactionDate.IsZero() {
+		return fieldError("TransactionDate", ErrFieldRequired)
+	}
+	return s.Terminal.Validate()
+}
+
+// Addenda02 returns the Addenda02 describing the card transaction
+func (s *CardSettlement) Addenda02() (*Addenda02, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	addenda02 := NewAddenda02()
+	addenda02.ReferenceInformationOne = s.ReferenceInformationOne
+	addenda02.ReferenceInformationTwo = s.ReferenceInformationTwo
+	addenda02.TransactionSerialNumber = s.TransactionSerialNumber
+	addenda02.SetTransactionDate(s.TransactionDate)
+	addenda02.AuthorizationCodeOrExpireDate = s.AuthorizationCode
+	if err := addenda02.SetTerminal(s.Terminal); err != nil {
+		return nil, err
+	}
+	return addenda02, nil
+}
+
+// EntryDetail returns a POS or SHR EntryDetail (according to sec) with its Addenda02.
+// The TraceNumber is set by the batch.
+func (s *CardSettlement) EntryDetail(sec string) (*EntryDetail, error) {
+	addenda02, err := s.Addenda02()
+	if err != nil {
+		return nil, err
+	}
+	ed := NewEntryDetail()
+	ed.TransactionCode = s.transactionCode()
+	ed.SetRDFI(s.RoutingNumber)
+	ed.DFIAccountNumber = s.AccountNumber
+	ed.Amount = s.Amount
+	ed.DiscretionaryData = s.cardTransactionType()
+	ed.Category = CategoryForward
+
+	switch sec {
+	case POS:
+		ed.IdentificationNumber = s.IdentificationNumber
+		ed.IndividualName = s.IndividualName
+	case SHR:
+		if s.CardExpiration.IsZero() {
+			return nil, fieldError("CardExpiration", ErrFieldRequired)
+		}
+		if s.CardNumber == "" {
+			return nil, fieldError("CardNumber", ErrFieldRequired)
+		}
+		ed.SetSHRCardExpirationDate(s.CardExpiration.Format("0106"))
+		ed.SetSHRDocumentReferenceNumber(s.DocumentReferenceNumber)
+		ed.SetSHRIndividualCardAccountNumber(s.CardNumber)
+	default:
+		return nil, fieldError("StandardEntryClassCode", ErrSECCode, sec)
+	}
+	ed.Addenda02 = addenda02
+	ed.AddendaRecordIndicator = 1
+	return ed, nil
+}
+
+// NewCardSettlementBatch builds a POS or SHR batch (according to bh.StandardEntryClassCode)
+// with an entry for each settlement. Create() is called on the batch and each Addenda02
+// is given the TraceNumber of its entry.
+func NewCardSettlementBatch(bh *BatchHeader, settlements []*CardSettlement) (Batcher, error) {
+	if bh == nil {
+		return nil, errors.New("nil BatchHeader")
+	}
+	if len(settlements) == 0 {
+		return nil, ErrBatchNoEntries
+	}
+	switch bh.StandardEntryClassCode {
+	case POS, SHR:
+	default:
+		return nil, fieldError("StandardEntryClassCode", ErrSECCode, bh.StandardEntryClassCode)
+	}
+	batch, err := NewBatch(bh)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range settlements {
+		if s == nil {
+			return nil, fmt.Errorf("settlement %d: nil CardSettlement", i)
+		}
+		ed, err := s.EntryDetail(bh.StandardEntryClassCode)
+		if err != nil {
+			return nil, fmt.Errorf("settlement %d: %w", i, err)
+		}
+		batch.AddEntry(ed)
+	}
+	if err := batch.Create(); err != nil {
+		return nil, err
+	}
+	for _, ed := range batch.GetEntries() {
+		ed.Addenda02.TraceNumber = ed.TraceNumber
+	}
+	return batch, nil
+}
@@ -0,0 +1,120 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// mockCardSettlement creates a CardSettlement for a purchase
+func mockCardSettlement() *CardSettlement {
+	return &CardSettlement{
+		RoutingNumber:           "231380104",
+		AccountNumber:           "744-5678-99",
+		Amount:                  25000,
+		IndividualName:          "Wade Arnold",
+		IdentificationNumber:    "45689033",
+		CardNumber:              "1234567891123456789",
+		CardExpiration:          time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+		DocumentReferenceNumber: "12345678910",
+		Terminal: Terminal{
+			IdentificationCode: "TERM02",
+			Location:           "Target Store 0049",
+			City:               "PHILADELPHIA",
+			State:              "PA",
+		},
+		TransactionSerialNumber: "100049",
+		TransactionDate:         time.Date(2019, time.June, 12, 0, 0, 0, 0, time.UTC),
+		AuthorizationCode:       "123456",
+		ReferenceInformationOne: "REFONEA",
+	}
+}
+
+func TestNewCardSettlementBatch(t *testing.T) {
+	second := mockCardSettlement()
+	second.TransactionCode = SavingsDebit
+	second.CardTransactionType = "02"
+	second.TransactionSerialNumber = "100050"
+
+	for _, bh := range []*BatchHeader{mockBatchPOSHeader(), mockBatchSHRHeader()} {
+		bh.EffectiveEntryDate = "190614"
+		batch, err := NewCardSettlementBatch(bh, []*CardSettlement{mockCardSettlement(), second})
+		if err != nil {
+			t.Fatalf("%s: %v", bh.StandardEntryClassCode, err)
+		}
+		if batch.GetControl().TotalDebitEntryDollarAmount != 50000 || batch.GetControl().EntryAddendaCount != 4 {
+			t.Errorf("%s: debit=%d count=%d", bh.StandardEntryClassCode, batch.GetControl().TotalDebitEntryDollarAmount, batch.GetControl().EntryAddendaCount)
+		}
+
+		ed := batch.GetEntries()[1]
+		if ed.TransactionCode != SavingsDebit || ed.DiscretionaryData != "02" {
+			t.Errorf("%s: TransactionCode=%d DiscretionaryData=%s", bh.StandardEntryClassCode, ed.TransactionCode, ed.DiscretionaryData)
+		}
+		if ed.Addenda02.TraceNumber != ed.TraceNumber || ed.Addenda02.TransactionSerialNumber != "100050" {
+			t.Errorf("%s: unexpected Addenda02: %v", bh.StandardEntryClassCode, ed.Addenda02)
+		}
+		date, err := ed.Addenda02.TransactionDateTime(time.Date(2019, time.June, 14, 0, 0, 0, 0, time.UTC))
+		if err != nil || !date.Equal(second.TransactionDate) {
+			t.Errorf("%s: TransactionDateTime=%s err=%v", bh.StandardEntryClassCode, date, err)
+		}
+
+		switch bh.StandardEntryClassCode {
+		case POS:
+			if ed.IndividualName != "Wade Arnold" {
+				t.Errorf("IndividualName=%s", ed.IndividualName)
+			}
+		case SHR:
+			if ed.SHRCardExpirationDateField() != "0722" || ed.SHRDocumentReferenceNumberField() != "12345678910" {
+				t.Errorf("IdentificationNumber=%s", ed.IdentificationNumber)
+			}
+		}
+	}
+}
+
+func TestNewCardSettlementBatch__errors(t *testing.T) {
+	if _, err := NewCardSettlementBatch(nil, []*CardSettlement{mockCardSettlement()}); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := NewCardSettlementBatch(mockBatchPOSHeader(), nil); !errors.Is(err, ErrBatchNoEntries) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewCardSettlementBatch(mockBatchPPDHeader(), []*CardSettlement{mockCardSettlement()}); !errors.Is(err, ErrSECCode) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		field  string
+		modify func(s *CardSettlement)
+		err    error
+	}{
+		{"TransactionCode", func(s *CardSettlement) { s.TransactionCode = CheckingCredit }, ErrCardSettlementDebitOnly},
+		{"CardTransactionType", func(s *CardSettlement) { s.CardTransactionType = "77" }, ErrCardTransactionType},
+		{"TransactionDate", func(s *CardSettlement) { s.TransactionDate = time.Time{} }, ErrFieldRequired},
+		{"TerminalState", func(s *CardSettlement) { s.Terminal.State = "ZZ" }, ErrValidState},
+		{"CardNumber", func(s *CardSettlement) { s.CardNumber = "" }, ErrFieldRequired},
+	}
+	for _, tc := range cases {
+		s := mockCardSettlement()
+		tc.modify(s)
+		if _, err := NewCardSettlementBatch(mockBatchSHRHeader(), []*CardSettlement{s}); !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.field, err)
+		}
+	}
+}
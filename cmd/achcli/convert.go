@@ -0,0 +1,73 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// convert writes each ACH file as JSON and each JSON file as ACH, unless -json or -ach
+// chooses the output format.
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	toJSON := fs.Bool("json", false, "write every file as JSON")
+	toACH := fs.Bool("ach", false, "write every file as ACH")
+	dir := fs.String("dir", "", "directory to write files into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *toJSON && *toACH {
+		return fail(stderr, errors.New("use only one of -json and -ach"))
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	var jsonOutputs, achOutputs []output
+	for _, path := range paths {
+		bs, err := readBytes(path, stdin)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		file, err := parse(bs)
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		out := output{name: baseName(path), file: file}
+		if *toJSON || (!*toACH && !isJSON(bs)) {
+			jsonOutputs = append(jsonOutputs, out)
+		} else {
+			achOutputs = append(achOutputs, out)
+		}
+	}
+	if *dir == "" && len(jsonOutputs)+len(achOutputs) > 1 {
+		return fail(stderr, errors.New("several files were given, use -dir to choose where they are written"))
+	}
+	if err := writeOutputs(stdout, *dir, jsonOutputs, true); err != nil {
+		return fail(stderr, err)
+	}
+	if err := writeOutputs(stdout, *dir, achOutputs, false); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
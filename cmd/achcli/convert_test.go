@@ -0,0 +1,80 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	// ACH to JSON and back again
+	var asJSON, stderr bytes.Buffer
+	if code := run([]string{"convert", "../../test/testdata/ppd-debit.ach"}, nil, &asJSON, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if !isJSON(asJSON.Bytes()) {
+		t.Fatalf("expected JSON: %s", asJSON.String())
+	}
+	var asACH bytes.Buffer
+	if code := run([]string{"convert", "-"}, &asJSON, &asACH, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	original, err := ioutil.ReadFile("../../test/testdata/ppd-debit.ach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(asACH.String()) != strings.TrimSpace(string(original)) {
+		t.Errorf("round trip differs:\n%s", asACH.String())
+	}
+}
+
+func TestConvert__Dir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "achcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"convert", "-ach", "-dir", dir, "../../test/testdata/ppd-debit.ach", "../../test/testdata/ppd-valid.json"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	for _, name := range []string{"ppd-debit.ach", "ppd-valid.ach"} {
+		if _, err := readFile(filepath.Join(dir, name), nil); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestConvert__Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{"convert", "-json", "-ach", "../../test/testdata/ppd-debit.ach"},
+		{"convert", "../../test/testdata/ppd-debit.ach", "../../test/testdata/ppd-valid.json"},
+		{"convert", "missing.ach"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, nil, &stdout, &stderr); code != 2 || stderr.Len() == 0 {
+			t.Errorf("%v: exit code %d", args, code)
+		}
+	}
+}
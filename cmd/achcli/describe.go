@@ -0,0 +1,103 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ourly/ach/report"
+)
+
+// describe prints a summary of each file's header and batches. -entries prints the full
+// report including entries and -json the report as JSON.
+func describe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	entries := fs.Bool("entries", false, "list the entries of each batch")
+	asJSON := fs.Bool("json", false, "write the description as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	for i, path := range paths {
+		file, err := readFile(path, stdin)
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		r := report.New(file)
+		switch {
+		case *asJSON:
+			err = json.NewEncoder(stdout).Encode(r)
+		case *entries:
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "%s\n\n", path)
+			err = r.WriteText(stdout)
+		default:
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			err = summary(stdout, path, r)
+		}
+		if err != nil {
+			return fail(stderr, err)
+		}
+	}
+	return 0
+}
+
+// summary writes tables of the file header and batches
+func summary(w io.Writer, path string, r *report.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\n", path)
+	fmt.Fprintf(tw, "Origin\t%s\t%s\n", r.Header.ImmediateOrigin, r.Header.ImmediateOriginName)
+	fmt.Fprintf(tw, "Destination\t%s\t%s\n", r.Header.ImmediateDestination, r.Header.ImmediateDestinationName)
+	fmt.Fprintf(tw, "Created\t%s\tModifier %s\n", r.Header.FileCreation, r.Header.FileIDModifier)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Batch\tSEC\tCompany\tDescription\tEffective\tEntries\tDebits\tCredits")
+	for _, b := range r.Batches {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%16s\t%16s\n", b.Number, b.StandardEntryClassCode, b.CompanyName,
+			b.CompanyEntryDescription, b.EffectiveEntryDate, b.EntryCount, report.Dollars(b.TotalDebit), report.Dollars(b.TotalCredit))
+	}
+	entryCount := 0
+	for _, b := range r.Batches {
+		entryCount += b.EntryCount
+	}
+	fmt.Fprintf(tw, "Total\t\t\t\t\t%d\t%16s\t%16s\n", entryCount, report.Dollars(r.Totals.TotalDebit), report.Dollars(r.Totals.TotalCredit))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, d := range r.Discrepancies {
+		fmt.Fprintf(w, "DISCREPANCY: %s\n", d)
+	}
+	return nil
+}
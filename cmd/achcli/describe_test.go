@@ -0,0 +1,72 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach/report"
+)
+
+func TestDescribe(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"describe", "../../test/testdata/ppd-mixedDebitCredit.ach", "../../test/testdata/ppd-debit.ach"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, s := range []string{
+		"../../test/testdata/ppd-mixedDebitCredit.ach\n",
+		"Origin       121042882         My Bank Name\n",
+		"Created      2019-07-18 10:55  Modifier A\n",
+		"Batch  SEC  Company          Description  Effective   Entries  Debits            Credits\n",
+		"1      PPD  Name on Account  REG.SALARY   2019-07-19  3           $2,000,000.00     $2,000,000.00\n",
+		"../../test/testdata/ppd-debit.ach\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in:\n%s", s, out)
+		}
+	}
+}
+
+func TestDescribe__Entries(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"describe", "-entries", "../../test/testdata/ppd-mixedDebitCredit.ach"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "121042880000002") || strings.Contains(stdout.String(), "987654321") {
+		t.Errorf("expected entries with masked accounts:\n%s", stdout.String())
+	}
+}
+
+func TestDescribe__JSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"describe", "-json", "../../test/testdata/ppd-mixedDebitCredit.ach"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var r report.Report
+	if err := json.NewDecoder(&stdout).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Batches) != 1 || r.Totals.TotalDebit != 200000000 {
+		t.Errorf("unexpected report: %#v", r)
+	}
+}
@@ -0,0 +1,63 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ourly/ach"
+)
+
+// diff prints what changed from file a to file b. It exits with 0 when the files are
+// equivalent and 1 when they differ, like diff(1).
+func diff(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write the differences as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		usage(stderr)
+		return 2
+	}
+	a, err := readFile(fs.Arg(0), stdin)
+	if err != nil {
+		return fail(stderr, fmt.Errorf("%s: %v", fs.Arg(0), err))
+	}
+	b, err := readFile(fs.Arg(1), stdin)
+	if err != nil {
+		return fail(stderr, fmt.Errorf("%s: %v", fs.Arg(1), err))
+	}
+
+	d := ach.Diff(a, b)
+	if *asJSON {
+		if err := json.NewEncoder(stdout).Encode(d); err != nil {
+			return fail(stderr, err)
+		}
+	} else {
+		io.WriteString(stdout, d.String())
+	}
+	if d.Empty() {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,84 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func TestDiff(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"diff", "../../test/testdata/ppd-mixedDebitCredit.ach", "../../test/testdata/ppd-mixedDebitCredit-corrected.ach"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `Amount: "100000000" => "150000000"`) {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestDiff__JSON(t *testing.T) {
+	fd, err := os.Open("../../test/testdata/ppd-mixedDebitCredit-corrected.ach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"diff", "-json", "../../test/testdata/ppd-mixedDebitCredit.ach", "-"}, fd, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var d ach.FileDiff
+	if err := json.NewDecoder(&stdout).Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Batches) != 1 || len(d.Batches[0].Entries) != 4 {
+		t.Errorf("unexpected diff: %#v", d)
+	}
+}
+
+func TestDiff__Identical(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"diff", "../../test/testdata/ppd-debit.ach", "../../test/testdata/ppd-debit.ach"}, nil, &stdout, &stderr)
+	if code != 0 || stdout.Len() != 0 {
+		t.Errorf("exit code %d: %s", code, stdout.String())
+	}
+}
+
+func TestDiff__Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{"diff", "../../test/testdata/ppd-debit.ach"},
+		{"diff", "../../test/testdata/ppd-debit.ach", "missing.ach"},
+		{"diff", "-unknown"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, nil, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: exit code %d", args, code)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("%v: expected usage or error", args)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+// stdinPath names stdin on the command line
+const stdinPath = "-"
+
+// expandArgs expands glob patterns into the paths they match. No arguments reads stdin.
+func expandArgs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{stdinPath}, nil
+	}
+	var paths []string
+	for _, arg := range args {
+		if arg == stdinPath || !strings.ContainsAny(arg, "*?[") {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no matching files", arg)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// readBytes reads the file at path, or stdin when path is -
+func readBytes(path string, stdin io.Reader) ([]byte, error) {
+	if path == stdinPath {
+		return ioutil.ReadAll(stdin)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// isJSON returns true when bs holds a JSON encoded file
+func isJSON(bs []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(bs), []byte("{"))
+}
+
+// readFile reads an ACH or JSON file from path, or stdin when path is -
+func readFile(path string, stdin io.Reader) (*ach.File, error) {
+	bs, err := readBytes(path, stdin)
+	if err != nil {
+		return nil, err
+	}
+	return parse(bs)
+}
+
+// parse reads bs as an ACH or JSON file
+func parse(bs []byte) (*ach.File, error) {
+	if isJSON(bs) {
+		return ach.FileFromJSON(bs)
+	}
+	file, err := ach.NewReader(bytes.NewReader(bs)).Read()
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// readFiles reads every path, naming the file which could not be read
+func readFiles(paths []string, stdin io.Reader) ([]*ach.File, error) {
+	var files []*ach.File
+	for _, path := range paths {
+		file, err := readFile(path, stdin)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// writeFile writes file as ACH, or JSON when asJSON is true
+func writeFile(w io.Writer, file *ach.File, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(file)
+	}
+	return ach.NewWriter(w).Write(file)
+}
+
+// output is a file written by a command along with the name used in an output directory
+type output struct {
+	name string
+	file *ach.File
+}
+
+// writeOutputs writes a single output to stdout and several into dir, which is then required
+func writeOutputs(stdout io.Writer, dir string, outputs []output, asJSON bool) error {
+	if dir == "" {
+		if len(outputs) > 1 {
+			return errors.New("several files were created, use -dir to choose where they are written")
+		}
+		for _, out := range outputs {
+			return writeFile(stdout, out.file, asJSON)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, out := range outputs {
+		name := out.name + ".ach"
+		if asJSON {
+			name = out.name + ".json"
+		}
+		fd, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err // O_EXCL refuses to overwrite an existing (possibly input) file
+		}
+		if err := writeFile(fd, out.file, asJSON); err != nil {
+			fd.Close()
+			return err
+		}
+		if err := fd.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseName is the name of path without directory or extension, used to name outputs
+func baseName(path string) string {
+	if path == stdinPath {
+		return "stdin"
+	}
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
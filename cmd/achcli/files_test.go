@@ -0,0 +1,118 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func TestExpandArgs(t *testing.T) {
+	paths, err := expandArgs(nil)
+	if err != nil || len(paths) != 1 || paths[0] != stdinPath {
+		t.Errorf("expected stdin: %v: %v", paths, err)
+	}
+
+	paths, err = expandArgs([]string{"../../test/testdata/ppd-debit*.ach", "-", "missing.ach"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"../../test/testdata/ppd-debit-ebcdic.ach", "../../test/testdata/ppd-debit-fixedLength.ach", "../../test/testdata/ppd-debit.ach", "-", "missing.ach"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("got %v", paths)
+	}
+
+	if _, err := expandArgs([]string{"../../test/testdata/*.nope"}); err == nil {
+		t.Error("expected error for a pattern without matches")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	for _, name := range []string{"ppd-debit.ach", "ppd-valid.json"} {
+		if _, err := readFile(filepath.Join("..", "..", "test", "testdata", name), nil); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	bs, err := ioutil.ReadFile(filepath.Join("..", "..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFile(stdinPath, bytes.NewReader(bs)); err != nil {
+		t.Errorf("stdin: %v", err)
+	}
+	if _, err := readFiles([]string{"missing.ach"}, nil); err == nil || !strings.Contains(err.Error(), "missing.ach") {
+		t.Errorf("expected error naming the file: %v", err)
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	file, err := readFile(filepath.Join("..", "..", "test", "testdata", "ppd-debit.ach"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := []output{{name: "a", file: file}, {name: "b", file: file}}
+
+	var stdout bytes.Buffer
+	if err := writeOutputs(&stdout, "", outputs, false); err == nil {
+		t.Error("expected error writing several files to stdout")
+	}
+	if err := writeOutputs(&stdout, "", outputs[:1], false); err != nil || !strings.HasPrefix(stdout.String(), "101 ") {
+		t.Errorf("unexpected stdout: %q: %v", stdout.String(), err)
+	}
+
+	dir, err := ioutil.TempDir("", "achcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := writeOutputs(nil, dir, outputs, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.json", "b.json"} {
+		bs, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ach.FileFromJSON(bs); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	// existing files are not overwritten
+	if err := writeOutputs(nil, dir, outputs, true); err == nil {
+		t.Error("expected error overwriting a file")
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	for path, expected := range map[string]string{
+		"-":                    "stdin",
+		"incoming/file.ach":    "file",
+		"20190718.tar.gz":      "20190718.tar",
+		"../no-extension-file": "no-extension-file",
+	} {
+		if got := baseName(path); got != expected {
+			t.Errorf("baseName(%q)=%q, expected %q", path, got, expected)
+		}
+	}
+}
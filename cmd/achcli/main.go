@@ -0,0 +1,87 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// achcli is a command line tool for working with ACH files.
+//
+//     achcli validate *.ach
+//     achcli describe 20190718.ach
+//     achcli convert 20190718.ach > 20190718.json
+//     achcli merge -dir merged/ incoming/*.ach
+//     achcli segment -dir out/ 20190718.ach
+//     achcli flatten 20190718.ach > flattened.ach
+//     achcli diff original.ach corrected.ach
+//
+// Files can be ACH or JSON. Arguments may be glob patterns and a file is read from stdin when
+// no arguments (or -) are given. A single output file is written to stdout, several are
+// written into the -dir directory.
+//
+// validate and diff exit with 1 when a file is invalid or the files differ. Every command exits
+// with 2 on usage errors or when a file cannot be read.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: achcli validate [-lenient] [-fedach <file>] [file ...]")
+	fmt.Fprintln(w, "       achcli describe [-entries] [-json] [file ...]")
+	fmt.Fprintln(w, "       achcli convert [-json|-ach] [-dir <dir>] [file ...]")
+	fmt.Fprintln(w, "       achcli merge [-json] [-dir <dir>] [file ...]")
+	fmt.Fprintln(w, "       achcli segment [-json] [-dir <dir>] [file ...]")
+	fmt.Fprintln(w, "       achcli flatten [-json] [-dir <dir>] [file ...]")
+	fmt.Fprintln(w, "       achcli diff [-json] <a> <b>")
+}
+
+// run executes a subcommand and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	switch args[0] {
+	case "validate":
+		return validate(args[1:], stdin, stdout, stderr)
+	case "describe":
+		return describe(args[1:], stdin, stdout, stderr)
+	case "convert":
+		return convert(args[1:], stdin, stdout, stderr)
+	case "merge":
+		return merge(args[1:], stdin, stdout, stderr)
+	case "segment":
+		return segment(args[1:], stdin, stdout, stderr)
+	case "flatten":
+		return flatten(args[1:], stdin, stdout, stderr)
+	case "diff":
+		return diff(args[1:], stdin, stdout, stderr)
+	default:
+		usage(stderr)
+		return 2
+	}
+}
+
+// fail prints err and returns the exit code for trouble
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "ERROR: %v\n", err)
+	return 2
+}
@@ -0,0 +1,128 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ourly/ach"
+)
+
+// merge consolidates the files into as few files as possible with ach.MergeFiles
+func merge(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write files as JSON")
+	dir := fs.String("dir", "", "directory to write files into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+	files, err := readFiles(paths, stdin)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	merged, err := ach.MergeFiles(files)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	var outputs []output
+	for i := range merged {
+		outputs = append(outputs, output{name: fmt.Sprintf("merged-%d", i+1), file: merged[i]})
+	}
+	if err := writeOutputs(stdout, *dir, outputs, *asJSON); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+// segment splits each file into a file of credits and a file of debits with File.SegmentFile
+func segment(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("segment", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write files as JSON")
+	dir := fs.String("dir", "", "directory to write files into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	var outputs []output
+	for _, path := range paths {
+		file, err := readFile(path, stdin)
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		creditFile, debitFile, err := file.SegmentFile(ach.NewSegmentFileConfiguration())
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		// SegmentFile leaves a file without batches when there are no credits or debits
+		if len(creditFile.Batches) > 0 || len(creditFile.IATBatches) > 0 {
+			outputs = append(outputs, output{name: baseName(path) + "-credit", file: creditFile})
+		}
+		if len(debitFile.Batches) > 0 || len(debitFile.IATBatches) > 0 {
+			outputs = append(outputs, output{name: baseName(path) + "-debit", file: debitFile})
+		}
+	}
+	if err := writeOutputs(stdout, *dir, outputs, *asJSON); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+// flatten combines the batches of each file which share a batch header with File.FlattenBatches
+func flatten(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("flatten", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write files as JSON")
+	dir := fs.String("dir", "", "directory to write files into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	var outputs []output
+	for _, path := range paths {
+		file, err := readFile(path, stdin)
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		flattened, err := file.FlattenBatches()
+		if err != nil {
+			return fail(stderr, fmt.Errorf("%s: %v", path, err))
+		}
+		outputs = append(outputs, output{name: baseName(path) + "-flattened", file: flattened})
+	}
+	if err := writeOutputs(stdout, *dir, outputs, *asJSON); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "achcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestMerge(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "../../test/testdata/ppd-debit.ach", "../../test/testdata/ppd-debit.ach"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	file, err := parse(stdout.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Batches) != 1 {
+		t.Errorf("expected duplicate batches to be merged: %d batches", len(file.Batches))
+	}
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	code = run([]string{"merge", "-dir", dir, "../../test/testdata/ppd-debit.ach", "../../test/testdata/web-debit.ach"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	for _, name := range []string{"merged-1.ach", "merged-2.ach"} {
+		if _, err := readFile(filepath.Join(dir, name), nil); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestSegment(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"segment", "-dir", dir, "../../test/testdata/ppd-mixedDebitCredit.ach"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	credits, err := readFile(filepath.Join(dir, "ppd-mixedDebitCredit-credit.ach"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credits.Control.TotalDebitEntryDollarAmountInFile != 0 || credits.Control.TotalCreditEntryDollarAmountInFile == 0 {
+		t.Errorf("unexpected credit file control: %#v", credits.Control)
+	}
+	debits, err := readFile(filepath.Join(dir, "ppd-mixedDebitCredit-debit.ach"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if debits.Control.TotalCreditEntryDollarAmountInFile != 0 || debits.Control.TotalDebitEntryDollarAmountInFile == 0 {
+		t.Errorf("unexpected debit file control: %#v", debits.Control)
+	}
+
+	// a file of only debits is written to stdout
+	stdout.Reset()
+	if code := run([]string{"segment", "../../test/testdata/ppd-debit.ach"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if _, err := parse(stdout.Bytes()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"flatten", "-json", "../../test/testdata/flattenBatchesMultipleBatchHeaders.ach"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	file, err := parse(stdout.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := readFile("../../test/testdata/flattenBatchesMultipleBatchHeaders.ach", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Batches) >= len(original.Batches) {
+		t.Errorf("expected fewer batches: %d vs %d", len(file.Batches), len(original.Batches))
+	}
+
+	if code := run([]string{"flatten", "missing.ach"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d", code)
+	}
+}
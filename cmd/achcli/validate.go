@@ -0,0 +1,140 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/base"
+)
+
+// validate reads and validates each file, listing every error with its line number. It exits
+// with 1 when any file is invalid. With -lenient formatting defects are repaired and listed as
+// warnings instead, and with -fedach routing numbers are checked against a FedACH directory.
+func validate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	lenient := fs.Bool("lenient", false, "repair formatting defects, listing them as warnings")
+	fedachPath := fs.String("fedach", "", "FedACH directory file routing numbers must be found in")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var dir *fedach.Directory
+	if *fedachPath != "" {
+		d, err := fedach.Open(*fedachPath)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		dir = d
+	}
+	paths, err := expandArgs(fs.Args())
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	code := 0
+	for _, path := range paths {
+		bs, err := readBytes(path, stdin)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		problems, warnings := validationErrors(bs, ach.ReaderOptions{Lenient: *lenient}, dir)
+		for _, warning := range warnings {
+			fmt.Fprintf(stdout, "%s: warning: %s\n", path, warning)
+		}
+		if len(problems) == 0 {
+			fmt.Fprintf(stdout, "%s: valid\n", path)
+			continue
+		}
+		code = 1
+		for _, problem := range problems {
+			fmt.Fprintf(stdout, "%s: %s\n", path, problem)
+		}
+	}
+	return code
+}
+
+// validationErrors parses and validates an ACH or JSON file, returning each problem found and
+// the warnings of a lenient read. Problems found while reading an ACH file begin with their
+// line number. Routing numbers are checked against dir unless it is nil.
+func validationErrors(bs []byte, opts ach.ReaderOptions, dir *fedach.Directory) ([]string, []ach.Warning) {
+	if isJSON(bs) {
+		file, err := ach.FileFromJSON(bs)
+		if err != nil {
+			return []string{err.Error()}, nil
+		}
+		return routingErrors(dir, file), nil
+	}
+
+	r := ach.NewReader(bytes.NewReader(bs))
+	r.SetOptions(opts)
+	file, err := r.Read()
+	if err != nil {
+		var problems []string
+		var errs base.ErrorList
+		if !errors.As(err, &errs) {
+			errs = base.ErrorList{err}
+		}
+		for _, err := range errs {
+			problems = append(problems, describeError(err))
+		}
+		return problems, r.Warnings()
+	}
+	if err := file.Validate(); err != nil {
+		return []string{err.Error()}, r.Warnings()
+	}
+	return routingErrors(dir, &file), r.Warnings()
+}
+
+// routingErrors returns each routing number of file missing from dir
+func routingErrors(dir *fedach.Directory, file *ach.File) []string {
+	if dir == nil {
+		return nil
+	}
+	var errs base.ErrorList
+	if err := fedach.ValidateFile(dir, file); !errors.As(err, &errs) {
+		return nil
+	}
+	var problems []string
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// describeError formats a parse error as "line N: Record: message"
+func describeError(err error) string {
+	var pe *base.ParseError
+	if !errors.As(err, &pe) {
+		var v base.ParseError
+		if !errors.As(err, &v) {
+			return err.Error()
+		}
+		pe = &v
+	}
+	if pe.Record == "" {
+		return fmt.Sprintf("line %d: %v", pe.Line, pe.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", pe.Line, pe.Record, pe.Err)
+}
@@ -0,0 +1,118 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func TestValidate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"validate", "../../test/testdata/ppd-debit.ach", "../../test/testdata/web-invalidNOCFile.ach", "../../test/testdata/ppd-valid.json"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("exit code %d: %s", code, stderr.String())
+	}
+	for _, line := range []string{
+		"../../test/testdata/ppd-debit.ach: valid",
+		"../../test/testdata/web-invalidNOCFile.ach: line 4: Addenda: ChangeCode C92 found is not a valid addenda Change Code",
+		"../../test/testdata/web-invalidNOCFile.ach: line 5: Batches: batch #1 (COR) EntryAddendaCount calculated 1 is out-of-balance with batch control 2",
+		"../../test/testdata/ppd-valid.json: valid",
+	} {
+		if !strings.Contains(stdout.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestValidate__Stdin(t *testing.T) {
+	fd, err := os.Open("../../test/testdata/ppd-debit.ach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, fd, &stdout, &stderr); code != 0 {
+		t.Errorf("exit code %d: %s", code, stdout.String())
+	}
+	if stdout.String() != "-: valid\n" {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+}
+
+func TestValidate__Errors(t *testing.T) {
+	problems, _ := validationErrors([]byte("101 short line\n"), ach.ReaderOptions{}, nil)
+	if len(problems) == 0 || !strings.HasPrefix(problems[0], "line 1: ") {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+	if problems, _ := validationErrors([]byte(`{"fileHeader": {}}`), ach.ReaderOptions{}, nil); len(problems) != 1 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate", "missing.ach"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d", code)
+	}
+}
+
+func TestValidate__Lenient(t *testing.T) {
+	bs, err := ioutil.ReadFile("../../test/testdata/ppd-debit.ach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messy := strings.Replace(string(bs), "\n", "\r", -1)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate"}, strings.NewReader(messy), &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d: %s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"validate", "-lenient"}, strings.NewReader(messy), &stdout, &stderr); code != 0 {
+		t.Errorf("exit code %d: %s", code, stdout.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "-: warning: line 1: repaired line ending\n") || !strings.HasSuffix(stdout.String(), "-: valid\n") {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+}
+
+func TestValidate__FedACH(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"validate", "-fedach", "../../test/testdata/FedACHdir.txt", "../../test/testdata/ppd-debit.ach", "../../test/testdata/web-debit.ach"}
+	if code := run(args, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d: %s", code, stdout.String())
+	}
+	out := stdout.String()
+	if !strings.HasPrefix(out, "../../test/testdata/ppd-debit.ach: valid\n") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if !strings.HasSuffix(out, "web-debit.ach: batch 2 entry 081000030000005: RDFIIdentification routing number not found in FedACH directory: 101000019\n") {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"validate", "-fedach", "missing.txt", "../../test/testdata/ppd-debit.ach"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d", code)
+	}
+}
@@ -0,0 +1,165 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// achcsv converts between spreadsheet (CSV) rows and ACH files.
+//
+//     achcsv import -mapping payroll-mapping.json -origin 121042882 -destination 231380104 payroll.csv > payroll.ach
+//     achcsv export payroll.ach > payroll.csv
+//
+// Files are read from stdin when no path (or -) is given.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/achcsv"
+	"github.com/ourly/base"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: achcsv import -mapping <file> [flags] [file.csv]")
+	fmt.Fprintln(w, "       achcsv export [file.ach|file.json]")
+}
+
+// run executes a subcommand and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "import":
+		err = importCSV(args[1:], stdin, stdout, stderr)
+	case "export":
+		err = exportCSV(args[1:], stdin, stdout, stderr)
+	default:
+		usage(stderr)
+		return 2
+	}
+	if err != nil {
+		var errs base.ErrorList
+		if errors.As(err, &errs) {
+			for i := range errs {
+				fmt.Fprintf(stderr, "ERROR: %v\n", errs[i])
+			}
+		} else {
+			fmt.Fprintf(stderr, "ERROR: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func importCSV(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mappingPath := fs.String("mapping", "", "JSON column mapping (required)")
+	origin := fs.String("origin", "", "ImmediateOrigin routing number")
+	originName := fs.String("origin-name", "", "ImmediateOriginName")
+	destination := fs.String("destination", "", "ImmediateDestination routing number")
+	destinationName := fs.String("destination-name", "", "ImmediateDestinationName")
+	asJSON := fs.Bool("json", false, "write the file as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *mappingPath == "" {
+		return errors.New("missing -mapping")
+	}
+	mapping, err := achcsv.ReadMappingFile(*mappingPath)
+	if err != nil {
+		return err
+	}
+
+	r, closer, err := open(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	fh := ach.NewFileHeader()
+	fh.ImmediateOrigin = *origin
+	fh.ImmediateOriginName = *originName
+	fh.ImmediateDestination = *destination
+	fh.ImmediateDestinationName = *destinationName
+	fh.FileCreationDate = time.Now().Format("060102")
+	fh.FileCreationTime = time.Now().Format("1504")
+
+	file, err := achcsv.Read(r, fh, mapping)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(stdout).Encode(file)
+	}
+	return ach.NewWriter(stdout).Write(file)
+}
+
+func exportCSV(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r, closer, err := open(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var file *ach.File
+	if bytes.HasPrefix(bytes.TrimSpace(bs), []byte("{")) {
+		file, err = ach.FileFromJSON(bs)
+	} else {
+		var f ach.File
+		f, err = ach.NewReader(bytes.NewReader(bs)).Read()
+		file = &f
+	}
+	if err != nil {
+		return err
+	}
+	return achcsv.Write(stdout, file)
+}
+
+// open returns the file at path, or stdin when path is empty or -
+func open(path string, stdin io.Reader) (io.Reader, func(), error) {
+	if path == "" || path == "-" {
+		return stdin, func() {}, nil
+	}
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fd, func() { fd.Close() }, nil
+}
@@ -0,0 +1,95 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testdata(name string) string {
+	return filepath.Join("..", "..", "test", "testdata", name)
+}
+
+func TestRun__import(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"import", "-mapping", testdata("payroll-mapping.json"), "-origin", "121042882", "-destination", "231380104", testdata("payroll.csv")}
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "101 231380104") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	// round trip through export on stdin
+	var csv bytes.Buffer
+	if code := run([]string{"export"}, &stdout, &csv, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if lines := strings.Count(csv.String(), "\n"); lines != 5 {
+		t.Errorf("got %d lines:\n%s", lines, csv.String())
+	}
+
+	stdout.Reset()
+	args = append([]string{"import", "-json"}, args[1:]...)
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "{") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRun__export(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"export", testdata("ppd-valid.json")}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "Company Name,") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRun__errors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit %d", code)
+	}
+	if code := run([]string{"fmt"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit %d", code)
+	}
+
+	stderr.Reset()
+	csv := strings.NewReader("Employee,Routing,Account,Pay,Type\nJane Doe,231380100,1,1.00,checking\n")
+	if code := run([]string{"import", "-mapping", testdata("payroll-mapping.json")}, csv, &stdout, &stderr); code != 1 {
+		t.Errorf("exit %d", code)
+	}
+	if !strings.Contains(stderr.String(), "ERROR:") {
+		t.Errorf("stderr: %s", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"import"}, nil, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "-mapping") {
+		t.Errorf("exit %d: %s", code, stderr.String())
+	}
+	if code := run([]string{"export", testdata("missing.ach")}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("exit %d", code)
+	}
+}
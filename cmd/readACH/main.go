@@ -26,13 +26,15 @@ import (
 	"runtime/pprof"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/report"
 )
 
 var (
 	fPath      = flag.String("fPath", "201805101354.ach", "File Path")
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 
-	flagJson = flag.Bool("json", false, "Output ACH File in JSON to stdout")
+	flagJson   = flag.Bool("json", false, "Output ACH File in JSON to stdout")
+	flagReport = flag.String("report", "", "Output a text or html report of the ACH File to stdout")
 )
 
 func main() {
@@ -73,12 +75,23 @@ func main() {
 	}
 
 	// Output file contents
-	if *flagJson {
+	switch {
+	case *flagJson:
 		if err := json.NewEncoder(os.Stdout).Encode(achFile); err != nil {
 			fmt.Printf("ERROR: problem writing ACH File to stdout: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
+	case *flagReport == "text":
+		if err := report.Text(os.Stdout, &achFile); err != nil {
+			fmt.Printf("ERROR: problem writing report to stdout: %v\n", err)
+			os.Exit(1)
+		}
+	case *flagReport == "html":
+		if err := report.HTML(os.Stdout, &achFile); err != nil {
+			fmt.Printf("ERROR: problem writing report to stdout: %v\n", err)
+			os.Exit(1)
+		}
+	default:
 		fmt.Printf("total amount debit: %v \n", achFile.Control.TotalDebitEntryDollarAmountInFile)
 		fmt.Printf("total amount credit: %v \n", achFile.Control.TotalCreditEntryDollarAmountInFile)
 	}
@@ -25,10 +25,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/achsftp"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
+	"github.com/ourly/ach/pgp"
 	"github.com/ourly/ach/server"
 	"github.com/ourly/base/admin"
 	"github.com/ourly/base/http/bind"
@@ -76,8 +83,149 @@ func main() {
 	r := server.NewRepositoryInMemory(achFileTTL, logger)
 	svc = server.NewService(r)
 
+	// Setup optional OFAC screening of created files
+	var handlerOpts []server.HandlerOption
+	if path := os.Getenv("OFAC_SDN_FILE"); path != "" {
+		screener, err := ofac.OpenSDNScreener(path, os.Getenv("OFAC_ADDRESS_FILE"))
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem loading OFAC SDN list: %v", err))
+			os.Exit(1)
+		}
+		if v := os.Getenv("OFAC_MATCH_THRESHOLD"); v != "" {
+			threshold, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				logger.Log("main", fmt.Sprintf("invalid OFAC_MATCH_THRESHOLD: %v", err))
+				os.Exit(1)
+			}
+			screener.Threshold = threshold
+		}
+		block := strings.EqualFold(os.Getenv("OFAC_BLOCK_FILES"), "true")
+		logger.Log("main", fmt.Sprintf("Screening files against OFAC SDN list %s (block=%v)", path, block))
+		handlerOpts = append(handlerOpts, server.WithOFACScreener(screener, block))
+	}
+
+	if path := os.Getenv("FEDACH_DIRECTORY"); path != "" {
+		dir, err := fedach.Open(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem loading FedACH directory: %v", err))
+			os.Exit(1)
+		}
+		logger.Log("main", fmt.Sprintf("Checking routing numbers against %d FedACH participants from %s", dir.Len(), path))
+		handlerOpts = append(handlerOpts, server.WithFedACHDirectory(dir))
+	}
+
+	if v := os.Getenv("FILE_REDACTION"); v != "" {
+		policy, err := ach.ParseRedactionPolicy(v)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("invalid FILE_REDACTION: %v", err))
+			os.Exit(1)
+		}
+		policy.HashKey = os.Getenv("FILE_REDACTION_HASH_KEY")
+		handlerOpts = append(handlerOpts, server.WithRedaction(policy))
+	}
+
+	if v := os.Getenv("DUPLICATE_FILES"); v != "" {
+		mode, err := server.ParseDuplicateMode(v)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("invalid DUPLICATE_FILES: %v", err))
+			os.Exit(1)
+		}
+		logger.Log("main", fmt.Sprintf("Checking created files for duplicates (mode=%s)", mode))
+		handlerOpts = append(handlerOpts, server.WithDuplicateDetection(mode))
+	}
+
+	if path := os.Getenv("LIMITS_CONFIG"); path != "" {
+		cfg, err := limits.ReadConfig(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem reading limits config: %v", err))
+			os.Exit(1)
+		}
+		limiter, err := limits.NewLimiter(cfg, server.LimiterFiles(r))
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem creating limiter: %v", err))
+			os.Exit(1)
+		}
+		logger.Log("main", fmt.Sprintf("Enforcing limits of %d companies from %s", len(cfg.Companies), path))
+		handlerOpts = append(handlerOpts, server.WithLimits(limiter))
+	}
+
+	if dir := os.Getenv("PGP_KEYRING_DIR"); dir != "" {
+		keyring, err := pgp.LoadKeyringDir(dir)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem loading PGP keyring: %v", err))
+			os.Exit(1)
+		}
+		var signer *pgp.Keyring
+		if path := os.Getenv("PGP_SIGNING_KEY_FILE"); path != "" {
+			signer, err = pgp.LoadKeyring(path)
+			if err == nil {
+				err = signer.Unlock([]byte(os.Getenv("PGP_SIGNING_KEY_PASSPHRASE")))
+			}
+			if err != nil {
+				logger.Log("main", fmt.Sprintf("problem loading PGP signing key: %v", err))
+				os.Exit(1)
+			}
+		}
+		logger.Log("main", fmt.Sprintf("Encrypting file contents with %d PGP key(s) from %s", keyring.Len(), dir))
+		handlerOpts = append(handlerOpts, server.WithEncryption(keyring, signer))
+	}
+
 	// Create HTTP server
-	handler = server.MakeHTTPHandler(svc, r, log.With(logger, "component", "HTTP"))
+	handler = server.MakeHTTPHandler(svc, r, log.With(logger, "component", "HTTP"), handlerOpts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Setup optional SFTP exchange of files with ODFIs
+	var agent *achsftp.Agent
+	sftpInterval := 10 * time.Minute
+	if path := os.Getenv("SFTP_CONFIG"); path != "" {
+		cfg, err := achsftp.ReadConfig(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem reading SFTP config: %v", err))
+			os.Exit(1)
+		}
+		agent, err = achsftp.NewAgent(cfg, r, log.With(logger, "component", "SFTP"))
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem starting SFTP agent: %v", err))
+			os.Exit(1)
+		}
+		if v := os.Getenv("SFTP_INTERVAL"); v != "" {
+			if sftpInterval, err = time.ParseDuration(v); err != nil {
+				logger.Log("main", fmt.Sprintf("invalid SFTP_INTERVAL: %v", err))
+				os.Exit(1)
+			}
+		}
+		logger.Log("main", fmt.Sprintf("Exchanging files with %d ODFI(s) over SFTP every %v", len(cfg.ODFIs), sftpInterval))
+	}
+
+	// Setup optional release of pending files at cutoff times
+	if path := os.Getenv("SCHEDULER_CONFIG"); path != "" {
+		cfg, err := readSchedulerConfig(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem reading scheduler config: %v", err))
+			os.Exit(1)
+		}
+		sink, err := newSink(cfg.Sink, agent)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("invalid scheduler sink: %v", err))
+			os.Exit(1)
+		}
+		sched, err := newScheduler(cfg, r, sink, systemClock{}, log.With(logger, "component", "scheduler"))
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("problem starting scheduler: %v", err))
+			os.Exit(1)
+		}
+		if agent != nil {
+			// the scheduler releases files, the agent only downloads
+			agent.SetUploads(false)
+		}
+		logger.Log("main", fmt.Sprintf("Releasing pending files for %d destination(s) at their cutoffs", len(cfg.Destinations)))
+		go sched.run(ctx)
+	}
+	if agent != nil {
+		go agent.Run(ctx, sftpInterval)
+	}
 
 	// Listen for application termination.
 	errs := make(chan error)
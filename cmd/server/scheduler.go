@@ -0,0 +1,316 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/internal/cutoffs"
+	"github.com/ourly/ach/server"
+	"github.com/ourly/base"
+
+	"github.com/go-kit/kit/log"
+)
+
+// schedulerConfig is read as JSON from the SCHEDULER_CONFIG file
+type schedulerConfig struct {
+	// Timezone of every cutoff unless overridden for a destination, UTC when empty
+	Timezone     string              `json:"timezone,omitempty"`
+	Destinations []destinationConfig `json:"destinations"`
+	Sink         sinkConfig          `json:"sink"`
+}
+
+// destinationConfig holds the cutoff windows of an ImmediateDestination
+type destinationConfig struct {
+	ImmediateDestination string `json:"immediateDestination"`
+	// Cutoffs are the times of day, as "15:04", pending files are released
+	Cutoffs  []string `json:"cutoffs"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+func readSchedulerConfig(path string) (*schedulerConfig, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg schedulerConfig
+	if err := json.Unmarshal(bs, &cfg); err != nil {
+		return nil, fmt.Errorf("problem parsing %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// clock is replaced in tests to control when cutoffs pass
+type clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// window tracks the next cutoff of a destination
+type window struct {
+	destination string
+	cutoffs     *cutoffs.Schedule
+	next        time.Time
+}
+
+// nextCutoff returns the first cutoff after t, windows always have at least one cutoff
+func (w *window) nextCutoff(t time.Time) time.Time {
+	next, _ := w.cutoffs.Next(t)
+	return next
+}
+
+// scheduler releases the pending files of each destination at its cutoffs. Files are merged,
+// given a FileIDModifier unique for the day and stored in the Repository, then emitted to a
+// sink. It is not safe for concurrent use, run calls tick from a single goroutine.
+type scheduler struct {
+	repo    server.Repository
+	sink    sink
+	clock   clock
+	logger  log.Logger
+	windows []*window
+
+	modifiers *ach.ModifierAllocator
+}
+
+func newScheduler(cfg *schedulerConfig, repo server.Repository, sink sink, clock clock, logger log.Logger) (*scheduler, error) {
+	defaultLocation, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	s := &scheduler{
+		repo:      repo,
+		sink:      sink,
+		clock:     clock,
+		logger:    logger,
+		modifiers: ach.NewModifierAllocator(),
+	}
+	now := clock.Now()
+	seen := make(map[string]bool)
+	for _, dest := range cfg.Destinations {
+		if dest.ImmediateDestination == "" {
+			return nil, errors.New("missing immediateDestination")
+		}
+		if seen[dest.ImmediateDestination] {
+			return nil, fmt.Errorf("duplicate immediateDestination %s", dest.ImmediateDestination)
+		}
+		seen[dest.ImmediateDestination] = true
+		if len(dest.Cutoffs) == 0 {
+			return nil, fmt.Errorf("destination %s: missing cutoffs", dest.ImmediateDestination)
+		}
+		location := defaultLocation
+		if dest.Timezone != "" {
+			if location, err = time.LoadLocation(dest.Timezone); err != nil {
+				return nil, fmt.Errorf("destination %s: invalid timezone: %v", dest.ImmediateDestination, err)
+			}
+		}
+		w := &window{destination: dest.ImmediateDestination}
+		if w.cutoffs, err = cutoffs.Parse(dest.Cutoffs, location); err != nil {
+			return nil, fmt.Errorf("destination %s: %v", dest.ImmediateDestination, err)
+		}
+		w.next = w.nextCutoff(now)
+		s.windows = append(s.windows, w)
+	}
+	if repo != nil {
+		// merged files of earlier runs keep their modifiers
+		for _, file := range repo.FindAllFiles() {
+			if meta, err := repo.FindFileMetadata(file.ID); err == nil && len(meta.MergedFrom) > 0 {
+				s.modifiers.Observe(file)
+			}
+		}
+	}
+	return s, nil
+}
+
+// tick releases the files of every destination whose cutoff has passed. Cutoffs missed while
+// the scheduler wasn't running are released together.
+func (s *scheduler) tick() error {
+	now := s.clock.Now()
+	var errs base.ErrorList
+	for _, w := range s.windows {
+		if w.next.After(now) {
+			continue
+		}
+		if err := s.release(w.destination, w.next); err != nil {
+			errs.Add(fmt.Errorf("destination %s: %v", w.destination, err))
+		}
+		w.next = w.nextCutoff(now)
+	}
+	return errs.Err()
+}
+
+// next returns the earliest upcoming cutoff
+func (s *scheduler) next() time.Time {
+	var next time.Time
+	for _, w := range s.windows {
+		if next.IsZero() || w.next.Before(next) {
+			next = w.next
+		}
+	}
+	return next
+}
+
+// run calls tick at each cutoff until ctx is done, logging any errors.
+func (s *scheduler) run(ctx context.Context) {
+	if len(s.windows) == 0 {
+		return
+	}
+	for {
+		timer := time.NewTimer(s.next().Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := s.tick(); err != nil {
+			s.logger.Log("scheduler", "release", "error", err)
+		}
+	}
+}
+
+// release merges the pending files of destination and emits the merged files to the sink.
+// Each merged file is marked sent as soon as it has been emitted, those which fail are
+// emitted again at the next cutoff.
+func (s *scheduler) release(destination string, cutoff time.Time) error {
+	var errs base.ErrorList
+	var origins []string
+	byOrigin := make(map[string][]*ach.File)
+	for _, file := range server.PendingFiles(s.repo, destination) {
+		if s.merged(file) {
+			continue
+		}
+		origin := file.Header.ImmediateOrigin
+		if _, ok := byOrigin[origin]; !ok {
+			origins = append(origins, origin)
+		}
+		byOrigin[origin] = append(byOrigin[origin], file)
+	}
+	for _, origin := range origins {
+		if err := s.merge(cutoff, byOrigin[origin]); err != nil {
+			errs.Add(fmt.Errorf("origin %s: %v", origin, err))
+		}
+	}
+
+	for _, file := range server.PendingFiles(s.repo, destination) {
+		if !s.merged(file) {
+			continue
+		}
+		location, err := s.sink.emit(destination, file)
+		if err != nil {
+			errs.Add(fmt.Errorf("origin %s: merged file %s: %v", file.Header.ImmediateOrigin, file.ID, err))
+			continue
+		}
+		s.logger.Log("scheduler", fmt.Sprintf("released file %s to %s", file.ID, location),
+			"destination", destination, "origin", file.Header.ImmediateOrigin, "modifier", file.Header.FileIDModifier)
+		if err := s.repo.UpdateFileMetadata(file.ID, func(meta *server.FileMetadata) { meta.Sent = location }); err != nil {
+			errs.Add(fmt.Errorf("merged file %s: released to %s: %v", file.ID, location, err))
+		}
+	}
+	return errs.Err()
+}
+
+// merged returns true for files the scheduler merged from pending files
+func (s *scheduler) merged(file *ach.File) bool {
+	meta, err := s.repo.FindFileMetadata(file.ID)
+	return err == nil && len(meta.MergedFrom) > 0
+}
+
+// merge merges pending files sharing an ImmediateOrigin into as few files as possible and
+// stores them dated at cutoff. The pending files are then marked as merged, so their
+// entries are only sent in the merged files.
+func (s *scheduler) merge(cutoff time.Time, pending []*ach.File) error {
+	merged, err := mergeCopies(pending)
+	if err != nil {
+		return err
+	}
+	var mergedFrom, mergedInto []string
+	for _, file := range pending {
+		mergedFrom = append(mergedFrom, file.ID)
+	}
+	for _, file := range merged {
+		file.ID = base.ID()
+		file.Header.FileCreationDate = cutoff.Format("060102")
+		file.Header.FileCreationTime = cutoff.Format("1504")
+		if err := s.modifiers.Assign(file); err != nil {
+			return err
+		}
+		if err := file.Create(); err != nil {
+			return fmt.Errorf("merged file %s: %v", file.ID, err)
+		}
+		mergedInto = append(mergedInto, file.ID)
+	}
+	for _, file := range merged {
+		if err := s.repo.StoreFile(file); err != nil {
+			return fmt.Errorf("merged file %s: %v", file.ID, err)
+		}
+		if err := s.repo.UpdateFileMetadata(file.ID, func(meta *server.FileMetadata) { meta.MergedFrom = mergedFrom }); err != nil {
+			return fmt.Errorf("merged file %s: %v", file.ID, err)
+		}
+	}
+	var errs base.ErrorList
+	for _, file := range pending {
+		if err := s.repo.UpdateFileMetadata(file.ID, func(meta *server.FileMetadata) { meta.MergedInto = mergedInto }); err != nil {
+			errs.Add(fmt.Errorf("file %s: merged into %s: %v", file.ID, strings.Join(mergedInto, ","), err))
+		}
+	}
+	return errs.Err()
+}
+
+// mergeCopies merges copies of files, as MergeFiles appends to the first file of each origin
+func mergeCopies(files []*ach.File) ([]*ach.File, error) {
+	copies := make([]*ach.File, 0, len(files))
+	for _, file := range files {
+		cp, err := copyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, cp)
+	}
+	merged, err := ach.MergeFiles(copies)
+	if err != nil {
+		return nil, fmt.Errorf("problem merging files: %v", err)
+	}
+	return merged, nil
+}
+
+// copyFile returns a deep copy of file by writing and reading it back
+func copyFile(file *ach.File) (*ach.File, error) {
+	if err := file.Create(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := ach.NewWriter(&buf).Write(file); err != nil {
+		return nil, err
+	}
+	cp, err := ach.NewReader(&buf).Read()
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
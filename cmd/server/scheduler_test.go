@@ -0,0 +1,429 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/server"
+
+	"github.com/go-kit/kit/log"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) set(t *testing.T, value string) {
+	t.Helper()
+	now, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = now
+}
+
+// memorySink keeps emitted files and fails while err is set, only for files from failOrigin
+// when that is set and once it keeps failAfter files when that is set
+type memorySink struct {
+	files      []*ach.File
+	err        error
+	failOrigin string
+	failAfter  int
+}
+
+func (s *memorySink) emit(destination string, file *ach.File) (string, error) {
+	if s.err != nil && (s.failOrigin == "" || s.failOrigin == file.Header.ImmediateOrigin) && len(s.files) >= s.failAfter {
+		return "", s.err
+	}
+	s.files = append(s.files, file)
+	return "memory", nil
+}
+
+func readFile(t *testing.T, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func storeFile(t *testing.T, repo server.Repository, id, name string) *ach.File {
+	t.Helper()
+	file := readFile(t, name)
+	file.ID = id
+	if err := repo.StoreFile(file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func testScheduler(t *testing.T, clock *fakeClock, sink sink) (*scheduler, server.Repository) {
+	t.Helper()
+	cfg := &schedulerConfig{
+		Destinations: []destinationConfig{
+			{ImmediateDestination: "231380104", Cutoffs: []string{"16:45", "10:30", "14:00"}},
+			{ImmediateDestination: "031300012", Cutoffs: []string{"23:00"}},
+		},
+	}
+	repo := server.NewRepositoryInMemory(0, log.NewNopLogger())
+	s, err := newScheduler(cfg, repo, sink, clock, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, repo
+}
+
+func TestScheduler__release(t *testing.T) {
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	sink := &memorySink{}
+	s, repo := testScheduler(t, clock, sink)
+
+	storeFile(t, repo, "debit", "ppd-debit.ach")
+	storeFile(t, repo, "mixed", "ppd-mixedDebitCredit.ach")
+	storeFile(t, repo, "web", "web-debit.ach")
+
+	if next := s.next(); !next.Equal(time.Date(2019, 6, 24, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("next=%v", next)
+	}
+	if err := s.tick(); err != nil || len(sink.files) != 0 {
+		t.Fatalf("released %d files before the cutoff: %v", len(sink.files), err)
+	}
+
+	clock.set(t, "2019-06-24 10:31")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 1 {
+		t.Fatalf("got %d files", len(sink.files))
+	}
+	file := sink.files[0]
+	if n := len(file.Batches); n != 2 {
+		t.Errorf("merged file has %d batches", n)
+	}
+	if fh := file.Header; fh.FileCreationDate != "190624" || fh.FileCreationTime != "1030" || fh.FileIDModifier != "A" {
+		t.Errorf("unexpected header: %s %s %s", fh.FileCreationDate, fh.FileCreationTime, fh.FileIDModifier)
+	}
+	if file.ID == "" || file.ID == "debit" || file.ID == "mixed" {
+		t.Errorf("merged file ID=%q", file.ID)
+	}
+	if n := len(server.PendingFiles(repo, "231380104")); n != 0 {
+		t.Errorf("%d files still pending", n)
+	}
+
+	// the merged file is stored and sent in place of the files it was merged from
+	if meta, _ := repo.FindFileMetadata(file.ID); meta == nil || meta.Sent != "memory" || strings.Join(meta.MergedFrom, ",") != "debit,mixed" {
+		t.Errorf("merged file metadata: %#v", meta)
+	}
+	if meta, _ := repo.FindFileMetadata("debit"); meta.Sent != "" || len(meta.MergedInto) != 1 || meta.MergedInto[0] != file.ID {
+		t.Errorf("debit metadata: %#v", meta)
+	}
+
+	// stored files aren't changed by merging
+	stored, _ := repo.FindFile("debit")
+	if len(stored.Batches) != 1 || stored.Header.FileIDModifier != "A" || stored.Header.FileCreationTime != "0000" {
+		t.Errorf("stored file was modified: %#v", stored.Header)
+	}
+
+	// nothing pending at the next cutoff
+	clock.set(t, "2019-06-24 14:00")
+	if err := s.tick(); err != nil || len(sink.files) != 1 {
+		t.Fatalf("released %d files: %v", len(sink.files), err)
+	}
+
+	storeFile(t, repo, "debit2", "ppd-debit.ach")
+	clock.set(t, "2019-06-24 16:50")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 2 || sink.files[1].Header.FileIDModifier != "B" {
+		t.Fatalf("expected a second file with modifier B")
+	}
+
+	// the other destination, and modifiers start over the next day
+	storeFile(t, repo, "debit3", "ppd-debit.ach")
+	clock.set(t, "2019-06-25 11:00")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 4 {
+		t.Fatalf("got %d files", len(sink.files))
+	}
+	for _, file := range sink.files[2:] {
+		fh := file.Header
+		switch fh.ImmediateDestination {
+		case "031300012":
+			if fh.FileCreationDate != "190624" || fh.FileCreationTime != "2300" || fh.FileIDModifier != "A" {
+				t.Errorf("unexpected header: %s %s %s", fh.FileCreationDate, fh.FileCreationTime, fh.FileIDModifier)
+			}
+		case "231380104":
+			if fh.FileCreationDate != "190625" || fh.FileCreationTime != "1030" || fh.FileIDModifier != "A" {
+				t.Errorf("unexpected header: %s %s %s", fh.FileCreationDate, fh.FileCreationTime, fh.FileIDModifier)
+			}
+		default:
+			t.Errorf("unexpected destination %s", fh.ImmediateDestination)
+		}
+	}
+	if next := s.next(); !next.Equal(time.Date(2019, 6, 25, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("next=%v", next)
+	}
+}
+
+func TestScheduler__releaseError(t *testing.T) {
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	sink := &memorySink{err: errors.New("bad sink")}
+	s, repo := testScheduler(t, clock, sink)
+	storeFile(t, repo, "debit", "ppd-debit.ach")
+
+	clock.set(t, "2019-06-24 10:30")
+	if err := s.tick(); err == nil || !strings.Contains(err.Error(), "bad sink") {
+		t.Fatalf("expected error: %v", err)
+	}
+	// the merged file is kept until it has been emitted
+	pending := server.PendingFiles(repo, "231380104")
+	if len(pending) != 1 || pending[0].ID == "debit" {
+		t.Fatalf("unexpected pending files: %v", pending)
+	}
+
+	// the same file is released at the next cutoff
+	sink.err = nil
+	clock.set(t, "2019-06-24 14:00")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 1 || sink.files[0].ID != pending[0].ID {
+		t.Fatalf("released %d files", len(sink.files))
+	}
+	if fh := sink.files[0].Header; fh.FileCreationTime != "1030" || fh.FileIDModifier != "A" {
+		t.Errorf("unexpected header: %s %s", fh.FileCreationTime, fh.FileIDModifier)
+	}
+	if n := len(server.PendingFiles(repo, "231380104")); n != 0 {
+		t.Errorf("%d files still pending", n)
+	}
+}
+
+func TestScheduler__releasePartial(t *testing.T) {
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	sink := &memorySink{err: errors.New("bad sink"), failOrigin: "031300012"}
+	s, repo := testScheduler(t, clock, sink)
+	storeFile(t, repo, "debit", "ppd-debit.ach")
+	other := storeFile(t, repo, "other", "ppd-debit.ach")
+	other.Header.ImmediateOrigin = "031300012"
+
+	// the file of the other origin fails, but the emitted file is marked sent
+	clock.set(t, "2019-06-24 10:30")
+	if err := s.tick(); err == nil || !strings.Contains(err.Error(), "origin 031300012") {
+		t.Fatalf("expected error: %v", err)
+	}
+	if len(sink.files) != 1 {
+		t.Fatalf("released %d files", len(sink.files))
+	}
+	pending := server.PendingFiles(repo, "231380104")
+	if len(pending) != 1 || pending[0].Header.ImmediateOrigin != "031300012" {
+		t.Fatalf("unexpected pending files: %v", pending)
+	}
+	if meta, _ := repo.FindFileMetadata(sink.files[0].ID); meta.Sent != "memory" {
+		t.Errorf("merged file sent=%q", meta.Sent)
+	}
+
+	// only the failed file is released at the next cutoff
+	sink.err = nil
+	clock.set(t, "2019-06-24 14:00")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 2 || sink.files[1].Header.ImmediateOrigin != "031300012" {
+		t.Fatalf("unexpected release: %d files", len(sink.files))
+	}
+}
+
+func TestScheduler__restart(t *testing.T) {
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	sink := &memorySink{}
+	s, repo := testScheduler(t, clock, sink)
+	storeFile(t, repo, "debit", "ppd-debit.ach")
+
+	clock.set(t, "2019-06-24 10:30")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted scheduler doesn't reuse the modifiers of the day
+	s, err := newScheduler(&schedulerConfig{
+		Destinations: []destinationConfig{{ImmediateDestination: "231380104", Cutoffs: []string{"14:00"}}},
+	}, repo, sink, clock, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeFile(t, repo, "debit2", "ppd-debit.ach")
+	clock.set(t, "2019-06-24 14:00")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 2 || sink.files[1].Header.FileIDModifier != "B" {
+		t.Fatalf("expected a second file with modifier B")
+	}
+}
+
+// storeLargeFile stores a file of ppd-debit.ach's first batch repeated batches times with
+// entries entries each, every batch and entry differing by its name and amount
+func storeLargeFile(t *testing.T, repo server.Repository, id string, batches, entries int) {
+	t.Helper()
+	template := readFile(t, "ppd-debit.ach")
+	file := ach.NewFile()
+	file.ID = id
+	file.Header = template.Header
+	for i := 0; i < batches; i++ {
+		bh := *template.Batches[0].GetHeader()
+		bh.CompanyName = fmt.Sprintf("%s %d", id, i)
+		batch, err := ach.NewBatch(&bh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < entries; j++ {
+			ed := *template.Batches[0].GetEntries()[0]
+			ed.Amount = i*entries + j + 1
+			ed.SetTraceNumber(bh.ODFIIdentification, j+1)
+			batch.AddEntry(&ed)
+		}
+		if err := batch.Create(); err != nil {
+			t.Fatal(err)
+		}
+		file.AddBatch(batch)
+	}
+	if err := file.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StoreFile(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduler__releaseLineLimit(t *testing.T) {
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	sink := &memorySink{err: errors.New("bad sink"), failAfter: 1}
+	s, repo := testScheduler(t, clock, sink)
+
+	// together over the line limit, so merged into two files
+	storeLargeFile(t, repo, "first", 60, 90)
+	storeLargeFile(t, repo, "second", 60, 90)
+
+	clock.set(t, "2019-06-24 10:30")
+	if err := s.tick(); err == nil || !strings.Contains(err.Error(), "bad sink") {
+		t.Fatalf("expected error: %v", err)
+	}
+	if len(sink.files) != 1 {
+		t.Fatalf("released %d files", len(sink.files))
+	}
+	pending := server.PendingFiles(repo, "231380104")
+	if len(pending) != 1 || pending[0].ID == sink.files[0].ID {
+		t.Fatalf("unexpected pending files: %v", pending)
+	}
+
+	// only the merged file which failed is released at the next cutoff
+	sink.err = nil
+	clock.set(t, "2019-06-24 14:00")
+	if err := s.tick(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.files) != 2 || sink.files[1].ID != pending[0].ID {
+		t.Fatalf("released %d files", len(sink.files))
+	}
+	batches := 0
+	for _, file := range sink.files {
+		batches += len(file.Batches)
+	}
+	if batches != 120 || sink.files[0].Header.FileIDModifier == sink.files[1].Header.FileIDModifier {
+		t.Errorf("released %d batches with modifiers %s and %s", batches, sink.files[0].Header.FileIDModifier, sink.files[1].Header.FileIDModifier)
+	}
+}
+
+func TestScheduler__config(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scheduler.json")
+	body := `{"timezone": "America/New_York", "destinations": [{"immediateDestination": "231380104", "cutoffs": ["14:00"]}], "sink": {"directory": "` + dir + `"}}`
+	if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readSchedulerConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{}
+	clock.set(t, "2019-06-24 09:00")
+	s, err := newScheduler(cfg, nil, nil, clock, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.next(); !next.Equal(time.Date(2019, 6, 24, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("next=%v", next)
+	}
+	if _, err := newSink(cfg.Sink, nil); err != nil {
+		t.Error(err)
+	}
+
+	cases := []schedulerConfig{
+		{Timezone: "Mars/Olympus_Mons"},
+		{Destinations: []destinationConfig{{Cutoffs: []string{"14:00"}}}},
+		{Destinations: []destinationConfig{{ImmediateDestination: "231380104"}}},
+		{Destinations: []destinationConfig{{ImmediateDestination: "231380104", Cutoffs: []string{"2pm"}}}},
+		{Destinations: []destinationConfig{{ImmediateDestination: "231380104", Cutoffs: []string{"14:00"}, Timezone: "Mars/Olympus_Mons"}}},
+		{Destinations: []destinationConfig{
+			{ImmediateDestination: "231380104", Cutoffs: []string{"14:00"}},
+			{ImmediateDestination: "231380104", Cutoffs: []string{"16:00"}},
+		}},
+	}
+	for i := range cases {
+		if _, err := newScheduler(&cases[i], nil, nil, clock, log.NewNopLogger()); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSchedulerConfig(path); err == nil {
+		t.Error("expected error")
+	}
+}
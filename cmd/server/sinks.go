@@ -0,0 +1,148 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/achsftp"
+)
+
+// sinkConfig picks where released files are emitted, exactly one option must be set
+type sinkConfig struct {
+	// Directory files are written into
+	Directory string `json:"directory,omitempty"`
+	// SFTP uploads files to the ODFI of their destination with the SFTP_CONFIG agent
+	SFTP bool `json:"sftp,omitempty"`
+	// Webhook is a URL files are POSTed to
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// sink receives merged files at each cutoff and returns where they were emitted
+type sink interface {
+	emit(destination string, file *ach.File) (string, error)
+}
+
+// newSink returns the sink of cfg, agent is required for SFTP
+func newSink(cfg sinkConfig, agent *achsftp.Agent) (sink, error) {
+	n := 0
+	for _, set := range []bool{cfg.Directory != "", cfg.SFTP, cfg.Webhook != ""} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return nil, errors.New("sink requires exactly one of directory, sftp or webhook")
+	}
+	switch {
+	case cfg.Directory != "":
+		if fi, err := os.Stat(cfg.Directory); err != nil || !fi.IsDir() {
+			return nil, fmt.Errorf("sink directory %s is not a directory", cfg.Directory)
+		}
+		return &dirSink{dir: cfg.Directory}, nil
+	case cfg.SFTP:
+		if agent == nil {
+			return nil, errors.New("sftp sink requires SFTP_CONFIG")
+		}
+		return &sftpSink{agent: agent}, nil
+	default:
+		return &webhookSink{
+			url:    cfg.Webhook,
+			client: &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	}
+}
+
+func writeFile(file *ach.File) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ach.NewWriter(&buf).Write(file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dirSink writes files into a directory, never overwriting an existing file
+type dirSink struct {
+	dir string
+}
+
+func (s *dirSink) emit(destination string, file *ach.File) (string, error) {
+	bs, err := writeFile(file)
+	if err != nil {
+		return "", err
+	}
+	fh := file.Header
+	name := fmt.Sprintf("%s-%s-%s-%s.ach", destination, fh.FileCreationDate, fh.FileCreationTime, fh.FileIDModifier)
+	path := filepath.Join(s.dir, name)
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fd.Write(bs); err != nil {
+		fd.Close()
+		return "", err
+	}
+	return path, fd.Close()
+}
+
+// sftpSink uploads files to the ODFI of their destination
+type sftpSink struct {
+	agent *achsftp.Agent
+}
+
+func (s *sftpSink) emit(destination string, file *ach.File) (string, error) {
+	return s.agent.UploadFile(destination, file)
+}
+
+// webhookSink POSTs files as text/plain and expects a 2xx response
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) emit(destination string, file *ach.File) (string, error) {
+	bs, err := writeFile(file)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(bs))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Immediate-Destination", destination)
+	req.Header.Set("X-File-ID", file.ID)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("webhook %s returned %s", s.url, resp.Status)
+	}
+	return s.url, nil
+}
@@ -0,0 +1,117 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/server"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestSinks__newSink(t *testing.T) {
+	cases := []sinkConfig{
+		{},
+		{Directory: "/does/not/exist"},
+		{SFTP: true},
+		{Directory: os.TempDir(), Webhook: "http://localhost"},
+	}
+	for i := range cases {
+		if _, err := newSink(cases[i], nil); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if s, err := newSink(sinkConfig{Webhook: "http://localhost"}, nil); err != nil {
+		t.Error(err)
+	} else if _, ok := s.(*webhookSink); !ok {
+		t.Errorf("unexpected sink %T", s)
+	}
+}
+
+func TestSinks__dir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo := server.NewRepositoryInMemory(0, log.NewNopLogger())
+	file := storeFile(t, repo, "debit", "ppd-debit.ach")
+
+	s := &dirSink{dir: dir}
+	path, err := s.emit("231380104", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := filepath.Base(path); base != "231380104-190624-0000-A.ach" {
+		t.Errorf("unexpected name %s", base)
+	}
+	fd, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if _, err := ach.NewReader(fd).Read(); err != nil {
+		t.Fatal(err)
+	}
+
+	// never overwrite a released file
+	if _, err := s.emit("231380104", file); !os.IsExist(err) {
+		t.Errorf("expected exists error: %v", err)
+	}
+}
+
+func TestSinks__webhook(t *testing.T) {
+	var got *ach.File
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get("X-Immediate-Destination"); v != "231380104" {
+			t.Errorf("X-Immediate-Destination=%q", v)
+		}
+		file, err := ach.NewReader(r.Body).Read()
+		if err != nil {
+			t.Error(err)
+		}
+		got = &file
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	repo := server.NewRepositoryInMemory(0, log.NewNopLogger())
+	file := storeFile(t, repo, "debit", "ppd-debit.ach")
+
+	s := &webhookSink{url: srv.URL, client: srv.Client()}
+	if _, err := s.emit("231380104", file); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Header.ImmediateDestination != "231380104" || len(got.Batches) != 1 {
+		t.Errorf("unexpected file: %#v", got)
+	}
+
+	status = http.StatusBadGateway
+	if _, err := s.emit("231380104", file); err == nil {
+		t.Error("expected error")
+	}
+}
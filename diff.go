@@ -0,0 +1,585 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Change describes how a batch or entry differs between two files
+type Change string
+
+const (
+	// ChangeAdded is a batch or entry only found in the second file
+	ChangeAdded Change = "added"
+	// ChangeRemoved is a batch or entry only found in the first file
+	ChangeRemoved Change = "removed"
+	// ChangeModified is a batch or entry found in both files with different fields
+	ChangeModified Change = "modified"
+)
+
+// FileDiff is the result of comparing two files with Diff
+type FileDiff struct {
+	// Header lists the FileHeader fields which differ
+	Header []FieldChange `json:"header,omitempty"`
+	// Control lists the FileControl totals which differ
+	Control []ControlDelta `json:"control,omitempty"`
+	// Batches lists the batches which were added, removed or modified
+	Batches []BatchDiff `json:"batches,omitempty"`
+}
+
+// FieldChange is a record field whose value differs between two files
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ControlDelta is a control total whose value differs between two files
+type ControlDelta struct {
+	Field string `json:"field"`
+	Old   int    `json:"old"`
+	New   int    `json:"new"`
+	Delta int    `json:"delta"`
+}
+
+// BatchDiff describes a batch which was added, removed or modified
+type BatchDiff struct {
+	Change Change `json:"change"`
+	// OldBatchNumber and NewBatchNumber are the batch numbers in each file, zero when
+	// the batch is not found in that file.
+	OldBatchNumber         int    `json:"oldBatchNumber,omitempty"`
+	NewBatchNumber         int    `json:"newBatchNumber,omitempty"`
+	StandardEntryClassCode string `json:"standardEntryClassCode"`
+	CompanyName            string `json:"companyName,omitempty"`
+
+	Header  []FieldChange  `json:"header,omitempty"`
+	Control []ControlDelta `json:"control,omitempty"`
+	Entries []EntryDiff    `json:"entries,omitempty"`
+}
+
+// EntryDiff describes an entry which was added, removed or modified
+type EntryDiff struct {
+	Change Change `json:"change"`
+	// TraceNumber is read from the first file, unless the entry was added
+	TraceNumber      string        `json:"traceNumber"`
+	DFIAccountNumber string        `json:"DFIAccountNumber"`
+	Amount           int           `json:"amount"`
+	Fields           []FieldChange `json:"fields,omitempty"`
+}
+
+// Empty returns true when both files were found to be equivalent
+func (d *FileDiff) Empty() bool {
+	return d == nil || (len(d.Header) == 0 && len(d.Control) == 0 && len(d.Batches) == 0)
+}
+
+// Diff compares two files, usually an original file and the corrected file a partner
+// resent, and describes what changed from a to b.
+//
+// Batches are matched on their header. A batch whose header changed is still matched on
+// its StandardEntryClassCode, CompanyIdentification, CompanyEntryDescription and
+// ODFIIdentification and reported as modified. Entries are matched on their trace number,
+// falling back to the receiving account and amount. ADV entries are not compared, only
+// their batch totals.
+func Diff(a, b *File) *FileDiff {
+	if a == nil {
+		a = NewFile()
+	}
+	if b == nil {
+		b = NewFile()
+	}
+	d := &FileDiff{
+		Header:  fieldChanges(fileHeaderFields(a.Header), fileHeaderFields(b.Header)),
+		Control: controlDeltas(fileControlFields(a.Control), fileControlFields(b.Control)),
+	}
+
+	batchesA, batchesB := diffBatches(a), diffBatches(b)
+	matches := matchBatches(batchesA, batchesB)
+	matched := make(map[int]bool)
+	for i, ba := range batchesA {
+		j, ok := matches[i]
+		if !ok {
+			d.Batches = append(d.Batches, BatchDiff{
+				Change:                 ChangeRemoved,
+				OldBatchNumber:         ba.number,
+				StandardEntryClassCode: ba.sec,
+				CompanyName:            ba.companyName,
+			})
+			continue
+		}
+		matched[j] = true
+		bb := batchesB[j]
+		bd := BatchDiff{
+			Change:                 ChangeModified,
+			OldBatchNumber:         ba.number,
+			NewBatchNumber:         bb.number,
+			StandardEntryClassCode: bb.sec,
+			CompanyName:            bb.companyName,
+			Header:                 fieldChanges(ba.header, bb.header),
+			Control:                controlDeltas(ba.control, bb.control),
+			Entries:                diffEntries(ba.entries, bb.entries),
+		}
+		if len(bd.Header) > 0 || len(bd.Control) > 0 || len(bd.Entries) > 0 {
+			d.Batches = append(d.Batches, bd)
+		}
+	}
+	for j, bb := range batchesB {
+		if !matched[j] {
+			d.Batches = append(d.Batches, BatchDiff{
+				Change:                 ChangeAdded,
+				NewBatchNumber:         bb.number,
+				StandardEntryClassCode: bb.sec,
+				CompanyName:            bb.companyName,
+			})
+		}
+	}
+	return d
+}
+
+// String renders the differences as indented text, one change per line. Removed batches
+// and entries are prefixed with '-', added ones with '+' and modified ones with '~'.
+func (d *FileDiff) String() string {
+	if d.Empty() {
+		return ""
+	}
+	var buf strings.Builder
+	writeFieldChanges(&buf, "FileHeader", "", d.Header)
+	writeControlDeltas(&buf, "FileControl", "", d.Control)
+	for _, bd := range d.Batches {
+		number := bd.OldBatchNumber
+		if bd.Change == ChangeAdded {
+			number = bd.NewBatchNumber
+		}
+		fmt.Fprintf(&buf, "%s batch %d %s %s", changeSymbol(bd.Change), number, bd.StandardEntryClassCode, bd.CompanyName)
+		if bd.Change == ChangeModified && bd.OldBatchNumber != bd.NewBatchNumber {
+			fmt.Fprintf(&buf, " (now batch %d)", bd.NewBatchNumber)
+		}
+		buf.WriteString("\n")
+		writeFieldChanges(&buf, "BatchHeader", "    ", bd.Header)
+		for _, ed := range bd.Entries {
+			fmt.Fprintf(&buf, "    %s entry %s account %s amount %d\n", changeSymbol(ed.Change), ed.TraceNumber, ed.DFIAccountNumber, ed.Amount)
+			for _, fc := range ed.Fields {
+				fmt.Fprintf(&buf, "        %s: %q => %q\n", fc.Field, fc.Old, fc.New)
+			}
+		}
+		writeControlDeltas(&buf, "BatchControl", "    ", bd.Control)
+	}
+	return buf.String()
+}
+
+func changeSymbol(c Change) string {
+	switch c {
+	case ChangeAdded:
+		return "+"
+	case ChangeRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func writeFieldChanges(buf *strings.Builder, record, indent string, changes []FieldChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "%s%s\n", indent, record)
+	for _, fc := range changes {
+		fmt.Fprintf(buf, "%s    %s: %q => %q\n", indent, fc.Field, fc.Old, fc.New)
+	}
+}
+
+func writeControlDeltas(buf *strings.Builder, record, indent string, deltas []ControlDelta) {
+	if len(deltas) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "%s%s\n", indent, record)
+	for _, cd := range deltas {
+		fmt.Fprintf(buf, "%s    %s: %d => %d (%+d)\n", indent, cd.Field, cd.Old, cd.New, cd.Delta)
+	}
+}
+
+// diffField is a named record field rendered as a string for comparison
+type diffField struct {
+	name  string
+	value string
+}
+
+// diffTotal is a named control total
+type diffTotal struct {
+	name  string
+	value int
+}
+
+// diffBatch holds the fields of a Batch or IATBatch which Diff compares
+type diffBatch struct {
+	key         string
+	number      int
+	sec         string
+	companyName string
+	header      []diffField
+	control     []diffTotal
+	entries     []diffEntry
+}
+
+// diffEntry holds the fields of an EntryDetail or IATEntryDetail which Diff compares
+type diffEntry struct {
+	traceNumber string
+	account     string
+	amount      int
+	fields      []diffField
+}
+
+// fieldChanges compares fields by name, a field missing from either side has an empty value
+func fieldChanges(a, b []diffField) []FieldChange {
+	values := make(map[string]string, len(b))
+	for _, f := range b {
+		values[f.name] = f.value
+	}
+	var out []FieldChange
+	seen := make(map[string]bool, len(a))
+	for _, f := range a {
+		seen[f.name] = true
+		if f.value != values[f.name] {
+			out = append(out, FieldChange{Field: f.name, Old: f.value, New: values[f.name]})
+		}
+	}
+	for _, f := range b {
+		if !seen[f.name] && f.value != "" {
+			out = append(out, FieldChange{Field: f.name, New: f.value})
+		}
+	}
+	return out
+}
+
+func controlDeltas(a, b []diffTotal) []ControlDelta {
+	var out []ControlDelta
+	for i := range a {
+		if i < len(b) && a[i].value != b[i].value {
+			out = append(out, ControlDelta{Field: a[i].name, Old: a[i].value, New: b[i].value, Delta: b[i].value - a[i].value})
+		}
+	}
+	return out
+}
+
+// headerValue is the part of a header compared when matching batches, which excludes the batch number
+func (b diffBatch) headerValue() string {
+	var buf strings.Builder
+	for _, f := range b.header {
+		if f.name != "BatchNumber" {
+			buf.WriteString(f.value)
+			buf.WriteString("|")
+		}
+	}
+	return buf.String()
+}
+
+// matchBatches returns the index in b of each batch in a with an equivalent batch. Batches
+// are first matched on their header and then on their key.
+func matchBatches(a, b []diffBatch) map[int]int {
+	matches := make(map[int]int)
+	used := make(map[int]bool)
+	for _, key := range []func(x diffBatch) string{
+		func(x diffBatch) string { return x.headerValue() },
+		func(x diffBatch) string { return x.key },
+	} {
+		matchKeys(matches, used, len(a), len(b), func(i int) string { return key(a[i]) }, func(j int) string { return key(b[j]) })
+	}
+	return matches
+}
+
+// matchKeys matches each unmatched index of a to the first unused index of b with the same
+// key, recording the pair in matches and used. Empty keys never match.
+func matchKeys(matches map[int]int, used map[int]bool, lenA, lenB int, keyA, keyB func(int) string) {
+	index := make(map[string][]int)
+	for j := 0; j < lenB; j++ {
+		if k := keyB(j); k != "" && !used[j] {
+			index[k] = append(index[k], j)
+		}
+	}
+	for i := 0; i < lenA; i++ {
+		if _, ok := matches[i]; ok {
+			continue
+		}
+		k := keyA(i)
+		if js := index[k]; k != "" && len(js) > 0 {
+			matches[i] = js[0]
+			used[js[0]] = true
+			index[k] = js[1:]
+		}
+	}
+}
+
+// diffEntries matches entries on their trace number and then on account and amount
+func diffEntries(a, b []diffEntry) []EntryDiff {
+	matches := make(map[int]int)
+	used := make(map[int]bool)
+	for _, key := range []func(x diffEntry) string{
+		func(x diffEntry) string { return x.traceNumber },
+		func(x diffEntry) string { return fmt.Sprintf("%s|%d", x.account, x.amount) },
+	} {
+		matchKeys(matches, used, len(a), len(b), func(i int) string { return key(a[i]) }, func(j int) string { return key(b[j]) })
+	}
+
+	var out []EntryDiff
+	for i, ea := range a {
+		j, ok := matches[i]
+		if !ok {
+			out = append(out, EntryDiff{Change: ChangeRemoved, TraceNumber: ea.traceNumber, DFIAccountNumber: ea.account, Amount: ea.amount})
+			continue
+		}
+		if changes := fieldChanges(ea.fields, b[j].fields); len(changes) > 0 {
+			out = append(out, EntryDiff{Change: ChangeModified, TraceNumber: ea.traceNumber, DFIAccountNumber: ea.account, Amount: ea.amount, Fields: changes})
+		}
+	}
+	for j, eb := range b {
+		if !used[j] {
+			out = append(out, EntryDiff{Change: ChangeAdded, TraceNumber: eb.traceNumber, DFIAccountNumber: eb.account, Amount: eb.amount})
+		}
+	}
+	return out
+}
+
+func fileHeaderFields(fh FileHeader) []diffField {
+	return []diffField{
+		{"ImmediateDestination", strings.TrimSpace(fh.ImmediateDestination)},
+		{"ImmediateOrigin", strings.TrimSpace(fh.ImmediateOrigin)},
+		{"FileCreationDate", fh.FileCreationDate},
+		{"FileCreationTime", fh.FileCreationTime},
+		{"FileIDModifier", fh.FileIDModifier},
+		{"ImmediateDestinationName", strings.TrimSpace(fh.ImmediateDestinationName)},
+		{"ImmediateOriginName", strings.TrimSpace(fh.ImmediateOriginName)},
+		{"ReferenceCode", strings.TrimSpace(fh.ReferenceCode)},
+	}
+}
+
+func fileControlFields(fc FileControl) []diffTotal {
+	return []diffTotal{
+		{"BatchCount", fc.BatchCount},
+		{"BlockCount", fc.BlockCount},
+		{"EntryAddendaCount", fc.EntryAddendaCount},
+		{"EntryHash", fc.EntryHash},
+		{"TotalDebitEntryDollarAmountInFile", fc.TotalDebitEntryDollarAmountInFile},
+		{"TotalCreditEntryDollarAmountInFile", fc.TotalCreditEntryDollarAmountInFile},
+	}
+}
+
+func batchControlFields(bc *BatchControl) []diffTotal {
+	if bc == nil {
+		bc = NewBatchControl()
+	}
+	return []diffTotal{
+		{"EntryAddendaCount", bc.EntryAddendaCount},
+		{"EntryHash", bc.EntryHash},
+		{"TotalDebitEntryDollarAmount", bc.TotalDebitEntryDollarAmount},
+		{"TotalCreditEntryDollarAmount", bc.TotalCreditEntryDollarAmount},
+	}
+}
+
+func diffBatches(f *File) []diffBatch {
+	var out []diffBatch
+	for _, batch := range f.Batches {
+		bh := batch.GetHeader()
+		if bh == nil {
+			continue
+		}
+		db := diffBatch{
+			key:         strings.Join([]string{bh.StandardEntryClassCode, strings.TrimSpace(bh.CompanyIdentification), strings.TrimSpace(bh.CompanyEntryDescription), bh.ODFIIdentification}, "|"),
+			number:      bh.BatchNumber,
+			sec:         bh.StandardEntryClassCode,
+			companyName: strings.TrimSpace(bh.CompanyName),
+			header: []diffField{
+				{"ServiceClassCode", strconv.Itoa(bh.ServiceClassCode)},
+				{"CompanyName", strings.TrimSpace(bh.CompanyName)},
+				{"CompanyDiscretionaryData", strings.TrimSpace(bh.CompanyDiscretionaryData)},
+				{"CompanyIdentification", strings.TrimSpace(bh.CompanyIdentification)},
+				{"StandardEntryClassCode", bh.StandardEntryClassCode},
+				{"CompanyEntryDescription", strings.TrimSpace(bh.CompanyEntryDescription)},
+				{"CompanyDescriptiveDate", strings.TrimSpace(bh.CompanyDescriptiveDate)},
+				{"EffectiveEntryDate", bh.EffectiveEntryDate},
+				{"OriginatorStatusCode", strconv.Itoa(bh.OriginatorStatusCode)},
+				{"ODFIIdentification", bh.ODFIIdentification},
+				{"BatchNumber", strconv.Itoa(bh.BatchNumber)},
+			},
+			control: batchControlFields(batch.GetControl()),
+		}
+		for _, ed := range batch.GetEntries() {
+			db.entries = append(db.entries, entryDetailFields(ed))
+		}
+		out = append(out, db)
+	}
+	for i := range f.IATBatches {
+		bh := f.IATBatches[i].Header
+		if bh == nil {
+			continue
+		}
+		db := diffBatch{
+			key:         strings.Join([]string{bh.StandardEntryClassCode, strings.TrimSpace(bh.OriginatorIdentification), strings.TrimSpace(bh.CompanyEntryDescription), bh.ODFIIdentification}, "|"),
+			number:      bh.BatchNumber,
+			sec:         bh.StandardEntryClassCode,
+			companyName: strings.TrimSpace(bh.OriginatorIdentification),
+			header: []diffField{
+				{"ServiceClassCode", strconv.Itoa(bh.ServiceClassCode)},
+				{"IATIndicator", strings.TrimSpace(bh.IATIndicator)},
+				{"ForeignExchangeIndicator", bh.ForeignExchangeIndicator},
+				{"ForeignExchangeReferenceIndicator", strconv.Itoa(bh.ForeignExchangeReferenceIndicator)},
+				{"ForeignExchangeReference", strings.TrimSpace(bh.ForeignExchangeReference)},
+				{"ISODestinationCountryCode", bh.ISODestinationCountryCode},
+				{"OriginatorIdentification", strings.TrimSpace(bh.OriginatorIdentification)},
+				{"StandardEntryClassCode", bh.StandardEntryClassCode},
+				{"CompanyEntryDescription", strings.TrimSpace(bh.CompanyEntryDescription)},
+				{"ISOOriginatingCurrencyCode", bh.ISOOriginatingCurrencyCode},
+				{"ISODestinationCurrencyCode", bh.ISODestinationCurrencyCode},
+				{"EffectiveEntryDate", bh.EffectiveEntryDate},
+				{"OriginatorStatusCode", strconv.Itoa(bh.OriginatorStatusCode)},
+				{"ODFIIdentification", bh.ODFIIdentification},
+				{"BatchNumber", strconv.Itoa(bh.BatchNumber)},
+			},
+			control: batchControlFields(f.IATBatches[i].Control),
+		}
+		for _, ed := range f.IATBatches[i].Entries {
+			db.entries = append(db.entries, iatEntryDetailFields(ed))
+		}
+		out = append(out, db)
+	}
+	return out
+}
+
+// addendaRecord is implemented by every addenda addendaRecord
+type addendaRecord interface {
+	String() string
+}
+
+// addendaFields renders each addenda record, using an empty value when absent
+func addendaFields(names []string, records []addendaRecord) []diffField {
+	out := make([]diffField, len(names))
+	for i := range names {
+		out[i].name = names[i]
+		if records[i] != nil {
+			out[i].value = records[i].String()
+		}
+	}
+	return out
+}
+
+// addendaListFields renders a list of addenda records under indexed names, e.g. Addenda05[1]
+func addendaListFields(name string, records []addendaRecord) []diffField {
+	out := make([]diffField, len(records))
+	for i := range records {
+		out[i] = diffField{fmt.Sprintf("%s[%d]", name, i), records[i].String()}
+	}
+	return out
+}
+
+func entryDetailFields(ed *EntryDetail) diffEntry {
+	de := diffEntry{
+		traceNumber: ed.TraceNumber,
+		account:     strings.TrimSpace(ed.DFIAccountNumber),
+		amount:      ed.Amount,
+		fields: []diffField{
+			{"TransactionCode", strconv.Itoa(ed.TransactionCode)},
+			{"RDFIIdentification", ed.RDFIIdentification},
+			{"CheckDigit", ed.CheckDigit},
+			{"DFIAccountNumber", strings.TrimSpace(ed.DFIAccountNumber)},
+			{"Amount", strconv.Itoa(ed.Amount)},
+			{"IdentificationNumber", strings.TrimSpace(ed.IdentificationNumber)},
+			{"IndividualName", strings.TrimSpace(ed.IndividualName)},
+			{"DiscretionaryData", strings.TrimSpace(ed.DiscretionaryData)},
+			{"AddendaRecordIndicator", strconv.Itoa(ed.AddendaRecordIndicator)},
+			{"TraceNumber", ed.TraceNumber},
+		},
+	}
+	var a02, a98, a99 addendaRecord
+	if ed.Addenda02 != nil {
+		a02 = ed.Addenda02
+	}
+	if ed.Addenda98 != nil {
+		a98 = ed.Addenda98
+	}
+	if ed.Addenda99 != nil {
+		a99 = ed.Addenda99
+	}
+	de.fields = append(de.fields, addendaFields([]string{"Addenda02", "Addenda98", "Addenda99"}, []addendaRecord{a02, a98, a99})...)
+	var a05 []addendaRecord
+	for _, a := range ed.Addenda05 {
+		a05 = append(a05, a)
+	}
+	de.fields = append(de.fields, addendaListFields("Addenda05", a05)...)
+	return de
+}
+
+func iatEntryDetailFields(ed *IATEntryDetail) diffEntry {
+	de := diffEntry{
+		traceNumber: ed.TraceNumber,
+		account:     strings.TrimSpace(ed.DFIAccountNumber),
+		amount:      ed.Amount,
+		fields: []diffField{
+			{"TransactionCode", strconv.Itoa(ed.TransactionCode)},
+			{"RDFIIdentification", ed.RDFIIdentification},
+			{"CheckDigit", ed.CheckDigit},
+			{"AddendaRecords", strconv.Itoa(ed.AddendaRecords)},
+			{"Amount", strconv.Itoa(ed.Amount)},
+			{"DFIAccountNumber", strings.TrimSpace(ed.DFIAccountNumber)},
+			{"OFACScreeningIndicator", strings.TrimSpace(ed.OFACScreeningIndicator)},
+			{"SecondaryOFACScreeningIndicator", strings.TrimSpace(ed.SecondaryOFACScreeningIndicator)},
+			{"AddendaRecordIndicator", strconv.Itoa(ed.AddendaRecordIndicator)},
+			{"TraceNumber", ed.TraceNumber},
+		},
+	}
+	records := make([]addendaRecord, 9)
+	if ed.Addenda10 != nil {
+		records[0] = ed.Addenda10
+	}
+	if ed.Addenda11 != nil {
+		records[1] = ed.Addenda11
+	}
+	if ed.Addenda12 != nil {
+		records[2] = ed.Addenda12
+	}
+	if ed.Addenda13 != nil {
+		records[3] = ed.Addenda13
+	}
+	if ed.Addenda14 != nil {
+		records[4] = ed.Addenda14
+	}
+	if ed.Addenda15 != nil {
+		records[5] = ed.Addenda15
+	}
+	if ed.Addenda16 != nil {
+		records[6] = ed.Addenda16
+	}
+	if ed.Addenda98 != nil {
+		records[7] = ed.Addenda98
+	}
+	if ed.Addenda99 != nil {
+		records[8] = ed.Addenda99
+	}
+	names := []string{"Addenda10", "Addenda11", "Addenda12", "Addenda13", "Addenda14", "Addenda15", "Addenda16", "Addenda98", "Addenda99"}
+	de.fields = append(de.fields, addendaFields(names, records)...)
+	var a17, a18 []addendaRecord
+	for _, a := range ed.Addenda17 {
+		a17 = append(a17, a)
+	}
+	for _, a := range ed.Addenda18 {
+		a18 = append(a18, a)
+	}
+	de.fields = append(de.fields, addendaListFields("Addenda17", a17)...)
+	de.fields = append(de.fields, addendaListFields("Addenda18", a18)...)
+	return de
+}
@@ -0,0 +1,199 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff__Identical(t *testing.T) {
+	for _, name := range []string{"ppd-mixedDebitCredit.ach", "iat-mixedDebitCredit.ach", "20110805A.ach"} {
+		a, err := readACHFilepath(filepath.Join("test", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := readACHFilepath(filepath.Join("test", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := Diff(a, b); !d.Empty() {
+			t.Errorf("%s: unexpected diff:\n%s", name, d)
+		}
+	}
+}
+
+func TestDiff__Corrected(t *testing.T) {
+	a, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit-corrected.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Diff(a, b)
+
+	if len(d.Header) != 1 || d.Header[0] != (FieldChange{Field: "FileCreationTime", Old: "1055", New: "1130"}) {
+		t.Errorf("unexpected header changes: %#v", d.Header)
+	}
+	if len(d.Control) != 1 || d.Control[0] != (ControlDelta{Field: "TotalCreditEntryDollarAmountInFile", Old: 200000000, New: 225000000, Delta: 25000000}) {
+		t.Errorf("unexpected control deltas: %#v", d.Control)
+	}
+	if len(d.Batches) != 1 {
+		t.Fatalf("got %d batch diffs", len(d.Batches))
+	}
+	bd := d.Batches[0]
+	if bd.Change != ChangeModified || bd.OldBatchNumber != 1 || bd.NewBatchNumber != 1 || len(bd.Header) != 0 {
+		t.Errorf("unexpected batch diff: %#v", bd)
+	}
+	if len(bd.Control) != 1 || bd.Control[0].Field != "TotalCreditEntryDollarAmount" || bd.Control[0].Delta != 25000000 {
+		t.Errorf("unexpected batch control deltas: %#v", bd.Control)
+	}
+
+	expected := []struct {
+		change Change
+		trace  string
+		fields []string
+	}{
+		{ChangeModified, "121042880000001", []string{"TraceNumber"}}, // matched on account and amount
+		{ChangeModified, "121042880000002", []string{"Amount"}},
+		{ChangeRemoved, "121042880000003", nil},
+		{ChangeAdded, "121042880000004", nil},
+	}
+	if len(bd.Entries) != len(expected) {
+		t.Fatalf("got %d entry diffs: %#v", len(bd.Entries), bd.Entries)
+	}
+	for i, exp := range expected {
+		ed := bd.Entries[i]
+		if ed.Change != exp.change || ed.TraceNumber != exp.trace || len(ed.Fields) != len(exp.fields) {
+			t.Errorf("entry %d: unexpected diff: %#v", i, ed)
+			continue
+		}
+		for j := range exp.fields {
+			if ed.Fields[j].Field != exp.fields[j] {
+				t.Errorf("entry %d: field %s changed, expected %s", i, ed.Fields[j].Field, exp.fields[j])
+			}
+		}
+	}
+
+	text := d.String()
+	for _, line := range []string{
+		`    FileCreationTime: "1055" => "1130"`,
+		`    TotalCreditEntryDollarAmountInFile: 200000000 => 225000000 (+25000000)`,
+		`~ batch 1 PPD Name on Account`,
+		`        Amount: "100000000" => "150000000"`,
+		`    - entry 121042880000003 account 837098765 amount 100000000`,
+		`    + entry 121042880000004 account 555444333 amount 75000000`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestDiff__Batches(t *testing.T) {
+	a := mockFilePPD()
+	b := mockFilePPD()
+
+	// a changed header is still matched on company and SEC code
+	b.Batches[0].GetHeader().EffectiveEntryDate = "190817"
+	d := Diff(a, b)
+	if len(d.Batches) != 1 || d.Batches[0].Change != ChangeModified {
+		t.Fatalf("unexpected batch diffs: %#v", d.Batches)
+	}
+	if h := d.Batches[0].Header; len(h) != 1 || h[0].Field != "EffectiveEntryDate" || h[0].New != "190817" {
+		t.Errorf("unexpected header changes: %#v", h)
+	}
+
+	// a batch for another company is added and the original removed
+	b.Batches[0].GetHeader().CompanyIdentification = "987654321"
+	d = Diff(a, b)
+	if len(d.Batches) != 2 {
+		t.Fatalf("unexpected batch diffs: %#v", d.Batches)
+	}
+	if d.Batches[0].Change != ChangeRemoved || d.Batches[0].OldBatchNumber != 1 || d.Batches[0].NewBatchNumber != 0 {
+		t.Errorf("unexpected removed batch: %#v", d.Batches[0])
+	}
+	if d.Batches[1].Change != ChangeAdded || d.Batches[1].NewBatchNumber != 1 {
+		t.Errorf("unexpected added batch: %#v", d.Batches[1])
+	}
+	if text := d.String(); !strings.Contains(text, "- batch 1 PPD") || !strings.Contains(text, "+ batch 1 PPD") {
+		t.Errorf("unexpected text:\n%s", text)
+	}
+
+	// an empty file removes every batch
+	d = Diff(a, nil)
+	if len(d.Batches) != 1 || d.Batches[0].Change != ChangeRemoved {
+		t.Errorf("unexpected batch diffs: %#v", d.Batches)
+	}
+}
+
+func TestDiff__manyEntries(t *testing.T) {
+	// renumbered entries are matched on account and amount, duplicates in order
+	var a, b []diffEntry
+	for i := 0; i < 20000; i++ {
+		account := fmt.Sprintf("%d", i/2)
+		a = append(a, diffEntry{traceNumber: fmt.Sprintf("1%014d", i), account: account, amount: 100, fields: []diffField{{"Seq", fmt.Sprintf("%d", i%2)}}})
+		b = append(b, diffEntry{traceNumber: fmt.Sprintf("2%014d", i), account: account, amount: 100, fields: []diffField{{"Seq", fmt.Sprintf("%d", i%2)}}})
+	}
+	b[len(b)-1].amount = 200
+	out := diffEntries(a, b)
+	if len(out) != 2 || out[0].Change != ChangeRemoved || out[1].Change != ChangeAdded || out[1].Amount != 200 {
+		t.Errorf("unexpected entry diffs: %#v", out)
+	}
+}
+
+func TestDiff__Addenda(t *testing.T) {
+	a := mockFilePPD()
+	b := mockFilePPD()
+
+	addenda05 := NewAddenda05()
+	addenda05.PaymentRelatedInformation = "Invoice 1234"
+	addenda05.SequenceNumber = 1
+	addenda05.EntryDetailSequenceNumber = 1
+	b.Batches[0].GetEntries()[0].AddAddenda05(addenda05)
+
+	d := Diff(a, b)
+	if len(d.Batches) != 1 || len(d.Batches[0].Entries) != 1 {
+		t.Fatalf("unexpected batch diffs: %#v", d.Batches)
+	}
+	fields := d.Batches[0].Entries[0].Fields
+	if len(fields) != 1 || fields[0].Field != "Addenda05[0]" || fields[0].Old != "" || !strings.Contains(fields[0].New, "Invoice 1234") {
+		t.Errorf("unexpected field changes: %#v", fields)
+	}
+
+	// and in reverse the addenda is removed
+	d = Diff(b, a)
+	fields = d.Batches[0].Entries[0].Fields
+	if len(fields) != 1 || fields[0].Field != "Addenda05[0]" || fields[0].New != "" {
+		t.Errorf("unexpected field changes: %#v", fields)
+	}
+}
+
+func TestDiff__Empty(t *testing.T) {
+	var d *FileDiff
+	if !d.Empty() || d.String() != "" {
+		t.Error("expected nil diff to be empty")
+	}
+	if d := Diff(nil, nil); !d.Empty() {
+		t.Errorf("unexpected diff: %s", d)
+	}
+}
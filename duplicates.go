@@ -0,0 +1,170 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FileIDModifiers are the values of FileHeader.FileIDModifier in the order they are assigned
+const FileIDModifiers = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// ErrModifiersExhausted is returned by ModifierAllocator when every FileIDModifier of the day is used
+var ErrModifiersExhausted = errors.New("every FileIDModifier is used")
+
+// ModifierAllocator tracks the FileIDModifier of files per ImmediateOrigin, ImmediateDestination
+// and FileCreationDate and assigns unused ones. NACHA treats files sharing all four as duplicates.
+//
+// A ModifierAllocator is safe for concurrent use.
+type ModifierAllocator struct {
+	mu   sync.Mutex
+	used map[string]string // modifiers used per headerKey
+}
+
+// NewModifierAllocator returns a ModifierAllocator with no modifiers used
+func NewModifierAllocator() *ModifierAllocator {
+	return &ModifierAllocator{used: make(map[string]string)}
+}
+
+// headerKey identifies the files whose FileIDModifier must differ
+func headerKey(fh FileHeader) string {
+	return strings.Join([]string{
+		strings.TrimSpace(fh.ImmediateOrigin),
+		strings.TrimSpace(fh.ImmediateDestination),
+		fh.FileCreationDate,
+	}, "/")
+}
+
+// Observe records the FileIDModifier of previously sent files as used
+func (a *ModifierAllocator) Observe(files ...*File) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, file := range files {
+		if file == nil {
+			continue
+		}
+		key, modifier := headerKey(file.Header), file.Header.FileIDModifier
+		if modifier != "" && !strings.Contains(a.used[key], modifier) {
+			a.used[key] += modifier
+		}
+	}
+}
+
+// Used returns true if the FileIDModifier of fh is used by another file of the day
+func (a *ModifierAllocator) Used(fh FileHeader) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return fh.FileIDModifier != "" && strings.Contains(a.used[headerKey(fh)], fh.FileIDModifier)
+}
+
+// Next returns the first unused FileIDModifier for the origin, destination and creation
+// date of fh and records it as used.
+func (a *ModifierAllocator) Next(fh FileHeader) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := headerKey(fh)
+	for i := 0; i < len(FileIDModifiers); i++ {
+		modifier := FileIDModifiers[i : i+1]
+		if !strings.Contains(a.used[key], modifier) {
+			a.used[key] += modifier
+			return modifier, nil
+		}
+	}
+	return "", fmt.Errorf("%w for origin %s to %s on %s", ErrModifiersExhausted,
+		strings.TrimSpace(fh.ImmediateOrigin), strings.TrimSpace(fh.ImmediateDestination), fh.FileCreationDate)
+}
+
+// Assign sets the FileIDModifier of file to the next unused one
+func (a *ModifierAllocator) Assign(file *File) error {
+	modifier, err := a.Next(file.Header)
+	if err != nil {
+		return err
+	}
+	file.Header.FileIDModifier = modifier
+	return nil
+}
+
+// Duplicate is a part of a File which was already sent in another file
+type Duplicate struct {
+	// FileID is the ID of the previously sent file
+	FileID string `json:"fileID"`
+	// TraceNumber of the duplicated entry, empty when the FileHeader is duplicated
+	TraceNumber string `json:"traceNumber,omitempty"`
+}
+
+func (d Duplicate) String() string {
+	if d.TraceNumber == "" {
+		return fmt.Sprintf("file header duplicates file %s", d.FileID)
+	}
+	return fmt.Sprintf("trace number %s duplicates an entry of file %s", d.TraceNumber, d.FileID)
+}
+
+// FindDuplicates checks file against previously sent files. A file duplicates another when
+// they share ImmediateOrigin, ImmediateDestination, FileCreationDate and FileIDModifier, and an
+// entry duplicates another with the same TraceNumber in a file with the same ImmediateOrigin,
+// ImmediateDestination and FileCreationDate. Files in sent with the ID of file are skipped.
+func FindDuplicates(file *File, sent []*File) []Duplicate {
+	traces := make(map[string]bool)
+	for _, trace := range traceNumbers(file) {
+		traces[trace] = true
+	}
+
+	var dups []Duplicate
+	key := headerKey(file.Header)
+	for _, other := range sent {
+		if other == nil || other == file || (file.ID != "" && other.ID == file.ID) {
+			continue
+		}
+		if headerKey(other.Header) != key {
+			continue
+		}
+		if other.Header.FileIDModifier == file.Header.FileIDModifier {
+			dups = append(dups, Duplicate{FileID: other.ID})
+		}
+		for _, trace := range traceNumbers(other) {
+			if traces[trace] {
+				dups = append(dups, Duplicate{FileID: other.ID, TraceNumber: trace})
+			}
+		}
+	}
+	return dups
+}
+
+// traceNumbers returns the non-zero TraceNumber of every entry of file, ADV entries have none
+func traceNumbers(file *File) []string {
+	var traces []string
+	add := func(trace string) {
+		if strings.Trim(trace, "0") != "" {
+			traces = append(traces, trace)
+		}
+	}
+	for _, batch := range file.Batches {
+		for _, entry := range batch.GetEntries() {
+			add(entry.TraceNumberField())
+		}
+	}
+	for i := range file.IATBatches {
+		for _, entry := range file.IATBatches[i].GetEntries() {
+			add(entry.TraceNumberField())
+		}
+	}
+	return traces
+}
@@ -0,0 +1,158 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestModifierAllocator(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewModifierAllocator()
+	if a.Used(file.Header) {
+		t.Error("modifier A is not used yet")
+	}
+	a.Observe(file)
+	if !a.Used(file.Header) {
+		t.Error("modifier A is used")
+	}
+
+	var got string
+	for {
+		modifier, err := a.Next(file.Header)
+		if err != nil {
+			if !errors.Is(err, ErrModifiersExhausted) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			break
+		}
+		got += modifier
+	}
+	if got != FileIDModifiers[1:] {
+		t.Errorf("got %s", got)
+	}
+
+	// another day starts over
+	fh := file.Header
+	fh.FileCreationDate = "190625"
+	if modifier, err := a.Next(fh); err != nil || modifier != "A" {
+		t.Errorf("modifier=%s: %v", modifier, err)
+	}
+
+	// padded routing numbers are the same origin and destination
+	fh.ImmediateDestination = " " + fh.ImmediateDestination
+	fh.FileIDModifier = "A"
+	if !a.Used(fh) {
+		t.Error("modifier A is used")
+	}
+}
+
+func TestModifierAllocator__Assign(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewModifierAllocator()
+	a.Observe(file, nil)
+
+	var wg sync.WaitGroup
+	modifiers := make([]string, 10)
+	for i := range modifiers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f := *file
+			if err := a.Assign(&f); err != nil {
+				t.Error(err)
+			}
+			modifiers[i] = f.Header.FileIDModifier
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{"A": true}
+	for _, m := range modifiers {
+		if seen[m] {
+			t.Errorf("modifier %s assigned twice: %v", m, modifiers)
+		}
+		seen[m] = true
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	debit, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	debit.ID = "debit"
+	mixed, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixed.ID = "mixed"
+	web, err := readACHFilepath(filepath.Join("test", "testdata", "web-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	web.ID = "web"
+
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.ID = "new"
+
+	// mixed shares a trace number but was created on another day
+	dups := FindDuplicates(file, []*File{debit, mixed, web, file})
+	if len(dups) != 2 {
+		t.Fatalf("got %v", dups)
+	}
+	if dups[0].FileID != "debit" || dups[0].TraceNumber != "" {
+		t.Errorf("expected duplicate header: %v", dups[0])
+	}
+	if dups[1].FileID != "debit" || dups[1].TraceNumber != "121042880000001" {
+		t.Errorf("expected duplicate trace number: %v", dups[1])
+	}
+	if v := dups[0].String(); v != "file header duplicates file debit" {
+		t.Errorf("got %q", v)
+	}
+	if v := dups[1].String(); v != "trace number 121042880000001 duplicates an entry of file debit" {
+		t.Errorf("got %q", v)
+	}
+
+	// the same trace number on the same day is a duplicate
+	mixed.Header.FileCreationDate = file.Header.FileCreationDate
+	mixed.Header.FileIDModifier = "C"
+	dups = FindDuplicates(file, []*File{mixed})
+	if len(dups) != 1 || dups[0].FileID != "mixed" || dups[0].TraceNumber != "121042880000001" {
+		t.Errorf("expected duplicate trace number: %v", dups)
+	}
+
+	// a new modifier and trace number aren't duplicates
+	file.Header.FileIDModifier = "B"
+	file.Batches[0].GetEntries()[0].TraceNumber = "121042880000099"
+	if dups := FindDuplicates(file, []*File{debit, mixed, web}); len(dups) != 0 {
+		t.Errorf("got %v", dups)
+	}
+}
@@ -0,0 +1,124 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader__EBCDIC(t *testing.T) {
+	expected, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit-ebcdic.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(file, expected) {
+		t.Errorf("EBCDIC file differs from ASCII file")
+	}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReader__EBCDICForcedASCII(t *testing.T) {
+	bs, err := ioutil.ReadFile(filepath.Join("test", "testdata", "ppd-debit-ebcdic.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(bs))
+	r.SetOptions(ReaderOptions{Encoding: EncodingASCII})
+	if _, err := r.Read(); err == nil {
+		t.Error("expected error reading EBCDIC as ASCII")
+	}
+}
+
+func TestReader__EBCDICLineSeparators(t *testing.T) {
+	bs, err := ioutil.ReadFile(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// records separated by EBCDIC NL (0x15) and CR LF (0x0D 0x25)
+	lines := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	var buf bytes.Buffer
+	for i, line := range lines {
+		buf.WriteString(encodeEBCDIC(line))
+		if i%2 == 0 {
+			buf.WriteString("\x15")
+		} else {
+			buf.WriteString("\x0D\x25")
+		}
+	}
+
+	file, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(file.Batches[0].GetEntries()); n != 1 {
+		t.Errorf("got %d entries", n)
+	}
+}
+
+func TestWriter__EBCDIC(t *testing.T) {
+	for _, name := range []string{"ppd-debit.ach", "iat-mixedDebitCredit.ach", "return-WEB.ach"} {
+		file, err := readACHFilepath(filepath.Join("test", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		w.SetOptions(WriterOptions{Encoding: EncodingEBCDIC})
+		if err := w.Write(file); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len()%(RecordLength*10) != 0 {
+			t.Errorf("%s: %d bytes are not fixed length blocks", name, buf.Len())
+		}
+		if buf.Bytes()[0] != ebcdicFileHeader || bytes.IndexByte(buf.Bytes(), '\n') >= 0 {
+			t.Errorf("%s: not EBCDIC encoded", name)
+		}
+
+		read, err := NewReader(&buf).Read()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(read.Header, file.Header) || len(read.Batches) != len(file.Batches) || len(read.IATBatches) != len(file.IATBatches) {
+			t.Errorf("%s: round trip differs", name)
+		}
+	}
+}
+
+func TestEncoding__CP037(t *testing.T) {
+	ascii := " 0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz.<(+|&!$*);-/,%_>?`:#@'=\""
+	encoded := encodeEBCDIC(ascii)
+	if encoded[0] != 0x40 || encoded[1] != 0xF0 || encoded[11] != 0xC1 {
+		t.Errorf("unexpected encoding % X", encoded[:12])
+	}
+	d := &decodingReader{}
+	if decoded := string(d.decode([]byte(encoded))); decoded != ascii {
+		t.Errorf("got %q", decoded)
+	}
+}
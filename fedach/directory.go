@@ -0,0 +1,264 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fedach
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotFound is returned for routing numbers missing from the directory
+	ErrNotFound = errors.New("routing number not found in FedACH directory")
+	// ErrInactive is returned for institutions which don't receive ACH entries
+	ErrInactive = errors.New("institution is not an active FedACH participant")
+)
+
+// recordLength is the length of every line of the fixed-width directory
+const recordLength = 155
+
+const (
+	// RecordTypeFederalReserve is the RecordTypeCode of a Federal Reserve Bank
+	RecordTypeFederalReserve = "0"
+	// RecordTypeCustomer is the RecordTypeCode of institutions which receive entries at their own routing number
+	RecordTypeCustomer = "1"
+	// RecordTypeNewRoutingNumber is the RecordTypeCode of institutions whose entries are sent to NewRoutingNumber,
+	// usually after a merger
+	RecordTypeNewRoutingNumber = "2"
+
+	// StatusReceivesEntries is the StatusCode of institutions receiving government and commercial entries
+	StatusReceivesEntries = "1"
+)
+
+// Location is the address of a Participant
+type Location struct {
+	Address             string `json:"address"`
+	City                string `json:"city"`
+	State               string `json:"state"`
+	PostalCode          string `json:"postalCode"`
+	PostalCodeExtension string `json:"postalCodeExtension"`
+}
+
+// Participant is an institution of the FedACH directory
+type Participant struct {
+	// RoutingNumber is the 9 digit routing number of the institution
+	RoutingNumber string `json:"routingNumber"`
+	// OfficeCode is O for a main office and B for a branch
+	OfficeCode string `json:"officeCode"`
+	// ServicingFRBNumber is the routing number of the Federal Reserve Bank servicing the institution
+	ServicingFRBNumber string `json:"servicingFRBNumber"`
+	// RecordTypeCode is one of the RecordType constants
+	RecordTypeCode string `json:"recordTypeCode"`
+	// ChangeDate is the MMDDYY the record last changed
+	ChangeDate string `json:"changeDate"`
+	// NewRoutingNumber replaces RoutingNumber when RecordTypeCode is RecordTypeNewRoutingNumber
+	NewRoutingNumber string `json:"newRoutingNumber"`
+	// CustomerName is the name of the institution
+	CustomerName string   `json:"customerName"`
+	Location     Location `json:"achLocation"`
+	PhoneNumber  string   `json:"phoneNumber"`
+	// StatusCode is StatusReceivesEntries for institutions which receive ACH entries
+	StatusCode string `json:"statusCode"`
+	ViewCode   string `json:"viewCode"`
+}
+
+// Active returns true if the institution receives ACH entries
+func (p *Participant) Active() bool {
+	return p.StatusCode == StatusReceivesEntries
+}
+
+// replaced returns the routing number entries for p are sent to instead, if any
+func (p *Participant) replaced() (string, bool) {
+	rn := strings.TrimSpace(p.NewRoutingNumber)
+	if p.RecordTypeCode != RecordTypeNewRoutingNumber || rn == "" || strings.Trim(rn, "0") == "" || rn == p.RoutingNumber {
+		return "", false
+	}
+	return rn, true
+}
+
+// Directory holds the FedACH participants by routing number
+type Directory struct {
+	participants map[string]*Participant
+}
+
+// Open reads the directory file at path, see Read
+func Open(path string) (*Directory, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	dir, err := Read(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return dir, nil
+}
+
+// Read parses a FedACH directory in its fixed-width or JSON form. JSON is either an array of
+// participants or the {"fedACHParticipants": {"fedACHParticipants": [...]}} document served
+// by the Federal Reserve.
+func Read(r io.Reader) (*Directory, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("empty FedACH directory")
+			}
+			return nil, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+			continue
+		case '{', '[':
+			return readJSON(br)
+		}
+		return readFixedWidth(br)
+	}
+}
+
+func newDirectory(participants []*Participant) (*Directory, error) {
+	dir := &Directory{participants: make(map[string]*Participant, len(participants))}
+	for i, p := range participants {
+		if utf8.RuneCountInString(p.RoutingNumber) != 9 {
+			return nil, fmt.Errorf("participant %d: invalid routing number %q", i+1, p.RoutingNumber)
+		}
+		dir.participants[p.RoutingNumber] = p
+	}
+	return dir, nil
+}
+
+func readJSON(r io.Reader) (*Directory, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var participants []*Participant
+	if bytes.HasPrefix(bytes.TrimSpace(bs), []byte("[")) {
+		err = json.Unmarshal(bs, &participants)
+	} else {
+		var wrapper struct {
+			FedACHParticipants struct {
+				FedACHParticipants []*Participant `json:"fedACHParticipants"`
+			} `json:"fedACHParticipants"`
+		}
+		err = json.Unmarshal(bs, &wrapper)
+		participants = wrapper.FedACHParticipants.FedACHParticipants
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing JSON: %v", err)
+	}
+	return newDirectory(participants)
+}
+
+func readFixedWidth(r io.Reader) (*Directory, error) {
+	var participants []*Participant
+	s := bufio.NewScanner(r)
+	line := 0
+	for s.Scan() {
+		line++
+		text := strings.TrimRight(s.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		// trailing filler is often trimmed
+		if n := utf8.RuneCountInString(text); n < recordLength-5 || n > recordLength {
+			return nil, fmt.Errorf("line %d: invalid record length of %d", line, n)
+		}
+		text += strings.Repeat(" ", recordLength-utf8.RuneCountInString(text))
+		participants = append(participants, parseRecord([]rune(text)))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return newDirectory(participants)
+}
+
+// parseRecord reads the fields of a fixed-width record of recordLength runes
+func parseRecord(record []rune) *Participant {
+	field := func(start, end int) string {
+		return strings.TrimSpace(string(record[start:end]))
+	}
+	return &Participant{
+		RoutingNumber:      field(0, 9),
+		OfficeCode:         field(9, 10),
+		ServicingFRBNumber: field(10, 19),
+		RecordTypeCode:     field(19, 20),
+		ChangeDate:         field(20, 26),
+		NewRoutingNumber:   field(26, 35),
+		CustomerName:       field(35, 71),
+		Location: Location{
+			Address:             field(71, 107),
+			City:                field(107, 127),
+			State:               field(127, 129),
+			PostalCode:          field(129, 134),
+			PostalCodeExtension: field(134, 138),
+		},
+		PhoneNumber: field(138, 148),
+		StatusCode:  field(148, 149),
+		ViewCode:    field(149, 150),
+	}
+}
+
+// Len returns the number of participants in the directory
+func (d *Directory) Len() int {
+	return len(d.participants)
+}
+
+// Lookup returns the participant with routingNumber without following NewRoutingNumber
+func (d *Directory) Lookup(routingNumber string) (*Participant, bool) {
+	p, ok := d.participants[strings.TrimSpace(routingNumber)]
+	return p, ok
+}
+
+// Resolve returns the participant receiving entries for routingNumber, following the
+// NewRoutingNumber of merged institutions. An error wrapping ErrNotFound or ErrInactive is
+// returned when routingNumber can't receive entries.
+func (d *Directory) Resolve(routingNumber string) (*Participant, error) {
+	rn := strings.TrimSpace(routingNumber)
+	p, ok := d.participants[rn]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, rn)
+	}
+	for hops := 0; ; hops++ {
+		next, ok := p.replaced()
+		if !ok {
+			break
+		}
+		if hops == len(d.participants) {
+			return nil, fmt.Errorf("%w: %s is replaced in a loop", ErrNotFound, rn)
+		}
+		if p, ok = d.participants[next]; !ok {
+			return nil, fmt.Errorf("%w: %s replaced by %s", ErrNotFound, rn, next)
+		}
+	}
+	if !p.Active() {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrInactive, rn, p.CustomerName)
+	}
+	return p, nil
+}
@@ -0,0 +1,140 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fedach
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestDirectory(t *testing.T, name string) *Directory {
+	t.Helper()
+	dir, err := Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDirectory__Open(t *testing.T) {
+	for _, name := range []string{"FedACHdir.txt", "FedACHdir.json"} {
+		dir := openTestDirectory(t, name)
+		if n := dir.Len(); n != 10 {
+			t.Errorf("%s: got %d participants", name, n)
+		}
+		p, ok := dir.Lookup("231380104")
+		if !ok {
+			t.Fatalf("%s: missing 231380104", name)
+		}
+		expected := Participant{
+			RoutingNumber:      "231380104",
+			OfficeCode:         "O",
+			ServicingFRBNumber: "031000040",
+			RecordTypeCode:     RecordTypeCustomer,
+			ChangeDate:         "090814",
+			NewRoutingNumber:   "000000000",
+			CustomerName:       "CITADEL FEDERAL CREDIT UNION",
+			Location: Location{
+				Address:             "520 EAGLEVIEW BLVD",
+				City:                "EXTON",
+				State:               "PA",
+				PostalCode:          "19341",
+				PostalCodeExtension: "0000",
+			},
+			PhoneNumber: "6105344444",
+			StatusCode:  StatusReceivesEntries,
+			ViewCode:    "1",
+		}
+		if *p != expected {
+			t.Errorf("%s: got %#v", name, p)
+		}
+	}
+}
+
+func TestDirectory__Read(t *testing.T) {
+	// trimmed filler and blank lines
+	line := "231380104O0310000401090814000000000CITADEL FEDERAL CREDIT UNION        520 EAGLEVIEW BLVD                  EXTON               PA193410000610534444411"
+	dir, err := Read(strings.NewReader("\n" + line + "\r\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := dir.Lookup(" 231380104"); !ok || p.CustomerName != "CITADEL FEDERAL CREDIT UNION" {
+		t.Errorf("unexpected participant: %#v", p)
+	}
+
+	dir, err = Read(strings.NewReader(`[{"routingNumber": "231380104", "customerName": "CITADEL", "statusCode": "1"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := dir.Resolve("231380104"); err != nil || p.CustomerName != "CITADEL" {
+		t.Errorf("unexpected participant: %#v: %v", p, err)
+	}
+
+	cases := []string{
+		"",
+		"  \n",
+		line[:100],
+		`{"fedACHParticipants": `,
+		`[{"routingNumber": "23138010"}]`,
+	}
+	for i, body := range cases {
+		if _, err := Read(strings.NewReader(body)); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+}
+
+func TestDirectory__Resolve(t *testing.T) {
+	dir := openTestDirectory(t, "FedACHdir.txt")
+
+	p, err := dir.Resolve("121042882")
+	if err != nil || p.CustomerName != "WELLS FARGO BANK NA" {
+		t.Errorf("unexpected participant: %#v: %v", p, err)
+	}
+
+	// merged banks resolve to their new routing number
+	p, err = dir.Resolve("261073782")
+	if err != nil || p.RoutingNumber != "322271779" {
+		t.Errorf("unexpected participant: %#v: %v", p, err)
+	}
+	if p, _ := dir.Lookup("261073782"); p.CustomerName != "FIRST HOMETOWN BANK" {
+		t.Errorf("Lookup followed the new routing number: %#v", p)
+	}
+
+	if _, err := dir.Resolve("111000614"); !errors.Is(err, ErrInactive) {
+		t.Errorf("expected ErrInactive: %v", err)
+	}
+	// passes the check digit but isn't a participant
+	if _, err := dir.Resolve("011000028"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound: %v", err)
+	}
+
+	// new routing numbers which are missing or loop
+	dir.participants["231380104"].RecordTypeCode = RecordTypeNewRoutingNumber
+	dir.participants["231380104"].NewRoutingNumber = "011000028"
+	if _, err := dir.Resolve("231380104"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound: %v", err)
+	}
+	dir.participants["322271779"].RecordTypeCode = RecordTypeNewRoutingNumber
+	dir.participants["322271779"].NewRoutingNumber = "261073782"
+	if _, err := dir.Resolve("261073782"); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "loop") {
+		t.Errorf("expected loop: %v", err)
+	}
+}
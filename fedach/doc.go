@@ -0,0 +1,43 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package fedach looks up routing numbers in the FedACH participant directory published by
+// the Federal Reserve, catching routing numbers which pass the ABA check digit but belong to
+// no institution, a closed one or one merged into another bank.
+//
+// https://www.frbservices.org/EPaymentsDirectory/search.html
+//
+// The directory is read in its fixed-width form (FedACHdir.txt) or as JSON.
+//
+//     dir, err := fedach.Open("FedACHdir.txt")
+//     if err != nil {
+//         log.Fatalf("problem loading FedACH directory: %v", err)
+//     }
+//     p, err := dir.Resolve("231380104")
+//     if err != nil {
+//         log.Fatal(err)
+//     }
+//     fmt.Println(p.CustomerName, p.Location.City, p.Location.State)
+//
+// ValidateFile checks the ImmediateDestination and the RDFI of every entry of a File, and
+// FillDestinationName sets an empty ImmediateDestinationName from the directory.
+//
+//     fedach.FillDestinationName(dir, file)
+//     if err := fedach.ValidateFile(dir, file); err != nil {
+//         log.Fatalf("invalid routing numbers: %v", err)
+//     }
+package fedach
@@ -0,0 +1,124 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fedach
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/base"
+)
+
+// RoutingError is a routing number of an ACH file which can't receive entries
+type RoutingError struct {
+	// BatchNumber and TraceNumber of the entry, empty for the FileHeader
+	BatchNumber int    `json:"batchNumber,omitempty"`
+	TraceNumber string `json:"traceNumber,omitempty"`
+	// Field is ImmediateDestination or RDFIIdentification
+	Field string `json:"field"`
+	// RoutingNumber which failed to resolve
+	RoutingNumber string `json:"routingNumber"`
+	Err           error  `json:"-"`
+}
+
+func (e *RoutingError) Error() string {
+	if e.TraceNumber == "" {
+		return fmt.Sprintf("%s %s", e.Field, e.Err)
+	}
+	return fmt.Sprintf("batch %d entry %s: %s %s", e.BatchNumber, e.TraceNumber, e.Field, e.Err)
+}
+
+func (e *RoutingError) Unwrap() error { return e.Err }
+
+// ValidateFile checks the ImmediateDestination of file and the RDFI of every entry resolve
+// to an active participant of dir. A base.ErrorList of every *RoutingError is returned.
+func ValidateFile(dir *Directory, file *ach.File) error {
+	if dir == nil || file == nil {
+		return errors.New("fedach: nil Directory or File")
+	}
+	var errs base.ErrorList
+	checkRoutingNumber(dir, &errs, 0, "", "ImmediateDestination", file.Header.ImmediateDestination)
+	for _, batch := range file.Batches {
+		checkBatch(dir, &errs, batch)
+	}
+	for i := range file.IATBatches {
+		batch := &file.IATBatches[i]
+		for _, entry := range batch.Entries {
+			checkRoutingNumber(dir, &errs, batch.Header.BatchNumber, entry.TraceNumber, "RDFIIdentification", entry.RDFIIdentificationField()+entry.CheckDigit)
+		}
+	}
+	if errs.Empty() {
+		return nil
+	}
+	return errs
+}
+
+// ValidateBatch checks the RDFI of every entry of batch resolves to an active participant of
+// dir, e.g. for a batch added to a validated file. A base.ErrorList of every *RoutingError
+// is returned.
+func ValidateBatch(dir *Directory, batch ach.Batcher) error {
+	if dir == nil || batch == nil || batch.GetHeader() == nil {
+		return errors.New("fedach: nil Directory or Batch")
+	}
+	var errs base.ErrorList
+	checkBatch(dir, &errs, batch)
+	if errs.Empty() {
+		return nil
+	}
+	return errs
+}
+
+func checkBatch(dir *Directory, errs *base.ErrorList, batch ach.Batcher) {
+	bh := batch.GetHeader()
+	for _, entry := range batch.GetEntries() {
+		checkRoutingNumber(dir, errs, bh.BatchNumber, entry.TraceNumber, "RDFIIdentification", entry.RDFIIdentificationField()+entry.CheckDigit)
+	}
+}
+
+// checkRoutingNumber adds a *RoutingError to errs unless routingNumber resolves in dir
+func checkRoutingNumber(dir *Directory, errs *base.ErrorList, batchNumber int, traceNumber, field, routingNumber string) {
+	if _, err := dir.Resolve(routingNumber); err != nil {
+		errs.Add(&RoutingError{
+			BatchNumber:   batchNumber,
+			TraceNumber:   traceNumber,
+			Field:         field,
+			RoutingNumber: strings.TrimSpace(routingNumber),
+			Err:           err,
+		})
+	}
+}
+
+// FillDestinationName sets an empty ImmediateDestinationName of file to the name of its
+// ImmediateDestination in dir and returns true if it was set.
+func FillDestinationName(dir *Directory, file *ach.File) bool {
+	if dir == nil || file == nil || strings.TrimSpace(file.Header.ImmediateDestinationName) != "" {
+		return false
+	}
+	p, err := dir.Resolve(file.Header.ImmediateDestination)
+	if err != nil {
+		return false
+	}
+	name := p.CustomerName
+	if len(name) > 23 {
+		name = strings.TrimSpace(name[:23])
+	}
+	file.Header.ImmediateDestinationName = name
+	return true
+}
@@ -0,0 +1,126 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fedach
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/base"
+)
+
+func readFile(t *testing.T, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestValidateFile(t *testing.T) {
+	dir := openTestDirectory(t, "FedACHdir.txt")
+
+	for _, name := range []string{"ppd-debit.ach", "ppd-mixedDebitCredit.ach", "iat-debit.ach"} {
+		if err := ValidateFile(dir, readFile(t, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	file := readFile(t, "ppd-mixedDebitCredit.ach")
+	file.Header.ImmediateDestination = "111000614"
+	entry := file.Batches[0].GetEntries()[1]
+	entry.SetRDFI("011000028")
+
+	err := ValidateFile(dir, file)
+	var errs base.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 errors: %v", err)
+	}
+	var re *RoutingError
+	if !errors.As(errs[0], &re) || re.Field != "ImmediateDestination" || !errors.Is(re, ErrInactive) {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+	if !errors.As(errs[1], &re) || re.Field != "RDFIIdentification" || re.RoutingNumber != "011000028" ||
+		re.TraceNumber != entry.TraceNumber || !errors.Is(re, ErrNotFound) {
+		t.Errorf("unexpected error: %v", errs[1])
+	}
+	if v := errs[1].Error(); v != "batch 1 entry "+entry.TraceNumber+": RDFIIdentification routing number not found in FedACH directory: 011000028" {
+		t.Errorf("got %q", v)
+	}
+
+	if err := ValidateFile(nil, file); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	dir := openTestDirectory(t, "FedACHdir.txt")
+
+	file := readFile(t, "ppd-mixedDebitCredit.ach")
+	batch := file.Batches[0]
+	if err := ValidateBatch(dir, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := batch.GetEntries()[1]
+	entry.SetRDFI("011000028")
+	err := ValidateBatch(dir, batch)
+	var errs base.ErrorList
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("expected 1 error: %v", err)
+	}
+	var re *RoutingError
+	if !errors.As(errs[0], &re) || re.TraceNumber != entry.TraceNumber || !errors.Is(re, ErrNotFound) {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+
+	if err := ValidateBatch(nil, batch); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFillDestinationName(t *testing.T) {
+	dir := openTestDirectory(t, "FedACHdir.txt")
+
+	file := readFile(t, "ppd-debit.ach")
+	if FillDestinationName(dir, file) {
+		t.Error("filled a file with a name")
+	}
+	file.Header.ImmediateDestinationName = ""
+	if !FillDestinationName(dir, file) || file.Header.ImmediateDestinationName != "CITADEL FEDERAL CREDIT" {
+		t.Errorf("ImmediateDestinationName=%q", file.Header.ImmediateDestinationName)
+	}
+	if err := file.Header.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	file.Header.ImmediateDestinationName = ""
+	file.Header.ImmediateDestination = "011000028"
+	if FillDestinationName(dir, file) {
+		t.Error("filled an unknown destination")
+	}
+}
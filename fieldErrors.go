@@ -27,6 +27,8 @@ var (
 
 	//ErrNonAlphanumeric is given when a field has non-alphanumeric characters
 	ErrNonAlphanumeric = errors.New("has non alphanumeric characters")
+	//ErrNonNumeric is given when a field has non-numeric characters
+	ErrNonNumeric = errors.New("has non numeric characters")
 	//ErrUpperAlpha is given when a field is not in uppercase
 	ErrUpperAlpha = errors.New("is not uppercase A-Z or 0-9")
 	//ErrFieldInclusion is given when a field is mandatory and has a default value
@@ -55,12 +57,18 @@ var (
 	ErrValidDay = errors.New("is an invalid day")
 	//ErrValidYear is given when there's an invalid year
 	ErrValidYear = errors.New("is an invalid year")
+	// ErrValidDate is given when there's an invalid date
+	ErrValidDate = errors.New("is an invalid date")
 	// ErrValidState is the error given when a field has an invalid US state or territory
 	ErrValidState = errors.New("is an invalid US state or territory")
 	// ErrValidISO3166 is the error given when a field has an invalid ISO 3166-1-alpha-2 code
 	ErrValidISO3166 = errors.New("is an invalid ISO 3166-1-alpha-2 code")
 	// ErrValidISO4217 is the error given when a field has an invalid ISO 4217 code
 	ErrValidISO4217 = errors.New("is an invalid ISO 4217 code")
+	// ErrValidTaxIdentificationNumber is the error given when a field is not a nine digit SSN or TIN
+	ErrValidTaxIdentificationNumber = errors.New("is an invalid SSN or TIN")
+	// ErrEnrolleeClassificationCode is the error given when an ENR Enrollee Classification Code is not 0 or 1
+	ErrEnrolleeClassificationCode = errors.New("is an invalid Enrollee Classification Code")
 
 	// EntryDetail errors
 
@@ -84,7 +92,7 @@ var (
 	// ErrRecordSize is given when there's an invalid record size
 	ErrRecordSize = errors.New("is not 094")
 	// ErrBlockingFactor is given when there's an invalid blocking factor
-	ErrBlockingFactor = errors.New("is not 10")
+	ErrBlockingFactor = errors.New("is not between 01 and 99")
 	// ErrFormatCode is given when there's an invalid format code
 	ErrFormatCode = errors.New("is not 1")
 
@@ -89,7 +89,7 @@ type fileControl struct {
 }
 
 type advFileControl struct {
-	ADVControl ADVFileControl `json:"advFileControl"`
+	ADVControl ADVFileControl `json:"fileADVControl"`
 }
 
 // FileFromJSON attempts to return a *File object assuming the input is valid JSON.
@@ -261,11 +261,15 @@ func setIATEntryRecordType(e *IATEntryDetail) {
 // json struct tag decoding).
 func (f *File) setBatchesFromJSON(bs []byte) error {
 	var batches batchesJSON
+	var secEntries secEntriesJSON
 	var iatBatches iatBatchesJSON
 
 	if err := json.Unmarshal(bs, &batches); err != nil {
 		return err
 	}
+	if err := json.Unmarshal(bs, &secEntries); err != nil {
+		return err
+	}
 	// Clear out any nil batches
 	for i := range f.Batches {
 		if f.Batches[i] == nil {
@@ -280,6 +284,13 @@ func (f *File) setBatchesFromJSON(bs []byte) error {
 		batch := *batches.Batches[i]
 		batch.Header.recordType = batchHeaderPos
 
+		// Entries can also be written as their SEC specific views (e.g. popEntries)
+		entries, err := secEntries.entryDetails(i)
+		if err != nil {
+			return batch.Error("Invalid Batch", err, batch.Header.ID)
+		}
+		batch.Entries = append(batch.Entries, entries...)
+
 		for _, e := range batch.Entries {
 			// these values need to be inferred from the json field names
 			setEntryRecordType(e)
@@ -379,6 +390,15 @@ func datetimeParse(v string) (time.Time, error) {
 // Create implementations are free to modify computable fields in a file and should
 // call the Batch's Validate() function at the end of their execution.
 func (f *File) Create() error {
+	return f.CreateWithOptions(WriterOptions{})
+}
+
+// CreateWithOptions tabulates the file like Create, setting the blocking factor of opts in the
+// FileHeader and computing the FileControl BlockCount with it. Files should be written with
+// the same options.
+func (f *File) CreateWithOptions(opts WriterOptions) error {
+	f.Header = opts.header(f.Header)
+
 	// Requires a valid FileHeader to build FileControl
 	if err := f.Header.Validate(); err != nil {
 		return err
@@ -431,19 +451,14 @@ func (f *File) Create() error {
 		fc := NewFileControl()
 		fc.ID = f.ID
 		fc.BatchCount = batchSeq - 1
-		// blocking factor of 10 is static default value in f.Header.blockingFactor.
-		if (totalRecordsInFile % 10) != 0 {
-			fc.BlockCount = totalRecordsInFile/10 + 1
-		} else {
-			fc.BlockCount = totalRecordsInFile / 10
-		}
+		fc.BlockCount = blockCount(totalRecordsInFile, opts.blockingFactor(f.Header))
 		fc.EntryAddendaCount = fileEntryAddendaCount
 		fc.EntryHash = fileEntryHashSum
 		fc.TotalDebitEntryDollarAmountInFile = totalDebitAmount
 		fc.TotalCreditEntryDollarAmountInFile = totalCreditAmount
 		f.Control = fc
 	} else {
-		if err := f.createFileADV(); err != nil {
+		if err := f.createFileADV(opts); err != nil {
 			return err
 		}
 	}
@@ -688,7 +703,7 @@ func (f *File) IsADV() bool {
 	return false
 }
 
-func (f *File) createFileADV() error {
+func (f *File) createFileADV(opts WriterOptions) error {
 	// add 2 for FileHeader/control and reset if build was called twice do to error
 	totalRecordsInFile := 2
 	batchSeq := 1
@@ -720,12 +735,7 @@ func (f *File) createFileADV() error {
 	fc := NewADVFileControl()
 	fc.ID = f.ID
 	fc.BatchCount = batchSeq - 1
-	// blocking factor of 10 is static default value in f.Header.blockingFactor.
-	if (totalRecordsInFile % 10) != 0 {
-		fc.BlockCount = totalRecordsInFile/10 + 1
-	} else {
-		fc.BlockCount = totalRecordsInFile / 10
-	}
+	fc.BlockCount = blockCount(totalRecordsInFile, opts.blockingFactor(f.Header))
 	fc.EntryAddendaCount = fileEntryAddendaCount
 	fc.EntryHash = fileEntryHashSum
 	fc.TotalDebitEntryDollarAmountInFile = totalDebitAmount
@@ -18,6 +18,7 @@
 package ach
 
 import (
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -78,7 +79,8 @@ type FileHeader struct {
 	// (a block is 940 characters). For all files moving between a DFI and an ACH
 	// Operator (either way), the value "10" must be used. If the number of records
 	// within the file is not a multiple of ten, the remainder of the block must
-	// be nine-filled.
+	// be nine-filled. Other values are written with WriterOptions.BlockingFactor
+	// for ODFIs which require them.
 	blockingFactor string
 
 	// FormatCode a code to allow for future format variations. As
@@ -139,8 +141,8 @@ func (fh *FileHeader) Parse(record string) {
 	fh.FileIDModifier = record[33:34]
 	// 35-37 always "094"
 	fh.recordSize = "094"
-	//38-39 always "10"
-	fh.blockingFactor = "10"
+	//38-39 "10" unless written with another WriterOptions.BlockingFactor
+	fh.blockingFactor = record[37:39]
 	//40 always "1"
 	fh.formatCode = "1"
 	//41-63 The name of the ODFI. example "SILICON VALLEY BANK    "
@@ -197,7 +199,7 @@ func (fh *FileHeader) Validate() error {
 	if fh.recordSize != "094" {
 		return fieldError("recordSize", ErrRecordSize, fh.recordSize)
 	}
-	if fh.blockingFactor != "10" {
+	if n, err := strconv.Atoi(fh.blockingFactor); err != nil || len(fh.blockingFactor) != 2 || n < 1 {
 		return fieldError("blockingFactor", ErrBlockingFactor, fh.blockingFactor)
 	}
 	if fh.formatCode != "1" {
@@ -263,22 +263,27 @@ func BenchmarkValidateRecordSize(b *testing.B) {
 	}
 }
 
-// testBlockingFactor validates blocking factor  is "10"
+// testBlockingFactor validates blocking factor is between "01" and "99"
 func testBlockingFactor(t testing.TB) {
 	fh := mockFileHeader()
-	fh.blockingFactor = "99"
-	err := fh.Validate()
-	if !base.Match(err, ErrBlockingFactor) {
+	for _, v := range []string{"00", "5", "1A"} {
+		fh.blockingFactor = v
+		if err := fh.Validate(); !base.Match(err, ErrBlockingFactor) {
+			t.Errorf("%s: %T: %s", v, err, err)
+		}
+	}
+	fh.blockingFactor = "05"
+	if err := fh.Validate(); err != nil {
 		t.Errorf("%T: %s", err, err)
 	}
 }
 
-// TestBlockingFactor tests validating blocking factor  is "10"
+// TestBlockingFactor tests validating blocking factor is between "01" and "99"
 func TestBlockingFactor(t *testing.T) {
 	testBlockingFactor(t)
 }
 
-// BenchmarkBlockingFactor benchmarks validating blocking factor  is "10"
+// BenchmarkBlockingFactor benchmarks validating blocking factor is between "01" and "99"
 func BenchmarkBlockingFactor(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
@@ -59,9 +59,11 @@ type IATEntryDetail struct {
 	DFIAccountNumber string `json:"DFIAccountNumber"`
 	// reservedTwo - Leave blank
 	reservedTwo string
-	// OFACScreeningIndicator - Leave blank
+	// OFACScreeningIndicator is "1" when the Gateway Operator's OFAC screening of the
+	// originator or receiver found a match, otherwise "0" or blank
 	OFACScreeningIndicator string `json:"OFACScreeningIndicator"`
-	// SecondaryOFACScreeningIndicator - Leave blank
+	// SecondaryOFACScreeningIndicator is "1" when OFAC screening of the financial
+	// institutions found a match, otherwise "0" or blank
 	SecondaryOFACScreeningIndicator string `json:"SecondaryOFACScreeningIndicator"`
 	// AddendaRecordIndicator indicates the existence of an Addenda Record.
 	// A value of "1" indicates that one or more addenda records follow,
@@ -172,9 +174,9 @@ func (iatEd *IATEntryDetail) Parse(record string) {
 	// 75-76 reserved2 Leave blank
 	iatEd.reservedTwo = "  "
 	// 77 OFACScreeningIndicator
-	iatEd.OFACScreeningIndicator = " "
+	iatEd.OFACScreeningIndicator = iatEd.parseStringField(record[76:77])
 	// 78-78 Secondary SecondaryOFACScreeningIndicator
-	iatEd.SecondaryOFACScreeningIndicator = " "
+	iatEd.SecondaryOFACScreeningIndicator = iatEd.parseStringField(record[77:78])
 	// 79-79 1 if addenda exists 0 if it does not
 	//iatEd.AddendaRecordIndicator = 1
 	iatEd.AddendaRecordIndicator = iatEd.parseNumField(record[78:79])
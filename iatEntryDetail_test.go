@@ -137,6 +137,19 @@ func TestParseIATEntryDetail(t *testing.T) {
 	testParseIATEntryDetail(t)
 }
 
+// TestParseIATEntryDetailOFACScreeningIndicators tests the OFAC screening indicators are kept when parsing
+func TestParseIATEntryDetailOFACScreeningIndicators(t *testing.T) {
+	var line = "6221210428820007             000010000012345678901234567890123456789012345  101231380100000001"
+	iatEd := NewIATEntryDetail()
+	iatEd.Parse(line)
+	if iatEd.OFACScreeningIndicator != "1" || iatEd.SecondaryOFACScreeningIndicator != "0" {
+		t.Errorf("OFACScreeningIndicator=%q SecondaryOFACScreeningIndicator=%q", iatEd.OFACScreeningIndicator, iatEd.SecondaryOFACScreeningIndicator)
+	}
+	if v := iatEd.String(); v[76:78] != "10" {
+		t.Errorf("unexpected record: %q", v)
+	}
+}
+
 // BenchmarkParseIATEntryDetail benchmarks parsing a known IATEntryDetail record string.
 func BenchmarkParseIATEntryDetail(b *testing.B) {
 	b.ReportAllocs()
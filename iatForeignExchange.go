@@ -0,0 +1,346 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ourly/ach/internal/iso4217"
+)
+
+var (
+	// ErrForeignExchangeFixedCurrency is returned when a Fixed-to-Fixed (FF) IATBatchHeader has different originating and destination currencies
+	ErrForeignExchangeFixedCurrency = errors.New("fixed-to-fixed entries must originate and settle in the same currency")
+	// ErrForeignExchangeCountryCurrency is returned when the destination currency isn't used in the destination country
+	ErrForeignExchangeCountryCurrency = errors.New("is not a currency of the destination country")
+	// ErrExchangeRateNotFound is returned when no rate exists between two currencies
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+)
+
+// ExchangeRate is the rate to convert one unit of From currency into To currency.
+type ExchangeRate struct {
+	// From is the ISO 4217 code of the source currency
+	From string `json:"from"`
+	// To is the ISO 4217 code of the target currency
+	To string `json:"to"`
+	// Rate is a decimal string, e.g. "1.3215", to avoid floating point errors
+	Rate string `json:"rate"`
+}
+
+// ExchangeRates is a table of currency exchange rates used to compute IAT foreign exchange amounts.
+//
+// Rates are looked up directly (From -> To) and then by their inverse (To -> From).
+type ExchangeRates struct {
+	rates map[string]*big.Rat
+}
+
+// NewExchangeRates returns an ExchangeRates table after validating each currency code and rate.
+func NewExchangeRates(rates ...ExchangeRate) (*ExchangeRates, error) {
+	er := &ExchangeRates{
+		rates: make(map[string]*big.Rat),
+	}
+	for i := range rates {
+		from, to := strings.ToUpper(strings.TrimSpace(rates[i].From)), strings.ToUpper(strings.TrimSpace(rates[i].To))
+		if !iso4217.Valid(from) {
+			return nil, fieldError("From", ErrValidISO4217, rates[i].From)
+		}
+		if !iso4217.Valid(to) {
+			return nil, fieldError("To", ErrValidISO4217, rates[i].To)
+		}
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(rates[i].Rate))
+		if !ok || r.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q for %s/%s", rates[i].Rate, from, to)
+		}
+		er.rates[from+"/"+to] = r
+	}
+	return er, nil
+}
+
+// ReadExchangeRatesCSV reads rates from CSV records of "from,to,rate". A header row
+// (where the rate column isn't numeric) is skipped.
+func ReadExchangeRatesCSV(r io.Reader) (*ExchangeRates, error) {
+	rd := csv.NewReader(r)
+	rd.FieldsPerRecord = 3
+	rd.TrimLeadingSpace = true
+	records, err := rd.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("problem reading exchange rates: %v", err)
+	}
+	var rates []ExchangeRate
+	for i := range records {
+		if _, ok := new(big.Rat).SetString(records[i][2]); !ok && i == 0 {
+			continue // header row
+		}
+		rates = append(rates, ExchangeRate{From: records[i][0], To: records[i][1], Rate: records[i][2]})
+	}
+	return NewExchangeRates(rates...)
+}
+
+// ReadExchangeRatesJSON reads rates from a JSON array of ExchangeRate objects.
+func ReadExchangeRatesJSON(r io.Reader) (*ExchangeRates, error) {
+	var rates []ExchangeRate
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("problem reading exchange rates: %v", err)
+	}
+	return NewExchangeRates(rates...)
+}
+
+// ReadExchangeRatesFile reads a rate table from a local .csv or .json file.
+func ReadExchangeRatesFile(path string) (*ExchangeRates, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadExchangeRatesCSV(fd)
+	case ".json":
+		return ReadExchangeRatesJSON(fd)
+	}
+	return nil, fmt.Errorf("unknown exchange rate file format: %s", path)
+}
+
+// Rate returns the rate to convert one unit of from currency into to currency.
+func (er *ExchangeRates) Rate(from, to string) (*big.Rat, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if er != nil {
+		if r, ok := er.rates[from+"/"+to]; ok {
+			return new(big.Rat).Set(r), nil
+		}
+		if r, ok := er.rates[to+"/"+from]; ok {
+			return new(big.Rat).Inv(r), nil
+		}
+	}
+	return nil, fmt.Errorf("%s/%s: %w", from, to, ErrExchangeRateNotFound)
+}
+
+// Convert converts amount, in the minor units of from currency (e.g. cents), into the minor
+// units of to currency. Each currency's ISO 4217 exponent is honored and the result is
+// rounded half away from zero.
+func (er *ExchangeRates) Convert(amount int, from, to string) (int, error) {
+	rate, err := er.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return convertMinorUnits(amount, rate, from, to)
+}
+
+func convertMinorUnits(amount int, rate *big.Rat, from, to string) (int, error) {
+	fromExp, ok := iso4217.MinorUnits(from)
+	if !ok {
+		return 0, fieldError("ISOOriginatingCurrencyCode", ErrValidISO4217, from)
+	}
+	toExp, ok := iso4217.MinorUnits(to)
+	if !ok {
+		return 0, fieldError("ISODestinationCurrencyCode", ErrValidISO4217, to)
+	}
+
+	v := new(big.Rat).Mul(big.NewRat(int64(amount), 1), rate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil)
+	if toExp > fromExp {
+		v.Mul(v, new(big.Rat).SetInt(scale))
+	} else {
+		v.Quo(v, new(big.Rat).SetInt(scale))
+	}
+	return roundRat(v), nil
+}
+
+// roundRat rounds v to the nearest integer, with halves rounded away from zero
+func roundRat(v *big.Rat) int {
+	num, den := new(big.Int).Abs(v.Num()), v.Denom()
+	// (2*num + den) / (2*den)
+	q := new(big.Int).Add(new(big.Int).Lsh(num, 1), den)
+	q.Quo(q, new(big.Int).Lsh(den, 1))
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return int(q.Int64())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// referenceRate rounds rate once to the decimal string written to ForeignExchangeReference, with
+// at most 10 decimal places within its 15 characters, and returns the rounded rate so amounts
+// are converted with the rate the reference reports.
+func referenceRate(rate *big.Rat) (*big.Rat, string, error) {
+	decimals := 15 - len(new(big.Int).Quo(rate.Num(), rate.Denom()).String()) - 1
+	if decimals > 10 {
+		decimals = 10
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	s := rate.FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if len(s) > 15 {
+		return nil, "", fieldError("ForeignExchangeReference", NewErrValidFieldLength(15), s)
+	}
+	rounded, _ := new(big.Rat).SetString(s)
+	if rounded.Sign() <= 0 {
+		return nil, "", fmt.Errorf("exchange rate %s rounds to zero in ForeignExchangeReference", rate.FloatString(15))
+	}
+	return rounded, s, nil
+}
+
+// IATForeignExchange is the result of applying an IATBatchHeader's ForeignExchangeIndicator to an amount.
+type IATForeignExchange struct {
+	// OriginatingAmount is in the minor units of ISOOriginatingCurrencyCode
+	OriginatingAmount int `json:"originatingAmount"`
+	// DestinationAmount is in the minor units of ISODestinationCurrencyCode
+	DestinationAmount int `json:"destinationAmount"`
+	// Rate used for the conversion, empty for Fixed-to-Fixed entries
+	Rate string `json:"rate,omitempty"`
+}
+
+// ForeignExchange computes the originating and destination amounts of an IAT entry from the
+// IATBatchHeader's ForeignExchangeIndicator and updates the header's ForeignExchangeReferenceIndicator
+// and ForeignExchangeReference. The rate is rounded to fit the ForeignExchangeReference and amounts
+// are converted with the rounded rate.
+//
+// FV (Fixed-to-Variable): amount is the fixed originating amount and the destination amount is converted.
+//
+// VF (Variable-to-Fixed): amount is the fixed destination amount and the originating amount is converted.
+//
+// FF (Fixed-to-Fixed): no conversion happens and the ForeignExchangeReference is space filled.
+func (er *ExchangeRates) ForeignExchange(bh *IATBatchHeader, amount int) (*IATForeignExchange, error) {
+	if bh == nil {
+		return nil, errors.New("nil IATBatchHeader")
+	}
+	if amount < 0 {
+		return nil, fieldError("Amount", ErrNegativeAmount, amount)
+	}
+	if err := bh.ValidateCurrencies(); err != nil {
+		return nil, err
+	}
+
+	orig, dest := strings.ToUpper(bh.ISOOriginatingCurrencyCode), strings.ToUpper(bh.ISODestinationCurrencyCode)
+	switch bh.ForeignExchangeIndicator {
+	case "FF":
+		bh.ForeignExchangeReferenceIndicator = 3
+		bh.ForeignExchangeReference = ""
+		return &IATForeignExchange{OriginatingAmount: amount, DestinationAmount: amount}, nil
+
+	case "FV":
+		rate, err := er.Rate(orig, dest)
+		if err != nil {
+			return nil, err
+		}
+		rate, reference, err := referenceRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		destAmount, err := convertMinorUnits(amount, rate, orig, dest)
+		if err != nil {
+			return nil, err
+		}
+		bh.ForeignExchangeReferenceIndicator = 1
+		bh.ForeignExchangeReference = reference
+		return &IATForeignExchange{OriginatingAmount: amount, DestinationAmount: destAmount, Rate: bh.ForeignExchangeReference}, nil
+
+	case "VF":
+		rate, err := er.Rate(orig, dest)
+		if err != nil {
+			return nil, err
+		}
+		rate, reference, err := referenceRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		origAmount, err := convertMinorUnits(amount, new(big.Rat).Inv(rate), dest, orig)
+		if err != nil {
+			return nil, err
+		}
+		bh.ForeignExchangeReferenceIndicator = 1
+		bh.ForeignExchangeReference = reference
+		return &IATForeignExchange{OriginatingAmount: origAmount, DestinationAmount: amount, Rate: bh.ForeignExchangeReference}, nil
+	}
+	return nil, fieldError("ForeignExchangeIndicator", ErrForeignExchangeIndicator, bh.ForeignExchangeIndicator)
+}
+
+// ApplyForeignExchange computes foreign exchange amounts for every entry in an IATBatch.
+//
+// For FV and FF batches IATEntryDetail.Amount is the fixed amount and Addenda10.ForeignPaymentAmount
+// is set to the destination amount. For VF batches Addenda10.ForeignPaymentAmount is the fixed destination
+// amount and IATEntryDetail.Amount is set to the originating amount.
+//
+// Callers should call Create() on the batch afterwards to recompute the BatchControl.
+func (er *ExchangeRates) ApplyForeignExchange(batch *IATBatch) error {
+	for _, entry := range batch.GetEntries() {
+		if entry.Addenda10 == nil {
+			return batch.Error("Addenda10", ErrFieldInclusion)
+		}
+		amount := entry.Amount
+		if batch.Header.ForeignExchangeIndicator == "VF" {
+			amount = entry.Addenda10.ForeignPaymentAmount
+		}
+		fx, err := er.ForeignExchange(batch.Header, amount)
+		if err != nil {
+			return batch.Error("ForeignExchange", err)
+		}
+		entry.Amount = fx.OriginatingAmount
+		entry.Addenda10.ForeignPaymentAmount = fx.DestinationAmount
+	}
+	return nil
+}
+
+// ValidateCurrencies checks ISOOriginatingCurrencyCode and ISODestinationCurrencyCode agree with
+// ISODestinationCountryCode and the ForeignExchangeIndicator.
+//
+// Entries destined for the US must settle in USD. Entries destined elsewhere must settle in
+// USD or a currency of the destination country. Fixed-to-Fixed entries must originate and
+// settle in the same currency.
+func (iatBh *IATBatchHeader) ValidateCurrencies() error {
+	orig, dest := strings.ToUpper(iatBh.ISOOriginatingCurrencyCode), strings.ToUpper(iatBh.ISODestinationCurrencyCode)
+	if !iso4217.Valid(orig) {
+		return fieldError("ISOOriginatingCurrencyCode", ErrValidISO4217, iatBh.ISOOriginatingCurrencyCode)
+	}
+	if !iso4217.Valid(dest) {
+		return fieldError("ISODestinationCurrencyCode", ErrValidISO4217, iatBh.ISODestinationCurrencyCode)
+	}
+	country := strings.ToUpper(iatBh.ISODestinationCountryCode)
+	if country == "US" && dest != "USD" {
+		return fieldError("ISODestinationCurrencyCode", ErrForeignExchangeCountryCurrency, iatBh.ISODestinationCurrencyCode)
+	}
+	if dest != "USD" && !iso4217.UsedIn(dest, country) {
+		return fieldError("ISODestinationCurrencyCode", ErrForeignExchangeCountryCurrency, iatBh.ISODestinationCurrencyCode)
+	}
+	if iatBh.ForeignExchangeIndicator == "FF" && orig != dest {
+		return fieldError("ForeignExchangeIndicator", ErrForeignExchangeFixedCurrency, iatBh.ForeignExchangeIndicator)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mockExchangeRates(t testing.TB) *ExchangeRates {
+	rates, err := ReadExchangeRatesFile(filepath.Join("test", "testdata", "exchange-rates.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rates
+}
+
+// mockIATBatchHeaderFV creates an outbound IAT BatchHeader that is Fixed-Variable from USD to CAD
+func mockIATBatchHeaderFV() *IATBatchHeader {
+	bh := mockIATBatchHeaderFF()
+	bh.ForeignExchangeIndicator = "FV"
+	bh.ISODestinationCountryCode = "CA"
+	bh.ISOOriginatingCurrencyCode = "USD"
+	bh.ISODestinationCurrencyCode = "CAD"
+	return bh
+}
+
+func TestExchangeRates__read(t *testing.T) {
+	csvRates := mockExchangeRates(t)
+	jsonRates, err := ReadExchangeRatesFile(filepath.Join("test", "testdata", "exchange-rates.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rates := range []*ExchangeRates{csvRates, jsonRates} {
+		r, err := rates.Rate("usd", "cad")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.FloatString(4) != "1.3215" {
+			t.Errorf("USD/CAD=%s", r.FloatString(4))
+		}
+		// inverse
+		r, err = rates.Rate("USD", "EUR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.FloatString(6) != "0.906454" {
+			t.Errorf("USD/EUR=%s", r.FloatString(6))
+		}
+		if _, err := rates.Rate("USD", "GBP"); !errors.Is(err, ErrExchangeRateNotFound) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := ReadExchangeRatesFile(filepath.Join("test", "testdata", "ppd-debit.ach")); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ReadExchangeRatesCSV(strings.NewReader("USD,QZA,1.0\n")); !errors.Is(err, ErrValidISO4217) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ReadExchangeRatesCSV(strings.NewReader("USD,CAD,-1.0\n")); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ReadExchangeRatesJSON(strings.NewReader("{")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestExchangeRates__Convert(t *testing.T) {
+	rates := mockExchangeRates(t)
+	cases := []struct {
+		amount   int
+		from, to string
+		expected int
+	}{
+		{100000, "USD", "CAD", 132150}, // $1,000.00 -> CA$1,321.50
+		{12345, "USD", "JPY", 13397},   // $123.45 -> ¥13,396.794 rounds up
+		{100, "USD", "BHD", 377},       // $1.00 -> BD0.377
+		{13397, "JPY", "USD", 12345},   // ¥13,397 -> $123.45
+		{5, "USD", "CAD", 7},           // $0.05 -> CA$0.066075
+		{100, "USD", "USD", 100},
+	}
+	for _, tc := range cases {
+		got, err := rates.Convert(tc.amount, tc.from, tc.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("%d %s -> %s: got %d expected %d", tc.amount, tc.from, tc.to, got, tc.expected)
+		}
+	}
+	if _, err := rates.Convert(100, "USD", "XAU"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestExchangeRates__ForeignExchange(t *testing.T) {
+	rates := mockExchangeRates(t)
+
+	// Fixed-to-Variable
+	bh := mockIATBatchHeaderFV()
+	fx, err := rates.ForeignExchange(bh, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fx.OriginatingAmount != 100000 || fx.DestinationAmount != 132150 {
+		t.Errorf("FV: %#v", fx)
+	}
+	if bh.ForeignExchangeReferenceIndicator != 1 || bh.ForeignExchangeReference != "1.3215" {
+		t.Errorf("FV: indicator=%d reference=%s", bh.ForeignExchangeReferenceIndicator, bh.ForeignExchangeReference)
+	}
+	if err := bh.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// Variable-to-Fixed
+	bh.ForeignExchangeIndicator = "VF"
+	fx, err = rates.ForeignExchange(bh, 132150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fx.OriginatingAmount != 100000 || fx.DestinationAmount != 132150 {
+		t.Errorf("VF: %#v", fx)
+	}
+
+	// Fixed-to-Fixed
+	bh.ForeignExchangeIndicator = "FF"
+	bh.ISODestinationCurrencyCode = "USD"
+	fx, err = rates.ForeignExchange(bh, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fx.OriginatingAmount != 5000 || fx.DestinationAmount != 5000 || fx.Rate != "" {
+		t.Errorf("FF: %#v", fx)
+	}
+	if bh.ForeignExchangeReferenceIndicator != 3 || bh.ForeignExchangeReference != "" {
+		t.Errorf("FF: indicator=%d reference=%s", bh.ForeignExchangeReferenceIndicator, bh.ForeignExchangeReference)
+	}
+
+	// errors
+	if _, err := rates.ForeignExchange(nil, 1); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := rates.ForeignExchange(mockIATBatchHeaderFV(), -1); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	bh = mockIATBatchHeaderFV()
+	bh.ForeignExchangeIndicator = "XX"
+	if _, err := rates.ForeignExchange(bh, 1); !errors.Is(err, ErrForeignExchangeIndicator) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	bh = mockIATBatchHeaderFV()
+	bh.ISODestinationCountryCode = "GB"
+	bh.ISODestinationCurrencyCode = "GBP"
+	if _, err := rates.ForeignExchange(bh, 1); !errors.Is(err, ErrExchangeRateNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExchangeRates__ForeignExchangeRounding(t *testing.T) {
+	rates, err := NewExchangeRates(ExchangeRate{From: "USD", To: "CAD", Rate: "123456.123456784"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bh := mockIATBatchHeaderFV()
+	fx, err := rates.ForeignExchange(bh, 1000000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// converted with the rounded rate, not 123456.123456784
+	if bh.ForeignExchangeReference != "123456.12345678" || fx.DestinationAmount != 123456123456780 {
+		t.Errorf("reference=%s fx=%#v", bh.ForeignExchangeReference, fx)
+	}
+	if err := bh.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	bh.ForeignExchangeIndicator = "VF"
+	if fx, err = rates.ForeignExchange(bh, 123456123456780); err != nil || fx.OriginatingAmount != 1000000000 {
+		t.Errorf("fx=%#v err=%v", fx, err)
+	}
+
+	// rates which don't fit ForeignExchangeReference are rejected
+	for _, rate := range []string{"1234567890123456", "0.00000000001"} {
+		rates, err := NewExchangeRates(ExchangeRate{From: "USD", To: "CAD", Rate: rate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rates.ForeignExchange(mockIATBatchHeaderFV(), 100); err == nil {
+			t.Errorf("%s: expected error", rate)
+		}
+	}
+}
+
+func TestIATBatchHeader__ValidateCurrencies(t *testing.T) {
+	cases := []struct {
+		country, orig, dest, indicator string
+		err                            error
+	}{
+		{"CA", "USD", "CAD", "FV", nil},
+		{"CA", "USD", "USD", "FF", nil},
+		{"US", "CAD", "USD", "VF", nil},
+		{"US", "CAD", "CAD", "FV", ErrForeignExchangeCountryCurrency},
+		{"MX", "USD", "CAD", "FV", ErrForeignExchangeCountryCurrency},
+		{"US", "CAD", "USD", "FF", ErrForeignExchangeFixedCurrency},
+		{"US", "QZA", "USD", "FV", ErrValidISO4217},
+		{"US", "USD", "QZA", "FV", ErrValidISO4217},
+	}
+	for _, tc := range cases {
+		bh := mockIATBatchHeaderFF()
+		bh.ISODestinationCountryCode = tc.country
+		bh.ISOOriginatingCurrencyCode = tc.orig
+		bh.ISODestinationCurrencyCode = tc.dest
+		bh.ForeignExchangeIndicator = tc.indicator
+		if err := bh.ValidateCurrencies(); !errors.Is(err, tc.err) {
+			t.Errorf("%s %s->%s %s: unexpected error: %v", tc.country, tc.orig, tc.dest, tc.indicator, err)
+		}
+	}
+}
+
+func TestExchangeRates__ApplyForeignExchange(t *testing.T) {
+	rates := mockExchangeRates(t)
+
+	batch, err := NewIATBatchFromPayments(mockIATBatchHeaderFV(), []*IATPayment{mockIATPayment()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rates.ApplyForeignExchange(&batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Create(); err != nil {
+		t.Fatal(err)
+	}
+	entry := batch.GetEntries()[0]
+	if entry.Amount != 100000 || entry.Addenda10.ForeignPaymentAmount != 132150 {
+		t.Errorf("Amount=%d ForeignPaymentAmount=%d", entry.Amount, entry.Addenda10.ForeignPaymentAmount)
+	}
+	if batch.Header.ForeignExchangeReference != "1.3215" {
+		t.Errorf("ForeignExchangeReference=%s", batch.Header.ForeignExchangeReference)
+	}
+
+	// Variable-to-Fixed recomputes the entry amount
+	batch.Header.ForeignExchangeIndicator = "VF"
+	entry.Addenda10.ForeignPaymentAmount = 264300
+	if err := rates.ApplyForeignExchange(&batch); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Amount != 200000 {
+		t.Errorf("Amount=%d", entry.Amount)
+	}
+
+	entry.Addenda10 = nil
+	if err := rates.ApplyForeignExchange(&batch); !errors.Is(err, ErrFieldInclusion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
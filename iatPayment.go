@@ -0,0 +1,397 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ourly/ach/internal/iso3166"
+)
+
+// Identification Number Qualifier values used by Addenda13, Addenda14 and Addenda18
+const (
+	// IDNumberQualifierNationalClearingSystem identifies a national clearing system number (ABA routing number in the US)
+	IDNumberQualifierNationalClearingSystem = "01"
+	// IDNumberQualifierBIC identifies a SWIFT Business Identifier Code
+	IDNumberQualifierBIC = "02"
+	// IDNumberQualifierIBAN identifies an International Bank Account Number
+	IDNumberQualifierIBAN = "03"
+)
+
+var (
+	// ErrIATPaymentRemittanceCount is returned when an IATPayment has more remittance lines than Addenda17 records allow
+	ErrIATPaymentRemittanceCount = errors.New("an IAT entry can carry a maximum of 2 remittance lines")
+	// ErrIATPaymentCorrespondentCount is returned when an IATPayment has more correspondent banks than Addenda18 records allow
+	ErrIATPaymentCorrespondentCount = errors.New("an IAT entry can carry a maximum of 5 correspondent banks")
+)
+
+// IATParty describes the Originator or Receiver of an IAT entry.
+//
+// The Originator is written to Addenda11 and Addenda12 while the Receiver is written
+// to Addenda10 (Name), Addenda15 and Addenda16.
+type IATParty struct {
+	// Name of the company or individual
+	Name string `json:"name"`
+	// IdentificationNumber is the Receiver Identification Number (Addenda15). It is ignored for Originators.
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+	// StreetAddress is the physical street address of the party
+	StreetAddress string `json:"streetAddress"`
+	// City is the city of the party
+	City string `json:"city"`
+	// StateProvince is the state or province of the party
+	StateProvince string `json:"stateProvince"`
+	// Country is the ISO 3166-1-alpha-2 code of the party's country
+	Country string `json:"country"`
+	// PostalCode is the postal code of the party
+	PostalCode string `json:"postalCode"`
+}
+
+// validate checks the IATParty contains the data required by its addenda records
+func (p IATParty) validate(field string) error {
+	if p.Name == "" {
+		return fieldError(field+".Name", ErrFieldRequired)
+	}
+	if p.StreetAddress == "" {
+		return fieldError(field+".StreetAddress", ErrFieldRequired)
+	}
+	if p.City == "" {
+		return fieldError(field+".City", ErrFieldRequired)
+	}
+	if !iso3166.Valid(p.Country) {
+		return fieldError(field+".Country", ErrValidISO3166, p.Country)
+	}
+	return nil
+}
+
+// cityStateProvince formats the City and StateProvince as used in Addenda12 and Addenda16, e.g. San Francisco*CA\
+func (p IATParty) cityStateProvince() string {
+	return p.City + "*" + p.StateProvince + `\`
+}
+
+// countryPostalCode formats the Country and PostalCode as used in Addenda12 and Addenda16, e.g. US*10036\
+func (p IATParty) countryPostalCode() string {
+	return strings.ToUpper(p.Country) + "*" + p.PostalCode + `\`
+}
+
+// setCityStateProvince reads an asterisk delimited and backslash terminated City and StateProvince
+func (p *IATParty) setCityStateProvince(s string) {
+	p.City, p.StateProvince = splitIATAddendaPair(s)
+}
+
+// setCountryPostalCode reads an asterisk delimited and backslash terminated Country and PostalCode
+func (p *IATParty) setCountryPostalCode(s string) {
+	p.Country, p.PostalCode = splitIATAddendaPair(s)
+}
+
+// splitIATAddendaPair splits a value like "US*10036\" into its two data elements
+func splitIATAddendaPair(s string) (string, string) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), `\`)
+	parts := strings.SplitN(s, "*", 2)
+	if len(parts) == 1 {
+		return strings.TrimSpace(parts[0]), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// IATBank describes a financial institution involved in an IAT entry. It's used for the ODFI
+// (Addenda13), RDFI (Addenda14) and each Foreign Correspondent Bank (Addenda18).
+type IATBank struct {
+	// Name of the financial institution
+	Name string `json:"name"`
+	// IDNumberQualifier identifies the numbering scheme of Identification.
+	// See IDNumberQualifierNationalClearingSystem, IDNumberQualifierBIC and IDNumberQualifierIBAN.
+	IDNumberQualifier string `json:"IDNumberQualifier"`
+	// Identification is the bank identification number (routing number, BIC or IBAN)
+	Identification string `json:"identification"`
+	// BranchCountryCode is the ISO 3166-1-alpha-2 code of the country the branch is located in
+	BranchCountryCode string `json:"branchCountryCode"`
+}
+
+// validate checks the IATBank contains the data required by its addenda record
+func (b IATBank) validate(field string) error {
+	if b.Name == "" {
+		return fieldError(field+".Name", ErrFieldRequired)
+	}
+	switch b.IDNumberQualifier {
+	case IDNumberQualifierNationalClearingSystem, IDNumberQualifierBIC, IDNumberQualifierIBAN:
+	default:
+		return fieldError(field+".IDNumberQualifier", ErrIDNumberQualifier, b.IDNumberQualifier)
+	}
+	if b.Identification == "" {
+		return fieldError(field+".Identification", ErrFieldRequired)
+	}
+	if !iso3166.Valid(b.BranchCountryCode) {
+		return fieldError(field+".BranchCountryCode", ErrValidISO3166, b.BranchCountryCode)
+	}
+	return nil
+}
+
+// IATPayment is a high-level description of an IAT entry and all of its addenda records.
+//
+// Use IATPayment.IATEntryDetail to build an IATEntryDetail with Addenda10 through Addenda18
+// populated or NewIATBatchFromPayments to build a complete IATBatch. IATPaymentFromEntry
+// converts an existing IATEntryDetail back into an IATPayment.
+type IATPayment struct {
+	// TransactionCode of the entry, e.g. CheckingCredit or SavingsDebit
+	TransactionCode int `json:"transactionCode"`
+	// TransactionTypeCode describes the type of payment (Addenda10), e.g. ANN, BUS or SAL
+	TransactionTypeCode string `json:"transactionTypeCode"`
+	// Amount is the number of cents being debited/credited
+	Amount int `json:"amount"`
+	// ForeignPaymentAmount is the amount of the payment in its foreign currency (Addenda10).
+	// If zero Amount is used.
+	ForeignPaymentAmount int `json:"foreignPaymentAmount,omitempty"`
+	// ForeignTraceNumber is the trace number assigned by the foreign gateway (Addenda10)
+	ForeignTraceNumber string `json:"foreignTraceNumber,omitempty"`
+	// RDFIRoutingNumber is the 9 digit routing number of the gateway or RDFI
+	RDFIRoutingNumber string `json:"RDFIRoutingNumber"`
+	// DFIAccountNumber is the Receiver's account number
+	DFIAccountNumber string `json:"DFIAccountNumber"`
+
+	// Originator of the payment (Addenda11 and Addenda12)
+	Originator IATParty `json:"originator"`
+	// Receiver of the payment (Addenda10, Addenda15 and Addenda16)
+	Receiver IATParty `json:"receiver"`
+	// ODFI is the originating financial institution (Addenda13)
+	ODFI IATBank `json:"ODFI"`
+	// RDFI is the receiving financial institution (Addenda14)
+	RDFI IATBank `json:"RDFI"`
+	// CorrespondentBanks are the Foreign Correspondent Banks involved in the payment (Addenda18)
+	CorrespondentBanks []IATBank `json:"correspondentBanks,omitempty"`
+	// Remittance is payment related information (Addenda17), up to 80 characters per line
+	Remittance []string `json:"remittance,omitempty"`
+}
+
+// Validate checks the IATPayment has the information required to build an IATEntryDetail
+func (p *IATPayment) Validate() error {
+	if p.TransactionCode == 0 {
+		return fieldError("TransactionCode", ErrFieldRequired)
+	}
+	if p.TransactionTypeCode == "" {
+		return fieldError("TransactionTypeCode", ErrFieldRequired)
+	}
+	if p.Amount < 0 || p.ForeignPaymentAmount < 0 {
+		return fieldError("Amount", ErrNegativeAmount, p.Amount)
+	}
+	if err := CheckRoutingNumber(p.RDFIRoutingNumber); err != nil {
+		return fieldError("RDFIRoutingNumber", err, p.RDFIRoutingNumber)
+	}
+	if p.DFIAccountNumber == "" {
+		return fieldError("DFIAccountNumber", ErrFieldRequired)
+	}
+	if err := p.Originator.validate("Originator"); err != nil {
+		return err
+	}
+	if err := p.Receiver.validate("Receiver"); err != nil {
+		return err
+	}
+	if err := p.ODFI.validate("ODFI"); err != nil {
+		return err
+	}
+	if err := p.RDFI.validate("RDFI"); err != nil {
+		return err
+	}
+	if len(p.CorrespondentBanks) > 5 {
+		return fieldError("CorrespondentBanks", ErrIATPaymentCorrespondentCount, len(p.CorrespondentBanks))
+	}
+	for i := range p.CorrespondentBanks {
+		if err := p.CorrespondentBanks[i].validate("CorrespondentBanks"); err != nil {
+			return err
+		}
+	}
+	if len(p.Remittance) > 2 {
+		return fieldError("Remittance", ErrIATPaymentRemittanceCount, len(p.Remittance))
+	}
+	return nil
+}
+
+// IATEntryDetail returns an IATEntryDetail with all mandatory (Addenda10-16) and optional
+// (Addenda17, Addenda18) addenda records populated from the IATPayment.
+//
+// The TraceNumber and each addenda's EntryDetailSequenceNumber are left empty and are
+// assigned when the entry is added to an IATBatch and Create() is called.
+func (p *IATPayment) IATEntryDetail() (*IATEntryDetail, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	ed := NewIATEntryDetail()
+	ed.TransactionCode = p.TransactionCode
+	ed.SetRDFI(p.RDFIRoutingNumber)
+	ed.Amount = p.Amount
+	ed.DFIAccountNumber = p.DFIAccountNumber
+
+	addenda10 := NewAddenda10()
+	addenda10.TransactionTypeCode = p.TransactionTypeCode
+	addenda10.ForeignPaymentAmount = p.ForeignPaymentAmount
+	if addenda10.ForeignPaymentAmount == 0 {
+		addenda10.ForeignPaymentAmount = p.Amount
+	}
+	addenda10.ForeignTraceNumber = p.ForeignTraceNumber
+	addenda10.Name = p.Receiver.Name
+	ed.Addenda10 = addenda10
+
+	addenda11 := NewAddenda11()
+	addenda11.OriginatorName = p.Originator.Name
+	addenda11.OriginatorStreetAddress = p.Originator.StreetAddress
+	ed.Addenda11 = addenda11
+
+	addenda12 := NewAddenda12()
+	addenda12.OriginatorCityStateProvince = p.Originator.cityStateProvince()
+	addenda12.OriginatorCountryPostalCode = p.Originator.countryPostalCode()
+	ed.Addenda12 = addenda12
+
+	addenda13 := NewAddenda13()
+	addenda13.ODFIName = p.ODFI.Name
+	addenda13.ODFIIDNumberQualifier = p.ODFI.IDNumberQualifier
+	addenda13.ODFIIdentification = p.ODFI.Identification
+	addenda13.ODFIBranchCountryCode = strings.ToUpper(p.ODFI.BranchCountryCode)
+	ed.Addenda13 = addenda13
+
+	addenda14 := NewAddenda14()
+	addenda14.RDFIName = p.RDFI.Name
+	addenda14.RDFIIDNumberQualifier = p.RDFI.IDNumberQualifier
+	addenda14.RDFIIdentification = p.RDFI.Identification
+	addenda14.RDFIBranchCountryCode = strings.ToUpper(p.RDFI.BranchCountryCode)
+	ed.Addenda14 = addenda14
+
+	addenda15 := NewAddenda15()
+	addenda15.ReceiverIDNumber = p.Receiver.IdentificationNumber
+	addenda15.ReceiverStreetAddress = p.Receiver.StreetAddress
+	ed.Addenda15 = addenda15
+
+	addenda16 := NewAddenda16()
+	addenda16.ReceiverCityStateProvince = p.Receiver.cityStateProvince()
+	addenda16.ReceiverCountryPostalCode = p.Receiver.countryPostalCode()
+	ed.Addenda16 = addenda16
+
+	for i := range p.Remittance {
+		addenda17 := NewAddenda17()
+		addenda17.PaymentRelatedInformation = p.Remittance[i]
+		addenda17.SequenceNumber = i + 1
+		ed.AddAddenda17(addenda17)
+	}
+	for i, bank := range p.CorrespondentBanks {
+		addenda18 := NewAddenda18()
+		addenda18.ForeignCorrespondentBankName = bank.Name
+		addenda18.ForeignCorrespondentBankIDNumberQualifier = bank.IDNumberQualifier
+		addenda18.ForeignCorrespondentBankIDNumber = bank.Identification
+		addenda18.ForeignCorrespondentBankBranchCountryCode = strings.ToUpper(bank.BranchCountryCode)
+		addenda18.SequenceNumber = i + 1
+		ed.AddAddenda18(addenda18)
+	}
+
+	// Seven mandatory addenda records plus optional Addenda17 and Addenda18
+	ed.AddendaRecords = 7 + len(ed.Addenda17) + len(ed.Addenda18)
+	return ed, nil
+}
+
+// NewIATBatchFromPayments builds an IATBatch from the given header and payments. Create() is
+// called on the returned batch so all sequence numbers, addenda and controls are populated and validated.
+func NewIATBatchFromPayments(bh *IATBatchHeader, payments []*IATPayment) (IATBatch, error) {
+	batch := NewIATBatch(bh)
+	for i := range payments {
+		if payments[i] == nil {
+			continue
+		}
+		ed, err := payments[i].IATEntryDetail()
+		if err != nil {
+			return batch, err
+		}
+		batch.AddEntry(ed)
+	}
+	if err := batch.Create(); err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
+
+// IATPaymentFromEntry converts an IATEntryDetail and its addenda records into an IATPayment.
+// An error is returned if any of the mandatory Addenda10-16 records are missing.
+func IATPaymentFromEntry(ed *IATEntryDetail) (*IATPayment, error) {
+	if ed == nil {
+		return nil, errors.New("nil IATEntryDetail")
+	}
+	// Addenda10-16 are required regardless of the entry's Category
+	forward := *ed
+	forward.Category = CategoryForward
+	if err := (&IATBatch{}).addendaFieldInclusion(&forward); err != nil {
+		return nil, err
+	}
+
+	p := &IATPayment{
+		TransactionCode:      ed.TransactionCode,
+		TransactionTypeCode:  ed.Addenda10.TransactionTypeCode,
+		Amount:               ed.Amount,
+		ForeignPaymentAmount: ed.Addenda10.ForeignPaymentAmount,
+		ForeignTraceNumber:   ed.Addenda10.ForeignTraceNumber,
+		RDFIRoutingNumber:    ed.RDFIIdentificationField() + ed.CheckDigit,
+		DFIAccountNumber:     strings.TrimSpace(ed.DFIAccountNumber),
+	}
+
+	p.Originator.Name = ed.Addenda11.OriginatorName
+	p.Originator.StreetAddress = ed.Addenda11.OriginatorStreetAddress
+	p.Originator.setCityStateProvince(ed.Addenda12.OriginatorCityStateProvince)
+	p.Originator.setCountryPostalCode(ed.Addenda12.OriginatorCountryPostalCode)
+
+	p.Receiver.Name = ed.Addenda10.Name
+	p.Receiver.IdentificationNumber = ed.Addenda15.ReceiverIDNumber
+	p.Receiver.StreetAddress = ed.Addenda15.ReceiverStreetAddress
+	p.Receiver.setCityStateProvince(ed.Addenda16.ReceiverCityStateProvince)
+	p.Receiver.setCountryPostalCode(ed.Addenda16.ReceiverCountryPostalCode)
+
+	p.ODFI = IATBank{
+		Name:              ed.Addenda13.ODFIName,
+		IDNumberQualifier: ed.Addenda13.ODFIIDNumberQualifier,
+		Identification:    ed.Addenda13.ODFIIdentification,
+		BranchCountryCode: ed.Addenda13.ODFIBranchCountryCode,
+	}
+	p.RDFI = IATBank{
+		Name:              ed.Addenda14.RDFIName,
+		IDNumberQualifier: ed.Addenda14.RDFIIDNumberQualifier,
+		Identification:    ed.Addenda14.RDFIIdentification,
+		BranchCountryCode: ed.Addenda14.RDFIBranchCountryCode,
+	}
+
+	for _, addenda17 := range ed.Addenda17 {
+		p.Remittance = append(p.Remittance, strings.TrimSpace(addenda17.PaymentRelatedInformation))
+	}
+	for _, addenda18 := range ed.Addenda18 {
+		p.CorrespondentBanks = append(p.CorrespondentBanks, IATBank{
+			Name:              addenda18.ForeignCorrespondentBankName,
+			IDNumberQualifier: addenda18.ForeignCorrespondentBankIDNumberQualifier,
+			Identification:    addenda18.ForeignCorrespondentBankIDNumber,
+			BranchCountryCode: addenda18.ForeignCorrespondentBankBranchCountryCode,
+		})
+	}
+	return p, nil
+}
+
+// IATPaymentsFromBatch converts each IATEntryDetail in an IATBatch into an IATPayment.
+func IATPaymentsFromBatch(batch IATBatch) ([]*IATPayment, error) {
+	var out []*IATPayment
+	for _, ed := range batch.GetEntries() {
+		p, err := IATPaymentFromEntry(ed)
+		if err != nil {
+			return nil, batch.Error("IATPayment", err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
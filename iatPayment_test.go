@@ -0,0 +1,227 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mockIATPayment creates an IATPayment with all parties and banks populated
+func mockIATPayment() *IATPayment {
+	return &IATPayment{
+		TransactionCode:     CheckingCredit,
+		TransactionTypeCode: "ANN",
+		Amount:              100000,
+		ForeignTraceNumber:  "928383-23938",
+		RDFIRoutingNumber:   "121042882",
+		DFIAccountNumber:    "123456789",
+		Originator: IATParty{
+			Name:          "BEK Solutions",
+			StreetAddress: "15 West Place Street",
+			City:          "JacobsTown",
+			StateProvince: "PA",
+			Country:       "US",
+			PostalCode:    "19305",
+		},
+		Receiver: IATParty{
+			Name:                 "BEK Enterprises",
+			IdentificationNumber: "987465493213987",
+			StreetAddress:        "2121 Front Street",
+			City:                 "LetterTown",
+			StateProvince:        "AB",
+			Country:              "CA",
+			PostalCode:           "80014",
+		},
+		ODFI: IATBank{
+			Name:              "Wells Fargo",
+			IDNumberQualifier: IDNumberQualifierNationalClearingSystem,
+			Identification:    "231380104",
+			BranchCountryCode: "US",
+		},
+		RDFI: IATBank{
+			Name:              "Citadel Bank",
+			IDNumberQualifier: IDNumberQualifierNationalClearingSystem,
+			Identification:    "121042882",
+			BranchCountryCode: "CA",
+		},
+		CorrespondentBanks: []IATBank{
+			{
+				Name:              "Bank of France",
+				IDNumberQualifier: IDNumberQualifierBIC,
+				Identification:    "BDFEFRPPCCT",
+				BranchCountryCode: "FR",
+			},
+		},
+		Remittance: []string{"Invoice 12345"},
+	}
+}
+
+func TestIATPayment__IATEntryDetail(t *testing.T) {
+	ed, err := mockIATPayment().IATEntryDetail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.AddendaRecords != 9 {
+		t.Errorf("AddendaRecords=%d", ed.AddendaRecords)
+	}
+	if ed.RDFIIdentification != "12104288" || ed.CheckDigit != "2" {
+		t.Errorf("RDFIIdentification=%s CheckDigit=%s", ed.RDFIIdentification, ed.CheckDigit)
+	}
+	if ed.Addenda10.ForeignPaymentAmount != 100000 {
+		t.Errorf("ForeignPaymentAmount=%d", ed.Addenda10.ForeignPaymentAmount)
+	}
+	if ed.Addenda12.OriginatorCityStateProvince != `JacobsTown*PA\` {
+		t.Errorf("OriginatorCityStateProvince=%s", ed.Addenda12.OriginatorCityStateProvince)
+	}
+	if ed.Addenda16.ReceiverCountryPostalCode != `CA*80014\` {
+		t.Errorf("ReceiverCountryPostalCode=%s", ed.Addenda16.ReceiverCountryPostalCode)
+	}
+	if len(ed.Addenda17) != 1 || ed.Addenda17[0].SequenceNumber != 1 {
+		t.Errorf("unexpected Addenda17: %v", ed.Addenda17)
+	}
+	if len(ed.Addenda18) != 1 || ed.Addenda18[0].ForeignCorrespondentBankIDNumberQualifier != "02" {
+		t.Errorf("unexpected Addenda18: %v", ed.Addenda18)
+	}
+}
+
+func TestIATPayment__Validate(t *testing.T) {
+	cases := []struct {
+		field  string
+		modify func(p *IATPayment)
+		err    error
+	}{
+		{"TransactionCode", func(p *IATPayment) { p.TransactionCode = 0 }, ErrFieldRequired},
+		{"Amount", func(p *IATPayment) { p.Amount = -1 }, ErrNegativeAmount},
+		{"Originator.Name", func(p *IATPayment) { p.Originator.Name = "" }, ErrFieldRequired},
+		{"Receiver.Country", func(p *IATPayment) { p.Receiver.Country = "ZZ" }, ErrValidISO3166},
+		{"ODFI.IDNumberQualifier", func(p *IATPayment) { p.ODFI.IDNumberQualifier = "09" }, ErrIDNumberQualifier},
+		{"RDFI.BranchCountryCode", func(p *IATPayment) { p.RDFI.BranchCountryCode = "" }, ErrValidISO3166},
+		{"Remittance", func(p *IATPayment) { p.Remittance = []string{"a", "b", "c"} }, ErrIATPaymentRemittanceCount},
+		{"CorrespondentBanks", func(p *IATPayment) {
+			for len(p.CorrespondentBanks) <= 5 {
+				p.CorrespondentBanks = append(p.CorrespondentBanks, p.CorrespondentBanks[0])
+			}
+		}, ErrIATPaymentCorrespondentCount},
+	}
+	for _, tc := range cases {
+		p := mockIATPayment()
+		tc.modify(p)
+		err := p.Validate()
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.field, err)
+		}
+		if !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("%s: expected field in error: %v", tc.field, err)
+		}
+	}
+
+	p := mockIATPayment()
+	p.RDFIRoutingNumber = "121042881"
+	if err := p.Validate(); err == nil {
+		t.Error("expected invalid check digit")
+	}
+}
+
+func TestIATPayment__NewIATBatchFromPayments(t *testing.T) {
+	second := mockIATPayment()
+	second.TransactionCode = CheckingDebit
+	second.Amount = 2500
+	second.Remittance = nil
+	second.CorrespondentBanks = nil
+
+	bh := mockIATBatchHeaderFF()
+	bh.ServiceClassCode = MixedDebitsAndCredits
+	batch, err := NewIATBatchFromPayments(bh, []*IATPayment{mockIATPayment(), second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(batch.GetEntries()); n != 2 {
+		t.Fatalf("got %d entries", n)
+	}
+	if batch.Control.EntryAddendaCount != (1+9)+(1+7) {
+		t.Errorf("EntryAddendaCount=%d", batch.Control.EntryAddendaCount)
+	}
+	if batch.Control.TotalCreditEntryDollarAmount != 100000 || batch.Control.TotalDebitEntryDollarAmount != 2500 {
+		t.Errorf("credit=%d debit=%d", batch.Control.TotalCreditEntryDollarAmount, batch.Control.TotalDebitEntryDollarAmount)
+	}
+	ed := batch.GetEntries()[1]
+	if ed.Addenda16.EntryDetailSequenceNumberField() != ed.TraceNumberField()[8:] {
+		t.Errorf("Addenda16 sequence=%s trace=%s", ed.Addenda16.EntryDetailSequenceNumberField(), ed.TraceNumber)
+	}
+
+	// invalid payments are returned as errors
+	bad := mockIATPayment()
+	bad.DFIAccountNumber = ""
+	if _, err := NewIATBatchFromPayments(bh, []*IATPayment{bad}); !errors.Is(err, ErrFieldRequired) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIATPayment__roundTrip(t *testing.T) {
+	bh := mockIATBatchHeaderFF()
+	batch, err := NewIATBatchFromPayments(bh, []*IATPayment{mockIATPayment()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddIATBatch(batch)
+	if err := file.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := IATPaymentsFromBatch(read.IATBatches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payments) != 1 {
+		t.Fatalf("got %d payments", len(payments))
+	}
+	expected := mockIATPayment()
+	expected.ForeignPaymentAmount = expected.Amount // defaulted when building Addenda10
+	if !reflect.DeepEqual(expected, payments[0]) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, payments[0])
+	}
+}
+
+func TestIATPaymentFromEntry__missingAddenda(t *testing.T) {
+	if _, err := IATPaymentFromEntry(nil); err == nil {
+		t.Error("expected error")
+	}
+	ed, err := mockIATPayment().IATEntryDetail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed.Addenda14 = nil
+	if _, err := IATPaymentFromEntry(ed); !errors.Is(err, ErrFieldInclusion) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
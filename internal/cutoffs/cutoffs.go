@@ -0,0 +1,75 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cutoffs parses the daily cutoff times files are delivered by and finds the next one.
+package cutoffs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Schedule is a sorted set of times of day in a location
+type Schedule struct {
+	times    []time.Duration
+	location *time.Location
+}
+
+// Parse returns the Schedule of values, each formatted as "15:04", in loc. UTC is used when
+// loc is nil.
+func Parse(values []string, loc *time.Location) (*Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	s := &Schedule{location: loc}
+	for _, v := range values {
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			return nil, fmt.Errorf("cutoff %q is not formatted as 15:04", v)
+		}
+		s.times = append(s.times, time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute)
+	}
+	sort.Slice(s.times, func(i, j int) bool { return s.times[i] < s.times[j] })
+	return s, nil
+}
+
+// Len returns how many cutoffs are in each day
+func (s *Schedule) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.times)
+}
+
+// Next returns the first cutoff after t, or false if the Schedule is empty. Cutoffs are
+// computed from the calendar date so they keep their wall clock time across DST changes.
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	if s.Len() == 0 {
+		return time.Time{}, false
+	}
+	local := t.In(s.location)
+	for day := 0; ; day++ {
+		y, m, d := local.AddDate(0, 0, day).Date()
+		for _, cutoff := range s.times {
+			at := time.Date(y, m, d, int(cutoff/time.Hour), int(cutoff%time.Hour/time.Minute), 0, 0, s.location)
+			if at.After(t) {
+				return at, true
+			}
+		}
+	}
+}
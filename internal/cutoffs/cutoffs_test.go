@@ -0,0 +1,75 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cutoffs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule__Next(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := Parse([]string{"16:45", "10:30"}, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("got %d cutoffs", s.Len())
+	}
+
+	cases := []struct {
+		now, want string
+	}{
+		{"2019-06-03T09:00:00-04:00", "2019-06-03T10:30:00-04:00"},
+		{"2019-06-03T10:30:00-04:00", "2019-06-03T16:45:00-04:00"},
+		{"2019-06-03T17:00:00-04:00", "2019-06-04T10:30:00-04:00"},
+		// DST starts overnight, the cutoff keeps its wall clock time
+		{"2019-03-09T17:00:00-05:00", "2019-03-10T10:30:00-04:00"},
+	}
+	for _, tc := range cases {
+		now, _ := time.Parse(time.RFC3339, tc.now)
+		want, _ := time.Parse(time.RFC3339, tc.want)
+		next, ok := s.Next(now)
+		if !ok || !next.Equal(want) {
+			t.Errorf("Next(%s) = %s, %v want %s", tc.now, next, ok, tc.want)
+		}
+	}
+}
+
+func TestSchedule__empty(t *testing.T) {
+	s, err := Parse(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Next(time.Now()); ok {
+		t.Error("expected no cutoff")
+	}
+	var nilSchedule *Schedule
+	if _, ok := nilSchedule.Next(time.Now()); ok {
+		t.Error("expected no cutoff")
+	}
+}
+
+func TestSchedule__invalid(t *testing.T) {
+	if _, err := Parse([]string{"4pm"}, time.UTC); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,272 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso4217
+
+// countryCurrencies maps ISO 3166-1-alpha-2 country codes to the ISO 4217 currencies
+// which are legal tender in that country. The first currency is the primary one.
+//
+// Unlike iso4217.go this table is maintained by hand.
+var countryCurrencies = map[string][]string{
+	"AF": {"AFN"},               // Afghanistan
+	"AX": {"EUR"},               // Åland Islands
+	"AL": {"ALL"},               // Albania
+	"DZ": {"DZD"},               // Algeria
+	"AS": {"USD"},               // American Samoa
+	"AD": {"EUR"},               // Andorra
+	"AO": {"AOA"},               // Angola
+	"AI": {"XCD"},               // Anguilla
+	"AG": {"XCD"},               // Antigua and Barbuda
+	"AR": {"ARS"},               // Argentina
+	"AM": {"AMD"},               // Armenia
+	"AW": {"AWG"},               // Aruba
+	"AU": {"AUD"},               // Australia
+	"AT": {"EUR"},               // Austria
+	"AZ": {"AZN"},               // Azerbaijan
+	"BS": {"BSD"},               // Bahamas
+	"BH": {"BHD"},               // Bahrain
+	"BD": {"BDT"},               // Bangladesh
+	"BB": {"BBD"},               // Barbados
+	"BY": {"BYN"},               // Belarus
+	"BE": {"EUR"},               // Belgium
+	"BZ": {"BZD"},               // Belize
+	"BJ": {"XOF"},               // Benin
+	"BM": {"BMD"},               // Bermuda
+	"BT": {"BTN", "INR"},        // Bhutan
+	"BO": {"BOB", "BOV"},        // Bolivia, Plurinational State of
+	"BQ": {"USD"},               // Bonaire, Sint Eustatius and Saba
+	"BA": {"BAM"},               // Bosnia and Herzegovina
+	"BW": {"BWP"},               // Botswana
+	"BV": {"NOK"},               // Bouvet Island
+	"BR": {"BRL"},               // Brazil
+	"IO": {"USD"},               // British Indian Ocean Territory
+	"BN": {"BND"},               // Brunei Darussalam
+	"BG": {"BGN"},               // Bulgaria
+	"BF": {"XOF"},               // Burkina Faso
+	"BI": {"BIF"},               // Burundi
+	"KH": {"KHR"},               // Cambodia
+	"CM": {"XAF"},               // Cameroon
+	"CA": {"CAD"},               // Canada
+	"CV": {"CVE"},               // Cape Verde
+	"KY": {"KYD"},               // Cayman Islands
+	"CF": {"XAF"},               // Central African Republic
+	"TD": {"XAF"},               // Chad
+	"CL": {"CLP", "CLF"},        // Chile
+	"CN": {"CNY"},               // China
+	"CX": {"AUD"},               // Christmas Island
+	"CC": {"AUD"},               // Cocos (Keeling) Islands
+	"CO": {"COP", "COU"},        // Colombia
+	"KM": {"KMF"},               // Comoros
+	"CG": {"XAF"},               // Congo
+	"CD": {"CDF"},               // Congo, the Democratic Republic of the
+	"CK": {"NZD"},               // Cook Islands
+	"CR": {"CRC"},               // Costa Rica
+	"CI": {"XOF"},               // Côte d'Ivoire
+	"HR": {"HRK"},               // Croatia
+	"CU": {"CUP", "CUC"},        // Cuba
+	"CW": {"ANG"},               // Curaçao
+	"CY": {"EUR"},               // Cyprus
+	"CZ": {"CZK"},               // Czech Republic
+	"DK": {"DKK"},               // Denmark
+	"DJ": {"DJF"},               // Djibouti
+	"DM": {"XCD"},               // Dominica
+	"DO": {"DOP"},               // Dominican Republic
+	"EC": {"USD"},               // Ecuador
+	"EG": {"EGP"},               // Egypt
+	"SV": {"SVC", "USD"},        // El Salvador
+	"GQ": {"XAF"},               // Equatorial Guinea
+	"ER": {"ERN"},               // Eritrea
+	"EE": {"EUR"},               // Estonia
+	"ET": {"ETB"},               // Ethiopia
+	"FK": {"FKP"},               // Falkland Islands (Malvinas)
+	"FO": {"DKK"},               // Faroe Islands
+	"FJ": {"FJD"},               // Fiji
+	"FI": {"EUR"},               // Finland
+	"FR": {"EUR"},               // France
+	"GF": {"EUR"},               // French Guiana
+	"PF": {"XPF"},               // French Polynesia
+	"TF": {"EUR"},               // French Southern Territories
+	"GA": {"XAF"},               // Gabon
+	"GM": {"GMD"},               // Gambia
+	"GE": {"GEL"},               // Georgia
+	"DE": {"EUR"},               // Germany
+	"GH": {"GHS"},               // Ghana
+	"GI": {"GIP"},               // Gibraltar
+	"GR": {"EUR"},               // Greece
+	"GL": {"DKK"},               // Greenland
+	"GD": {"XCD"},               // Grenada
+	"GP": {"EUR"},               // Guadeloupe
+	"GU": {"USD"},               // Guam
+	"GT": {"GTQ"},               // Guatemala
+	"GG": {"GBP"},               // Guernsey
+	"GN": {"GNF"},               // Guinea
+	"GW": {"XOF"},               // Guinea-Bissau
+	"GY": {"GYD"},               // Guyana
+	"HT": {"HTG", "USD"},        // Haiti
+	"HM": {"AUD"},               // Heard Island and McDonald Islands
+	"VA": {"EUR"},               // Holy See (Vatican City State)
+	"HN": {"HNL"},               // Honduras
+	"HK": {"HKD"},               // Hong Kong
+	"HU": {"HUF"},               // Hungary
+	"IS": {"ISK"},               // Iceland
+	"IN": {"INR"},               // India
+	"ID": {"IDR"},               // Indonesia
+	"IR": {"IRR"},               // Iran, Islamic Republic of
+	"IQ": {"IQD"},               // Iraq
+	"IE": {"EUR"},               // Ireland
+	"IM": {"GBP"},               // Isle of Man
+	"IL": {"ILS"},               // Israel
+	"IT": {"EUR"},               // Italy
+	"JM": {"JMD"},               // Jamaica
+	"JP": {"JPY"},               // Japan
+	"JE": {"GBP"},               // Jersey
+	"JO": {"JOD"},               // Jordan
+	"KZ": {"KZT"},               // Kazakhstan
+	"KE": {"KES"},               // Kenya
+	"KI": {"AUD"},               // Kiribati
+	"KP": {"KPW"},               // Korea, Democratic People's Republic of
+	"KR": {"KRW"},               // Korea, Republic of
+	"KW": {"KWD"},               // Kuwait
+	"KG": {"KGS"},               // Kyrgyzstan
+	"LA": {"LAK"},               // Lao People's Democratic Republic
+	"LV": {"EUR"},               // Latvia
+	"LB": {"LBP"},               // Lebanon
+	"LS": {"LSL", "ZAR"},        // Lesotho
+	"LR": {"LRD"},               // Liberia
+	"LY": {"LYD"},               // Libya
+	"LI": {"CHF"},               // Liechtenstein
+	"LT": {"EUR"},               // Lithuania
+	"LU": {"EUR"},               // Luxembourg
+	"MO": {"MOP"},               // Macao
+	"MK": {"MKD"},               // Macedonia, the Former Yugoslav Republic of
+	"MG": {"MGA"},               // Madagascar
+	"MW": {"MWK"},               // Malawi
+	"MY": {"MYR"},               // Malaysia
+	"MV": {"MVR"},               // Maldives
+	"ML": {"XOF"},               // Mali
+	"MT": {"EUR"},               // Malta
+	"MH": {"USD"},               // Marshall Islands
+	"MQ": {"EUR"},               // Martinique
+	"MR": {"MRO"},               // Mauritania
+	"MU": {"MUR"},               // Mauritius
+	"YT": {"EUR"},               // Mayotte
+	"MX": {"MXN", "MXV"},        // Mexico
+	"FM": {"USD"},               // Micronesia, Federated States of
+	"MD": {"MDL"},               // Moldova, Republic of
+	"MC": {"EUR"},               // Monaco
+	"MN": {"MNT"},               // Mongolia
+	"ME": {"EUR"},               // Montenegro
+	"MS": {"XCD"},               // Montserrat
+	"MA": {"MAD"},               // Morocco
+	"MZ": {"MZN"},               // Mozambique
+	"MM": {"MMK"},               // Myanmar
+	"NA": {"NAD", "ZAR"},        // Namibia
+	"NR": {"AUD"},               // Nauru
+	"NP": {"NPR"},               // Nepal
+	"NL": {"EUR"},               // Netherlands
+	"NC": {"XPF"},               // New Caledonia
+	"NZ": {"NZD"},               // New Zealand
+	"NI": {"NIO"},               // Nicaragua
+	"NE": {"XOF"},               // Niger
+	"NG": {"NGN"},               // Nigeria
+	"NU": {"NZD"},               // Niue
+	"NF": {"AUD"},               // Norfolk Island
+	"MP": {"USD"},               // Northern Mariana Islands
+	"NO": {"NOK"},               // Norway
+	"OM": {"OMR"},               // Oman
+	"PK": {"PKR"},               // Pakistan
+	"PW": {"USD"},               // Palau
+	"PS": {"ILS", "JOD"},        // Palestine, State of
+	"PA": {"PAB", "USD"},        // Panama
+	"PG": {"PGK"},               // Papua New Guinea
+	"PY": {"PYG"},               // Paraguay
+	"PE": {"PEN"},               // Peru
+	"PH": {"PHP"},               // Philippines
+	"PN": {"NZD"},               // Pitcairn
+	"PL": {"PLN"},               // Poland
+	"PT": {"EUR"},               // Portugal
+	"PR": {"USD"},               // Puerto Rico
+	"QA": {"QAR"},               // Qatar
+	"RE": {"EUR"},               // Réunion
+	"RO": {"RON"},               // Romania
+	"RU": {"RUB"},               // Russian Federation
+	"RW": {"RWF"},               // Rwanda
+	"BL": {"EUR"},               // Saint Barthélemy
+	"SH": {"SHP"},               // Saint Helena, Ascension and Tristan da Cunha
+	"KN": {"XCD"},               // Saint Kitts and Nevis
+	"LC": {"XCD"},               // Saint Lucia
+	"MF": {"EUR"},               // Saint Martin (French part)
+	"PM": {"EUR"},               // Saint Pierre and Miquelon
+	"VC": {"XCD"},               // Saint Vincent and the Grenadines
+	"WS": {"WST"},               // Samoa
+	"SM": {"EUR"},               // San Marino
+	"ST": {"STD"},               // Sao Tome and Principe
+	"SA": {"SAR"},               // Saudi Arabia
+	"SN": {"XOF"},               // Senegal
+	"RS": {"RSD"},               // Serbia
+	"SC": {"SCR"},               // Seychelles
+	"SL": {"SLL"},               // Sierra Leone
+	"SG": {"SGD"},               // Singapore
+	"SX": {"ANG"},               // Sint Maarten (Dutch part)
+	"SK": {"EUR"},               // Slovakia
+	"SI": {"EUR"},               // Slovenia
+	"SB": {"SBD"},               // Solomon Islands
+	"SO": {"SOS"},               // Somalia
+	"ZA": {"ZAR"},               // South Africa
+	"SS": {"SSP"},               // South Sudan
+	"ES": {"EUR"},               // Spain
+	"LK": {"LKR"},               // Sri Lanka
+	"SD": {"SDG"},               // Sudan
+	"SR": {"SRD"},               // Suriname
+	"SJ": {"NOK"},               // Svalbard and Jan Mayen
+	"SZ": {"SZL"},               // Swaziland
+	"SE": {"SEK"},               // Sweden
+	"CH": {"CHF", "CHE", "CHW"}, // Switzerland
+	"SY": {"SYP"},               // Syrian Arab Republic
+	"TW": {"TWD"},               // Taiwan, Province of China
+	"TJ": {"TJS"},               // Tajikistan
+	"TZ": {"TZS"},               // Tanzania, United Republic of
+	"TH": {"THB"},               // Thailand
+	"TL": {"USD"},               // Timor-Leste
+	"TG": {"XOF"},               // Togo
+	"TK": {"NZD"},               // Tokelau
+	"TO": {"TOP"},               // Tonga
+	"TT": {"TTD"},               // Trinidad and Tobago
+	"TN": {"TND"},               // Tunisia
+	"TR": {"TRY"},               // Turkey
+	"TM": {"TMT"},               // Turkmenistan
+	"TC": {"USD"},               // Turks and Caicos Islands
+	"TV": {"AUD"},               // Tuvalu
+	"UG": {"UGX"},               // Uganda
+	"UA": {"UAH"},               // Ukraine
+	"AE": {"AED"},               // United Arab Emirates
+	"GB": {"GBP"},               // United Kingdom
+	"US": {"USD", "USN"},        // United States
+	"UM": {"USD"},               // United States Minor Outlying Islands
+	"UY": {"UYU", "UYI"},        // Uruguay
+	"UZ": {"UZS"},               // Uzbekistan
+	"VU": {"VUV"},               // Vanuatu
+	"VE": {"VEF"},               // Venezuela, Bolivarian Republic of
+	"VN": {"VND"},               // Viet Nam
+	"VG": {"USD"},               // Virgin Islands, British
+	"VI": {"USD"},               // Virgin Islands, U.S.
+	"WF": {"XPF"},               // Wallis and Futuna
+	"EH": {"MAD"},               // Western Sahara
+	"YE": {"YER"},               // Yemen
+	"ZM": {"ZMW"},               // Zambia
+	"ZW": {"ZWL"},               // Zimbabwe
+}
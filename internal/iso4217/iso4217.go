@@ -319,3 +319,171 @@ var currencyCodes = map[string]bool{
 	"XRE": true, // RINET Funds Code
 	"XFU": true, // UIC-Franc
 }
+
+var minorUnits = map[string]int{
+	"AFN": 2, // Afghani
+	"EUR": 2, // Euro
+	"ALL": 2, // Lek
+	"DZD": 2, // Algerian Dinar
+	"USD": 2, // US Dollar
+	"AOA": 2, // Kwanza
+	"XCD": 2, // East Caribbean Dollar
+	"ARS": 2, // Argentine Peso
+	"AMD": 2, // Armenian Dram
+	"AWG": 2, // Aruban Florin
+	"AUD": 2, // Australian Dollar
+	"AZN": 2, // Azerbaijanian Manat
+	"BSD": 2, // Bahamian Dollar
+	"BHD": 3, // Bahraini Dinar
+	"BDT": 2, // Taka
+	"BBD": 2, // Barbados Dollar
+	"BYN": 2, // Belarusian Ruble
+	"BZD": 2, // Belize Dollar
+	"XOF": 0, // CFA Franc BCEAO
+	"BMD": 2, // Bermudian Dollar
+	"INR": 2, // Indian Rupee
+	"BTN": 2, // Ngultrum
+	"BOB": 2, // Boliviano
+	"BOV": 2, // Mvdol
+	"BAM": 2, // Convertible Mark
+	"BWP": 2, // Pula
+	"NOK": 2, // Norwegian Krone
+	"BRL": 2, // Brazilian Real
+	"BND": 2, // Brunei Dollar
+	"BGN": 2, // Bulgarian Lev
+	"BIF": 0, // Burundi Franc
+	"CVE": 2, // Cabo Verde Escudo
+	"KHR": 2, // Riel
+	"XAF": 0, // CFA Franc BEAC
+	"CAD": 2, // Canadian Dollar
+	"KYD": 2, // Cayman Islands Dollar
+	"CLP": 0, // Chilean Peso
+	"CLF": 4, // Unidad de Fomento
+	"CNY": 2, // Yuan Renminbi
+	"COP": 2, // Colombian Peso
+	"COU": 2, // Unidad de Valor Real
+	"KMF": 0, // Comoro Franc
+	"CDF": 2, // Congolese Franc
+	"NZD": 2, // New Zealand Dollar
+	"CRC": 2, // Costa Rican Colon
+	"HRK": 2, // Kuna
+	"CUP": 2, // Cuban Peso
+	"CUC": 2, // Peso Convertible
+	"ANG": 2, // Netherlands Antillean Guilder
+	"CZK": 2, // Czech Koruna
+	"DKK": 2, // Danish Krone
+	"DJF": 0, // Djibouti Franc
+	"DOP": 2, // Dominican Peso
+	"EGP": 2, // Egyptian Pound
+	"SVC": 2, // El Salvador Colon
+	"ERN": 2, // Nakfa
+	"ETB": 2, // Ethiopian Birr
+	"FKP": 2, // Falkland Islands Pound
+	"FJD": 2, // Fiji Dollar
+	"XPF": 0, // CFP Franc
+	"GMD": 2, // Dalasi
+	"GEL": 2, // Lari
+	"GHS": 2, // Ghana Cedi
+	"GIP": 2, // Gibraltar Pound
+	"GTQ": 2, // Quetzal
+	"GBP": 2, // Pound Sterling
+	"GNF": 0, // Guinea Franc
+	"GYD": 2, // Guyana Dollar
+	"HTG": 2, // Gourde
+	"HNL": 2, // Lempira
+	"HKD": 2, // Hong Kong Dollar
+	"HUF": 2, // Forint
+	"ISK": 0, // Iceland Krona
+	"IDR": 2, // Rupiah
+	"IRR": 2, // Iranian Rial
+	"IQD": 3, // Iraqi Dinar
+	"ILS": 2, // New Israeli Sheqel
+	"JMD": 2, // Jamaican Dollar
+	"JPY": 0, // Yen
+	"JOD": 3, // Jordanian Dinar
+	"KZT": 2, // Tenge
+	"KES": 2, // Kenyan Shilling
+	"KPW": 2, // North Korean Won
+	"KRW": 0, // Won
+	"KWD": 3, // Kuwaiti Dinar
+	"KGS": 2, // Som
+	"LAK": 2, // Kip
+	"LBP": 2, // Lebanese Pound
+	"LSL": 2, // Loti
+	"ZAR": 2, // Rand
+	"LRD": 2, // Liberian Dollar
+	"LYD": 3, // Libyan Dinar
+	"CHF": 2, // Swiss Franc
+	"MOP": 2, // Pataca
+	"MKD": 2, // Denar
+	"MGA": 2, // Malagasy Ariary
+	"MWK": 2, // Malawi Kwacha
+	"MYR": 2, // Malaysian Ringgit
+	"MVR": 2, // Rufiyaa
+	"MRO": 2, // Ouguiya
+	"MUR": 2, // Mauritius Rupee
+	"MXN": 2, // Mexican Peso
+	"MXV": 2, // Mexican Unidad de Inversion (UDI)
+	"MDL": 2, // Moldovan Leu
+	"MNT": 2, // Tugrik
+	"MAD": 2, // Moroccan Dirham
+	"MZN": 2, // Mozambique Metical
+	"MMK": 2, // Kyat
+	"NAD": 2, // Namibia Dollar
+	"NPR": 2, // Nepalese Rupee
+	"NIO": 2, // Cordoba Oro
+	"NGN": 2, // Naira
+	"OMR": 3, // Rial Omani
+	"PKR": 2, // Pakistan Rupee
+	"PAB": 2, // Balboa
+	"PGK": 2, // Kina
+	"PYG": 0, // Guarani
+	"PEN": 2, // Sol
+	"PHP": 2, // Philippine Peso
+	"PLN": 2, // Zloty
+	"QAR": 2, // Qatari Rial
+	"RON": 2, // Romanian Leu
+	"RUB": 2, // Russian Ruble
+	"RWF": 0, // Rwanda Franc
+	"SHP": 2, // Saint Helena Pound
+	"WST": 2, // Tala
+	"STD": 2, // Dobra
+	"SAR": 2, // Saudi Riyal
+	"RSD": 2, // Serbian Dinar
+	"SCR": 2, // Seychelles Rupee
+	"SLL": 2, // Leone
+	"SGD": 2, // Singapore Dollar
+	"SBD": 2, // Solomon Islands Dollar
+	"SOS": 2, // Somali Shilling
+	"SSP": 2, // South Sudanese Pound
+	"LKR": 2, // Sri Lanka Rupee
+	"SDG": 2, // Sudanese Pound
+	"SRD": 2, // Surinam Dollar
+	"SZL": 2, // Lilangeni
+	"SEK": 2, // Swedish Krona
+	"CHE": 2, // WIR Euro
+	"CHW": 2, // WIR Franc
+	"SYP": 2, // Syrian Pound
+	"TWD": 2, // New Taiwan Dollar
+	"TJS": 2, // Somoni
+	"TZS": 2, // Tanzanian Shilling
+	"THB": 2, // Baht
+	"TOP": 2, // Pa’anga
+	"TTD": 2, // Trinidad and Tobago Dollar
+	"TND": 3, // Tunisian Dinar
+	"TRY": 2, // Turkish Lira
+	"TMT": 2, // Turkmenistan New Manat
+	"UGX": 0, // Uganda Shilling
+	"UAH": 2, // Hryvnia
+	"AED": 2, // UAE Dirham
+	"USN": 2, // US Dollar (Next day)
+	"UYU": 2, // Peso Uruguayo
+	"UYI": 0, // Uruguay Peso en Unidades Indexadas (URUIURUI)
+	"UZS": 2, // Uzbekistan Sum
+	"VUV": 0, // Vatu
+	"VEF": 2, // Bolívar
+	"VND": 0, // Dong
+	"YER": 2, // Yemeni Rial
+	"ZMW": 2, // Zambian Kwacha
+	"ZWL": 2, // Zimbabwe Dollar
+}
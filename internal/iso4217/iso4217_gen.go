@@ -40,6 +40,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"time"
 )
 
@@ -48,10 +49,12 @@ var (
 	outputFilename = filepath.Join("internal", "iso4217", "iso4217.go")
 )
 
-// {"AlphabeticCode": "AFN", "Currency": "Afghani", ... }
+// {"AlphabeticCode": "AFN", "Currency": "Afghani", "MinorUnit": "2", ... }
 type currency struct {
-	Code string `json:"AlphabeticCode"`
-	Name string `json:"Currency"`
+	Code           string `json:"AlphabeticCode"`
+	Name           string `json:"Currency"`
+	MinorUnit      string `json:"MinorUnit"`
+	WithdrawalDate string `json:"WithdrawalDate"`
 }
 
 func main() {
@@ -114,6 +117,27 @@ package iso4217
 	}
 	fmt.Fprintln(&buf, "}")
 
+	// Write minor units (decimal places) of active currencies. Funds, precious metals
+	// and testing codes have no minor unit ("N.A.") and are skipped.
+	ms := make(map[string]bool, 150)
+	fmt.Fprintln(&buf, "")
+	fmt.Fprintln(&buf, "var minorUnits = map[string]int{")
+	for i := range currencies {
+		code, name := currencies[i].Code, currencies[i].Name
+		if code == "" || currencies[i].WithdrawalDate != "" {
+			continue
+		}
+		n, err := strconv.Atoi(currencies[i].MinorUnit)
+		if err != nil {
+			continue
+		}
+		if _, exists := ms[code]; !exists {
+			ms[code] = true // mark as seen
+			fmt.Fprintf(&buf, `"%s": %d, // %s`+"\n", code, n, name)
+		}
+	}
+	fmt.Fprintln(&buf, "}")
+
 	// format source code and write file
 	out, err := format.Source(buf.Bytes())
 	if err != nil {
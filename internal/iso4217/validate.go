@@ -27,3 +27,29 @@ func Valid(code string) bool {
 	_, ok := currencyCodes[strings.ToUpper(code)]
 	return ok
 }
+
+// MinorUnits returns the number of decimal places used by a currency,
+// e.g. 2 for USD, 0 for JPY and 3 for BHD. Codes without a minor unit
+// (funds, precious metals and historic currencies) return false.
+func MinorUnits(code string) (int, bool) {
+	n, ok := minorUnits[strings.ToUpper(code)]
+	return n, ok
+}
+
+// CountryCurrencies returns the currencies which are legal tender in the
+// ISO 3166-1-alpha-2 country. Example: US returns USD and USN
+func CountryCurrencies(country string) []string {
+	return countryCurrencies[strings.ToUpper(country)]
+}
+
+// UsedIn returns successful if the currency is legal tender in the
+// ISO 3166-1-alpha-2 country.
+func UsedIn(code, country string) bool {
+	code = strings.ToUpper(code)
+	for _, c := range CountryCurrencies(country) {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
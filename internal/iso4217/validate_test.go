@@ -38,3 +38,45 @@ func TestValidate(t *testing.T) {
 		t.Errorf("invalid")
 	}
 }
+
+func TestMinorUnits(t *testing.T) {
+	cases := map[string]int{"USD": 2, "jpy": 0, "BHD": 3, "CLF": 4, "EUR": 2}
+	for code, expected := range cases {
+		n, ok := MinorUnits(code)
+		if !ok || n != expected {
+			t.Errorf("%s: got %d (ok=%v)", code, n, ok)
+		}
+	}
+	if _, ok := MinorUnits("XAU"); ok {
+		t.Error("gold has no minor unit")
+	}
+	if _, ok := MinorUnits("QZA"); ok {
+		t.Error("invalid")
+	}
+}
+
+func TestCountryCurrencies(t *testing.T) {
+	if cs := CountryCurrencies("us"); len(cs) == 0 || cs[0] != "USD" {
+		t.Errorf("US: %v", cs)
+	}
+	if !UsedIn("eur", "FR") {
+		t.Error("expected EUR in FR")
+	}
+	if UsedIn("USD", "MX") {
+		t.Error("USD is not legal tender in MX")
+	}
+	if len(CountryCurrencies("AQ")) != 0 {
+		t.Error("AQ has no currency")
+	}
+	// every currency we map to needs a minor unit
+	for country, codes := range countryCurrencies {
+		for _, code := range codes {
+			if !Valid(code) {
+				t.Errorf("%s: invalid currency %s", country, code)
+			}
+			if _, ok := MinorUnits(code); !ok {
+				t.Errorf("%s: %s is missing minor units", country, code)
+			}
+		}
+	}
+}
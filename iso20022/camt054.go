@@ -0,0 +1,228 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// Camt054Namespace is the XML namespace of camt.054.001.02 documents
+const Camt054Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.054.001.02"
+
+// Camt054 is a camt.054.001.02 Bank To Customer Debit Credit Notification document
+type Camt054 struct {
+	XMLName                               xml.Name                              `xml:"urn:iso:std:iso:20022:tech:xsd:camt.054.001.02 Document"`
+	BankToCustomerDebitCreditNotification BankToCustomerDebitCreditNotification `xml:"BkToCstmrDbtCdtNtfctn"`
+}
+
+// BankToCustomerDebitCreditNotification is the BkToCstmrDbtCdtNtfctn message
+type BankToCustomerDebitCreditNotification struct {
+	GroupHeader   StatusGroupHeader `xml:"GrpHdr"`
+	Notifications []Notification    `xml:"Ntfctn"`
+}
+
+// Notification is an Ntfctn of the entries booked to one account
+type Notification struct {
+	ID               string              `xml:"Id"`
+	CreationDateTime string              `xml:"CreDtTm"`
+	Account          NotificationAccount `xml:"Acct"`
+	Entries          []NotificationEntry `xml:"Ntry"`
+}
+
+// NotificationAccount is the account an Ntfctn reports on and the agent servicing it
+type NotificationAccount struct {
+	Identification AccountIdentification          `xml:"Id"`
+	Servicer       *BranchAndFinancialInstitution `xml:"Svcr,omitempty"`
+}
+
+// NotificationEntry is an Ntry booked to an account
+type NotificationEntry struct {
+	Reference            string               `xml:"NtryRef,omitempty"`
+	Amount               ActiveCurrencyAmount `xml:"Amt"`
+	CreditDebitIndicator string               `xml:"CdtDbtInd"`
+	Status               string               `xml:"Sts"`
+	BookingDate          *Date                `xml:"BookgDt,omitempty"`
+	ValueDate            *Date                `xml:"ValDt,omitempty"`
+	BankTransactionCode  BankTransactionCode  `xml:"BkTxCd"`
+	EntryDetails         []EntryDetails       `xml:"NtryDtls,omitempty"`
+}
+
+// Date is a DateAndDateTimeChoice holding an ISODate
+type Date struct {
+	Date string `xml:"Dt"`
+}
+
+// BankTransactionCode is the BkTxCd of an entry
+type BankTransactionCode struct {
+	Proprietary ProprietaryBankTransactionCode `xml:"Prtry"`
+}
+
+// ProprietaryBankTransactionCode is a bank transaction code and its issuer
+type ProprietaryBankTransactionCode struct {
+	Code   string `xml:"Cd"`
+	Issuer string `xml:"Issr,omitempty"`
+}
+
+// EntryDetails is the NtryDtls of an entry
+type EntryDetails struct {
+	TransactionDetails []TransactionDetails `xml:"TxDtls"`
+}
+
+// TransactionDetails is the TxDtls of the transaction behind an entry
+type TransactionDetails struct {
+	References            *TransactionReferences `xml:"Refs,omitempty"`
+	RelatedParties        *RelatedParties        `xml:"RltdPties,omitempty"`
+	RemittanceInformation *RemittanceInformation `xml:"RmtInf,omitempty"`
+}
+
+// TransactionReferences are the Refs of a transaction
+type TransactionReferences struct {
+	EndToEndID    string `xml:"EndToEndId,omitempty"`
+	TransactionID string `xml:"TxId,omitempty"`
+}
+
+// RelatedParties are the RltdPties of a transaction
+type RelatedParties struct {
+	Debtor          *PartyIdentification `xml:"Dbtr,omitempty"`
+	DebtorAccount   *CashAccount         `xml:"DbtrAcct,omitempty"`
+	Creditor        *PartyIdentification `xml:"Cdtr,omitempty"`
+	CreditorAccount *CashAccount         `xml:"CdtrAcct,omitempty"`
+}
+
+const (
+	// Credit is the CdtDbtInd of an entry crediting the account
+	Credit = "CRDT"
+	// Debit is the CdtDbtInd of an entry debiting the account
+	Debit = "DBIT"
+	// StatusBooked is the Sts of a settled entry
+	StatusBooked = "BOOK"
+)
+
+// NewCamt054 notifies each receiver of the forward entries settled to their account.
+// Entries are grouped into one Ntfctn per RDFI and account, booked on the batch's
+// EffectiveEntryDate with the originator as the related party. Returns, notifications
+// of change and zero dollar entries (such as prenotes) are not included.
+func NewCamt054(file *ach.File) (*Camt054, error) {
+	if file == nil {
+		return nil, errors.New("nil File")
+	}
+	created, err := fileCreated(file)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Camt054{}
+	msg := &doc.BankToCustomerDebitCreditNotification
+	msg.GroupHeader.MessageID = messageID(file, created)
+	msg.GroupHeader.CreationDateTime = created.Format(isoDateTimeFormat)
+
+	accounts := make(map[string]int) // index of each account's Ntfctn
+	for i, batch := range file.Batches {
+		if category := batch.Category(); category == ach.CategoryReturn || category == ach.CategoryNOC {
+			continue
+		}
+		bh := batch.GetHeader()
+		booked, err := time.Parse("060102", bh.EffectiveEntryDate)
+		if err != nil {
+			return nil, fmt.Errorf("batch %d: invalid EffectiveEntryDate %q", i, bh.EffectiveEntryDate)
+		}
+		for _, ed := range batch.GetEntries() {
+			if ed.Amount == 0 || ed.Category == ach.CategoryReturn || ed.Category == ach.CategoryNOC {
+				continue
+			}
+			key := ed.RDFIIdentificationField() + strings.TrimSpace(ed.DFIAccountNumber)
+			idx, ok := accounts[key]
+			if !ok {
+				idx = len(msg.Notifications)
+				accounts[key] = idx
+				servicer := agent(ed.RDFIIdentificationField())
+				msg.Notifications = append(msg.Notifications, Notification{
+					ID:               fmt.Sprintf("%s-%d", msg.GroupHeader.MessageID, idx+1),
+					CreationDateTime: msg.GroupHeader.CreationDateTime,
+					Account: NotificationAccount{
+						Identification: AccountIdentification{
+							Other: &GenericIdentification{ID: strings.TrimSpace(ed.DFIAccountNumber)},
+						},
+						Servicer: &servicer,
+					},
+				})
+			}
+			ntfctn := &msg.Notifications[idx]
+			ntfctn.Entries = append(ntfctn.Entries, notificationEntry(bh, ed, booked))
+		}
+	}
+	return doc, nil
+}
+
+// notificationEntry is the Ntry of a forward entry booked to the receiver's account
+func notificationEntry(bh *ach.BatchHeader, ed *ach.EntryDetail, booked time.Time) NotificationEntry {
+	date := &Date{Date: booked.Format(isoDateFormat)}
+	entry := NotificationEntry{
+		Reference:            ed.TraceNumber,
+		Amount:               ActiveCurrencyAmount{Amount: Amount(ed.Amount), Currency: "USD"},
+		CreditDebitIndicator: Credit,
+		Status:               StatusBooked,
+		BookingDate:          date,
+		ValueDate:            date,
+		BankTransactionCode: BankTransactionCode{
+			Proprietary: ProprietaryBankTransactionCode{Code: bh.StandardEntryClassCode, Issuer: "NACHA"},
+		},
+	}
+
+	tx := TransactionDetails{
+		References: &TransactionReferences{
+			EndToEndID:    strings.TrimSpace(ed.IdentificationNumber),
+			TransactionID: ed.TraceNumber,
+		},
+	}
+	originator := &PartyIdentification{
+		Name: strings.TrimSpace(bh.CompanyName),
+		Identification: &PartyChoice{
+			Organisation: &GenericIdentifications{
+				Other: []GenericIdentification{{ID: strings.TrimSpace(bh.CompanyIdentification)}},
+			},
+		},
+	}
+	receiver := &PartyIdentification{Name: strings.TrimSpace(ed.IndividualName)}
+	if ed.CreditOrDebit() == "D" {
+		entry.CreditDebitIndicator = Debit
+		tx.RelatedParties = &RelatedParties{Debtor: receiver, Creditor: originator}
+	} else {
+		tx.RelatedParties = &RelatedParties{Debtor: originator, Creditor: receiver}
+	}
+	for _, addenda05 := range ed.Addenda05 {
+		if tx.RemittanceInformation == nil {
+			tx.RemittanceInformation = &RemittanceInformation{}
+		}
+		tx.RemittanceInformation.Unstructured = append(tx.RemittanceInformation.Unstructured, strings.TrimSpace(addenda05.PaymentRelatedInformation))
+	}
+	entry.EntryDetails = []EntryDetails{{TransactionDetails: []TransactionDetails{tx}}}
+	return entry
+}
+
+// Write encodes the document as indented XML with an XML declaration.
+func (doc *Camt054) Write(w io.Writer) error {
+	return writeXML(w, doc)
+}
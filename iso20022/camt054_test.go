@@ -0,0 +1,109 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewCamt054(t *testing.T) {
+	file := readACHFile(t, "ppd-mixedDebitCredit.ach")
+	doc, err := NewCamt054(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := doc.BankToCustomerDebitCreditNotification
+
+	entries := 0
+	for _, batch := range file.Batches {
+		for _, ed := range batch.GetEntries() {
+			if ed.Amount > 0 {
+				entries++
+			}
+		}
+	}
+	booked := 0
+	for _, ntfctn := range msg.Notifications {
+		booked += len(ntfctn.Entries)
+		if ntfctn.Account.Identification.Other == nil || ntfctn.Account.Servicer == nil {
+			t.Errorf("Acct: %#v", ntfctn.Account)
+		}
+	}
+	if booked != entries {
+		t.Errorf("booked %d of %d entries", booked, entries)
+	}
+
+	bh := file.Batches[0].GetHeader()
+	for i, ed := range file.Batches[0].GetEntries() {
+		var found *NotificationEntry
+		for j := range msg.Notifications {
+			for k := range msg.Notifications[j].Entries {
+				if msg.Notifications[j].Entries[k].Reference == ed.TraceNumber {
+					found = &msg.Notifications[j].Entries[k]
+				}
+			}
+		}
+		if found == nil {
+			t.Fatalf("entry %d not booked", i)
+		}
+		indicator := Credit
+		if ed.CreditOrDebit() == "D" {
+			indicator = Debit
+		}
+		if found.CreditDebitIndicator != indicator || int(found.Amount.Amount) != ed.Amount || found.Status != StatusBooked {
+			t.Errorf("entry %d: %#v", i, found)
+		}
+		if found.BankTransactionCode.Proprietary.Code != bh.StandardEntryClassCode {
+			t.Errorf("entry %d: BkTxCd=%#v", i, found.BankTransactionCode)
+		}
+		parties := found.EntryDetails[0].TransactionDetails[0].RelatedParties
+		receiver := parties.Creditor
+		if indicator == Debit {
+			receiver = parties.Debtor
+		}
+		if receiver.Name != strings.TrimSpace(ed.IndividualName) {
+			t.Errorf("entry %d: RltdPties=%#v", i, parties)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<Document xmlns="`+Camt054Namespace+`">`) {
+		t.Error("missing namespace")
+	}
+	if !strings.Contains(buf.String(), "<CdtDbtInd>DBIT</CdtDbtInd>") || !strings.Contains(buf.String(), "<CdtDbtInd>CRDT</CdtDbtInd>") {
+		t.Error("missing debit or credit")
+	}
+}
+
+func TestNewCamt054__returnsSkipped(t *testing.T) {
+	doc, err := NewCamt054(readACHFile(t, "return-WEB.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(doc.BankToCustomerDebitCreditNotification.Notifications); n != 0 {
+		t.Errorf("got %d notifications", n)
+	}
+	if _, err := NewCamt054(nil); err == nil {
+		t.Error("expected error")
+	}
+}
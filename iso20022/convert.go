@@ -0,0 +1,446 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// Report lists the parts of a message which were not carried over by a conversion,
+// such as elements with no ACH equivalent or values which were truncated.
+type Report struct {
+	Items []ReportItem `json:"items"`
+}
+
+// ReportItem is a single element or value which was not converted
+type ReportItem struct {
+	// Path of the element, relative to the Document (for example CstmrCdtTrfInitn/PmtInf[0]/CdtTrfTxInf[2]/Purp)
+	Path string `json:"path"`
+	// Message describes what was lost
+	Message string `json:"message"`
+}
+
+func (r *Report) add(path, format string, args ...interface{}) {
+	r.Items = append(r.Items, ReportItem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Empty returns true when everything was converted
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Items) == 0
+}
+
+const (
+	isoDateFormat     = "2006-01-02"
+	isoDateTimeFormat = "2006-01-02T15:04:05"
+
+	// notProvided is the ISO 20022 placeholder for an absent EndToEndId
+	notProvided = "NOTPROVIDED"
+	// usaba is the clearing system code of ABA routing numbers
+	usaba = "USABA"
+)
+
+// pain001Mapped are the element paths (without indexes) which are converted onto ACH records.
+// Any other element in a document is listed in the Report.
+var pain001Mapped = map[string]bool{
+	"CstmrCdtTrfInitn":                                                               true,
+	"CstmrCdtTrfInitn/GrpHdr":                                                        true,
+	"CstmrCdtTrfInitn/GrpHdr/MsgId":                                                  true,
+	"CstmrCdtTrfInitn/GrpHdr/CreDtTm":                                                true,
+	"CstmrCdtTrfInitn/GrpHdr/NbOfTxs":                                                true,
+	"CstmrCdtTrfInitn/GrpHdr/CtrlSum":                                                true,
+	"CstmrCdtTrfInitn/GrpHdr/InitgPty":                                               true,
+	"CstmrCdtTrfInitn/GrpHdr/InitgPty/Nm":                                            true,
+	"CstmrCdtTrfInitn/PmtInf":                                                        true,
+	"CstmrCdtTrfInitn/PmtInf/PmtInfId":                                               true,
+	"CstmrCdtTrfInitn/PmtInf/PmtMtd":                                                 true,
+	"CstmrCdtTrfInitn/PmtInf/NbOfTxs":                                                true,
+	"CstmrCdtTrfInitn/PmtInf/CtrlSum":                                                true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf":                                               true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/LclInstrm":                                     true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/LclInstrm/Cd":                                  true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/LclInstrm/Prtry":                               true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/CtgyPurp":                                      true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/CtgyPurp/Cd":                                   true,
+	"CstmrCdtTrfInitn/PmtInf/PmtTpInf/CtgyPurp/Prtry":                                true,
+	"CstmrCdtTrfInitn/PmtInf/ReqdExctnDt":                                            true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr":                                                   true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Nm":                                                true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id":                                                true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/OrgId":                                          true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/OrgId/Othr":                                     true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/OrgId/Othr/Id":                                  true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/PrvtId":                                         true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/PrvtId/Othr":                                    true,
+	"CstmrCdtTrfInitn/PmtInf/Dbtr/Id/PrvtId/Othr/Id":                                 true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt":                                                true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt/FinInstnId":                                     true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt/FinInstnId/ClrSysMmbId":                         true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt/FinInstnId/ClrSysMmbId/ClrSysId":                true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt/FinInstnId/ClrSysMmbId/ClrSysId/Cd":             true,
+	"CstmrCdtTrfInitn/PmtInf/DbtrAgt/FinInstnId/ClrSysMmbId/MmbId":                   true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf":                                            true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/PmtId":                                      true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/PmtId/EndToEndId":                           true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/Amt":                                        true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/Amt/InstdAmt":                               true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt":                                    true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt/FinInstnId":                         true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt/FinInstnId/ClrSysMmbId":             true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt/FinInstnId/ClrSysMmbId/ClrSysId":    true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt/FinInstnId/ClrSysMmbId/ClrSysId/Cd": true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAgt/FinInstnId/ClrSysMmbId/MmbId":       true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/Cdtr":                                       true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/Cdtr/Nm":                                    true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct":                                   true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Id":                                true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Id/Othr":                           true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Id/Othr/Id":                        true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Tp":                                true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Tp/Cd":                             true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/CdtrAcct/Ccy":                               true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/RmtInf":                                     true,
+	"CstmrCdtTrfInitn/PmtInf/CdtTrfTxInf/RmtInf/Ustrd":                               true,
+}
+
+// repeatedElements are indexed in report paths
+var repeatedElements = map[string]bool{
+	"PmtInf":      true,
+	"CdtTrfTxInf": true,
+	"Othr":        true,
+	"Ustrd":       true,
+}
+
+// unmappedElements walks an XML document and returns an item for each element (under
+// the Document root) whose path is not in mapped. Elements nested within an unmapped
+// element are not listed separately.
+func unmappedElements(r io.Reader, mapped map[string]bool) ([]ReportItem, error) {
+	var items []ReportItem
+	var names, path []string // element names, and names with indexes, under Document
+	counts := []map[string]int{make(map[string]int)}
+	skip := 0
+	dec := xml.NewDecoder(r)
+	for depth := 0; ; {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				continue // Document
+			}
+			name := t.Name.Local
+			idx := counts[len(counts)-1][name]
+			counts[len(counts)-1][name]++
+			counts = append(counts, make(map[string]int))
+			names = append(names, name)
+			if repeatedElements[name] {
+				name = fmt.Sprintf("%s[%d]", name, idx)
+			}
+			path = append(path, name)
+			if skip > 0 {
+				skip++
+				continue
+			}
+			if !mapped[strings.Join(names, "/")] {
+				items = append(items, ReportItem{
+					Path:    strings.Join(path, "/"),
+					Message: "element has no ACH equivalent and was ignored",
+				})
+				skip = 1
+			}
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				continue
+			}
+			counts = counts[:len(counts)-1]
+			names = names[:len(names)-1]
+			path = path[:len(path)-1]
+			if skip > 0 {
+				skip--
+			}
+		}
+	}
+}
+
+// ReadPain001 decodes a pain.001.001.03 document. Elements which are not converted onto
+// ACH records are remembered and listed in the Report returned by File.
+func ReadPain001(r io.Reader) (*Pain001, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading pain.001: %v", err)
+	}
+	var doc Pain001
+	if err := xml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("reading pain.001: %v", err)
+	}
+	doc.unmapped, err = unmappedElements(bytes.NewReader(bs), pain001Mapped)
+	if err != nil {
+		return nil, fmt.Errorf("reading pain.001: %v", err)
+	}
+	return &doc, nil
+}
+
+// File converts the credit transfers into an ACH file using fh as its FileHeader.
+// Each PmtInf becomes a CCD or PPD batch and each CdtTrfTxInf an entry, with any
+// unstructured remittance information written as an Addenda05. Create() is called
+// on the file. When fh has no FileCreationDate the message's CreDtTm is used.
+//
+// The Report lists elements which have no ACH equivalent and values which were truncated.
+func (doc *Pain001) File(fh ach.FileHeader) (*ach.File, *Report, error) {
+	report := &Report{Items: append([]ReportItem(nil), doc.unmapped...)}
+	msg := doc.CustomerCreditTransferInitiation
+
+	if fh.FileCreationDate == "" {
+		created, err := time.Parse(isoDateTimeFormat, trimZone(msg.GroupHeader.CreationDateTime))
+		if err != nil {
+			return nil, nil, fmt.Errorf("GrpHdr/CreDtTm: invalid date time %q", msg.GroupHeader.CreationDateTime)
+		}
+		fh.FileCreationDate = created.Format("060102")
+		fh.FileCreationTime = created.Format("1504")
+	}
+
+	file := ach.NewFile().SetHeader(fh)
+	file.ID = msg.GroupHeader.MessageID
+
+	count, sum := 0, 0
+	for i := range msg.PaymentInformation {
+		path := fmt.Sprintf("CstmrCdtTrfInitn/PmtInf[%d]", i)
+		batch, err := msg.PaymentInformation[i].batch(path, report)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		batch.SetID(msg.PaymentInformation[i].PaymentInformationID)
+		file.AddBatch(batch)
+		count += len(batch.GetEntries())
+		sum += batch.GetControl().TotalCreditEntryDollarAmount
+	}
+	if count != msg.GroupHeader.NumberOfTransactions {
+		report.add("CstmrCdtTrfInitn/GrpHdr/NbOfTxs", "NbOfTxs is %d but %d transactions were converted", msg.GroupHeader.NumberOfTransactions, count)
+	}
+	if msg.GroupHeader.ControlSum != nil && int(*msg.GroupHeader.ControlSum) != sum {
+		report.add("CstmrCdtTrfInitn/GrpHdr/CtrlSum", "CtrlSum is %s but converted transactions total %s", amountString(int(*msg.GroupHeader.ControlSum)), amountString(sum))
+	}
+	if err := file.Create(); err != nil {
+		return nil, nil, err
+	}
+	return file, report, nil
+}
+
+// batch converts a PmtInf into an ACH batch
+func (info *PaymentInformation) batch(path string, report *Report) (ach.Batcher, error) {
+	if info.PaymentMethod != "TRF" {
+		return nil, fmt.Errorf("PmtMtd: unsupported payment method %q", info.PaymentMethod)
+	}
+	if len(info.CreditTransferTransactionInformation) == 0 {
+		return nil, errors.New("no CdtTrfTxInf")
+	}
+	executionDate, err := time.Parse(isoDateFormat, info.RequestedExecutionDate)
+	if err != nil {
+		return nil, fmt.Errorf("ReqdExctnDt: invalid date %q", info.RequestedExecutionDate)
+	}
+	odfi, err := routingNumber(&info.DebtorAgent)
+	if err != nil {
+		return nil, fmt.Errorf("DbtrAgt: %w", err)
+	}
+
+	bh := ach.NewBatchHeader()
+	bh.ServiceClassCode = ach.CreditsOnly
+	bh.StandardEntryClassCode = info.standardEntryClassCode(path, report)
+	bh.CompanyName = truncate(report, path+"/Dbtr/Nm", info.Debtor.Name, 16)
+	bh.CompanyIdentification = truncate(report, path+"/Dbtr/Id", info.Debtor.identification(), 10)
+	bh.CompanyEntryDescription = info.companyEntryDescription(path, report)
+	bh.EffectiveEntryDate = executionDate.Format("060102")
+	bh.ODFIIdentification = odfi[:8]
+
+	batch, err := ach.NewBatch(bh)
+	if err != nil {
+		return nil, err
+	}
+	sum := 0
+	for i := range info.CreditTransferTransactionInformation {
+		txPath := fmt.Sprintf("%s/CdtTrfTxInf[%d]", path, i)
+		ed, err := info.CreditTransferTransactionInformation[i].entryDetail(txPath, report)
+		if err != nil {
+			return nil, fmt.Errorf("CdtTrfTxInf[%d]: %w", i, err)
+		}
+		ed.SetTraceNumber(bh.ODFIIdentification, i+1)
+		batch.AddEntry(ed)
+		sum += ed.Amount
+	}
+	if n := len(info.CreditTransferTransactionInformation); info.NumberOfTransactions != 0 && info.NumberOfTransactions != n {
+		report.add(path+"/NbOfTxs", "NbOfTxs is %d but %d transactions were converted", info.NumberOfTransactions, n)
+	}
+	if info.ControlSum != nil && int(*info.ControlSum) != sum {
+		report.add(path+"/CtrlSum", "CtrlSum is %s but converted transactions total %s", amountString(int(*info.ControlSum)), amountString(sum))
+	}
+	if err := batch.Create(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// standardEntryClassCode returns the SEC code of the batch. A proprietary local instrument
+// of CCD or PPD is used as is, otherwise organisations are paid with CCD and private persons with PPD.
+func (info *PaymentInformation) standardEntryClassCode(path string, report *Report) string {
+	if tp := info.PaymentTypeInformation; tp != nil && tp.LocalInstrument != nil {
+		switch code := strings.ToUpper(tp.LocalInstrument.Proprietary); code {
+		case ach.CCD, ach.PPD:
+			return code
+		default:
+			report.add(path+"/PmtTpInf/LclInstrm", "local instrument %q is not supported, SEC code taken from Dbtr", tp.LocalInstrument.Code+code)
+		}
+	}
+	if id := info.Debtor.Identification; id != nil && id.Private != nil {
+		return ach.PPD
+	}
+	return ach.CCD
+}
+
+// categoryPurposes describe common ISO category purpose codes in a CompanyEntryDescription
+var categoryPurposes = map[string]string{
+	"SALA": "PAYROLL",
+	"PENS": "PENSION",
+	"SUPP": "SUPPLIER",
+	"TAXS": "TAX",
+	"DIVI": "DIVIDEND",
+	"INTC": "TRANSFER",
+}
+
+// companyEntryDescription is taken from the category purpose of the payments
+func (info *PaymentInformation) companyEntryDescription(path string, report *Report) string {
+	if tp := info.PaymentTypeInformation; tp != nil && tp.CategoryPurpose != nil {
+		if tp.CategoryPurpose.Proprietary != "" {
+			return truncate(report, path+"/PmtTpInf/CtgyPurp/Prtry", strings.ToUpper(tp.CategoryPurpose.Proprietary), 10)
+		}
+		if desc, ok := categoryPurposes[tp.CategoryPurpose.Code]; ok {
+			return desc
+		}
+		report.add(path+"/PmtTpInf/CtgyPurp/Cd", "category purpose %q has no description, PAYMENT used", tp.CategoryPurpose.Code)
+	}
+	return "PAYMENT"
+}
+
+// identification returns the first Othr identification of the party
+func (p PartyIdentification) identification() string {
+	if p.Identification == nil {
+		return ""
+	}
+	for _, ids := range []*GenericIdentifications{p.Identification.Organisation, p.Identification.Private} {
+		if ids != nil && len(ids.Other) > 0 {
+			return ids.Other[0].ID
+		}
+	}
+	return ""
+}
+
+// entryDetail converts a CdtTrfTxInf into a credit EntryDetail
+func (tx *CreditTransferTransaction) entryDetail(path string, report *Report) (*ach.EntryDetail, error) {
+	amt := tx.Amount.InstructedAmount
+	if amt.Currency != "USD" {
+		return nil, fmt.Errorf("Amt: unsupported currency %q", amt.Currency)
+	}
+	if amt.Amount < 0 {
+		return nil, fmt.Errorf("Amt: %w", ach.ErrNegativeAmount)
+	}
+	if tx.CreditorAgent == nil {
+		return nil, errors.New("CdtrAgt: missing")
+	}
+	rdfi, err := routingNumber(tx.CreditorAgent)
+	if err != nil {
+		return nil, fmt.Errorf("CdtrAgt: %w", err)
+	}
+	if tx.CreditorAccount == nil || tx.CreditorAccount.Identification.Other == nil {
+		return nil, errors.New("CdtrAcct: missing Othr account identification")
+	}
+
+	ed := ach.NewEntryDetail()
+	ed.TransactionCode = ach.CheckingCredit
+	if tp := tx.CreditorAccount.Type; tp != nil && tp.Code == "SVGS" {
+		ed.TransactionCode = ach.SavingsCredit
+	}
+	ed.SetRDFI(rdfi)
+	ed.DFIAccountNumber = truncate(report, path+"/CdtrAcct/Id/Othr/Id", tx.CreditorAccount.Identification.Other.ID, 17)
+	ed.Amount = int(amt.Amount)
+	if id := tx.PaymentID.EndToEndID; id != notProvided {
+		ed.IdentificationNumber = truncate(report, path+"/PmtId/EndToEndId", id, 15)
+	}
+	ed.IndividualName = truncate(report, path+"/Cdtr/Nm", tx.Creditor.Name, 22)
+	ed.Category = ach.CategoryForward
+
+	if tx.RemittanceInformation != nil && len(tx.RemittanceInformation.Unstructured) > 0 {
+		info := strings.Join(tx.RemittanceInformation.Unstructured, " ")
+		addenda05 := ach.NewAddenda05()
+		addenda05.PaymentRelatedInformation = truncate(report, path+"/RmtInf/Ustrd", info, 80)
+		ed.AddAddenda05(addenda05)
+		ed.AddendaRecordIndicator = 1
+	}
+	return ed, nil
+}
+
+// routingNumber returns the 9 digit ABA routing number of an agent
+func routingNumber(agent *BranchAndFinancialInstitution) (string, error) {
+	member := agent.FinancialInstitution.ClearingSystemMemberID
+	if member == nil {
+		return "", errors.New("missing ClrSysMmbId")
+	}
+	if sys := member.ClearingSystem; sys != nil && sys.Code != "" && sys.Code != usaba {
+		return "", fmt.Errorf("unsupported clearing system %q", sys.Code)
+	}
+	if err := ach.CheckRoutingNumber(member.MemberID); err != nil {
+		return "", fmt.Errorf("MmbId: %w", err)
+	}
+	return member.MemberID, nil
+}
+
+// truncate returns s cut to max characters, adding a report item when anything is lost
+func truncate(report *Report, path, s string, max int) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > max {
+		report.add(path, "%q truncated to %d characters", s, max)
+		return string(r[:max])
+	}
+	return s
+}
+
+// trimZone drops any fractional seconds and time zone from an ISODateTime
+func trimZone(s string) string {
+	if len(s) > len(isoDateTimeFormat) {
+		return s[:len(isoDateTimeFormat)]
+	}
+	return s
+}
+
+func amountString(cents int) string {
+	bs, _ := Amount(cents).MarshalText()
+	return string(bs)
+}
@@ -0,0 +1,196 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func mockFileHeader() ach.FileHeader {
+	fh := ach.NewFileHeader()
+	fh.ImmediateDestination = "231380104"
+	fh.ImmediateOrigin = "121042882"
+	fh.ImmediateDestinationName = "Federal Reserve Bank"
+	fh.ImmediateOriginName = "My Bank Name"
+	return fh
+}
+
+func TestPain001__File(t *testing.T) {
+	file, report, err := readPain001(t).File(mockFileHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.ID != "MSG-20191120-001" || file.Header.FileCreationDate != "191120" || file.Header.FileCreationTime != "0930" {
+		t.Errorf("ID=%s FileCreationDate=%s FileCreationTime=%s", file.ID, file.Header.FileCreationDate, file.Header.FileCreationTime)
+	}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Batches) != 2 {
+		t.Fatalf("got %d batches", len(file.Batches))
+	}
+
+	payroll := file.Batches[0]
+	bh := payroll.GetHeader()
+	if bh.StandardEntryClassCode != ach.CCD || bh.CompanyIdentification != "1234567890" || bh.CompanyEntryDescription != "PAYROLL" {
+		t.Errorf("SEC=%s CompanyIdentification=%s CompanyEntryDescription=%s", bh.StandardEntryClassCode, bh.CompanyIdentification, bh.CompanyEntryDescription)
+	}
+	if bh.EffectiveEntryDate != "191122" || bh.ODFIIdentification != "23138010" || bh.ServiceClassCode != ach.CreditsOnly {
+		t.Errorf("EffectiveEntryDate=%s ODFIIdentification=%s ServiceClassCode=%d", bh.EffectiveEntryDate, bh.ODFIIdentification, bh.ServiceClassCode)
+	}
+	if payroll.ID() != "PAYROLL-1120" {
+		t.Errorf("batch ID=%s", payroll.ID())
+	}
+	entries := payroll.GetEntries()
+	if ed := entries[0]; ed.TransactionCode != ach.CheckingCredit || ed.Amount != 100000 || ed.IdentificationNumber != "E2E-0001" ||
+		ed.RDFIIdentification != "12104288" || ed.CheckDigit != "2" || ed.DFIAccountNumber != "12345678" || ed.IndividualName != "Jane Doe" {
+		t.Errorf("entry 0: %#v", ed)
+	}
+	if ed := entries[0]; len(ed.Addenda05) != 1 || ed.Addenda05[0].PaymentRelatedInformation != "November salary" {
+		t.Errorf("entry 0 addenda: %v", ed.Addenda05)
+	}
+	if ed := entries[1]; ed.TransactionCode != ach.SavingsCredit || ed.IdentificationNumber != "" || ed.IndividualName != "Johnathan Quincy Adams" {
+		t.Errorf("entry 1: %#v", ed)
+	}
+
+	refunds := file.Batches[1]
+	if sec := refunds.GetHeader().StandardEntryClassCode; sec != ach.PPD {
+		t.Errorf("SEC=%s", sec)
+	}
+	if ed := refunds.GetEntries()[0]; ed.Amount != 25000 || ed.Addenda05[0].PaymentRelatedInformation != "Order 77 returned goods" {
+		t.Errorf("refund: %#v", ed)
+	}
+
+	expected := []string{
+		"CstmrCdtTrfInitn/PmtInf[0]/PmtTpInf/SvcLvl",
+		"CstmrCdtTrfInitn/PmtInf[0]/DbtrAcct",
+		"CstmrCdtTrfInitn/PmtInf[0]/ChrgBr",
+		"CstmrCdtTrfInitn/PmtInf[0]/CdtTrfTxInf[0]/PmtId/InstrId",
+		"CstmrCdtTrfInitn/PmtInf[0]/CdtTrfTxInf[0]/Cdtr/PstlAdr",
+		"CstmrCdtTrfInitn/PmtInf[1]/DbtrAcct",
+		"CstmrCdtTrfInitn/PmtInf[0]/CdtTrfTxInf[1]/Cdtr/Nm",
+	}
+	if len(report.Items) != len(expected) {
+		t.Fatalf("report: %#v", report.Items)
+	}
+	for i := range expected {
+		if report.Items[i].Path != expected[i] {
+			t.Errorf("item %d: got %s expected %s", i, report.Items[i].Path, expected[i])
+		}
+	}
+}
+
+func TestPain001__FileReport(t *testing.T) {
+	doc := readPain001(t)
+	msg := &doc.CustomerCreditTransferInitiation
+	msg.GroupHeader.NumberOfTransactions = 4
+	msg.PaymentInformation[0].PaymentTypeInformation.LocalInstrument = &Code{Proprietary: "CTX"}
+	msg.PaymentInformation[0].PaymentTypeInformation.CategoryPurpose = &Code{Code: "GOVT"}
+	msg.PaymentInformation[1].PaymentTypeInformation = &PaymentTypeInformation{LocalInstrument: &Code{Proprietary: "ccd"}}
+	tx := &msg.PaymentInformation[1].CreditTransferTransactionInformation[0]
+	tx.PaymentID.EndToEndID = "REFUND-77-ABCDEFGHIJ"
+	tx.RemittanceInformation.Unstructured = []string{strings.Repeat("X", 90)}
+
+	file, report, err := doc.File(mockFileHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sec := file.Batches[0].GetHeader().StandardEntryClassCode; sec != ach.CCD {
+		t.Errorf("SEC=%s", sec)
+	}
+	if desc := file.Batches[0].GetHeader().CompanyEntryDescription; desc != "PAYMENT" {
+		t.Errorf("CompanyEntryDescription=%s", desc)
+	}
+	if sec := file.Batches[1].GetHeader().StandardEntryClassCode; sec != ach.CCD {
+		t.Errorf("SEC=%s", sec)
+	}
+	ed := file.Batches[1].GetEntries()[0]
+	if ed.IdentificationNumber != "REFUND-77-ABCDE" || len(ed.Addenda05[0].PaymentRelatedInformation) != 80 {
+		t.Errorf("entry: %#v", ed)
+	}
+
+	paths := make(map[string]bool)
+	for _, item := range report.Items {
+		paths[item.Path] = true
+	}
+	for _, p := range []string{
+		"CstmrCdtTrfInitn/GrpHdr/NbOfTxs",
+		"CstmrCdtTrfInitn/PmtInf[0]/PmtTpInf/LclInstrm",
+		"CstmrCdtTrfInitn/PmtInf[0]/PmtTpInf/CtgyPurp/Cd",
+		"CstmrCdtTrfInitn/PmtInf[1]/CdtTrfTxInf[0]/PmtId/EndToEndId",
+		"CstmrCdtTrfInitn/PmtInf[1]/CdtTrfTxInf[0]/RmtInf/Ustrd",
+	} {
+		if !paths[p] {
+			t.Errorf("missing report item for %s", p)
+		}
+	}
+}
+
+func TestPain001__FileErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(msg *CustomerCreditTransferInitiation)
+		err    error
+	}{
+		{"PmtMtd", func(msg *CustomerCreditTransferInitiation) { msg.PaymentInformation[0].PaymentMethod = "CHK" }, nil},
+		{"ReqdExctnDt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].RequestedExecutionDate = "22/11/2019"
+		}, nil},
+		{"DbtrAgt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].DebtorAgent.FinancialInstitution.ClearingSystemMemberID = nil
+		}, nil},
+		{"Amt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].CreditTransferTransactionInformation[0].Amount.InstructedAmount.Currency = "EUR"
+		}, nil},
+		{"Amt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].CreditTransferTransactionInformation[0].Amount.InstructedAmount.Amount = -1
+		}, ach.ErrNegativeAmount},
+		{"CdtrAgt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].CreditTransferTransactionInformation[0].CreditorAgent.FinancialInstitution.ClearingSystemMemberID.MemberID = "121042881"
+		}, nil},
+		{"CdtrAgt", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].CreditTransferTransactionInformation[0].CreditorAgent.FinancialInstitution.ClearingSystemMemberID.ClearingSystem.Code = "CACPA"
+		}, nil},
+		{"CdtrAcct", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[0].CreditTransferTransactionInformation[0].CreditorAccount.Identification = AccountIdentification{IBAN: "DE89370400440532013000"}
+		}, nil},
+		{"CdtTrfTxInf", func(msg *CustomerCreditTransferInitiation) {
+			msg.PaymentInformation[1].CreditTransferTransactionInformation = nil
+		}, nil},
+		{"CreDtTm", func(msg *CustomerCreditTransferInitiation) { msg.GroupHeader.CreationDateTime = "yesterday" }, nil},
+	}
+	for _, tc := range cases {
+		doc := readPain001(t)
+		tc.modify(&doc.CustomerCreditTransferInitiation)
+		_, _, err := doc.File(mockFileHeader())
+		if err == nil {
+			t.Errorf("%s: expected error", tc.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.name) {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package iso20022 converts between ACH files and ISO 20022 payment messages.
+//
+// pain.001.001.03 credit transfer initiations are read into an ach.File, with a Report
+// of each element which could not be mapped onto an ACH record. Files can be exported
+// back to pain.001 for archiving.
+//
+// Returns and notifications of change are reported as pain.002 payment status reports
+// and settled forward entries as camt.054 debit/credit notifications.
+//
+// Convert a pain.001 message
+//     doc, err := iso20022.ReadPain001(fd)
+//     if err != nil {
+//         log.Fatalf("problem reading pain.001: %v", err)
+//     }
+//     file, report, err := doc.File(fh)
+//     for _, item := range report.Items {
+//         fmt.Printf("%s: %s\n", item.Path, item.Message)
+//     }
+//
+// Report returns to the originator
+//     status, err := iso20022.NewPain002(file, originalMsgID)
+//     if err != nil {
+//         log.Fatalf("problem exporting returns: %v", err)
+//     }
+//     status.Write(os.Stdout)
+package iso20022
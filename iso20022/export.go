@@ -0,0 +1,205 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// NewPain001 exports the credit entries of an ACH file as a pain.001.001.03 document.
+// Each batch becomes a PmtInf, with its SEC code as the proprietary local instrument,
+// and each credit entry a CdtTrfTxInf. Debits, prenotes and IAT batches have no
+// pain.001 equivalent and are listed in the Report.
+func NewPain001(file *ach.File) (*Pain001, *Report, error) {
+	if file == nil {
+		return nil, nil, errors.New("nil File")
+	}
+	report := &Report{}
+	created, err := fileCreated(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc := &Pain001{}
+	msg := &doc.CustomerCreditTransferInitiation
+	msg.GroupHeader.MessageID = messageID(file, created)
+	msg.GroupHeader.CreationDateTime = created.Format(isoDateTimeFormat)
+	msg.GroupHeader.InitiatingParty.Name = strings.TrimSpace(file.Header.ImmediateOriginName)
+
+	sum := 0
+	for i, batch := range file.Batches {
+		path := fmt.Sprintf("Batches[%d]", i)
+		info, err := paymentInformation(batch, path, report)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if info == nil {
+			continue
+		}
+		msg.PaymentInformation = append(msg.PaymentInformation, *info)
+		msg.GroupHeader.NumberOfTransactions += info.NumberOfTransactions
+		sum += int(*info.ControlSum)
+	}
+	for i := range file.IATBatches {
+		report.add(fmt.Sprintf("IATBatches[%d]", i), "IAT batches are not exported")
+	}
+	ctrlSum := Amount(sum)
+	msg.GroupHeader.ControlSum = &ctrlSum
+	return doc, report, nil
+}
+
+// paymentInformation exports a batch's credits as a PmtInf, or nil when it has none
+func paymentInformation(batch ach.Batcher, path string, report *Report) (*PaymentInformation, error) {
+	bh := batch.GetHeader()
+	if len(bh.EffectiveEntryDate) != 6 {
+		return nil, fmt.Errorf("BatchHeader: invalid EffectiveEntryDate %q", bh.EffectiveEntryDate)
+	}
+	executionDate, err := time.Parse("060102", bh.EffectiveEntryDate)
+	if err != nil {
+		return nil, fmt.Errorf("BatchHeader: invalid EffectiveEntryDate %q", bh.EffectiveEntryDate)
+	}
+
+	info := &PaymentInformation{
+		PaymentInformationID: paymentInformationID(batch),
+		PaymentMethod:        "TRF",
+		PaymentTypeInformation: &PaymentTypeInformation{
+			LocalInstrument: &Code{Proprietary: bh.StandardEntryClassCode},
+		},
+		RequestedExecutionDate: executionDate.Format(isoDateFormat),
+		Debtor:                 PartyIdentification{Name: strings.TrimSpace(bh.CompanyName)},
+		DebtorAccount: CashAccount{
+			Identification: AccountIdentification{Other: &GenericIdentification{ID: notProvided}},
+		},
+		DebtorAgent: agent(bh.ODFIIdentification),
+	}
+	ids := &GenericIdentifications{Other: []GenericIdentification{{ID: strings.TrimSpace(bh.CompanyIdentification)}}}
+	if bh.StandardEntryClassCode == ach.PPD {
+		info.Debtor.Identification = &PartyChoice{Private: ids}
+	} else {
+		info.Debtor.Identification = &PartyChoice{Organisation: ids}
+	}
+	report.add(path+"/Header", "the originator's account is not part of an ACH file, DbtrAcct is %s", notProvided)
+
+	sum := 0
+	for i, ed := range batch.GetEntries() {
+		entryPath := fmt.Sprintf("%s/Entries[%d]", path, i)
+		if ed.CreditOrDebit() != "C" || ed.Amount == 0 {
+			report.add(entryPath, "transaction code %d is not a credit transfer and was not exported", ed.TransactionCode)
+			continue
+		}
+		info.CreditTransferTransactionInformation = append(info.CreditTransferTransactionInformation, creditTransfer(ed))
+		sum += ed.Amount
+	}
+	if len(info.CreditTransferTransactionInformation) == 0 {
+		report.add(path, "batch has no credit transfers and was not exported")
+		return nil, nil
+	}
+	info.NumberOfTransactions = len(info.CreditTransferTransactionInformation)
+	ctrlSum := Amount(sum)
+	info.ControlSum = &ctrlSum
+	return info, nil
+}
+
+// creditTransfer exports a credit entry as a CdtTrfTxInf
+func creditTransfer(ed *ach.EntryDetail) CreditTransferTransaction {
+	tx := CreditTransferTransaction{
+		PaymentID: PaymentID{
+			InstructionID: ed.TraceNumber,
+			EndToEndID:    strings.TrimSpace(ed.IdentificationNumber),
+		},
+		Amount: InstructedAmount{
+			InstructedAmount: ActiveCurrencyAmount{Amount: Amount(ed.Amount), Currency: "USD"},
+		},
+		Creditor: PartyIdentification{Name: strings.TrimSpace(ed.IndividualName)},
+		CreditorAccount: &CashAccount{
+			Identification: AccountIdentification{
+				Other: &GenericIdentification{ID: strings.TrimSpace(ed.DFIAccountNumber)},
+			},
+			Type: &Code{Code: "CACC"},
+		},
+	}
+	if tx.PaymentID.EndToEndID == "" {
+		tx.PaymentID.EndToEndID = notProvided
+	}
+	creditorAgent := agent(ed.RDFIIdentificationField())
+	tx.CreditorAgent = &creditorAgent
+	if ed.TransactionCode == ach.SavingsCredit {
+		tx.CreditorAccount.Type.Code = "SVGS"
+	}
+	for _, addenda05 := range ed.Addenda05 {
+		if tx.RemittanceInformation == nil {
+			tx.RemittanceInformation = &RemittanceInformation{}
+		}
+		tx.RemittanceInformation.Unstructured = append(tx.RemittanceInformation.Unstructured, strings.TrimSpace(addenda05.PaymentRelatedInformation))
+	}
+	return tx
+}
+
+// fileCreated returns when the file was created from its FileHeader
+func fileCreated(file *ach.File) (time.Time, error) {
+	created, err := time.Parse("0601021504", file.Header.FileCreationDateField()+file.Header.FileCreationTimeField())
+	if err != nil {
+		return created, fmt.Errorf("FileHeader: invalid FileCreationDate %q", file.Header.FileCreationDate)
+	}
+	return created, nil
+}
+
+// messageID identifies a message exported from file, using the file's ID when it has one
+func messageID(file *ach.File, created time.Time) string {
+	if file.ID != "" {
+		return file.ID
+	}
+	return strings.TrimSpace(file.Header.ImmediateOrigin) + created.Format("060102") + file.Header.FileIDModifier
+}
+
+// paymentInformationID identifies the PmtInf of a batch, using the batch's ID when it has one
+func paymentInformationID(batch ach.Batcher) string {
+	if id := batch.ID(); id != "" {
+		return id
+	}
+	bh := batch.GetHeader()
+	return fmt.Sprintf("%s-%07d", strings.TrimSpace(bh.CompanyIdentification), bh.BatchNumber)
+}
+
+// agent returns the financial institution of an 8 digit ABA routing number
+func agent(routing string) BranchAndFinancialInstitution {
+	routing += checkDigit(routing)
+	return BranchAndFinancialInstitution{
+		FinancialInstitution: FinancialInstitution{
+			ClearingSystemMemberID: &ClearingSystemMemberID{
+				ClearingSystem: &Code{Code: usaba},
+				MemberID:       routing,
+			},
+		},
+	}
+}
+
+// checkDigit calculates the ninth digit of an 8 digit ABA routing number
+func checkDigit(routing string) string {
+	weights := [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+	sum := 0
+	for i := 0; i < len(routing) && i < 8; i++ {
+		sum += int(routing[i]-'0') * weights[i]
+	}
+	return fmt.Sprintf("%d", (10-sum%10)%10)
+}
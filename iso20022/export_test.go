@@ -0,0 +1,139 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func TestNewPain001(t *testing.T) {
+	file, _, err := readPain001(t).File(mockFileHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, report, err := NewPain001(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := doc.CustomerCreditTransferInitiation
+	if msg.GroupHeader.MessageID != "MSG-20191120-001" || msg.GroupHeader.CreationDateTime != "2019-11-20T09:30:00" {
+		t.Errorf("GrpHdr: %#v", msg.GroupHeader)
+	}
+	if msg.GroupHeader.NumberOfTransactions != 3 || *msg.GroupHeader.ControlSum != 175125 {
+		t.Errorf("NbOfTxs=%d CtrlSum=%d", msg.GroupHeader.NumberOfTransactions, *msg.GroupHeader.ControlSum)
+	}
+	info := msg.PaymentInformation[0]
+	if info.PaymentInformationID != "PAYROLL-1120" || info.RequestedExecutionDate != "2019-11-22" || info.PaymentTypeInformation.LocalInstrument.Proprietary != ach.CCD {
+		t.Errorf("PmtInf: %#v", info)
+	}
+	if info.DebtorAgent.FinancialInstitution.ClearingSystemMemberID.MemberID != "231380104" {
+		t.Errorf("DbtrAgt: %#v", info.DebtorAgent.FinancialInstitution.ClearingSystemMemberID)
+	}
+	if id := msg.PaymentInformation[1].Debtor.Identification; id.Private == nil || id.Private.Other[0].ID != "1234567890" {
+		t.Errorf("Dbtr/Id: %#v", id)
+	}
+	tx := info.CreditTransferTransactionInformation[1]
+	if tx.PaymentID.EndToEndID != notProvided || tx.CreditorAccount.Type.Code != "SVGS" || tx.Amount.InstructedAmount.Amount != 50125 {
+		t.Errorf("CdtTrfTxInf: %#v", tx)
+	}
+	if tx.CreditorAgent.FinancialInstitution.ClearingSystemMemberID.MemberID != "231380104" {
+		t.Errorf("CdtrAgt: %#v", tx.CreditorAgent)
+	}
+	if ustrd := msg.PaymentInformation[1].CreditTransferTransactionInformation[0].RemittanceInformation.Unstructured; len(ustrd) != 1 || ustrd[0] != "Order 77 returned goods" {
+		t.Errorf("Ustrd: %v", ustrd)
+	}
+	// DbtrAcct is not known for each batch
+	if len(report.Items) != 2 {
+		t.Errorf("report: %#v", report.Items)
+	}
+
+	// the export converts back into the same entries
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadPain001(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := read.File(mockFileHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range file.Batches {
+		if file.Batches[i].GetControl().TotalCreditEntryDollarAmount != again.Batches[i].GetControl().TotalCreditEntryDollarAmount {
+			t.Errorf("batch %d: credit totals differ", i)
+		}
+		for j, ed := range file.Batches[i].GetEntries() {
+			if other := again.Batches[i].GetEntries()[j]; ed.String() != other.String() {
+				t.Errorf("batch %d entry %d:\n%s\n%s", i, j, ed.String(), other.String())
+			}
+		}
+	}
+}
+
+func TestNewPain001__skipped(t *testing.T) {
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "ppd-mixedDebitCredit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, report, err := NewPain001(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credits, debits := 0, 0
+	for _, ed := range file.Batches[0].GetEntries() {
+		if ed.CreditOrDebit() == "C" {
+			credits++
+		} else {
+			debits++
+		}
+	}
+	if n := doc.CustomerCreditTransferInitiation.GroupHeader.NumberOfTransactions; n != credits {
+		t.Errorf("NbOfTxs=%d expected %d", n, credits)
+	}
+	if len(report.Items) != 1+debits {
+		t.Errorf("report: %#v", report.Items)
+	}
+
+	if _, _, err := NewPain001(nil); err == nil {
+		t.Error("expected error")
+	}
+	file.Header.FileCreationDate = "bad"
+	if _, _, err := NewPain001(&file); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	for routing, digit := range map[string]string{"23138010": "4", "12104288": "2", "27397579": "7"} {
+		if got := checkDigit(routing); got != digit {
+			t.Errorf("%s: got %s expected %s", routing, got, digit)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pain001Namespace is the XML namespace of pain.001.001.03 documents
+const Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Pain001 is a pain.001.001.03 Customer Credit Transfer Initiation document.
+// Only the elements which map onto ACH records are modeled.
+type Pain001 struct {
+	XMLName                          xml.Name                         `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.03 Document"`
+	CustomerCreditTransferInitiation CustomerCreditTransferInitiation `xml:"CstmrCdtTrfInitn"`
+
+	// unmapped are the elements read which have no ACH equivalent
+	unmapped []ReportItem
+}
+
+// CustomerCreditTransferInitiation is the CstmrCdtTrfInitn message
+type CustomerCreditTransferInitiation struct {
+	GroupHeader        GroupHeader          `xml:"GrpHdr"`
+	PaymentInformation []PaymentInformation `xml:"PmtInf"`
+}
+
+// GroupHeader is the GrpHdr of a message
+type GroupHeader struct {
+	MessageID            string              `xml:"MsgId"`
+	CreationDateTime     string              `xml:"CreDtTm"`
+	NumberOfTransactions int                 `xml:"NbOfTxs"`
+	ControlSum           *Amount             `xml:"CtrlSum,omitempty"`
+	InitiatingParty      PartyIdentification `xml:"InitgPty"`
+}
+
+// PaymentInformation is a PmtInf block, a set of credit transfers from one debtor account
+type PaymentInformation struct {
+	PaymentInformationID                 string                        `xml:"PmtInfId"`
+	PaymentMethod                        string                        `xml:"PmtMtd"`
+	NumberOfTransactions                 int                           `xml:"NbOfTxs,omitempty"`
+	ControlSum                           *Amount                       `xml:"CtrlSum,omitempty"`
+	PaymentTypeInformation               *PaymentTypeInformation       `xml:"PmtTpInf,omitempty"`
+	RequestedExecutionDate               string                        `xml:"ReqdExctnDt"`
+	Debtor                               PartyIdentification           `xml:"Dbtr"`
+	DebtorAccount                        CashAccount                   `xml:"DbtrAcct"`
+	DebtorAgent                          BranchAndFinancialInstitution `xml:"DbtrAgt"`
+	CreditTransferTransactionInformation []CreditTransferTransaction   `xml:"CdtTrfTxInf"`
+}
+
+// PaymentTypeInformation is the PmtTpInf of a payment
+type PaymentTypeInformation struct {
+	ServiceLevel    *Code `xml:"SvcLvl,omitempty"`
+	LocalInstrument *Code `xml:"LclInstrm,omitempty"`
+	CategoryPurpose *Code `xml:"CtgyPurp,omitempty"`
+}
+
+// Code is a choice of an external code (Cd) or a proprietary value (Prtry)
+type Code struct {
+	Code        string `xml:"Cd,omitempty"`
+	Proprietary string `xml:"Prtry,omitempty"`
+}
+
+// PartyIdentification names a party and identifies it as an organisation or a private person
+type PartyIdentification struct {
+	Name           string       `xml:"Nm,omitempty"`
+	Identification *PartyChoice `xml:"Id,omitempty"`
+}
+
+// PartyChoice is the Id of a party, either an organisation (OrgId) or private person (PrvtId)
+type PartyChoice struct {
+	Organisation *GenericIdentifications `xml:"OrgId,omitempty"`
+	Private      *GenericIdentifications `xml:"PrvtId,omitempty"`
+}
+
+// GenericIdentifications holds the Othr identifications of a party
+type GenericIdentifications struct {
+	Other []GenericIdentification `xml:"Othr"`
+}
+
+// GenericIdentification is an Othr identification
+type GenericIdentification struct {
+	ID string `xml:"Id"`
+}
+
+// CashAccount identifies an account by IBAN or another (Othr) identification
+type CashAccount struct {
+	Identification AccountIdentification `xml:"Id"`
+	Type           *Code                 `xml:"Tp,omitempty"`
+	Currency       string                `xml:"Ccy,omitempty"`
+}
+
+// AccountIdentification is the Id of an account
+type AccountIdentification struct {
+	IBAN  string                 `xml:"IBAN,omitempty"`
+	Other *GenericIdentification `xml:"Othr,omitempty"`
+}
+
+// BranchAndFinancialInstitution identifies an agent by BIC or clearing system member id
+type BranchAndFinancialInstitution struct {
+	FinancialInstitution FinancialInstitution `xml:"FinInstnId"`
+}
+
+// FinancialInstitution is the FinInstnId of an agent
+type FinancialInstitution struct {
+	BIC                    string                  `xml:"BIC,omitempty"`
+	ClearingSystemMemberID *ClearingSystemMemberID `xml:"ClrSysMmbId,omitempty"`
+	Name                   string                  `xml:"Nm,omitempty"`
+}
+
+// ClearingSystemMemberID is a routing number within a clearing system (USABA for ACH)
+type ClearingSystemMemberID struct {
+	ClearingSystem *Code  `xml:"ClrSysId,omitempty"`
+	MemberID       string `xml:"MmbId"`
+}
+
+// CreditTransferTransaction is a CdtTrfTxInf, one credit to a creditor account
+type CreditTransferTransaction struct {
+	PaymentID             PaymentID                      `xml:"PmtId"`
+	Amount                InstructedAmount               `xml:"Amt"`
+	CreditorAgent         *BranchAndFinancialInstitution `xml:"CdtrAgt,omitempty"`
+	Creditor              PartyIdentification            `xml:"Cdtr"`
+	CreditorAccount       *CashAccount                   `xml:"CdtrAcct,omitempty"`
+	RemittanceInformation *RemittanceInformation         `xml:"RmtInf,omitempty"`
+}
+
+// PaymentID identifies a transaction to the instructing party (InstrId) and end to end (EndToEndId)
+type PaymentID struct {
+	InstructionID string `xml:"InstrId,omitempty"`
+	EndToEndID    string `xml:"EndToEndId"`
+}
+
+// InstructedAmount is the Amt of a transaction
+type InstructedAmount struct {
+	InstructedAmount ActiveCurrencyAmount `xml:"InstdAmt"`
+}
+
+// ActiveCurrencyAmount is an amount in a currency
+type ActiveCurrencyAmount struct {
+	Amount   Amount `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// RemittanceInformation is the RmtInf of a transaction
+type RemittanceInformation struct {
+	Unstructured []string `xml:"Ustrd"`
+}
+
+// Amount is a decimal amount held in cents
+type Amount int
+
+// MarshalText writes the amount with two decimal places
+func (a Amount) MarshalText() ([]byte, error) {
+	n := int(a)
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return []byte(fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)), nil
+}
+
+// UnmarshalText reads a decimal amount with at most two decimal places
+func (a *Amount) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	whole, frac := s, ""
+	if idx := strings.Index(s, "."); idx >= 0 {
+		whole, frac = s[:idx], s[idx+1:]
+	}
+	if len(frac) > 2 {
+		return fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	dollars, err := strconv.Atoi(whole)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	cents, err := strconv.Atoi(frac)
+	if err != nil || cents < 0 {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	if strings.HasPrefix(whole, "-") {
+		cents = -cents
+	}
+	*a = Amount(dollars*100 + cents)
+	return nil
+}
+
+// Write encodes the document as indented XML with an XML declaration.
+func (doc *Pain001) Write(w io.Writer) error {
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,127 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readPain001(t *testing.T) *Pain001 {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "pain.001.001.03.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	doc, err := ReadPain001(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestAmount(t *testing.T) {
+	cases := []struct {
+		text  string
+		cents int
+	}{
+		{"1000.00", 100000},
+		{"501.25", 50125},
+		{"250", 25000},
+		{"0.5", 50},
+		{" 12.34 ", 1234},
+		{"-1.05", -105},
+	}
+	for _, tc := range cases {
+		var a Amount
+		if err := a.UnmarshalText([]byte(tc.text)); err != nil {
+			t.Fatalf("%q: %v", tc.text, err)
+		}
+		if int(a) != tc.cents {
+			t.Errorf("%q: got %d", tc.text, a)
+		}
+	}
+	for _, s := range []string{"", "1.234", "1,00", "abc", "1.-5"} {
+		var a Amount
+		if err := a.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+	for cents, expected := range map[int]string{100000: "1000.00", 5: "0.05", -105: "-1.05"} {
+		bs, _ := Amount(cents).MarshalText()
+		if string(bs) != expected {
+			t.Errorf("%d: got %s", cents, bs)
+		}
+	}
+}
+
+func TestReadPain001(t *testing.T) {
+	doc := readPain001(t)
+	msg := doc.CustomerCreditTransferInitiation
+	if msg.GroupHeader.MessageID != "MSG-20191120-001" || msg.GroupHeader.NumberOfTransactions != 3 {
+		t.Errorf("GrpHdr: %#v", msg.GroupHeader)
+	}
+	if n := len(msg.PaymentInformation); n != 2 {
+		t.Fatalf("got %d PmtInf", n)
+	}
+	tx := msg.PaymentInformation[0].CreditTransferTransactionInformation[1]
+	if tx.Amount.InstructedAmount.Amount != 50125 || tx.Amount.InstructedAmount.Currency != "USD" {
+		t.Errorf("Amt: %#v", tx.Amount)
+	}
+	if id := msg.PaymentInformation[1].Debtor.identification(); id != "1234567890" {
+		t.Errorf("Dbtr/Id=%s", id)
+	}
+
+	if _, err := ReadPain001(strings.NewReader("<Document>")); err == nil {
+		t.Error("expected error")
+	}
+	// other messages are rejected by namespace
+	if _, err := ReadPain001(strings.NewReader(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.008.001.02"></Document>`)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestPain001__Write(t *testing.T) {
+	doc := readPain001(t)
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("missing XML declaration: %s", buf.String()[:40])
+	}
+	if !strings.Contains(buf.String(), `<Document xmlns="`+Pain001Namespace+`">`) {
+		t.Error("missing namespace")
+	}
+	if !strings.Contains(buf.String(), `<InstdAmt Ccy="USD">501.25</InstdAmt>`) {
+		t.Error("missing amount")
+	}
+
+	read, err := ReadPain001(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.CustomerCreditTransferInitiation.PaymentInformation) != 2 {
+		t.Error("PmtInf not written")
+	}
+}
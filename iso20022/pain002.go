@@ -0,0 +1,280 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+// Pain002Namespace is the XML namespace of pain.002.001.03 documents
+const Pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+
+// Pain002 is a pain.002.001.03 Customer Payment Status Report document
+type Pain002 struct {
+	XMLName                     xml.Name                    `xml:"urn:iso:std:iso:20022:tech:xsd:pain.002.001.03 Document"`
+	CustomerPaymentStatusReport CustomerPaymentStatusReport `xml:"CstmrPmtStsRpt"`
+}
+
+// CustomerPaymentStatusReport is the CstmrPmtStsRpt message
+type CustomerPaymentStatusReport struct {
+	GroupHeader                         StatusGroupHeader            `xml:"GrpHdr"`
+	OriginalGroupInformationAndStatus   OriginalGroupInformation     `xml:"OrgnlGrpInfAndSts"`
+	OriginalPaymentInformationAndStatus []OriginalPaymentInformation `xml:"OrgnlPmtInfAndSts"`
+}
+
+// StatusGroupHeader is the GrpHdr of a status report
+type StatusGroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+// OriginalGroupInformation refers to the message whose status is reported
+type OriginalGroupInformation struct {
+	OriginalMessageID     string `xml:"OrgnlMsgId"`
+	OriginalMessageNameID string `xml:"OrgnlMsgNmId"`
+	GroupStatus           string `xml:"GrpSts,omitempty"`
+}
+
+// OriginalPaymentInformation reports the status of transactions from one original PmtInf
+type OriginalPaymentInformation struct {
+	OriginalPaymentInformationID string                   `xml:"OrgnlPmtInfId"`
+	PaymentInformationStatus     string                   `xml:"PmtInfSts,omitempty"`
+	TransactionInformation       []TransactionInformation `xml:"TxInfAndSts"`
+}
+
+// TransactionInformation is the TxInfAndSts of a single original transaction
+type TransactionInformation struct {
+	StatusID                     string                        `xml:"StsId,omitempty"`
+	OriginalInstructionID        string                        `xml:"OrgnlInstrId,omitempty"`
+	OriginalEndToEndID           string                        `xml:"OrgnlEndToEndId"`
+	TransactionStatus            string                        `xml:"TxSts"`
+	StatusReasonInformation      []StatusReason                `xml:"StsRsnInf,omitempty"`
+	OriginalTransactionReference *OriginalTransactionReference `xml:"OrgnlTxRef,omitempty"`
+}
+
+// StatusReason explains a status with a reason code and additional information
+type StatusReason struct {
+	Reason                *Code    `xml:"Rsn,omitempty"`
+	AdditionalInformation []string `xml:"AddtlInf,omitempty"`
+}
+
+// OriginalTransactionReference holds key elements of the original transaction
+type OriginalTransactionReference struct {
+	Amount          *InstructedAmount    `xml:"Amt,omitempty"`
+	Debtor          *PartyIdentification `xml:"Dbtr,omitempty"`
+	DebtorAccount   *CashAccount         `xml:"DbtrAcct,omitempty"`
+	Creditor        *PartyIdentification `xml:"Cdtr,omitempty"`
+	CreditorAccount *CashAccount         `xml:"CdtrAcct,omitempty"`
+}
+
+const (
+	// StatusRejected is the TxSts of a returned entry
+	StatusRejected = "RJCT"
+	// StatusAcceptedWithChange is the TxSts of an entry with a notification of change
+	StatusAcceptedWithChange = "ACWC"
+	// StatusAccepted is the GrpSts and PmtInfSts when no transactions were rejected
+	StatusAccepted = "ACCP"
+	// StatusPartiallyAccepted is the GrpSts and PmtInfSts when only some transactions were rejected
+	StatusPartiallyAccepted = "PART"
+)
+
+// returnReasons maps NACHA return codes onto ISO 20022 ExternalStatusReason1Code values.
+// Return codes without an equivalent are reported with the NACHA code as a proprietary reason.
+var returnReasons = map[string]string{
+	"R01": "AM04", // Insufficient Funds
+	"R02": "AC04", // Account Closed
+	"R03": "AC01", // No Account/Unable to Locate Account
+	"R04": "AC01", // Invalid Account Number
+	"R05": "MD01", // Improper Debit to Consumer Account
+	"R06": "MS03", // Returned per ODFI's Request
+	"R07": "MD01", // Authorization Revoked by Customer
+	"R08": "MS02", // Payment Stopped
+	"R09": "AM04", // Uncollected Funds
+	"R10": "MD01", // Customer Advises Not Authorized
+	"R12": "RC01", // Branch Sold to Another DFI
+	"R13": "RC01", // RDFI not qualified to participate
+	"R14": "MD07", // Representative payee deceased
+	"R15": "MD07", // Beneficiary or bank account holder deceased
+	"R16": "AC06", // Bank account frozen
+	"R18": "DT01", // Improper effective entry date
+	"R19": "AM09", // Amount field error
+	"R20": "AG01", // Non-payment bank account
+	"R21": "BE05", // Invalid company ID number
+	"R22": "BE01", // Invalid individual ID number
+	"R23": "MS02", // Credit entry refused by receiver
+	"R24": "AM05", // Duplicate entry
+	"R26": "MS03", // Mandatory field error
+	"R28": "RC01", // Transit routing number check digit error
+	"R29": "MD01", // Corporate customer advises not authorized
+	"R34": "RC01", // Limited participation RDFI
+	"R82": "RC01", // Invalid Foreign Receiving DFI Identification
+}
+
+// ReasonCode returns the ISO 20022 status reason for a NACHA return code, or an empty
+// string when there is no equivalent.
+func ReasonCode(returnCode string) string {
+	return returnReasons[strings.ToUpper(returnCode)]
+}
+
+// NewPain002 reports the return entries (File.ReturnEntries) of an ACH file as rejected and
+// its notifications of change (File.NotificationOfChange) as accepted with change. Each
+// transaction refers to its original EndToEndId, read from the entry's IdentificationNumber,
+// and its original InstrId, the trace number of the forward entry. originalMessageID is the
+// MsgId of the pain.001 the entries were originated from and may be empty. The group and
+// each payment information block are RJCT, ACCP or PART depending on how many of their
+// transactions were rejected.
+func NewPain002(file *ach.File, originalMessageID string) (*Pain002, error) {
+	if file == nil {
+		return nil, errors.New("nil File")
+	}
+	created, err := fileCreated(file)
+	if err != nil {
+		return nil, err
+	}
+	if originalMessageID == "" {
+		originalMessageID = notProvided
+	}
+
+	doc := &Pain002{}
+	msg := &doc.CustomerPaymentStatusReport
+	msg.GroupHeader.MessageID = messageID(file, created)
+	msg.GroupHeader.CreationDateTime = created.Format(isoDateTimeFormat)
+	msg.OriginalGroupInformationAndStatus = OriginalGroupInformation{
+		OriginalMessageID:     originalMessageID,
+		OriginalMessageNameID: "pain.001.001.03",
+	}
+
+	var all []TransactionInformation
+
+	batches := append(append([]ach.Batcher(nil), file.ReturnEntries...), file.NotificationOfChange...)
+	for i, batch := range batches {
+		info := OriginalPaymentInformation{
+			OriginalPaymentInformationID: paymentInformationID(batch),
+		}
+		for j, ed := range batch.GetEntries() {
+			tx, err := transactionInformation(ed)
+			if err != nil {
+				return nil, fmt.Errorf("batch %d entry %d: %w", i, j, err)
+			}
+			info.TransactionInformation = append(info.TransactionInformation, tx)
+		}
+		if len(info.TransactionInformation) > 0 {
+			info.PaymentInformationStatus = groupStatus(info.TransactionInformation)
+			msg.OriginalPaymentInformationAndStatus = append(msg.OriginalPaymentInformationAndStatus, info)
+			all = append(all, info.TransactionInformation...)
+		}
+	}
+	msg.OriginalGroupInformationAndStatus.GroupStatus = groupStatus(all)
+	return doc, nil
+}
+
+// groupStatus returns RJCT when every transaction was rejected, ACCP when none were
+// and PART otherwise.
+func groupStatus(txs []TransactionInformation) string {
+	rejected := 0
+	for _, tx := range txs {
+		if tx.TransactionStatus == StatusRejected {
+			rejected++
+		}
+	}
+	switch {
+	case rejected == 0:
+		return StatusAccepted
+	case rejected == len(txs):
+		return StatusRejected
+	default:
+		return StatusPartiallyAccepted
+	}
+}
+
+// transactionInformation reports the status of a return or notification of change entry
+func transactionInformation(ed *ach.EntryDetail) (TransactionInformation, error) {
+	tx := TransactionInformation{
+		StatusID:           ed.TraceNumber,
+		OriginalEndToEndID: strings.TrimSpace(ed.IdentificationNumber),
+		OriginalTransactionReference: &OriginalTransactionReference{
+			Amount: &InstructedAmount{
+				InstructedAmount: ActiveCurrencyAmount{Amount: Amount(ed.Amount), Currency: "USD"},
+			},
+		},
+	}
+	// the receiver is the creditor of a returned credit and the debtor of a returned debit
+	receiver := &PartyIdentification{Name: strings.TrimSpace(ed.IndividualName)}
+	account := &CashAccount{
+		Identification: AccountIdentification{
+			Other: &GenericIdentification{ID: strings.TrimSpace(ed.DFIAccountNumber)},
+		},
+	}
+	if ed.CreditOrDebit() == "D" {
+		tx.OriginalTransactionReference.Debtor, tx.OriginalTransactionReference.DebtorAccount = receiver, account
+	} else {
+		tx.OriginalTransactionReference.Creditor, tx.OriginalTransactionReference.CreditorAccount = receiver, account
+	}
+	if tx.OriginalEndToEndID == "" {
+		tx.OriginalEndToEndID = notProvided
+	}
+
+	switch {
+	case ed.Addenda99 != nil:
+		tx.OriginalInstructionID = ed.Addenda99.OriginalTrace
+		tx.TransactionStatus = StatusRejected
+		reason := StatusReason{Reason: &Code{Code: ReasonCode(ed.Addenda99.ReturnCode)}}
+		if reason.Reason.Code == "" {
+			reason.Reason = &Code{Proprietary: ed.Addenda99.ReturnCode}
+		}
+		if code := ach.LookupReturnCode(ed.Addenda99.ReturnCode); code != nil {
+			reason.AdditionalInformation = []string{additionalInformation(code.Code + " " + code.Reason)}
+		}
+		tx.StatusReasonInformation = []StatusReason{reason}
+	case ed.Addenda98 != nil:
+		tx.OriginalInstructionID = ed.Addenda98.OriginalTrace
+		tx.TransactionStatus = StatusAcceptedWithChange
+		reason := StatusReason{Reason: &Code{Proprietary: ed.Addenda98.ChangeCode}}
+		info := ed.Addenda98.ChangeCode
+		if code := ach.LookupChangeCode(ed.Addenda98.ChangeCode); code != nil {
+			info += " " + code.Reason
+		}
+		reason.AdditionalInformation = []string{
+			additionalInformation(info),
+			additionalInformation("Corrected data: " + strings.TrimSpace(ed.Addenda98.CorrectedData)),
+		}
+		tx.StatusReasonInformation = []StatusReason{reason}
+	default:
+		return tx, fmt.Errorf("%s entry has no Addenda98 or Addenda99", ed.TraceNumber)
+	}
+	return tx, nil
+}
+
+// additionalInformation truncates s to the 105 characters allowed in AddtlInf
+func additionalInformation(s string) string {
+	if r := []rune(s); len(r) > 105 {
+		return string(r[:105])
+	}
+	return s
+}
+
+// Write encodes the document as indented XML with an XML declaration.
+func (doc *Pain002) Write(w io.Writer) error {
+	return writeXML(w, doc)
+}
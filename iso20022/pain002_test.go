@@ -0,0 +1,183 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func readACHFile(t *testing.T, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestReasonCode(t *testing.T) {
+	if code := ReasonCode("R01"); code != "AM04" {
+		t.Errorf("R01: %s", code)
+	}
+	if code := ReasonCode("r02"); code != "AC04" {
+		t.Errorf("r02: %s", code)
+	}
+	if code := ReasonCode("R31"); code != "" {
+		t.Errorf("R31: %s", code)
+	}
+	// every mapped code is a NACHA return code
+	for code := range returnReasons {
+		if ach.LookupReturnCode(code) == nil {
+			t.Errorf("unknown return code %s", code)
+		}
+	}
+}
+
+func TestNewPain002__returns(t *testing.T) {
+	doc, err := NewPain002(readACHFile(t, "return-WEB.ach"), "MSG-20191120-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := doc.CustomerPaymentStatusReport
+	if msg.OriginalGroupInformationAndStatus.OriginalMessageID != "MSG-20191120-001" {
+		t.Errorf("OrgnlGrpInfAndSts: %#v", msg.OriginalGroupInformationAndStatus)
+	}
+	if n := len(msg.OriginalPaymentInformationAndStatus); n != 2 {
+		t.Fatalf("got %d OrgnlPmtInfAndSts", n)
+	}
+	if sts := msg.OriginalGroupInformationAndStatus.GroupStatus; sts != StatusRejected {
+		t.Errorf("GrpSts=%s", sts)
+	}
+	if sts := msg.OriginalPaymentInformationAndStatus[0].PaymentInformationStatus; sts != StatusRejected {
+		t.Errorf("PmtInfSts=%s", sts)
+	}
+
+	tx := msg.OriginalPaymentInformationAndStatus[0].TransactionInformation[0]
+	if tx.OriginalEndToEndID != "MjMxNDAwMjAtOGQ" || tx.OriginalInstructionID != "091400600000001" || tx.TransactionStatus != StatusRejected {
+		t.Errorf("TxInfAndSts: %#v", tx)
+	}
+	if reason := tx.StatusReasonInformation[0]; reason.Reason.Code != "AM04" || reason.AdditionalInformation[0] != "R01 Insufficient Funds" {
+		t.Errorf("StsRsnInf: %#v", reason)
+	}
+	ref := tx.OriginalTransactionReference
+	if ref.Amount.InstructedAmount.Amount != 12354 || ref.Debtor == nil || ref.Debtor.Name != "Paul Jones" || ref.Creditor != nil {
+		t.Errorf("OrgnlTxRef: %#v", ref)
+	}
+
+	tx = msg.OriginalPaymentInformationAndStatus[1].TransactionInformation[0]
+	if reason := tx.StatusReasonInformation[0]; reason.Reason.Code != "AC01" {
+		t.Errorf("StsRsnInf: %#v", reason)
+	}
+	if ref := tx.OriginalTransactionReference; ref.Creditor == nil || ref.CreditorAccount.Identification.Other.ID != "867530999999" {
+		t.Errorf("OrgnlTxRef: %#v", ref)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<Document xmlns="`+Pain002Namespace+`">`) {
+		t.Error("missing namespace")
+	}
+	// elements are written in schema order
+	order := []string{"<OrgnlInstrId>", "<OrgnlEndToEndId>", "<TxSts>", "<StsRsnInf>", "<OrgnlTxRef>"}
+	for i := 1; i < len(order); i++ {
+		if strings.Index(out, order[i-1]) > strings.Index(out, order[i]) {
+			t.Errorf("%s written after %s", order[i-1], order[i])
+		}
+	}
+	var read Pain002
+	if err := xml.Unmarshal(buf.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewPain002__notificationOfChange(t *testing.T) {
+	doc, err := NewPain002(readACHFile(t, "cor-example.ach"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := doc.CustomerPaymentStatusReport
+	if msg.OriginalGroupInformationAndStatus.OriginalMessageID != notProvided {
+		t.Errorf("OrgnlMsgId=%s", msg.OriginalGroupInformationAndStatus.OriginalMessageID)
+	}
+	tx := msg.OriginalPaymentInformationAndStatus[0].TransactionInformation[0]
+	if tx.TransactionStatus != StatusAcceptedWithChange || tx.OriginalInstructionID != "121042880000001" {
+		t.Errorf("TxInfAndSts: %#v", tx)
+	}
+	if sts := msg.OriginalGroupInformationAndStatus.GroupStatus; sts != StatusAccepted {
+		t.Errorf("GrpSts=%s", sts)
+	}
+	reason := tx.StatusReasonInformation[0]
+	if reason.Reason.Proprietary != "C01" || len(reason.AdditionalInformation) != 2 || !strings.HasPrefix(reason.AdditionalInformation[1], "Corrected data: 1918171614") {
+		t.Errorf("StsRsnInf: %#v", reason)
+	}
+}
+
+func TestNewPain002__partial(t *testing.T) {
+	file := readACHFile(t, "return-WEB.ach")
+	file.NotificationOfChange = readACHFile(t, "cor-example.ach").NotificationOfChange
+	doc, err := NewPain002(file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := doc.CustomerPaymentStatusReport
+	if sts := msg.OriginalGroupInformationAndStatus.GroupStatus; sts != StatusPartiallyAccepted {
+		t.Errorf("GrpSts=%s", sts)
+	}
+	infos := msg.OriginalPaymentInformationAndStatus
+	if sts := infos[0].PaymentInformationStatus; sts != StatusRejected {
+		t.Errorf("returns PmtInfSts=%s", sts)
+	}
+	if sts := infos[len(infos)-1].PaymentInformationStatus; sts != StatusAccepted {
+		t.Errorf("notification of change PmtInfSts=%s", sts)
+	}
+}
+
+func TestNewPain002__errors(t *testing.T) {
+	if _, err := NewPain002(nil, ""); err == nil {
+		t.Error("expected error")
+	}
+	file := readACHFile(t, "return-WEB.ach")
+	file.ReturnEntries[0].GetEntries()[0].Addenda99 = nil
+	if _, err := NewPain002(file, ""); err == nil {
+		t.Error("expected error")
+	}
+
+	// forward files have nothing to report
+	doc, err := NewPain002(readACHFile(t, "ppd-debit.ach"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(doc.CustomerPaymentStatusReport.OriginalPaymentInformationAndStatus); n != 0 {
+		t.Errorf("got %d OrgnlPmtInfAndSts", n)
+	}
+}
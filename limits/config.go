@@ -0,0 +1,172 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package limits
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultWindow is the rolling window of daily limits when Config.Window is empty
+const DefaultWindow = 24 * time.Hour
+
+// Config lists the limits of each originator, read from JSON or YAML with ReadConfig.
+type Config struct {
+	// Window is how far back daily limits look, as a duration like "24h". DefaultWindow when empty.
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+	// Default applies to companies without their own CompanyLimits, none are limited when nil
+	Default *CompanyLimits `json:"default,omitempty" yaml:"default,omitempty"`
+	// Companies are the limits of each CompanyIdentification
+	Companies []CompanyLimits `json:"companies" yaml:"companies"`
+
+	window    time.Duration
+	companies map[string]*CompanyLimits
+}
+
+// CompanyLimits are the limits of one originator. Amounts are in cents and zero is unlimited.
+type CompanyLimits struct {
+	CompanyIdentification string `json:"companyIdentification" yaml:"companyIdentification"`
+	// DailyDebitLimit and DailyCreditLimit cap the total of entries within the Window
+	DailyDebitLimit  int `json:"dailyDebitLimit,omitempty" yaml:"dailyDebitLimit,omitempty"`
+	DailyCreditLimit int `json:"dailyCreditLimit,omitempty" yaml:"dailyCreditLimit,omitempty"`
+	// FileDebitLimit caps the total debits of the company in a single file
+	FileDebitLimit int `json:"fileDebitLimit,omitempty" yaml:"fileDebitLimit,omitempty"`
+	// EntryLimit caps the amount of every entry and EntryLimits the entries of a SEC code
+	EntryLimit  int            `json:"entryLimit,omitempty" yaml:"entryLimit,omitempty"`
+	EntryLimits map[string]int `json:"entryLimits,omitempty" yaml:"entryLimits,omitempty"`
+	// AllowedSECCodes and AllowedTransactionCodes allow any code when empty
+	AllowedSECCodes         []string `json:"allowedSECCodes,omitempty" yaml:"allowedSECCodes,omitempty"`
+	AllowedTransactionCodes []int    `json:"allowedTransactionCodes,omitempty" yaml:"allowedTransactionCodes,omitempty"`
+}
+
+// ReadConfig reads the YAML (.yaml or .yml) or JSON file at path and validates it.
+func ReadConfig(path string) (*Config, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(bs, &cfg)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(bs))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing %s: %v", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the Window and every CompanyLimits.
+func (cfg *Config) Validate() error {
+	cfg.window = DefaultWindow
+	if cfg.Window != "" {
+		window, err := time.ParseDuration(cfg.Window)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid window %q", cfg.Window)
+		}
+		cfg.window = window
+	}
+	if cfg.Default != nil {
+		if err := cfg.Default.validate(); err != nil {
+			return fmt.Errorf("default: %v", err)
+		}
+	}
+	cfg.companies = make(map[string]*CompanyLimits)
+	for i := range cfg.Companies {
+		company := &cfg.Companies[i]
+		company.CompanyIdentification = strings.TrimSpace(company.CompanyIdentification)
+		if company.CompanyIdentification == "" {
+			return fmt.Errorf("company %d: missing companyIdentification", i+1)
+		}
+		if _, exists := cfg.companies[company.CompanyIdentification]; exists {
+			return fmt.Errorf("duplicate companyIdentification %s", company.CompanyIdentification)
+		}
+		if err := company.validate(); err != nil {
+			return fmt.Errorf("company %s: %v", company.CompanyIdentification, err)
+		}
+		cfg.companies[company.CompanyIdentification] = company
+	}
+	return nil
+}
+
+func (c *CompanyLimits) validate() error {
+	if c.DailyDebitLimit < 0 || c.DailyCreditLimit < 0 || c.FileDebitLimit < 0 || c.EntryLimit < 0 {
+		return errors.New("negative limit")
+	}
+	for code, limit := range c.EntryLimits {
+		if limit < 0 {
+			return fmt.Errorf("negative entry limit for %s", code)
+		}
+	}
+	return nil
+}
+
+// company returns the limits of companyIdentification, or nil when it isn't limited
+func (cfg *Config) company(companyIdentification string) *CompanyLimits {
+	if c, ok := cfg.companies[strings.TrimSpace(companyIdentification)]; ok {
+		return c
+	}
+	return cfg.Default
+}
+
+// entryLimit returns the largest amount of an entry in a batch of secCode
+func (c *CompanyLimits) entryLimit(secCode string) int {
+	if limit, ok := c.EntryLimits[secCode]; ok {
+		return limit
+	}
+	return c.EntryLimit
+}
+
+func (c *CompanyLimits) allowsSECCode(secCode string) bool {
+	if len(c.AllowedSECCodes) == 0 {
+		return true
+	}
+	for _, code := range c.AllowedSECCodes {
+		if strings.EqualFold(code, secCode) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CompanyLimits) allowsTransactionCode(transactionCode int) bool {
+	if len(c.AllowedTransactionCodes) == 0 {
+		return true
+	}
+	for _, code := range c.AllowedTransactionCodes {
+		if code == transactionCode {
+			return true
+		}
+	}
+	return false
+}
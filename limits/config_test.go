@@ -0,0 +1,114 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package limits
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlPath := writeConfig(t, dir, "limits.yaml", `
+window: 12h
+default:
+  entryLimit: 100
+companies:
+  - companyIdentification: "121042882"
+    dailyDebitLimit: 5000000
+    dailyCreditLimit: 4000000
+    fileDebitLimit: 2500000
+    entryLimit: 100000
+    entryLimits:
+      WEB: 25000
+    allowedSECCodes: [PPD, WEB]
+    allowedTransactionCodes: [22, 27]
+`)
+	jsonPath := writeConfig(t, dir, "limits.json", `{
+  "window": "12h",
+  "default": {"entryLimit": 100},
+  "companies": [{
+    "companyIdentification": "121042882",
+    "dailyDebitLimit": 5000000,
+    "dailyCreditLimit": 4000000,
+    "fileDebitLimit": 2500000,
+    "entryLimit": 100000,
+    "entryLimits": {"WEB": 25000},
+    "allowedSECCodes": ["PPD", "WEB"],
+    "allowedTransactionCodes": [22, 27]
+  }]
+}`)
+	for _, path := range []string{yamlPath, jsonPath} {
+		cfg, err := ReadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.window != 12*time.Hour {
+			t.Errorf("%s: window=%v", path, cfg.window)
+		}
+		c := cfg.company("121042882 ")
+		if c == nil || c.DailyDebitLimit != 5000000 || c.DailyCreditLimit != 4000000 || c.FileDebitLimit != 2500000 {
+			t.Fatalf("%s: unexpected limits: %#v", path, c)
+		}
+		if c.entryLimit("WEB") != 25000 || c.entryLimit("PPD") != 100000 {
+			t.Errorf("%s: unexpected entry limits: %#v", path, c.EntryLimits)
+		}
+		if !c.allowsSECCode("web") || c.allowsSECCode("CCD") || !c.allowsTransactionCode(27) || c.allowsTransactionCode(32) {
+			t.Errorf("%s: unexpected codes: %v %v", path, c.AllowedSECCodes, c.AllowedTransactionCodes)
+		}
+		if c := cfg.company("999999999"); c == nil || c.EntryLimit != 100 || !c.allowsSECCode("CCD") {
+			t.Errorf("%s: unexpected default: %#v", path, c)
+		}
+	}
+
+	cases := map[string]string{
+		"unknown.yaml":   "companies:\n  - companyIdentification: \"1\"\n    dailyLimit: 5\n",
+		"unknown.json":   `{"companies": [{"companyIdentification": "1", "dailyDebitLimt": 5}]}`,
+		"window.json":    `{"window": "daily"}`,
+		"missing.json":   `{"companies": [{"dailyDebitLimit": 5}]}`,
+		"duplicate.json": `{"companies": [{"companyIdentification": "1"}, {"companyIdentification": "1"}]}`,
+		"negative.json":  `{"companies": [{"companyIdentification": "1", "entryLimits": {"PPD": -1}}]}`,
+		"default.json":   `{"default": {"fileDebitLimit": -1}}`,
+		"broken.json":    `{`,
+	}
+	for name, body := range cases {
+		if _, err := ReadConfig(writeConfig(t, dir, name, body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := ReadConfig(filepath.Join(dir, "nope.json")); err == nil {
+		t.Error("expected error")
+	}
+}
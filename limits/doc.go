@@ -0,0 +1,49 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package limits enforces exposure and velocity limits of originators on ACH files and batches.
+//
+// Each CompanyIdentification has daily debit and credit caps over a rolling window, a cap on
+// the debits of a single file, per-entry caps (optionally per SEC code) and the SEC and
+// transaction codes it may use. Limits are read from YAML or JSON.
+//
+//     window: 24h
+//     companies:
+//       - companyIdentification: "121042882"
+//         dailyDebitLimit: 5000000
+//         fileDebitLimit: 2500000
+//         entryLimit: 100000
+//         entryLimits:
+//           WEB: 25000
+//         allowedSECCodes: [PPD, WEB]
+//         allowedTransactionCodes: [22, 27]
+//
+// Daily totals are accumulated from previously created files stored within the window. The
+// time each file was stored is used, as clients can backdate its FileCreationDate.
+//
+//     cfg, err := limits.ReadConfig("limits.yaml")
+//     if err != nil {
+//         log.Fatalf("problem reading limits: %v", err)
+//     }
+//     limiter, err := limits.NewLimiter(cfg, server.LimiterFiles(repo))
+//     if err != nil {
+//         log.Fatal(err)
+//     }
+//     for _, v := range limiter.EvaluateFile(file) {
+//         log.Println(v)
+//     }
+package limits
@@ -0,0 +1,254 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// Names of the limits a Violation can exceed, matching the fields of CompanyLimits
+const (
+	LimitDailyDebit      = "dailyDebitLimit"
+	LimitDailyCredit     = "dailyCreditLimit"
+	LimitFileDebit       = "fileDebitLimit"
+	LimitEntry           = "entryLimit"
+	LimitSECCode         = "allowedSECCodes"
+	LimitTransactionCode = "allowedTransactionCodes"
+)
+
+// Violation is a batch or entry exceeding a limit of its originator
+type Violation struct {
+	CompanyIdentification string `json:"companyIdentification"`
+	// Limit is one of the Limit constants
+	Limit string `json:"limit"`
+	// BatchNumber of the batch, and TraceNumber of an entry which exceeded an entry limit
+	BatchNumber int    `json:"batchNumber,omitempty"`
+	TraceNumber string `json:"traceNumber,omitempty"`
+	// Amount, or code, exceeding the limit and the Max allowed amount
+	Amount  int    `json:"amount,omitempty"`
+	Max     int    `json:"max,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Message
+}
+
+// Files returns the previously created files and when each was stored, see
+// server.LimiterFiles for a server.Repository.
+type Files interface {
+	// FindAllFiles returns the files counted towards daily limits, which excludes files
+	// received from an ODFI as they would count an originator's entries twice.
+	FindAllFiles() []*ach.File
+	// StoredAt returns when the file with id was stored, which unlike its FileCreationDate
+	// and FileCreationTime isn't supplied by clients.
+	StoredAt(id string) (time.Time, error)
+}
+
+// Limiter evaluates files and batches against a Config and the files stored within its
+// Window. A Limiter is safe for concurrent use, but two files evaluated concurrently don't
+// count towards each other's daily limits until they are stored.
+type Limiter struct {
+	cfg   *Config
+	files Files
+	now   func() time.Time
+}
+
+// NewLimiter returns a Limiter of cfg, which must be validated, accumulating daily totals from files.
+func NewLimiter(cfg *Config, files Files) (*Limiter, error) {
+	if cfg == nil || cfg.window == 0 {
+		return nil, errors.New("limits: nil or unvalidated Config")
+	}
+	return &Limiter{cfg: cfg, files: files, now: time.Now}, nil
+}
+
+// totals are the debits and credits of a company
+type totals struct {
+	debits, credits int
+}
+
+// add sums the entries of batches per CompanyIdentification. IAT batches have no
+// CompanyIdentification and aren't limited.
+func add(sums map[string]*totals, batches []ach.Batcher) {
+	for _, batch := range batches {
+		id := strings.TrimSpace(batch.GetHeader().CompanyIdentification)
+		t, ok := sums[id]
+		if !ok {
+			t = &totals{}
+			sums[id] = t
+		}
+		for _, entry := range batch.GetEntries() {
+			switch entry.CreditOrDebit() {
+			case "D":
+				t.debits += entry.Amount
+			case "C":
+				t.credits += entry.Amount
+			}
+		}
+	}
+}
+
+// storedAt returns when file was stored. Files without a stored time are treated as stored
+// now, so they always count.
+func (l *Limiter) storedAt(file *ach.File) time.Time {
+	if t, err := l.files.StoredAt(file.ID); err == nil {
+		return t
+	}
+	return l.now()
+}
+
+// daily returns the totals of files stored within the window, except the file with id
+func (l *Limiter) daily(id string) map[string]*totals {
+	sums := make(map[string]*totals)
+	if l.files == nil {
+		return sums
+	}
+	since := l.now().Add(-l.cfg.window)
+	for _, file := range l.files.FindAllFiles() {
+		if file == nil || (id != "" && file.ID == id) {
+			continue
+		}
+		if l.storedAt(file).After(since) {
+			add(sums, file.Batches)
+		}
+	}
+	return sums
+}
+
+// EvaluateFile returns the limits exceeded by the batches of file. A stored file with the
+// same ID isn't counted towards daily limits.
+func (l *Limiter) EvaluateFile(file *ach.File) []Violation {
+	return l.evaluate(file.ID, nil, file.Batches)
+}
+
+// EvaluateBatch returns the limits exceeded by adding batch to the stored file with fileID.
+func (l *Limiter) EvaluateBatch(fileID string, batch ach.Batcher) []Violation {
+	var existing []ach.Batcher
+	if l.files != nil {
+		for _, file := range l.files.FindAllFiles() {
+			if file != nil && file.ID == fileID {
+				existing = file.Batches
+				break
+			}
+		}
+	}
+	return l.evaluate(fileID, existing, []ach.Batcher{batch})
+}
+
+// evaluate checks batches added to a file of existing batches
+func (l *Limiter) evaluate(fileID string, existing, batches []ach.Batcher) []Violation {
+	var violations []Violation
+	for _, batch := range batches {
+		violations = append(violations, l.evaluateEntries(batch)...)
+	}
+
+	file := make(map[string]*totals)
+	add(file, existing)
+	added := make(map[string]*totals)
+	add(added, batches)
+	daily := l.daily(fileID)
+
+	for _, batch := range batches {
+		id := strings.TrimSpace(batch.GetHeader().CompanyIdentification)
+		c, t := l.cfg.company(id), added[id]
+		if c == nil || t == nil {
+			continue
+		}
+		delete(added, id) // check each company once
+		if ft := file[id]; ft != nil {
+			t.debits += ft.debits
+			t.credits += ft.credits
+		}
+		if c.FileDebitLimit > 0 && t.debits > c.FileDebitLimit {
+			violations = append(violations, exceeded(id, LimitFileDebit, t.debits, c.FileDebitLimit, "file debits"))
+		}
+		if dt := daily[id]; dt != nil {
+			t.debits += dt.debits
+			t.credits += dt.credits
+		}
+		if c.DailyDebitLimit > 0 && t.debits > c.DailyDebitLimit {
+			violations = append(violations, exceeded(id, LimitDailyDebit, t.debits, c.DailyDebitLimit, "daily debits"))
+		}
+		if c.DailyCreditLimit > 0 && t.credits > c.DailyCreditLimit {
+			violations = append(violations, exceeded(id, LimitDailyCredit, t.credits, c.DailyCreditLimit, "daily credits"))
+		}
+	}
+	return violations
+}
+
+func exceeded(companyIdentification, limit string, amount, max int, what string) Violation {
+	return Violation{
+		CompanyIdentification: companyIdentification,
+		Limit:                 limit,
+		Amount:                amount,
+		Max:                   max,
+		Message:               fmt.Sprintf("company %s %s of %d exceed %s of %d", companyIdentification, what, amount, limit, max),
+	}
+}
+
+// evaluateEntries checks the SEC code of batch and the transaction code and amount of each entry
+func (l *Limiter) evaluateEntries(batch ach.Batcher) []Violation {
+	bh := batch.GetHeader()
+	id := strings.TrimSpace(bh.CompanyIdentification)
+	c := l.cfg.company(id)
+	if c == nil {
+		return nil
+	}
+
+	var violations []Violation
+	if !c.allowsSECCode(bh.StandardEntryClassCode) {
+		violations = append(violations, Violation{
+			CompanyIdentification: id,
+			Limit:                 LimitSECCode,
+			BatchNumber:           bh.BatchNumber,
+			Code:                  bh.StandardEntryClassCode,
+			Message:               fmt.Sprintf("company %s batch %d: SEC code %s is not allowed", id, bh.BatchNumber, bh.StandardEntryClassCode),
+		})
+	}
+	entryMax := c.entryLimit(bh.StandardEntryClassCode)
+	for _, entry := range batch.GetEntries() {
+		if !c.allowsTransactionCode(entry.TransactionCode) {
+			violations = append(violations, Violation{
+				CompanyIdentification: id,
+				Limit:                 LimitTransactionCode,
+				BatchNumber:           bh.BatchNumber,
+				TraceNumber:           entry.TraceNumber,
+				Code:                  fmt.Sprintf("%d", entry.TransactionCode),
+				Message:               fmt.Sprintf("company %s entry %s: transaction code %d is not allowed", id, entry.TraceNumber, entry.TransactionCode),
+			})
+		}
+		if entryMax > 0 && entry.Amount > entryMax {
+			violations = append(violations, Violation{
+				CompanyIdentification: id,
+				Limit:                 LimitEntry,
+				BatchNumber:           bh.BatchNumber,
+				TraceNumber:           entry.TraceNumber,
+				Amount:                entry.Amount,
+				Max:                   entryMax,
+				Message:               fmt.Sprintf("company %s entry %s: amount of %d exceeds %s entry limit of %d", id, entry.TraceNumber, entry.Amount, bh.StandardEntryClassCode, entryMax),
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,270 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package limits
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// fileList is an in memory Files
+type fileList struct {
+	files    []*ach.File
+	storedAt map[string]time.Time
+}
+
+func storedFiles(at time.Time, files ...*ach.File) *fileList {
+	list := &fileList{storedAt: make(map[string]time.Time)}
+	for _, file := range files {
+		list.store(file, at)
+	}
+	return list
+}
+
+func (f *fileList) store(file *ach.File, at time.Time) {
+	f.files = append(f.files, file)
+	f.storedAt[file.ID] = at
+}
+
+func (f *fileList) FindAllFiles() []*ach.File { return f.files }
+
+func (f *fileList) StoredAt(id string) (time.Time, error) {
+	if at, ok := f.storedAt[id]; ok {
+		return at, nil
+	}
+	return time.Time{}, errors.New("file not found")
+}
+
+func readFile(t *testing.T, id, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.ID = id
+	return &file
+}
+
+func testLimiter(t *testing.T, cfg *Config, files Files, now *time.Time) *Limiter {
+	t.Helper()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLimiter(cfg, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func limitsOf(violations []Violation) []string {
+	var names []string
+	for _, v := range violations {
+		names = append(names, v.Limit)
+	}
+	return names
+}
+
+func TestLimiter__EvaluateFile(t *testing.T) {
+	cfg := &Config{
+		Companies: []CompanyLimits{{
+			CompanyIdentification: "121042882",
+			DailyDebitLimit:       250000000,
+			DailyCreditLimit:      150000000,
+			FileDebitLimit:        150000000,
+		}},
+	}
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+	l := testLimiter(t, cfg, storedFiles(now, readFile(t, "debit", "ppd-debit.ach")), &now)
+
+	violations := l.EvaluateFile(readFile(t, "mixed", "ppd-mixedDebitCredit.ach"))
+	if got := limitsOf(violations); len(got) != 3 || got[0] != LimitFileDebit || got[1] != LimitDailyDebit || got[2] != LimitDailyCredit {
+		t.Fatalf("got %v", violations)
+	}
+	v := violations[1]
+	if v.CompanyIdentification != "121042882" || v.Amount != 300000000 || v.Max != 250000000 {
+		t.Errorf("unexpected violation: %#v", v)
+	}
+	if v.String() != "company 121042882 daily debits of 300000000 exceed dailyDebitLimit of 250000000" {
+		t.Errorf("got %q", v.String())
+	}
+
+	// a stored copy of the evaluated file isn't counted twice
+	if violations := l.EvaluateFile(readFile(t, "debit", "ppd-debit.ach")); len(violations) != 0 {
+		t.Errorf("got %v", violations)
+	}
+
+	// other companies aren't limited without a default
+	other := readFile(t, "other", "ppd-mixedDebitCredit.ach")
+	other.Batches[0].GetHeader().CompanyIdentification = "999999999"
+	if violations := l.EvaluateFile(other); len(violations) != 0 {
+		t.Errorf("got %v", violations)
+	}
+	cfg.Default = &CompanyLimits{FileDebitLimit: 1}
+	if got := limitsOf(l.EvaluateFile(other)); len(got) != 1 || got[0] != LimitFileDebit {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestLimiter__rollingWindow(t *testing.T) {
+	cfg := &Config{
+		Companies: []CompanyLimits{{
+			CompanyIdentification: "121042882",
+			DailyDebitLimit:       250000000,
+		}},
+	}
+	files := storedFiles(time.Date(2019, 6, 24, 0, 0, 0, 0, time.UTC), readFile(t, "midnight", "ppd-debit.ach"))
+	files.store(readFile(t, "noon", "ppd-debit.ach"), time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC))
+
+	var now time.Time
+	l := testLimiter(t, cfg, files, &now)
+	evaluate := func(at time.Time) []string {
+		now = at
+		return limitsOf(l.EvaluateFile(readFile(t, "new", "ppd-debit.ach")))
+	}
+
+	if got := evaluate(time.Date(2019, 6, 24, 13, 0, 0, 0, time.UTC)); len(got) != 1 || got[0] != LimitDailyDebit {
+		t.Errorf("both stored files count: %v", got)
+	}
+	if got := evaluate(time.Date(2019, 6, 24, 23, 59, 0, 0, time.UTC)); len(got) != 1 {
+		t.Errorf("midnight file is still within the window: %v", got)
+	}
+	if got := evaluate(time.Date(2019, 6, 25, 0, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("midnight file left the window: %v", got)
+	}
+	if got := evaluate(time.Date(2019, 6, 25, 12, 1, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("both files left the window: %v", got)
+	}
+
+	// a shorter window
+	cfg.Window = "6h"
+	cfg.Companies[0].DailyDebitLimit = 150000000
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := evaluate(time.Date(2019, 6, 24, 17, 0, 0, 0, time.UTC)); len(got) != 1 {
+		t.Errorf("noon file is within the window: %v", got)
+	}
+	if got := evaluate(time.Date(2019, 6, 24, 18, 1, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("noon file left the window: %v", got)
+	}
+
+	// backdating the FileCreationDate doesn't move a file out of the window
+	backdated := readFile(t, "backdated", "ppd-debit.ach")
+	backdated.Header.FileCreationDate = "190101"
+	files.store(backdated, time.Date(2019, 6, 24, 18, 0, 0, 0, time.UTC))
+	if got := evaluate(time.Date(2019, 6, 24, 18, 1, 0, 0, time.UTC)); len(got) != 1 {
+		t.Errorf("backdated file counts: %v", got)
+	}
+}
+
+func TestLimiter__EvaluateBatch(t *testing.T) {
+	cfg := &Config{
+		Companies: []CompanyLimits{{
+			CompanyIdentification: "121042882",
+			FileDebitLimit:        250000000,
+		}},
+	}
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+	debit := readFile(t, "debit", "ppd-debit.ach")
+	l := testLimiter(t, cfg, storedFiles(now, debit, readFile(t, "other", "ppd-debit.ach")), &now)
+
+	batch := readFile(t, "mixed", "ppd-mixedDebitCredit.ach").Batches[0]
+	violations := l.EvaluateBatch("debit", batch)
+	if got := limitsOf(violations); len(got) != 1 || got[0] != LimitFileDebit {
+		t.Fatalf("got %v", violations)
+	}
+	if violations[0].Amount != 300000000 {
+		t.Errorf("unexpected violation: %#v", violations[0])
+	}
+
+	// the daily total counts the file once, along with the other stored file
+	cfg.Companies[0].DailyDebitLimit = 400000000
+	cfg.Companies[0].FileDebitLimit = 0
+	if violations := l.EvaluateBatch("debit", batch); len(violations) != 0 {
+		t.Errorf("got %v", violations)
+	}
+	cfg.Companies[0].DailyDebitLimit = 400000000 - 1
+	if got := limitsOf(l.EvaluateBatch("debit", batch)); len(got) != 1 || got[0] != LimitDailyDebit {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestLimiter__entries(t *testing.T) {
+	cfg := &Config{
+		Companies: []CompanyLimits{{
+			CompanyIdentification:   "121042882",
+			EntryLimit:              150000000,
+			EntryLimits:             map[string]int{"PPD": 50000000},
+			AllowedSECCodes:         []string{"WEB"},
+			AllowedTransactionCodes: []int{27},
+		}},
+	}
+	now := time.Date(2019, 6, 24, 12, 0, 0, 0, time.UTC)
+	l := testLimiter(t, cfg, nil, &now)
+
+	violations := l.EvaluateFile(readFile(t, "mixed", "ppd-mixedDebitCredit.ach"))
+	expected := []string{LimitSECCode, LimitEntry, LimitTransactionCode, LimitEntry, LimitTransactionCode, LimitEntry}
+	got := limitsOf(violations)
+	if len(got) != len(expected) {
+		t.Fatalf("got %v", violations)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("#%d: got %s", i, got[i])
+		}
+	}
+	if v := violations[0]; v.Code != "PPD" || v.BatchNumber != 1 {
+		t.Errorf("unexpected violation: %#v", v)
+	}
+	if v := violations[2]; v.Code != "22" || v.TraceNumber != "121042880000002" {
+		t.Errorf("unexpected violation: %#v", v)
+	}
+	if v := violations[1]; v.Amount != 200000000 || v.Max != 50000000 ||
+		v.String() != "company 121042882 entry 121042880000001: amount of 200000000 exceeds PPD entry limit of 50000000" {
+		t.Errorf("unexpected violation: %#v", v)
+	}
+
+	// the general entry limit applies to other SEC codes
+	delete(cfg.Companies[0].EntryLimits, "PPD")
+	cfg.Companies[0].AllowedSECCodes = nil
+	cfg.Companies[0].AllowedTransactionCodes = nil
+	if got := limitsOf(l.EvaluateFile(readFile(t, "mixed", "ppd-mixedDebitCredit.ach"))); len(got) != 1 || got[0] != LimitEntry {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	if _, err := NewLimiter(nil, nil); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := NewLimiter(&Config{}, nil); err == nil {
+		t.Error("expected error for unvalidated config")
+	}
+}
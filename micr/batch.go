@@ -0,0 +1,206 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package micr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/internal/usabbrev"
+)
+
+// ErrNoAcceptedItems is returned by NewBatch when every item was set aside as an exception
+var ErrNoAcceptedItems = errors.New("no items were accepted into the batch")
+
+// Item is a scanned check.
+type Item struct {
+	// MICR is the E-13B line read from the check
+	MICR string `json:"micr"`
+	// Amount of the check in cents. When zero the amount encoded in the MICR line is used.
+	Amount int `json:"amount,omitempty"`
+	// TransactionCode of the drawer's account. When zero ach.CheckingDebit is used.
+	TransactionCode int `json:"transactionCode,omitempty"`
+	// Name of the drawer, written as the entry's IndividualName (ARC, BOC, POP and RCK)
+	// or receiving company (TRX)
+	Name string `json:"name,omitempty"`
+	// ItemResearchNumber is used by XCK and TRC entries. When empty the check serial number is used.
+	ItemResearchNumber string `json:"itemResearchNumber,omitempty"`
+}
+
+// Location is where checks were collected. POP entries record the terminal city and
+// state, the other check conversion entries don't carry a location.
+type Location struct {
+	// City is abbreviated to 4 characters for POP entries
+	City string `json:"city,omitempty"`
+	// State is the two letter US state or territory
+	State string `json:"state,omitempty"`
+}
+
+// Exception is an item which was not added to the batch.
+type Exception struct {
+	// Index of the item in the slice passed to NewBatch
+	Index int   `json:"index"`
+	Item  Item  `json:"item"`
+	Err   error `json:"-"`
+	// Error is Err as a string for JSON encoding
+	Error string `json:"error"`
+}
+
+// Result is the batch built from a slice of items and the items set aside.
+type Result struct {
+	// Batch holds an entry for each accepted item. It is nil when no items were accepted.
+	Batch      ach.Batcher `json:"batch,omitempty"`
+	Exceptions []Exception `json:"exceptions,omitempty"`
+}
+
+// EntryDetail returns a forward EntryDetail for the item according to sec (ARC, BOC, POP,
+// RCK, XCK, TRC or TRX). The TraceNumber is set by the batch.
+func (item Item) EntryDetail(sec string, loc Location) (*ach.EntryDetail, error) {
+	line, err := Parse(item.MICR)
+	if err != nil {
+		return nil, err
+	}
+	ed := ach.NewEntryDetail()
+	ed.TransactionCode = item.TransactionCode
+	if ed.TransactionCode == 0 {
+		ed.TransactionCode = ach.CheckingDebit
+	}
+	ed.SetRDFI(line.RoutingNumber)
+	ed.DFIAccountNumber = line.AccountNumber
+	ed.Amount = item.Amount
+	if ed.Amount == 0 {
+		ed.Amount = line.Amount
+	}
+	if ed.Amount == 0 {
+		return nil, ach.ErrBatchAmountZero
+	}
+	ed.Category = ach.CategoryForward
+
+	switch sec {
+	case ach.ARC, ach.BOC, ach.RCK:
+		if line.CheckSerialNumber == "" {
+			return nil, ach.ErrBatchCheckSerialNumber
+		}
+		ed.SetCheckSerialNumber(line.CheckSerialNumber)
+		ed.IndividualName = item.Name
+
+	case ach.POP:
+		if line.CheckSerialNumber == "" {
+			return nil, ach.ErrBatchCheckSerialNumber
+		}
+		if len(line.CheckSerialNumber) > 9 {
+			return nil, fmt.Errorf("CheckSerialNumber %s: %w", line.CheckSerialNumber, ach.NewErrValidFieldLength(9))
+		}
+		if !usabbrev.Valid(loc.State) {
+			return nil, fmt.Errorf("TerminalState %s: %w", loc.State, ach.ErrValidState)
+		}
+		ed.SetPOPCheckSerialNumber(line.CheckSerialNumber)
+		ed.SetPOPTerminalCity(loc.City)
+		ed.SetPOPTerminalState(loc.State)
+		ed.IndividualName = item.Name
+
+	case ach.XCK, ach.TRC:
+		research := item.ItemResearchNumber
+		if research == "" {
+			research = line.CheckSerialNumber
+		}
+		control := line.ProcessControl
+		if control == "" {
+			control = line.CheckSerialNumber
+		}
+		ed.SetCheckSerialNumber(line.CheckSerialNumber)
+		ed.SetProcessControlField(control)
+		ed.SetItemResearchNumber(research)
+
+	case ach.TRX:
+		ed.IdentificationNumber = line.CheckSerialNumber
+		ed.SetCATXAddendaRecords(0)
+		ed.SetCATXReceivingCompany(item.Name)
+		ed.SetItemTypeIndicator("01")
+
+	default:
+		return nil, fmt.Errorf("StandardEntryClassCode %s: %w", sec, ach.ErrSECCode)
+	}
+	return ed, nil
+}
+
+// NewBatch builds a check conversion batch from a copy of bh with an entry for each item.
+//
+// Each item is checked on its own by the batch type's validator, so items it would
+// reject (for example ARC and BOC checks over $25,000 or unreadable MICR lines) are
+// returned as Exceptions rather than failing the batch. Create() is called on the batch
+// of accepted items.
+func NewBatch(bh *ach.BatchHeader, items []Item, loc Location) (*Result, error) {
+	if bh == nil {
+		return nil, errors.New("nil BatchHeader")
+	}
+	switch bh.StandardEntryClassCode {
+	case ach.ARC, ach.BOC, ach.POP, ach.RCK, ach.XCK, ach.TRC, ach.TRX:
+	default:
+		return nil, fmt.Errorf("StandardEntryClassCode %s: %w", bh.StandardEntryClassCode, ach.ErrSECCode)
+	}
+
+	result := &Result{}
+	var accepted []*ach.EntryDetail
+	for i, item := range items {
+		ed, err := item.EntryDetail(bh.StandardEntryClassCode, loc)
+		if err == nil {
+			err = check(bh, ed)
+		}
+		if err != nil {
+			result.Exceptions = append(result.Exceptions, Exception{
+				Index: i,
+				Item:  item,
+				Err:   err,
+				Error: err.Error(),
+			})
+			continue
+		}
+		accepted = append(accepted, ed)
+	}
+	if len(accepted) == 0 {
+		return result, ErrNoAcceptedItems
+	}
+
+	header := *bh
+	batch, err := ach.NewBatch(&header)
+	if err != nil {
+		return nil, err
+	}
+	for _, ed := range accepted {
+		batch.AddEntry(ed)
+	}
+	if err := batch.Create(); err != nil {
+		return nil, err
+	}
+	result.Batch = batch
+	return result, nil
+}
+
+// check runs the batch type's validator over a batch holding only ed
+func check(bh *ach.BatchHeader, ed *ach.EntryDetail) error {
+	header := *bh
+	batch, err := ach.NewBatch(&header)
+	if err != nil {
+		return err
+	}
+	trial := *ed
+	batch.AddEntry(&trial)
+	return batch.Create()
+}
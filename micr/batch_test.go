@@ -0,0 +1,130 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package micr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func mockBatchHeader(sec string) *ach.BatchHeader {
+	bh := ach.NewBatchHeader()
+	bh.ServiceClassCode = ach.DebitsOnly
+	bh.StandardEntryClassCode = sec
+	bh.CompanyName = "Payee Name"
+	bh.CompanyIdentification = "121042882"
+	bh.CompanyEntryDescription = "LOCKBOX"
+	if sec == ach.RCK {
+		bh.CompanyEntryDescription = "REDEPCHECK"
+	}
+	bh.ODFIIdentification = "12104288"
+	return bh
+}
+
+func mockItems() []Item {
+	return []Item{
+		{MICR: "⑆231380104⑆ 744567899⑈ 1001", Amount: 25000, Name: "Jane Doe"},
+		{MICR: "⑈001234⑈ ⑆121042882⑆ 7445678⑈ ⑇0000000500⑇", Name: "ABC Company"},
+		// over the ARC, BOC and POP limit of $25,000
+		{MICR: "⑆231380104⑆ 744567899⑈ 1002", Amount: 2500001, Name: "John Doe"},
+		// unreadable
+		{MICR: "⑆231380104 744567899⑈ 1003", Amount: 100},
+	}
+}
+
+func TestNewBatch(t *testing.T) {
+	for _, sec := range []string{ach.ARC, ach.BOC, ach.POP, ach.RCK, ach.XCK, ach.TRC, ach.TRX} {
+		result, err := NewBatch(mockBatchHeader(sec), mockItems(), Location{City: "PHIL", State: "PA"})
+		if err != nil {
+			t.Fatalf("%s: %v", sec, err)
+		}
+		entries := result.Batch.GetEntries()
+
+		// TRC and TRX have no amount limit
+		if sec == ach.TRC || sec == ach.TRX {
+			if len(entries) != 3 || len(result.Exceptions) != 1 {
+				t.Errorf("%s: %d entries %d exceptions", sec, len(entries), len(result.Exceptions))
+			}
+			continue
+		}
+
+		if len(entries) != 2 || len(result.Exceptions) != 2 {
+			t.Fatalf("%s: %d entries %d exceptions", sec, len(entries), len(result.Exceptions))
+		}
+		var amount ach.ErrBatchAmount
+		if ex := result.Exceptions[0]; ex.Index != 2 || !errors.As(ex.Err, &amount) {
+			t.Errorf("%s: unexpected exception: %#v", sec, ex)
+		}
+		if ex := result.Exceptions[1]; ex.Index != 3 || !errors.Is(ex.Err, ErrNoTransit) {
+			t.Errorf("%s: unexpected exception: %#v", sec, ex)
+		}
+		if result.Batch.GetControl().TotalDebitEntryDollarAmount != 25500 {
+			t.Errorf("%s: TotalDebitEntryDollarAmount=%d", sec, result.Batch.GetControl().TotalDebitEntryDollarAmount)
+		}
+
+		ed := entries[1]
+		if ed.RDFIIdentification != "12104288" || ed.DFIAccountNumber != "7445678" || ed.Amount != 500 {
+			t.Errorf("%s: unexpected entry: %v", sec, ed)
+		}
+		switch sec {
+		case ach.POP:
+			if ed.POPCheckSerialNumberField() != "001234" || ed.POPTerminalCityField() != "PHIL" || ed.POPTerminalStateField() != "PA" {
+				t.Errorf("IdentificationNumber=%q", ed.IdentificationNumber)
+			}
+		case ach.XCK:
+			if ed.IdentificationNumber != "001234" || ed.ItemResearchNumber() != "001234" {
+				t.Errorf("IdentificationNumber=%q IndividualName=%q", ed.IdentificationNumber, ed.IndividualName)
+			}
+		default:
+			if ed.IdentificationNumber != "001234" || ed.IndividualName != "ABC Company" {
+				t.Errorf("%s: IdentificationNumber=%q IndividualName=%q", sec, ed.IdentificationNumber, ed.IndividualName)
+			}
+		}
+	}
+}
+
+func TestNewBatch__TRC(t *testing.T) {
+	result, err := NewBatch(mockBatchHeader(ach.TRC), mockItems()[:1], Location{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed := result.Batch.GetEntries()[0]
+	if ed.ProcessControlField() != "1001" || ed.ItemResearchNumber() != "1001" {
+		t.Errorf("ProcessControlField=%q ItemResearchNumber=%q", ed.ProcessControlField(), ed.ItemResearchNumber())
+	}
+}
+
+func TestNewBatch__errors(t *testing.T) {
+	if _, err := NewBatch(nil, mockItems(), Location{}); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := NewBatch(mockBatchHeader(ach.PPD), mockItems(), Location{}); !errors.Is(err, ach.ErrSECCode) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// POP requires a terminal state
+	result, err := NewBatch(mockBatchHeader(ach.POP), mockItems(), Location{City: "PHIL"})
+	if !errors.Is(err, ErrNoAcceptedItems) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if result.Batch != nil || len(result.Exceptions) != 4 || !errors.Is(result.Exceptions[0].Err, ach.ErrValidState) {
+		t.Errorf("unexpected result: %#v", result)
+	}
+}
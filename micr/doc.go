@@ -0,0 +1,33 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package micr reads the E-13B MICR line printed along the bottom of paper checks and
+// builds check conversion (ARC, BOC, POP, RCK, XCK, TRC and TRX) batches from scanned items.
+//
+// Parse a MICR line
+//     line, err := micr.Parse("⑈1001⑈ ⑆231380104⑆ 744567899⑈ ⑇0000025000⑇")
+//     if err != nil {
+//         log.Fatalf("problem reading MICR: %v", err)
+//     }
+//     fmt.Println(line.RoutingNumber, line.AccountNumber, line.CheckSerialNumber)
+//
+// Build a batch from lockbox items, setting aside items the batch would reject
+//     result, err := micr.NewBatch(bh, items, micr.Location{})
+//     for _, ex := range result.Exceptions {
+//         fmt.Printf("item %d: %v\n", ex.Index, ex.Err)
+//     }
+package micr
@@ -0,0 +1,151 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package micr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+// E-13B control symbols. Scanners which can't output the Unicode symbols commonly
+// substitute ASCII letters, which Parse also accepts. Scanners disagree on A and O
+// (some use A for transit) so those letters are rejected rather than guessed.
+const (
+	// Transit surrounds the routing number (ASCII: T or t)
+	Transit = '⑆'
+	// OnUs ends the account number and surrounds the auxiliary on-us field (ASCII: U or u)
+	OnUs = '⑈'
+	// Amount surrounds the encoded amount (ASCII: $)
+	Amount = '⑇'
+	// Dash separates parts of the account number (ASCII: -)
+	Dash = '⑉'
+)
+
+var (
+	// ErrNoTransit is returned when a MICR line does not have a routing number between two transit symbols
+	ErrNoTransit = errors.New("MICR line has no transit field")
+	// ErrNoAccount is returned when a MICR line does not have an on-us account number
+	ErrNoAccount = errors.New("MICR line has no on-us account number")
+	// ErrInvalidCharacter is returned when a MICR line contains characters outside the E-13B set
+	ErrInvalidCharacter = errors.New("MICR line has a character outside the E-13B set")
+)
+
+// Line is a parsed E-13B MICR line.
+type Line struct {
+	// RoutingNumber is the 9 digit routing number of the drawee bank from the transit field
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is the drawer's account from the on-us field
+	AccountNumber string `json:"accountNumber"`
+	// CheckSerialNumber is read from the auxiliary on-us field of business checks or
+	// follows the account number in the on-us field of personal checks.
+	CheckSerialNumber string `json:"checkSerialNumber,omitempty"`
+	// ProcessControl is the on-us field following the account number, often the serial
+	// number of personal checks.
+	ProcessControl string `json:"processControl,omitempty"`
+	// Amount in cents when it has been encoded onto the check, otherwise zero.
+	Amount int `json:"amount,omitempty"`
+}
+
+// normalize maps the ASCII substitutes of E-13B symbols onto the symbols themselves and
+// drops spaces.
+func normalize(s string) (string, error) {
+	var buf strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			buf.WriteRune(r)
+		case r == Transit || r == 'T' || r == 't':
+			buf.WriteRune(Transit)
+		case r == OnUs || r == 'U' || r == 'u':
+			buf.WriteRune(OnUs)
+		case r == Amount || r == '$':
+			buf.WriteRune(Amount)
+		case r == Dash || r == '-':
+			buf.WriteRune('-')
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+		}
+	}
+	return buf.String(), nil
+}
+
+// Parse reads an E-13B MICR line into its routing, account, serial and amount fields.
+// The routing number is verified with ach.CheckRoutingNumber.
+func Parse(s string) (*Line, error) {
+	micr, err := normalize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// transit field
+	start := strings.IndexRune(micr, Transit)
+	if start < 0 {
+		return nil, ErrNoTransit
+	}
+	end := strings.IndexRune(micr[start+len(string(Transit)):], Transit)
+	if end < 0 {
+		return nil, ErrNoTransit
+	}
+	end += start + len(string(Transit))
+	line := &Line{
+		RoutingNumber: strings.Replace(micr[start+len(string(Transit)):end], "-", "", -1),
+	}
+	if err := ach.CheckRoutingNumber(line.RoutingNumber); err != nil {
+		return nil, fmt.Errorf("MICR transit field: %w", err)
+	}
+
+	// auxiliary on-us field, printed left of the transit field on business checks
+	aux := strings.Trim(micr[:start], string(OnUs))
+	line.CheckSerialNumber = strings.Replace(aux, "-", "", -1)
+
+	// on-us and amount fields
+	rest := micr[end+len(string(Transit)):]
+	if idx := strings.IndexRune(rest, Amount); idx >= 0 {
+		amount := strings.Trim(rest[idx:], string(Amount))
+		rest = rest[:idx]
+		if amount != "" {
+			n, err := strconv.Atoi(amount)
+			if err != nil {
+				return nil, fmt.Errorf("MICR amount field %q: %v", amount, err)
+			}
+			line.Amount = n
+		}
+	}
+	var parts []string
+	for _, p := range strings.Split(rest, string(OnUs)) {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, ErrNoAccount
+	}
+	line.AccountNumber = parts[0]
+	if len(parts) > 1 {
+		line.ProcessControl = strings.Join(parts[1:], "")
+		if line.CheckSerialNumber == "" {
+			line.CheckSerialNumber = line.ProcessControl
+		}
+	}
+	return line, nil
+}
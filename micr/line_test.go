@@ -0,0 +1,75 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package micr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		micr     string
+		expected Line
+	}{
+		// personal check, serial follows the account number
+		{"⑆231380104⑆ 744567899⑈ 1001", Line{RoutingNumber: "231380104", AccountNumber: "744567899", CheckSerialNumber: "1001", ProcessControl: "1001"}},
+		// business check with auxiliary on-us serial and encoded amount
+		{"⑈001234⑈ ⑆121042882⑆ 744⑉5678⑉99⑈ ⑇0000025000⑇", Line{RoutingNumber: "121042882", AccountNumber: "744-5678-99", CheckSerialNumber: "001234", Amount: 25000}},
+		// ASCII substitutes
+		{"U001234U T121042882T 7445678U \t$0002500000$", Line{RoutingNumber: "121042882", AccountNumber: "7445678", CheckSerialNumber: "001234", Amount: 2500000}},
+		{"t231380104t 744567899u 1001", Line{RoutingNumber: "231380104", AccountNumber: "744567899", CheckSerialNumber: "1001", ProcessControl: "1001"}},
+	}
+	for _, tc := range cases {
+		line, err := Parse(tc.micr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.micr, err)
+		}
+		if *line != tc.expected {
+			t.Errorf("%s: got %#v", tc.micr, line)
+		}
+	}
+}
+
+func TestParse__errors(t *testing.T) {
+	cases := []struct {
+		micr string
+		err  error
+	}{
+		{"744567899⑈ 1001", ErrNoTransit},
+		{"⑆231380104 744567899⑈", ErrNoTransit},
+		{"⑆231380104⑆", ErrNoAccount},
+		{"⑆231380104⑆ 7445#67899⑈", ErrInvalidCharacter},
+		// ambiguous scanner substitutes
+		{"A231380104A 744567899U 1001", ErrInvalidCharacter},
+		{"T231380104T 744567899O 1001", ErrInvalidCharacter},
+	}
+	for _, tc := range cases {
+		if _, err := Parse(tc.micr); !errors.Is(err, tc.err) {
+			t.Errorf("%s: unexpected error: %v", tc.micr, err)
+		}
+	}
+
+	// check digit mismatch
+	if _, err := Parse("⑆231380105⑆ 744567899⑈ 1001"); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := Parse("⑆231380104⑆ 744567899⑈ ⑇12⑉3⑇"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,37 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package ofac screens ACH files against the Office of Foreign Assets Control (OFAC)
+// Specially Designated Nationals (SDN) list.
+//
+// https://www.treasury.gov/resource-center/sanctions/SDN-List/Pages/default.aspx
+//
+// The default Screener loads the SDN list as published in CSV form (sdn.csv and add.csv)
+// and fuzzy matches names and addresses using Jaro-Winkler similarity.
+//
+//     screener, err := ofac.OpenSDNScreener("sdn.csv", "add.csv")
+//     if err != nil {
+//         log.Fatalf("problem loading SDN list: %v", err)
+//     }
+//     report, err := ofac.ScreenFile(screener, file)
+//     if err != nil {
+//         log.Fatalf("problem screening ACH file: %v", err)
+//     }
+//     if !report.Empty() {
+//         log.Printf("found %d OFAC hits", len(report.Hits))
+//     }
+package ofac
@@ -0,0 +1,106 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+// jaroWinklerBoostThreshold is the Jaro similarity above which the common
+// prefix bonus is applied.
+const jaroWinklerBoostThreshold = 0.7
+
+// JaroWinkler returns the Jaro-Winkler similarity of s1 and s2 between 0.0 (no
+// similarity) and 1.0 (identical strings).
+//
+// https://en.wikipedia.org/wiki/Jaro%E2%80%93Winkler_distance
+func JaroWinkler(s1, s2 string) float64 {
+	a, b := []rune(s1), []rune(s2)
+	sim := jaro(a, b)
+	if sim <= jaroWinklerBoostThreshold {
+		return sim
+	}
+	prefix := 0
+	for i := 0; i < len(a) && i < len(b) && i < 4; i++ {
+		if a[i] != b[i] {
+			break
+		}
+		prefix++
+	}
+	return sim + float64(prefix)*0.1*(1.0-sim)
+}
+
+// jaro returns the Jaro similarity of a and b
+func jaro(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1.0
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	aMatches := make([]bool, len(a))
+	bMatches := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		lo, hi := max(0, i-window), min(len(b), i+window+1)
+		for j := lo; j < hi; j++ {
+			if bMatches[j] || a[i] != b[j] {
+				continue
+			}
+			aMatches[i], bMatches[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0.0
+	}
+
+	transpositions, k := 0, 0
+	for i := range a {
+		if !aMatches[i] {
+			continue
+		}
+		for !bMatches[k] {
+			k++
+		}
+		if a[i] != b[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3.0
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
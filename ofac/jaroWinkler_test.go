@@ -0,0 +1,48 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	cases := []struct {
+		s1, s2   string
+		expected string
+	}{
+		{"martha", "marhta", "0.9611"},
+		{"dwayne", "duane", "0.8400"},
+		{"dixon", "dicksonx", "0.8133"},
+		{"jones", "johnson", "0.8324"},
+		{"abc", "abc", "1.0000"},
+		{"", "", "1.0000"},
+		{"abc", "", "0.0000"},
+		{"abc", "xyz", "0.0000"},
+	}
+	for _, tc := range cases {
+		if got := fmt.Sprintf("%.4f", JaroWinkler(tc.s1, tc.s2)); got != tc.expected {
+			t.Errorf("JaroWinkler(%q, %q)=%s expected %s", tc.s1, tc.s2, got, tc.expected)
+		}
+		// similarity is symmetric
+		if a, b := JaroWinkler(tc.s1, tc.s2), JaroWinkler(tc.s2, tc.s1); a != b {
+			t.Errorf("JaroWinkler(%q, %q) is not symmetric: %v vs %v", tc.s1, tc.s2, a, b)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+// ScreeningIndicatorHit is the value of IATEntryDetail OFACScreeningIndicator
+// and SecondaryOFACScreeningIndicator when a screened value matched the SDN list.
+const ScreeningIndicatorHit = "1"
+
+// Hit is a name or address in an ACH file which matched the sanctions list
+type Hit struct {
+	// BatchNumber of the batch containing the entry
+	BatchNumber int `json:"batchNumber"`
+	// TraceNumber of the entry
+	TraceNumber string `json:"traceNumber"`
+	// Field which was screened, e.g. IndividualName, Addenda10.Name or Addenda12
+	// for the city and country of an IAT originator
+	Field string `json:"field"`
+	// Value of the screened field
+	Value string `json:"value"`
+	// Matches returned by the Screener
+	Matches []Match `json:"matches"`
+}
+
+// Report lists every Hit found while screening an ACH file
+type Report struct {
+	Hits []Hit `json:"hits"`
+}
+
+// Empty returns true if no screened values matched
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Hits) == 0
+}
+
+// ScreenFile screens the IndividualName of each entry and the party names and addresses
+// of each IAT entry. IAT entries which match have OFACScreeningIndicator set for an
+// originator or receiver hit and SecondaryOFACScreeningIndicator set for a financial
+// institution hit.
+func ScreenFile(s Screener, file *ach.File) (*Report, error) {
+	if s == nil || file == nil {
+		return nil, errors.New("ofac: nil Screener or File")
+	}
+	report := &Report{}
+	for _, batch := range file.Batches {
+		if err := report.screenBatch(s, batch); err != nil {
+			return nil, err
+		}
+	}
+	for i := range file.IATBatches {
+		batch := &file.IATBatches[i]
+		for _, entry := range batch.Entries {
+			if err := report.screenIATEntry(s, batch.Header.BatchNumber, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// ScreenBatch screens the IndividualName of each entry of batch like ScreenFile, e.g. for a
+// batch added to a screened file.
+func ScreenBatch(s Screener, batch ach.Batcher) (*Report, error) {
+	if s == nil || batch == nil || batch.GetHeader() == nil {
+		return nil, errors.New("ofac: nil Screener or Batch")
+	}
+	report := &Report{}
+	if err := report.screenBatch(s, batch); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *Report) screenBatch(s Screener, batch ach.Batcher) error {
+	bh := batch.GetHeader()
+	for _, entry := range batch.GetEntries() {
+		if err := r.screen(s.ScreenName, bh.BatchNumber, entry.TraceNumber, "IndividualName", entry.IndividualName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// screenIATEntry screens the parties of entry and sets its OFAC screening indicators on a hit
+func (r *Report) screenIATEntry(s Screener, batchNumber int, entry *ach.IATEntryDetail) error {
+	type check struct {
+		screen    func(string) ([]Match, error)
+		field     string
+		value     string
+		secondary bool
+	}
+	var checks []check
+	if entry.Addenda10 != nil {
+		checks = append(checks, check{s.ScreenName, "Addenda10.Name", entry.Addenda10.Name, false})
+	}
+	if entry.Addenda11 != nil {
+		checks = append(checks,
+			check{s.ScreenName, "Addenda11.OriginatorName", entry.Addenda11.OriginatorName, false},
+			check{s.ScreenAddress, "Addenda11.OriginatorStreetAddress", entry.Addenda11.OriginatorStreetAddress, false},
+		)
+	}
+	if entry.Addenda12 != nil {
+		checks = append(checks, check{s.ScreenAddress, "Addenda12", iatLocation(entry.Addenda12.OriginatorCityStateProvince, entry.Addenda12.OriginatorCountryPostalCode), false})
+	}
+	if entry.Addenda13 != nil {
+		checks = append(checks, check{s.ScreenName, "Addenda13.ODFIName", entry.Addenda13.ODFIName, true})
+	}
+	if entry.Addenda14 != nil {
+		checks = append(checks, check{s.ScreenName, "Addenda14.RDFIName", entry.Addenda14.RDFIName, true})
+	}
+	if entry.Addenda15 != nil {
+		checks = append(checks, check{s.ScreenAddress, "Addenda15.ReceiverStreetAddress", entry.Addenda15.ReceiverStreetAddress, false})
+	}
+	if entry.Addenda16 != nil {
+		checks = append(checks, check{s.ScreenAddress, "Addenda16", iatLocation(entry.Addenda16.ReceiverCityStateProvince, entry.Addenda16.ReceiverCountryPostalCode), false})
+	}
+	for _, a18 := range entry.Addenda18 {
+		checks = append(checks, check{s.ScreenName, "Addenda18.ForeignCorrespondentBankName", a18.ForeignCorrespondentBankName, true})
+	}
+
+	for _, c := range checks {
+		hits := len(r.Hits)
+		if err := r.screen(c.screen, batchNumber, entry.TraceNumber, c.field, c.value); err != nil {
+			return err
+		}
+		if len(r.Hits) == hits {
+			continue
+		}
+		if c.secondary {
+			entry.SecondaryOFACScreeningIndicator = ScreeningIndicatorHit
+		} else {
+			entry.OFACScreeningIndicator = ScreeningIndicatorHit
+		}
+	}
+	return nil
+}
+
+// iatLocation returns the city and country of the IAT "City*State\" and
+// "Country*PostalCode\" fields, which are screened together.
+func iatLocation(cityStateProvince, countryPostalCode string) string {
+	first := func(s string) string {
+		if idx := strings.IndexAny(s, "*\\"); idx >= 0 {
+			s = s[:idx]
+		}
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(first(cityStateProvince) + " " + first(countryPostalCode))
+}
+
+// screen records a Hit if value matches
+func (r *Report) screen(fn func(string) ([]Match, error), batchNumber int, traceNumber, field, value string) error {
+	matches, err := fn(value)
+	if err != nil {
+		return err
+	}
+	if len(matches) > 0 {
+		r.Hits = append(r.Hits, Hit{
+			BatchNumber: batchNumber,
+			TraceNumber: traceNumber,
+			Field:       field,
+			Value:       value,
+			Matches:     matches,
+		})
+	}
+	return nil
+}
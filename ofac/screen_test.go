@@ -0,0 +1,187 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func readACHFile(t testing.TB, name string) *ach.File {
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestScreenFile__clean(t *testing.T) {
+	s := mockSDNScreener(t)
+	for _, name := range []string{"ppd-debit.ach", "20180716-IAT-A17-A18.ach"} {
+		report, err := ScreenFile(s, readACHFile(t, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Empty() {
+			t.Errorf("%s: unexpected hits: %#v", name, report.Hits)
+		}
+	}
+}
+
+func TestScreenFile__domestic(t *testing.T) {
+	file := readACHFile(t, "ppd-debit.ach")
+	entry := file.Batches[0].GetEntries()[0]
+	entry.IndividualName = "Nayif Hawatmeh"
+
+	report, err := ScreenFile(mockSDNScreener(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Hits) != 1 {
+		t.Fatalf("unexpected hits: %#v", report.Hits)
+	}
+	hit := report.Hits[0]
+	if hit.Field != "IndividualName" || hit.TraceNumber != entry.TraceNumber || hit.BatchNumber != 1 {
+		t.Errorf("unexpected hit: %#v", hit)
+	}
+}
+
+func TestScreenFile__IAT(t *testing.T) {
+	file := readACHFile(t, "20180716-IAT-A17-A18.ach")
+	entry := file.IATBatches[0].Entries[0]
+
+	// receiver address hit
+	entry.Addenda15.ReceiverStreetAddress = "1234 Damascus Road"
+	report, err := ScreenFile(mockSDNScreener(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Hits) != 1 || report.Hits[0].Field != "Addenda15.ReceiverStreetAddress" {
+		t.Fatalf("unexpected hits: %#v", report.Hits)
+	}
+	if entry.OFACScreeningIndicator != ScreeningIndicatorHit || entry.SecondaryOFACScreeningIndicator == ScreeningIndicatorHit {
+		t.Errorf("OFACScreeningIndicator=%q SecondaryOFACScreeningIndicator=%q", entry.OFACScreeningIndicator, entry.SecondaryOFACScreeningIndicator)
+	}
+
+	// correspondent bank hit
+	entry.Addenda18[0].ForeignCorrespondentBankName = "Anglo-Caribbean Co Ltd"
+	report, err = ScreenFile(mockSDNScreener(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Hits) != 2 || report.Hits[1].Field != "Addenda18.ForeignCorrespondentBankName" {
+		t.Fatalf("unexpected hits: %#v", report.Hits)
+	}
+	if entry.SecondaryOFACScreeningIndicator != ScreeningIndicatorHit {
+		t.Errorf("SecondaryOFACScreeningIndicator=%q", entry.SecondaryOFACScreeningIndicator)
+	}
+
+	// indicators are kept when written
+	if err := file.Create(); err != nil {
+		t.Fatal(err)
+	}
+	if v := entry.String()[76:78]; v != "11" {
+		t.Errorf("indicators=%q", v)
+	}
+}
+
+func TestScreenFile__IATLocation(t *testing.T) {
+	file := readACHFile(t, "20180716-IAT-A17-A18.ach")
+	entry := file.IATBatches[0].Entries[0]
+
+	report, err := ScreenFile(mockSDNScreener(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Empty() {
+		t.Fatalf("unexpected hits: %#v", report.Hits)
+	}
+
+	// originator and receiver in a sanctioned city
+	entry.Addenda12.OriginatorCityStateProvince = "Havana*\\"
+	entry.Addenda12.OriginatorCountryPostalCode = "Cuba*10400\\"
+	entry.Addenda16.ReceiverCityStateProvince = "Havana*\\"
+	entry.Addenda16.ReceiverCountryPostalCode = "Cuba*10400\\"
+	report, err = ScreenFile(mockSDNScreener(t), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Hits) != 2 || report.Hits[0].Field != "Addenda12" || report.Hits[1].Field != "Addenda16" {
+		t.Fatalf("unexpected hits: %#v", report.Hits)
+	}
+	if v := report.Hits[0].Value; v != "Havana Cuba" {
+		t.Errorf("Value=%q", v)
+	}
+	if entry.OFACScreeningIndicator != ScreeningIndicatorHit || entry.SecondaryOFACScreeningIndicator == ScreeningIndicatorHit {
+		t.Errorf("OFACScreeningIndicator=%q SecondaryOFACScreeningIndicator=%q", entry.OFACScreeningIndicator, entry.SecondaryOFACScreeningIndicator)
+	}
+}
+
+type errScreener struct{}
+
+func (errScreener) ScreenName(string) ([]Match, error)    { return nil, errors.New("bad") }
+func (errScreener) ScreenAddress(string) ([]Match, error) { return nil, errors.New("bad") }
+
+func TestScreenFile__errors(t *testing.T) {
+	if _, err := ScreenFile(nil, ach.NewFile()); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ScreenFile(errScreener{}, readACHFile(t, "ppd-debit.ach")); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ScreenFile(errScreener{}, readACHFile(t, "20180716-IAT-A17-A18.ach")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestScreenBatch(t *testing.T) {
+	batch := readACHFile(t, "ppd-debit.ach").Batches[0]
+	report, err := ScreenBatch(mockSDNScreener(t), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Empty() {
+		t.Errorf("unexpected hits: %#v", report.Hits)
+	}
+
+	entry := batch.GetEntries()[0]
+	entry.IndividualName = "Nayif Hawatmeh"
+	report, err = ScreenBatch(mockSDNScreener(t), batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Hits) != 1 || report.Hits[0].TraceNumber != entry.TraceNumber {
+		t.Errorf("unexpected hits: %#v", report.Hits)
+	}
+
+	if _, err := ScreenBatch(nil, batch); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ScreenBatch(errScreener{}, batch); err == nil {
+		t.Error("expected error")
+	}
+}
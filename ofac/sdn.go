@@ -0,0 +1,328 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the minimum Jaro-Winkler similarity an SDNScreener
+// reports as a Match when no Threshold is set.
+const DefaultThreshold = 0.90
+
+// sdnNull is the OFAC representation of an empty field
+const sdnNull = "-0-"
+
+// SDN is a Specially Designated National from the OFAC sdn.csv file
+type SDN struct {
+	// EntityID is the unique identifier (ent_num) of the SDN
+	EntityID string `json:"entityID"`
+	// Name of the individual, entity or vessel
+	Name string `json:"name"`
+	// Type is "individual", "vessel", "aircraft" or empty for entities
+	Type string `json:"type"`
+	// Programs are the sanctions programs the SDN is listed under
+	Programs []string `json:"programs"`
+	// Remarks are additional information about the SDN
+	Remarks string `json:"remarks"`
+
+	normalized []string
+}
+
+// Address is an address of a Specially Designated National from the OFAC add.csv file
+type Address struct {
+	// EntityID is the unique identifier (ent_num) of the SDN this address belongs to
+	EntityID string `json:"entityID"`
+	// AddressID is the unique identifier (add_num) of the address
+	AddressID string `json:"addressID"`
+	// Address is the street address
+	Address string `json:"address"`
+	// CityStateProvincePostalCode of the address
+	CityStateProvincePostalCode string `json:"cityStateProvincePostalCode"`
+	// Country of the address
+	Country string `json:"country"`
+
+	normalized string
+}
+
+// Match is an SDN name or address which is similar to a screened value
+type Match struct {
+	// EntityID is the unique identifier of the matched SDN
+	EntityID string `json:"entityID"`
+	// Name of the matched SDN
+	Name string `json:"name"`
+	// Address of the SDN, only set for address matches
+	Address string `json:"address,omitempty"`
+	// Score is the similarity between the screened value and the SDN from 0.0 to 1.0
+	Score float64 `json:"score"`
+}
+
+// Screener checks names and addresses against a sanctions list and returns
+// any matches. Implementations must be safe for concurrent use.
+type Screener interface {
+	// ScreenName returns the sanctioned parties similar to name
+	ScreenName(name string) ([]Match, error)
+	// ScreenAddress returns the sanctioned parties with an address similar to address
+	ScreenAddress(address string) ([]Match, error)
+}
+
+// SDNScreener is the default Screener which fuzzy matches against an in-memory
+// copy of the OFAC SDN list using Jaro-Winkler similarity.
+type SDNScreener struct {
+	// Threshold is the minimum similarity reported as a Match. DefaultThreshold is used when zero.
+	Threshold float64
+
+	sdns      []*SDN
+	byID      map[string]*SDN
+	addresses []*Address
+}
+
+// NewSDNScreener returns an SDNScreener for the given SDNs and their addresses.
+func NewSDNScreener(sdns []*SDN, addresses []*Address) *SDNScreener {
+	s := &SDNScreener{
+		Threshold: DefaultThreshold,
+		sdns:      sdns,
+		byID:      make(map[string]*SDN, len(sdns)),
+		addresses: addresses,
+	}
+	for _, sdn := range sdns {
+		sdn.normalized = normalizeName(sdn.Name, sdn.Type == "individual")
+		s.byID[sdn.EntityID] = sdn
+	}
+	for _, addr := range addresses {
+		addr.normalized = normalize(addr.Address)
+		if addr.normalized == "" {
+			// sanctioned cities and regions are listed without a street address
+			addr.normalized = normalize(addr.CityStateProvincePostalCode + " " + addr.Country)
+		}
+	}
+	return s
+}
+
+// OpenSDNScreener reads the OFAC sdn.csv file at sdnPath and, if addressPath is
+// non-empty, the add.csv file of addresses and returns an SDNScreener.
+func OpenSDNScreener(sdnPath, addressPath string) (*SDNScreener, error) {
+	fd, err := os.Open(sdnPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	sdns, err := ReadSDN(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", sdnPath, err)
+	}
+
+	var addresses []*Address
+	if addressPath != "" {
+		fd, err := os.Open(addressPath)
+		if err != nil {
+			return nil, err
+		}
+		defer fd.Close()
+		addresses, err = ReadAddresses(fd)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", addressPath, err)
+		}
+	}
+	return NewSDNScreener(sdns, addresses), nil
+}
+
+// ReadSDN parses the OFAC sdn.csv format which has no header row and the columns:
+// ent_num, SDN_Name, SDN_Type, Program, Title, Call_Sign, Vess_type, Tonnage, GRT,
+// Vess_flag, Vess_owner, Remarks
+func ReadSDN(r io.Reader) ([]*SDN, error) {
+	records, err := readOFACCSV(r, 12)
+	if err != nil {
+		return nil, err
+	}
+	sdns := make([]*SDN, 0, len(records))
+	for _, rec := range records {
+		sdn := &SDN{
+			EntityID: rec[0],
+			Name:     rec[1],
+			Type:     rec[2],
+			Remarks:  rec[11],
+		}
+		if rec[3] != "" {
+			for _, p := range strings.Split(rec[3], "] [") {
+				sdn.Programs = append(sdn.Programs, strings.Trim(p, "[] "))
+			}
+		}
+		sdns = append(sdns, sdn)
+	}
+	return sdns, nil
+}
+
+// ReadAddresses parses the OFAC add.csv format which has no header row and the columns:
+// ent_num, Add_num, Address, City/State/Province/Postal Code, Country, Add_remarks
+func ReadAddresses(r io.Reader) ([]*Address, error) {
+	records, err := readOFACCSV(r, 6)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]*Address, 0, len(records))
+	for _, rec := range records {
+		addresses = append(addresses, &Address{
+			EntityID:                    rec[0],
+			AddressID:                   rec[1],
+			Address:                     rec[2],
+			CityStateProvincePostalCode: rec[3],
+			Country:                     rec[4],
+		})
+	}
+	return addresses, nil
+}
+
+// readOFACCSV reads records with at least columns fields, replacing OFAC's null
+// value with an empty string. OFAC files end with a single EOF (0x1A) byte which is skipped.
+func readOFACCSV(r io.Reader, columns int) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var out [][]string
+	for line := 1; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) == 1 && strings.Trim(rec[0], "\x1a ") == "" {
+			continue
+		}
+		if len(rec) < columns {
+			return nil, fmt.Errorf("line %d: expected %d columns, found %d", line, columns, len(rec))
+		}
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+			if rec[i] == sdnNull {
+				rec[i] = ""
+			}
+		}
+		if rec[0] == "" {
+			return nil, fmt.Errorf("line %d: missing ent_num", line)
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (s *SDNScreener) threshold() float64 {
+	if s.Threshold <= 0 {
+		return DefaultThreshold
+	}
+	return s.Threshold
+}
+
+// ScreenName returns the SDNs whose name is at least Threshold similar to name,
+// ordered from most to least similar.
+func (s *SDNScreener) ScreenName(name string) ([]Match, error) {
+	var matches []Match
+	candidates := normalizeName(name, true)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	for _, sdn := range s.sdns {
+		score := 0.0
+		for _, a := range candidates {
+			for _, b := range sdn.normalized {
+				if sim := JaroWinkler(a, b); sim > score {
+					score = sim
+				}
+			}
+		}
+		if score >= s.threshold() {
+			matches = append(matches, Match{
+				EntityID: sdn.EntityID,
+				Name:     sdn.Name,
+				Score:    score,
+			})
+		}
+	}
+	sortMatches(matches)
+	return matches, nil
+}
+
+// ScreenAddress returns the SDNs with an address at least Threshold similar to
+// address, ordered from most to least similar. Addresses listed without a street
+// are compared by their city and country.
+func (s *SDNScreener) ScreenAddress(address string) ([]Match, error) {
+	var matches []Match
+	normalized := normalize(address)
+	if normalized == "" {
+		return nil, nil
+	}
+	for _, addr := range s.addresses {
+		if addr.normalized == "" {
+			continue
+		}
+		if score := JaroWinkler(normalized, addr.normalized); score >= s.threshold() {
+			m := Match{
+				EntityID: addr.EntityID,
+				Address:  addr.Address,
+				Score:    score,
+			}
+			if sdn, ok := s.byID[addr.EntityID]; ok {
+				m.Name = sdn.Name
+			}
+			matches = append(matches, m)
+		}
+	}
+	sortMatches(matches)
+	return matches, nil
+}
+
+func sortMatches(matches []Match) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+}
+
+// normalizeName lowercases name and strips punctuation. Individual names are
+// listed by OFAC as "LAST, First" so the reordered "first last" is also returned.
+func normalizeName(name string, reorder bool) []string {
+	out := []string{normalize(name)}
+	if reorder {
+		if idx := strings.Index(name, ","); idx > 0 {
+			out = append(out, normalize(name[idx+1:]+" "+name[:idx]))
+		}
+	}
+	if out[0] == "" {
+		return nil
+	}
+	return out
+}
+
+// normalize lowercases s, replaces punctuation with spaces and collapses whitespace
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,117 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ofac
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mockSDNScreener(t testing.TB) *SDNScreener {
+	s, err := OpenSDNScreener(
+		filepath.Join("..", "test", "testdata", "ofac-sdn.csv"),
+		filepath.Join("..", "test", "testdata", "ofac-add.csv"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestReadSDN(t *testing.T) {
+	s := mockSDNScreener(t)
+	if len(s.sdns) != 4 || len(s.addresses) != 3 {
+		t.Fatalf("sdns=%d addresses=%d", len(s.sdns), len(s.addresses))
+	}
+
+	sdn := s.byID["15036"]
+	if sdn.Name != "ARCTIC SEA" || sdn.Type != "vessel" || sdn.Remarks != "IMO 8912467." {
+		t.Errorf("unexpected SDN: %#v", sdn)
+	}
+	if len(sdn.Programs) != 2 || sdn.Programs[0] != "SDGT" || sdn.Programs[1] != "IRGC" {
+		t.Errorf("Programs=%v", sdn.Programs)
+	}
+	if sdn := s.byID["36"]; sdn.Type != "" {
+		t.Errorf("expected null fields to be empty: %#v", sdn)
+	}
+	if addr := s.addresses[0]; addr.Address != "" || addr.CityStateProvincePostalCode != "Havana" || addr.Country != "Cuba" {
+		t.Errorf("unexpected Address: %#v", addr)
+	}
+
+	if _, err := ReadSDN(strings.NewReader("1,\"NAME\",individual\n")); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := ReadAddresses(strings.NewReader(`,1,"Street","City","Country",-0-`)); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := OpenSDNScreener(filepath.Join("..", "test", "testdata", "missing.csv"), ""); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSDNScreener__ScreenName(t *testing.T) {
+	s := mockSDNScreener(t)
+	cases := []struct {
+		name     string
+		entityID string
+	}{
+		{"NAYIF HAWATMEH", "2674"},
+		{"Hawatmeh, Nayif", "2674"},
+		{"Naif Hawatmeh", "2674"},
+		{"Aerocaribbean Airlines", "36"},
+		{"Anglo Caribbean Co Ltd", "173"},
+		{"John Smith", ""},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		matches, err := s.ScreenName(tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.entityID == "" {
+			if len(matches) != 0 {
+				t.Errorf("%q: unexpected matches: %v", tc.name, matches)
+			}
+			continue
+		}
+		if len(matches) == 0 || matches[0].EntityID != tc.entityID {
+			t.Errorf("%q: unexpected matches: %v", tc.name, matches)
+		}
+	}
+
+	// a strict threshold only reports exact matches
+	s.Threshold = 1.0
+	if matches, _ := s.ScreenName("Naif Hawatmeh"); len(matches) != 0 {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+}
+
+func TestSDNScreener__ScreenAddress(t *testing.T) {
+	s := mockSDNScreener(t)
+	matches, err := s.ScreenAddress("1234 Damascus Rd.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].EntityID != "2674" || matches[0].Name != "HAWATMEH, Nayif" {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+	if matches, _ := s.ScreenAddress("2121 Front Street"); len(matches) != 0 {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+}
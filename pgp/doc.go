@@ -0,0 +1,41 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package pgp encrypts ACH files for transmission to ODFIs and decrypts inbound files with
+// OpenPGP keys. It uses the maintained github.com/ProtonMail/go-crypto fork, as the
+// golang.org/x/crypto/openpgp package is frozen and deprecated.
+//
+// Keys are read from ASCII armored key files, often one directory of public keys for every
+// ODFI a file is sent to along with the originator's own private key for signing.
+//
+//     recipients, err := pgp.LoadKeyringDir("/etc/ach/keys")
+//     if err != nil {
+//         log.Fatalf("problem reading keys: %v", err)
+//     }
+//     odfi, err := recipients.Select("1A2B3C4D5E6F7A8B")
+//     if err != nil {
+//         log.Fatal(err)
+//     }
+//     if err := pgp.WriteFile(fd, file, ach.WriterOptions{}, odfi, signer); err != nil {
+//         log.Fatalf("problem encrypting file: %v", err)
+//     }
+//
+// Inbound files are decrypted and, when a keyring of trusted signers is given, their
+// signature verified before they are parsed.
+//
+//     file, err := pgp.ReadFile(fd, private, odfi)
+package pgp
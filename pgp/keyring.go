@@ -0,0 +1,183 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pgp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+var (
+	// ErrKeyNotFound is returned when no key in a Keyring matches an ID
+	ErrKeyNotFound = errors.New("pgp: key not found")
+
+	// ErrNoPrivateKey is returned when signing or decrypting without a private key
+	ErrNoPrivateKey = errors.New("pgp: no private key")
+)
+
+// Keyring is a set of OpenPGP public and private keys. A Keyring is safe for concurrent use
+// once its private keys are unlocked.
+type Keyring struct {
+	entities openpgp.EntityList
+}
+
+// ReadKeyring reads the keys of an ASCII armored or binary OpenPGP key file.
+func ReadKeyring(r io.Reader) (*Keyring, error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var entities openpgp.EntityList
+	if isArmored(bs) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(bs))
+	} else {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(bs))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{entities: entities}, nil
+}
+
+// LoadKeyring reads each key file in paths into one Keyring.
+func LoadKeyring(paths ...string) (*Keyring, error) {
+	keyring := &Keyring{}
+	for _, path := range paths {
+		fd, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ReadKeyring(fd)
+		fd.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		keyring.entities = append(keyring.entities, k.entities...)
+	}
+	return keyring, nil
+}
+
+// LoadKeyringDir reads every .asc, .gpg and .pgp key file in dir into one Keyring.
+func LoadKeyringDir(dir string) (*Keyring, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, info := range infos {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".asc", ".gpg", ".pgp":
+			if !info.IsDir() {
+				paths = append(paths, filepath.Join(dir, info.Name()))
+			}
+		}
+	}
+	return LoadKeyring(paths...)
+}
+
+// Len returns the number of keys in k
+func (k *Keyring) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.entities)
+}
+
+// KeyIDs returns the hex encoded 64-bit ID of each key in k
+func (k *Keyring) KeyIDs() []string {
+	var ids []string
+	for _, e := range k.entities {
+		ids = append(ids, e.PrimaryKey.KeyIdString())
+	}
+	return ids
+}
+
+// Select returns a Keyring of the keys matching ids. An ID is a hex encoded 64-bit or short
+// 32-bit key ID, a fingerprint or an email address of the key's identities.
+func (k *Keyring) Select(ids ...string) (*Keyring, error) {
+	selected := &Keyring{}
+	for _, id := range ids {
+		e := k.find(id)
+		if e == nil {
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+		}
+		selected.entities = append(selected.entities, e)
+	}
+	return selected, nil
+}
+
+func (k *Keyring) find(id string) *openpgp.Entity {
+	id = strings.TrimPrefix(strings.ToUpper(strings.Replace(id, " ", "", -1)), "0X")
+	if id == "" {
+		return nil
+	}
+	for _, e := range k.entities {
+		fingerprint := fmt.Sprintf("%X", e.PrimaryKey.Fingerprint)
+		if id == fingerprint || (len(id) >= 8 && strings.HasSuffix(fingerprint, id)) {
+			return e
+		}
+		for _, identity := range e.Identities {
+			if identity.UserId != nil && strings.EqualFold(identity.UserId.Email, id) {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// Unlock decrypts the private keys of k which are protected with passphrase.
+func (k *Keyring) Unlock(passphrase []byte) error {
+	for _, e := range k.entities {
+		if e.PrivateKey != nil && e.PrivateKey.Encrypted {
+			if err := e.PrivateKey.Decrypt(passphrase); err != nil {
+				return fmt.Errorf("pgp: unlocking key %s: %v", e.PrimaryKey.KeyIdString(), err)
+			}
+		}
+		for _, sub := range e.Subkeys {
+			if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+				if err := sub.PrivateKey.Decrypt(passphrase); err != nil {
+					return fmt.Errorf("pgp: unlocking key %s: %v", sub.PublicKey.KeyIdString(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// signer returns the first key of k with an unlocked private key
+func (k *Keyring) signer() (*openpgp.Entity, error) {
+	for _, e := range k.entities {
+		if e.PrivateKey != nil && !e.PrivateKey.Encrypted {
+			return e, nil
+		}
+	}
+	return nil, ErrNoPrivateKey
+}
+
+// isArmored returns true if bs starts with an ASCII armor header
+func isArmored(bs []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(bs), []byte("-----BEGIN "))
+}
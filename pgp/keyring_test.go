@@ -0,0 +1,138 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pgp
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// throwawayKey generates a small key for tests and returns its armored public and private keys
+func throwawayKey(t *testing.T, name, email string) ([]byte, []byte) {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "test", email, &packet.Config{RSABits: 1024, DefaultHash: crypto.SHA256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SerializePrivate signs the identities including their preferred hash, so comes first
+	var private bytes.Buffer
+	w, err := armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	var public bytes.Buffer
+	w, err = armor.Encode(&public, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return public.Bytes(), private.Bytes()
+}
+
+func readKeyring(t *testing.T, bs []byte) *Keyring {
+	t.Helper()
+	k, err := ReadKeyring(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeyring__LoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgp-keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	odfi, _ := throwawayKey(t, "ODFI", "ach@odfi.example.com")
+	other, _ := throwawayKey(t, "Other Bank", "ach@other.example.com")
+	for name, bs := range map[string][]byte{"odfi.asc": odfi, "other.asc": other, "README": []byte("not a key")} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), bs, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keyring, err := LoadKeyringDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.Len() != 2 {
+		t.Fatalf("got %d keys", keyring.Len())
+	}
+
+	id := readKeyring(t, odfi).KeyIDs()[0]
+	for _, query := range []string{id, strings.ToLower(id), "0x" + id[8:], "ACH@odfi.example.com"} {
+		selected, err := keyring.Select(query)
+		if err != nil {
+			t.Errorf("%s: %v", query, err)
+			continue
+		}
+		if ids := selected.KeyIDs(); len(ids) != 1 || ids[0] != id {
+			t.Errorf("%s: selected %v", query, ids)
+		}
+	}
+	if _, err := keyring.Select(id, "nobody@example.com"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := keyring.Select(""); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := LoadKeyringDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.asc"), []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyringDir(dir); err == nil || !strings.Contains(err.Error(), "broken.asc") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyring__Signer(t *testing.T) {
+	public, private := throwawayKey(t, "Originator", "ach@originator.example.com")
+	if _, err := readKeyring(t, public).signer(); err != ErrNoPrivateKey {
+		t.Errorf("unexpected error: %v", err)
+	}
+	k := readKeyring(t, private)
+	if err := k.Unlock([]byte("unused")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.signer(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,178 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pgp
+
+import (
+	"bytes"
+	_ "crypto/sha256" // hash functions preferred by OpenPGP keys
+	_ "crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/ourly/ach"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// armorMessage is the armor block type of encrypted messages
+const armorMessage = "PGP MESSAGE"
+
+var (
+	// ErrUnsigned is returned when a message which must be signed has no signature
+	ErrUnsigned = errors.New("pgp: message is not signed")
+
+	// ErrUnknownSigner is returned when a message is signed by a key which isn't trusted
+	ErrUnknownSigner = errors.New("pgp: message signed by an unknown key")
+)
+
+// Encrypt returns a WriteCloser which encrypts to every key of recipients and writes the
+// ASCII armored message to w. Unless signer is nil the message is signed with its first
+// unlocked private key. The message is complete once Close is called.
+func Encrypt(w io.Writer, recipients *Keyring, signer *Keyring) (io.WriteCloser, error) {
+	if recipients.Len() == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrKeyNotFound)
+	}
+	var signedBy *openpgp.Entity
+	if signer != nil {
+		e, err := signer.signer()
+		if err != nil {
+			return nil, err
+		}
+		signedBy = e
+	}
+
+	armored, err := armor.Encode(w, armorMessage, nil)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openpgp.Encrypt(armored, recipients.entities, signedBy, nil, nil)
+	if err != nil {
+		armored.Close()
+		return nil, err
+	}
+	return &encryptWriter{plaintext: plaintext, armored: armored}, nil
+}
+
+type encryptWriter struct {
+	plaintext io.WriteCloser
+	armored   io.WriteCloser
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	return w.plaintext.Write(p)
+}
+
+func (w *encryptWriter) Close() error {
+	if err := w.plaintext.Close(); err != nil {
+		return err
+	}
+	return w.armored.Close()
+}
+
+// Decrypt reads an ASCII armored or binary message from r and decrypts it with the private
+// keys of keys. When signers is not nil the message must be signed by one of its keys and
+// the signature is verified.
+func Decrypt(r io.Reader, keys *Keyring, signers *Keyring) ([]byte, error) {
+	if keys.Len() == 0 {
+		return nil, ErrNoPrivateKey
+	}
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var in io.Reader = bytes.NewReader(bs)
+	if isArmored(bs) {
+		block, err := armor.Decode(in)
+		if err != nil {
+			return nil, err
+		}
+		if block.Type != armorMessage {
+			return nil, fmt.Errorf("pgp: unexpected %s armor block", block.Type)
+		}
+		in = block.Body
+	}
+
+	keyring := append(openpgp.EntityList{}, keys.entities...)
+	if signers != nil {
+		keyring = append(keyring, signers.entities...)
+	}
+	md, err := openpgp.ReadMessage(in, keyring, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// SignatureError is only set once the whole body has been read
+	if signers != nil {
+		if !md.IsSigned {
+			return nil, ErrUnsigned
+		}
+		if md.SignedBy == nil || !signers.contains(md.SignedBy.Entity) {
+			return nil, fmt.Errorf("%w: %016X", ErrUnknownSigner, md.SignedByKeyId)
+		}
+	}
+	if md.IsSigned && md.SignedBy != nil && md.SignatureError != nil {
+		return nil, fmt.Errorf("pgp: invalid signature: %v", md.SignatureError)
+	}
+	return plaintext, nil
+}
+
+// contains returns true if e is a key of k
+func (k *Keyring) contains(e *openpgp.Entity) bool {
+	for _, candidate := range k.entities {
+		if candidate == e {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFile writes file with opts, encrypted to recipients and signed by signer if not nil.
+func WriteFile(w io.Writer, file *ach.File, opts ach.WriterOptions, recipients *Keyring, signer *Keyring) error {
+	enc, err := Encrypt(w, recipients, signer)
+	if err != nil {
+		return err
+	}
+	aw := ach.NewWriter(enc)
+	aw.SetOptions(opts)
+	if err := aw.Write(file); err != nil {
+		enc.Close()
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadFile decrypts an encrypted ACH file with keys, verifying its signature when signers
+// is not nil, and parses it.
+func ReadFile(r io.Reader, keys *Keyring, signers *Keyring) (*ach.File, error) {
+	plaintext, err := Decrypt(r, keys, signers)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ach.NewReader(bytes.NewReader(plaintext)).Read()
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
@@ -0,0 +1,133 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pgp
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func readACHFile(t *testing.T, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestPGP__EncryptDecrypt(t *testing.T) {
+	odfiPublic, odfiPrivate := throwawayKey(t, "ODFI", "ach@odfi.example.com")
+	origPublic, origPrivate := throwawayKey(t, "Originator", "ach@originator.example.com")
+
+	file := readACHFile(t, "ppd-debit.ach")
+	var buf bytes.Buffer
+	if err := WriteFile(&buf, file, ach.WriterOptions{LineEnding: "\r\n"}, readKeyring(t, odfiPublic), readKeyring(t, origPrivate)); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := buf.Bytes()
+	if !strings.HasPrefix(string(encrypted), "-----BEGIN PGP MESSAGE-----") || bytes.Contains(encrypted, []byte(file.Header.ImmediateOriginName)) {
+		t.Fatalf("unexpected message:\n%s", encrypted)
+	}
+
+	// the ODFI decrypts and verifies the file
+	read, err := ReadFile(bytes.NewReader(encrypted), readKeyring(t, odfiPrivate), readKeyring(t, origPublic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Header, file.Header) || len(read.Batches) != len(file.Batches) {
+		t.Error("decrypted file differs")
+	}
+
+	// without trusted signers the signature isn't required
+	plaintext, err := Decrypt(bytes.NewReader(encrypted), readKeyring(t, odfiPrivate), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(plaintext, []byte("\r\n")) {
+		t.Error("expected writer options to be applied")
+	}
+
+	// signed by someone else
+	otherPublic, _ := throwawayKey(t, "Other", "other@example.com")
+	if _, err := Decrypt(bytes.NewReader(encrypted), readKeyring(t, odfiPrivate), readKeyring(t, otherPublic)); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// encrypted to someone else
+	_, otherPrivate := throwawayKey(t, "Other", "other@example.com")
+	if _, err := Decrypt(bytes.NewReader(encrypted), readKeyring(t, otherPrivate), nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestPGP__Unsigned(t *testing.T) {
+	public, private := throwawayKey(t, "ODFI", "ach@odfi.example.com")
+
+	var buf bytes.Buffer
+	w, err := Encrypt(&buf, readKeyring(t, public), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("hello"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := Decrypt(bytes.NewReader(buf.Bytes()), readKeyring(t, private), nil)
+	if err != nil || string(plaintext) != "hello" {
+		t.Errorf("got %q: %v", plaintext, err)
+	}
+	if _, err := Decrypt(bytes.NewReader(buf.Bytes()), readKeyring(t, private), readKeyring(t, public)); err != ErrUnsigned {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPGP__Errors(t *testing.T) {
+	public, _ := throwawayKey(t, "ODFI", "ach@odfi.example.com")
+
+	var buf bytes.Buffer
+	if _, err := Encrypt(&buf, &Keyring{}, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Encrypt(&buf, readKeyring(t, public), readKeyring(t, public)); err != ErrNoPrivateKey {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Decrypt(strings.NewReader("message"), nil, nil); err != ErrNoPrivateKey {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Decrypt(bytes.NewReader(public), readKeyring(t, public), nil); err == nil || !strings.Contains(err.Error(), "PUBLIC KEY") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// invalid files are not encrypted
+	if err := WriteFile(&buf, ach.NewFile(), ach.WriterOptions{}, readKeyring(t, public), nil); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -45,6 +45,9 @@ type Reader struct {
 	// r handles the IO.Reader sent to be parser.
 	scanner *bufio.Scanner
 
+	// decoder converts EBCDIC input to ASCII for scanner
+	decoder *decodingReader
+
 	// line is the current line being parsed from the input r
 	line string
 
@@ -59,6 +62,11 @@ type Reader struct {
 
 	// errors holds each error encountered when attempting to parse the file
 	errors base.ErrorList
+
+	// lenient repairs formatting defects, see ReaderOptions
+	lenient             bool
+	irregularLineEnding bool
+	warnings            []Warning
 }
 
 // error returns a new ParseError based on err
@@ -90,8 +98,10 @@ func (r *Reader) addIATCurrentBatch(iatBatch IATBatch) {
 
 // NewReader returns a new ACH Reader that reads from r.
 func NewReader(r io.Reader) *Reader {
+	decoder := newDecodingReader(r)
 	return &Reader{
-		scanner: bufio.NewScanner(r),
+		scanner: bufio.NewScanner(decoder),
+		decoder: decoder,
 	}
 }
 
@@ -111,6 +121,13 @@ func (r *Reader) Read() (File, error) {
 			break
 		}
 
+		if r.lenient {
+			var ok bool
+			if line, ok = r.repairLine(line); !ok {
+				continue
+			}
+		}
+
 		lineLength := len(line)
 
 		switch {
@@ -122,6 +139,9 @@ func (r *Reader) Read() (File, error) {
 			r.errors.Add(r.parseError(NewRecordWrongLengthErr(lineLength)))
 		default:
 			r.line = line
+			if r.lenient {
+				r.line = r.repairRecord(line)
+			}
 			if err := r.parseLine(); err != nil {
 				r.errors.Add(err)
 			}
@@ -159,6 +179,9 @@ func (r *Reader) processFixedWidthFile(line *string) error {
 		record = record + string(c)
 		if i > 0 && (i+1)%RecordLength == 0 {
 			r.line = record
+			if r.lenient {
+				r.line = r.repairRecord(record)
+			}
 			if err := r.parseLine(); err != nil {
 				return err
 			}
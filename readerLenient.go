@@ -0,0 +1,231 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReaderOptions configures optional behavior of a Reader
+type ReaderOptions struct {
+	// Lenient repairs common formatting defects of files produced by legacy systems
+	// before each record is parsed:
+	//   - short records are padded with spaces (or 9s for block padding) and long records trimmed
+	//   - a byte order mark, control characters and other non-ASCII bytes are removed
+	//   - line endings of CR, LF CR or CR CR LF and EBCDIC NEL (0x85, unless it is part of a
+	//     UTF-8 character) are accepted
+	//   - blank lines are skipped
+	//   - lowercase codes such as the FileIDModifier and SEC code are uppercased
+	// Each repair is recorded as a Warning.
+	Lenient bool
+
+	// Encoding of the file. EBCDIC files are detected from their first byte when no
+	// Encoding is set.
+	Encoding Encoding
+}
+
+// Warning describes a defect a lenient Reader repaired
+type Warning struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// SetOptions configures the Reader and must be called before Read.
+func (r *Reader) SetOptions(opts ReaderOptions) {
+	r.lenient = opts.Lenient
+	r.decoder.encoding = opts.Encoding
+	if r.lenient {
+		r.scanner.Split(r.scanLenientLines)
+	}
+}
+
+// Warnings returns the repairs made by a lenient Reader, in the order of their lines
+func (r *Reader) Warnings() []Warning {
+	return r.warnings
+}
+
+func (r *Reader) warn(format string, args ...interface{}) {
+	r.warnings = append(r.warnings, Warning{Line: r.lineNum, Message: fmt.Sprintf(format, args...)})
+}
+
+// nel is the EBCDIC next line character, which some conversions leave in files as 0x85
+const nel = 0x85
+
+// indexLineEnding returns the index of the first CR, LF or NEL in data. A 0x85 byte which
+// continues a valid UTF-8 character (e.g. the second byte of "Å") is not a NEL.
+func indexLineEnding(data []byte) int {
+	for i, c := range data {
+		if c == '\r' || c == '\n' || (c == nel && !continuesRune(data, i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// continuesRune returns true if data[i] is part of a valid multi-byte UTF-8 character
+func continuesRune(data []byte, i int) bool {
+	for start := i - 1; start >= 0 && start > i-utf8.UTFMax; start-- {
+		if utf8.RuneStart(data[start]) {
+			r, size := utf8.DecodeRune(data[start:])
+			return r != utf8.RuneError && start+size > i
+		}
+	}
+	return false
+}
+
+// scanLenientLines is a bufio.SplitFunc which ends lines at LF, CR, CRLF, LF CR, CR CR LF
+// or NEL. Line endings other than LF and CRLF are recorded for the next call of repairLine.
+func (r *Reader) scanLenientLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	i := indexLineEnding(data)
+	if i < 0 {
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil // request more data
+	}
+	// read enough to see the whole line ending
+	if !atEOF && i+3 > len(data) {
+		return 0, nil, nil
+	}
+	end := i
+	for end < len(data) && data[end] == '\r' {
+		end++
+	}
+	if end < len(data) && end == i && (data[end] == '\n' || data[end] == nel) {
+		end++
+		if end < len(data) && data[end] == '\r' && (end+1 >= len(data) || data[end+1] != '\n') {
+			end++ // LF CR
+		}
+	} else if end < len(data) && data[end] == '\n' {
+		end++ // CR LF or CR CR LF
+	}
+	ending := string(data[i:end])
+	r.irregularLineEnding = ending != "\n" && ending != "\r\n"
+	return end, data[:i], nil
+}
+
+// repairLine fixes the formatting defects of a line read in lenient mode. It returns false
+// for blank lines which should be skipped.
+func (r *Reader) repairLine(line string) (string, bool) {
+	if r.irregularLineEnding {
+		r.warn("repaired line ending")
+		r.irregularLineEnding = false
+	}
+	if r.lineNum == 1 && strings.HasPrefix(line, "\xEF\xBB\xBF") {
+		line = line[3:]
+		r.warn("removed byte order mark")
+	}
+
+	// drop trailing control characters (e.g. NUL or SUB padding) and replace the others byte
+	// by byte, as record positions are counted in bytes
+	end := len(line)
+	for end > 0 && !isPrintableASCII(line[end-1]) {
+		end--
+	}
+	if n := len(line) - end; n > 0 {
+		r.warn("removed %d trailing control character(s)", n)
+		line = line[:end]
+	}
+	replaced := 0
+	buf := []byte(line)
+	for i := range buf {
+		if !isPrintableASCII(buf[i]) {
+			buf[i] = ' '
+			replaced++
+		}
+	}
+	if replaced > 0 {
+		r.warn("replaced %d non-ASCII or control character(s) with spaces", replaced)
+		line = string(buf)
+	}
+
+	if strings.TrimSpace(line) == "" {
+		r.warn("skipped blank line")
+		return "", false
+	}
+
+	switch n := len(line); {
+	case r.lineNum == 1 && n > RecordLength && n%RecordLength == 0:
+		// a file without line endings is split into records by processFixedWidthFile
+	case n > RecordLength:
+		if extra := line[RecordLength:]; strings.TrimSpace(extra) == "" {
+			r.warn("trimmed %d trailing space(s)", len(extra))
+		} else {
+			r.warn("removed %d character(s) after position %d: %q", len(extra), RecordLength, extra)
+		}
+		line = line[:RecordLength]
+	case n < RecordLength:
+		if strings.Trim(line, "9") == "" {
+			line += strings.Repeat("9", RecordLength-n)
+			r.warn("padded %d character block record with 9s", n)
+		} else {
+			line = strings.TrimRight(line, " ")
+			r.warn("padded %d character record with spaces", n)
+			line += strings.Repeat(" ", RecordLength-len(line))
+		}
+	}
+	return line, true
+}
+
+func isPrintableASCII(c byte) bool {
+	return c >= ' ' && c <= '~'
+}
+
+// uppercaseFields are the code fields of each record type which must be uppercase
+var uppercaseFields = map[string][][2]int{
+	fileHeaderPos:  {{33, 34}},                               // FileIDModifier
+	batchHeaderPos: {{50, 53}},                               // StandardEntryClassCode
+	"5IAT":         {{20, 22}, {38, 40}, {63, 66}, {66, 69}}, // ForeignExchangeIndicator, ISO country and currency codes
+	"798":          {{3, 6}},                                 // ChangeCode
+	"799":          {{3, 6}},                                 // ReturnCode
+}
+
+// repairRecord uppercases the code fields of a 94 character record in lenient mode
+func (r *Reader) repairRecord(record string) string {
+	if len(record) != RecordLength {
+		return record
+	}
+	keys := []string{record[:1]}
+	switch record[:1] {
+	case batchHeaderPos:
+		if strings.EqualFold(record[50:53], IAT) {
+			keys = append(keys, "5IAT")
+		}
+	case entryAddendaPos:
+		keys = []string{record[:3]}
+	}
+	for _, key := range keys {
+		for _, field := range uppercaseFields[key] {
+			value := record[field[0]:field[1]]
+			if upper := strings.ToUpper(value); upper != value {
+				record = record[:field[0]] + upper + record[field[1]:]
+				r.warn("uppercased %q at position %d", value, field[0]+1)
+			}
+		}
+	}
+	return record
+}
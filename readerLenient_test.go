@@ -0,0 +1,183 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// messyFile returns the lines of ppd-mixedDebitCredit.ach with the defects of files from
+// legacy systems, along with the canonical file.
+func messyFile(t *testing.T) (string, string) {
+	t.Helper()
+	bs, err := ioutil.ReadFile(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical := string(bs)
+	lines := strings.Split(strings.TrimSuffix(canonical, "\n"), "\n")
+
+	lines[0] = "\xEF\xBB\xBF" + strings.Replace(lines[0], "1055A094101", "1055a094101", 1) // BOM and lowercase FileIDModifier
+	lines[1] = strings.Replace(lines[1], "PPDREG.SALARY", "ppdREG.SALARY", 1)              // lowercase SEC code
+	lines[2] = lines[2] + "   "                                                            // trailing spaces
+	lines[6] = strings.TrimRight(lines[6], " ")                                            // short record
+	lines[4] = strings.Replace(lines[4], "Credit Account 2", "Credit\x00Account 2", 1)     // NUL
+	lines[7] = "999999999"                                                                 // short block padding
+	lines[9] = lines[9] + "\x1a"                                                           // DOS end of file
+
+	var buf strings.Builder
+	for i, line := range lines {
+		buf.WriteString(line)
+		switch i {
+		case 2:
+			buf.WriteString("\r") // CR only
+		case 4:
+			buf.WriteString("\r\r\n\n") // CR CR LF and a blank line
+		case 5:
+			buf.WriteString("\x85") // EBCDIC NEL
+		default:
+			buf.WriteString("\r\n")
+		}
+	}
+	return buf.String(), canonical
+}
+
+func TestReader__Lenient(t *testing.T) {
+	messy, canonical := messyFile(t)
+
+	// the file is rejected without the lenient option
+	if _, err := NewReader(strings.NewReader(messy)).Read(); err == nil {
+		t.Fatal("expected error reading file")
+	}
+
+	r := NewReader(strings.NewReader(messy))
+	r.SetOptions(ReaderOptions{Lenient: true})
+	file, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != strings.TrimSpace(canonical) {
+		t.Errorf("unexpected file:\n%s\nexpected:\n%s", buf.String(), canonical)
+	}
+
+	warnings := r.Warnings()
+	expectedWarnings := []string{
+		"line 1: removed byte order mark",
+		`line 1: uppercased "a" at position 34`,
+		`line 2: uppercased "ppd" at position 51`,
+		"line 3: repaired line ending",
+		"line 3: trimmed 3 trailing space(s)",
+		"line 5: repaired line ending",
+		"line 5: replaced 1 non-ASCII or control character(s) with spaces",
+		"line 6: skipped blank line",
+		"line 7: repaired line ending",
+		"line 8: padded 55 character record with spaces",
+		"line 9: padded 9 character block record with 9s",
+		"line 11: removed 1 trailing control character(s)",
+	}
+	if len(warnings) != len(expectedWarnings) {
+		t.Errorf("got %d warnings: %v", len(warnings), warnings)
+	}
+	for i := range expectedWarnings {
+		if i < len(warnings) && warnings[i].String() != expectedWarnings[i] {
+			t.Errorf("warning %d: got %q, expected %q", i, warnings[i].String(), expectedWarnings[i])
+		}
+	}
+}
+
+func TestReader__LenientCanonical(t *testing.T) {
+	// a file without defects is read without warnings
+	for _, name := range []string{"ppd-debit.ach", "iat-mixedDebitCredit.ach", "ppd-debit-fixedLength.ach", "return-WEB.ach"} {
+		fd, err := ioutil.ReadFile(filepath.Join("test", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewReader(bytes.NewReader(fd))
+		r.SetOptions(ReaderOptions{Lenient: true})
+		if _, err := r.Read(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if len(r.Warnings()) > 0 {
+			t.Errorf("%s: unexpected warnings: %v", name, r.Warnings())
+		}
+	}
+}
+
+func TestReader__LenientLongRecord(t *testing.T) {
+	r := NewReader(strings.NewReader(""))
+	r.SetOptions(ReaderOptions{Lenient: true})
+	r.lineNum = 3
+	line, ok := r.repairLine(strings.Repeat("6", RecordLength) + "EXTRA")
+	if !ok || len(line) != RecordLength {
+		t.Errorf("unexpected line %q", line)
+	}
+	if w := r.Warnings(); len(w) != 1 || w[0].String() != `line 3: removed 5 character(s) after position 94: "EXTRA"` {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+}
+
+func TestReader__LenientReturnCode(t *testing.T) {
+	r := NewReader(strings.NewReader(""))
+	r.SetOptions(ReaderOptions{Lenient: true})
+	record := "799r01121042880000001      09101298Authorization revoked                       091012980000088"
+	record += strings.Repeat(" ", RecordLength-len(record))
+	if got := r.repairRecord(record); got[3:6] != "R01" {
+		t.Errorf("unexpected record %q", got)
+	}
+	// names are left as is
+	record = "5220foo                                 121042882 PPDREG.SALARY      190719   1121042880000001"
+	if got := r.repairRecord(record); got != record {
+		t.Errorf("unexpected record %q", got)
+	}
+}
+
+func TestReader__LenientNEL(t *testing.T) {
+	r := NewReader(strings.NewReader(""))
+	r.SetOptions(ReaderOptions{Lenient: true})
+	scanner := bufio.NewScanner(strings.NewReader("Åsa Ström\x85Jean Müller\xff\x85Zoë\n"))
+	scanner.Split(r.scanLenientLines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Åsa Ström", "Jean Müller\xff", "Zoë"}
+	if len(lines) != len(expected) {
+		t.Fatalf("got lines %q", lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: got %q, expected %q", i, lines[i], expected[i])
+		}
+	}
+}
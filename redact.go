@@ -0,0 +1,320 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Redaction is how a RedactionPolicy hides the value of a field
+type Redaction string
+
+const (
+	// RedactNone leaves the field unchanged
+	RedactNone Redaction = ""
+	// RedactMask hides all but the last four characters, e.g. *****6789
+	RedactMask Redaction = "mask"
+	// RedactHash replaces the field with a truncated HMAC-SHA256 of its value. Equal values
+	// hash the same so entries can still be matched.
+	RedactHash Redaction = "hash"
+	// RedactBlank removes the field
+	RedactBlank Redaction = "blank"
+)
+
+// RedactionPolicy describes how File.Redact hides personally identifiable information
+type RedactionPolicy struct {
+	// AccountNumbers applies to DFIAccountNumber of every entry and to account numbers
+	// in ENR payment information and NOC corrected data.
+	AccountNumbers Redaction `json:"accountNumbers,omitempty"`
+	// Names applies to IndividualName, the names in ENR payment information and the
+	// receiver and originator names of IAT entries.
+	Names Redaction `json:"names,omitempty"`
+	// Identifiers applies to IdentificationNumber, the SSNs in ENR and DNE payment
+	// information and the receiver ID of IAT entries.
+	Identifiers Redaction `json:"identifiers,omitempty"`
+	// Addresses applies to the receiver and originator addresses of IAT entries.
+	Addresses Redaction `json:"addresses,omitempty"`
+	// HashKey keys the HMAC used by RedactHash. Without a key hashed values could be
+	// recovered by hashing likely names or numbers.
+	HashKey string `json:"hashKey,omitempty"`
+}
+
+// DefaultRedactionPolicy masks account numbers, hashes names and identifiers and blanks addresses
+var DefaultRedactionPolicy = RedactionPolicy{
+	AccountNumbers: RedactMask,
+	Names:          RedactHash,
+	Identifiers:    RedactHash,
+	Addresses:      RedactBlank,
+}
+
+// redactHashLength is the number of hex characters kept from a hash, which fits every redacted field
+const redactHashLength = 12
+
+// ParseRedactionPolicy reads a policy written as comma separated field=redaction pairs,
+// e.g. "accountNumbers=mask,names=hash,identifiers=blank". "default" is DefaultRedactionPolicy.
+func ParseRedactionPolicy(s string) (RedactionPolicy, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "default") {
+		return DefaultRedactionPolicy, nil
+	}
+	var policy RedactionPolicy
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return policy, fmt.Errorf("invalid redaction %q", pair)
+		}
+		r := Redaction(strings.ToLower(strings.TrimSpace(parts[1])))
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "accountnumbers":
+			policy.AccountNumbers = r
+		case "names":
+			policy.Names = r
+		case "identifiers":
+			policy.Identifiers = r
+		case "addresses":
+			policy.Addresses = r
+		default:
+			return policy, fmt.Errorf("unknown redaction field %q", parts[0])
+		}
+	}
+	return policy, policy.Validate()
+}
+
+// Validate checks each field of the policy holds a known Redaction
+func (p RedactionPolicy) Validate() error {
+	for field, r := range map[string]Redaction{
+		"AccountNumbers": p.AccountNumbers,
+		"Names":          p.Names,
+		"Identifiers":    p.Identifiers,
+		"Addresses":      p.Addresses,
+	} {
+		switch r {
+		case RedactNone, RedactMask, RedactHash, RedactBlank:
+		default:
+			return fieldError(field, fmt.Errorf("unknown redaction %q", r))
+		}
+	}
+	return nil
+}
+
+// MaskAccountNumber hides all but the last four characters of an account number
+func MaskAccountNumber(account string) string {
+	account = strings.TrimSpace(account)
+	if len(account) <= 4 {
+		return strings.Repeat("*", len(account))
+	}
+	return strings.Repeat("*", len(account)-4) + account[len(account)-4:]
+}
+
+// redact hides value according to r. Hashes are cut to width characters so they fit the
+// record field they replace.
+func (p RedactionPolicy) redact(r Redaction, value string, width int) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value
+	}
+	switch r {
+	case RedactMask:
+		return MaskAccountNumber(value)
+	case RedactHash:
+		mac := hmac.New(sha256.New, []byte(p.HashKey))
+		mac.Write([]byte(value))
+		sum := hex.EncodeToString(mac.Sum(nil))
+		if width > redactHashLength {
+			width = redactHashLength
+		}
+		return sum[:width]
+	case RedactBlank:
+		return ""
+	default:
+		return value
+	}
+}
+
+// Redact returns a copy of the file with account numbers, names, identifiers and addresses
+// hidden according to policy, which can then be logged or returned from an API. The file
+// itself is unchanged. Redacted files are not valid for transmission.
+//
+// Addenda13 and Addenda14 records of IAT entries describe banks rather than parties and
+// are copied as is. An error is returned, rather than an unredacted copy, for batches which
+// can't be copied.
+func (f *File) Redact(policy RedactionPolicy) (*File, error) {
+	out := *f
+	out.Batches, out.IATBatches = nil, nil
+	out.NotificationOfChange, out.ReturnEntries = nil, nil
+
+	redacted := make(map[Batcher]Batcher, len(f.Batches))
+	for _, batch := range f.Batches {
+		b, err := policy.RedactBatch(batch)
+		if err != nil {
+			return nil, fmt.Errorf("redacting batch %s: %v", batch.ID(), err)
+		}
+		redacted[batch] = b
+		out.Batches = append(out.Batches, b)
+	}
+	for _, batch := range f.NotificationOfChange {
+		if b, ok := redacted[batch]; ok {
+			out.NotificationOfChange = append(out.NotificationOfChange, b)
+		}
+	}
+	for _, batch := range f.ReturnEntries {
+		if b, ok := redacted[batch]; ok {
+			out.ReturnEntries = append(out.ReturnEntries, b)
+		}
+	}
+	for i := range f.IATBatches {
+		out.IATBatches = append(out.IATBatches, policy.redactIATBatch(f.IATBatches[i]))
+	}
+	return &out, nil
+}
+
+// RedactBatch returns a copy of batch redacted like the batches of File.Redact, e.g. for
+// APIs which return a single batch.
+func (p RedactionPolicy) RedactBatch(batch Batcher) (Batcher, error) {
+	if batch.GetHeader() == nil {
+		return nil, errors.New("missing BatchHeader")
+	}
+	bh := *batch.GetHeader()
+	out, err := NewBatch(&bh)
+	if err != nil {
+		return nil, err // not a known SEC code, which Redact cannot copy
+	}
+	out.SetID(batch.ID())
+	if bc := batch.GetControl(); bc != nil {
+		c := *bc
+		out.SetControl(&c)
+	}
+	if bc := batch.GetADVControl(); bc != nil {
+		c := *bc
+		out.SetADVControl(&c)
+	}
+	for _, entry := range batch.GetEntries() {
+		ed := *entry
+		ed.DFIAccountNumber = p.redact(p.AccountNumbers, ed.DFIAccountNumber, 17)
+		ed.IndividualName = p.redact(p.Names, ed.IndividualName, 22)
+		ed.IdentificationNumber = p.redact(p.Identifiers, ed.IdentificationNumber, 15)
+		ed.Addenda05 = nil
+		for _, a := range entry.Addenda05 {
+			addenda05 := *a
+			addenda05.PaymentRelatedInformation = p.redactPaymentInformation(bh.StandardEntryClassCode, a.PaymentRelatedInformation)
+			ed.Addenda05 = append(ed.Addenda05, &addenda05)
+		}
+		if entry.Addenda98 != nil {
+			addenda98 := *entry.Addenda98
+			addenda98.CorrectedData = p.redactCorrectedData(addenda98.ChangeCode, addenda98.CorrectedData)
+			ed.Addenda98 = &addenda98
+		}
+		out.AddEntry(&ed)
+	}
+	for _, entry := range batch.GetADVEntries() {
+		ed := *entry
+		ed.DFIAccountNumber = p.redact(p.AccountNumbers, ed.DFIAccountNumber, 15)
+		ed.IndividualName = p.redact(p.Names, ed.IndividualName, 22)
+		out.AddADVEntry(&ed)
+	}
+	return out, nil
+}
+
+// redactPaymentInformation scrubs the '*' delimited payment information of ENR and DNE addenda
+func (p RedactionPolicy) redactPaymentInformation(sec, info string) string {
+	terminated := strings.HasSuffix(info, `\`)
+	parts := strings.Split(strings.TrimSuffix(info, `\`), "*")
+	switch {
+	case sec == ENR && len(parts) == 8:
+		parts[3] = p.redact(p.AccountNumbers, parts[3], 17)
+		parts[4] = p.redact(p.Identifiers, parts[4], 9)
+		parts[5] = p.redact(p.Names, parts[5], 22)
+		parts[6] = p.redact(p.Names, parts[6], 22)
+	case sec == DNE && len(parts) == 6:
+		parts[3] = p.redact(p.Identifiers, parts[3], 9)
+	default:
+		return info
+	}
+	out := strings.Join(parts, "*")
+	if terminated {
+		out += `\`
+	}
+	return out
+}
+
+// redactCorrectedData hides the account number in the corrected data of a C01, C03 or C06 NOC
+func (p RedactionPolicy) redactCorrectedData(code, data string) string {
+	switch code {
+	case "C01":
+		return p.redact(p.AccountNumbers, data, 17)
+	case "C03":
+		// routing number, three spaces and the account number
+		if len(data) > 12 {
+			return data[:12] + p.redact(p.AccountNumbers, data[12:], 17)
+		}
+	case "C06":
+		// account number, three spaces and the transaction code
+		if len(data) >= 22 {
+			return p.redact(p.AccountNumbers, data[:17], 17) + "   " + strings.TrimSpace(data[17:])
+		}
+	}
+	return data
+}
+
+func (p RedactionPolicy) redactIATBatch(batch IATBatch) IATBatch {
+	out := batch
+	out.Entries = nil
+	for _, entry := range batch.Entries {
+		ed := *entry
+		ed.DFIAccountNumber = p.redact(p.AccountNumbers, ed.DFIAccountNumber, 35)
+		if entry.Addenda10 != nil {
+			a := *entry.Addenda10
+			a.Name = p.redact(p.Names, a.Name, 35)
+			ed.Addenda10 = &a
+		}
+		if entry.Addenda11 != nil {
+			a := *entry.Addenda11
+			a.OriginatorName = p.redact(p.Names, a.OriginatorName, 35)
+			a.OriginatorStreetAddress = p.redact(p.Addresses, a.OriginatorStreetAddress, 35)
+			ed.Addenda11 = &a
+		}
+		if entry.Addenda12 != nil {
+			a := *entry.Addenda12
+			a.OriginatorCityStateProvince = p.redact(p.Addresses, a.OriginatorCityStateProvince, 35)
+			a.OriginatorCountryPostalCode = p.redact(p.Addresses, a.OriginatorCountryPostalCode, 35)
+			ed.Addenda12 = &a
+		}
+		if entry.Addenda15 != nil {
+			a := *entry.Addenda15
+			a.ReceiverIDNumber = p.redact(p.Identifiers, a.ReceiverIDNumber, 15)
+			a.ReceiverStreetAddress = p.redact(p.Addresses, a.ReceiverStreetAddress, 35)
+			ed.Addenda15 = &a
+		}
+		if entry.Addenda16 != nil {
+			a := *entry.Addenda16
+			a.ReceiverCityStateProvince = p.redact(p.Addresses, a.ReceiverCityStateProvince, 35)
+			a.ReceiverCountryPostalCode = p.redact(p.Addresses, a.ReceiverCountryPostalCode, 35)
+			ed.Addenda16 = &a
+		}
+		out.Entries = append(out.Entries, &ed)
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-mixedDebitCredit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Batches[0].GetEntries()[0].IdentificationNumber = "ID 4567"
+	redacted, err := file.Redact(DefaultRedactionPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ed := redacted.Batches[0].GetEntries()[0]
+	if ed.DFIAccountNumber != "*****6789" {
+		t.Errorf("DFIAccountNumber=%q", ed.DFIAccountNumber)
+	}
+	if len(ed.IndividualName) != redactHashLength {
+		t.Errorf("IndividualName=%q", ed.IndividualName)
+	}
+	if ed.IdentificationNumber == "ID 4567" || len(ed.IdentificationNumber) != redactHashLength {
+		t.Errorf("IdentificationNumber=%q", ed.IdentificationNumber)
+	}
+
+	// the original file is unchanged
+	if orig := file.Batches[0].GetEntries()[0]; strings.TrimSpace(orig.DFIAccountNumber) != "123456789" || strings.TrimSpace(orig.IndividualName) != "Debit Account" {
+		t.Errorf("original entry was redacted: %#v", orig)
+	}
+
+	// neither the written file nor JSON include account numbers
+	bs, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(redacted); err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range []string{buf.String(), string(bs)} {
+		for _, account := range []string{"123456789", "987654321", "837098765"} {
+			if strings.Contains(out, account) {
+				t.Errorf("found account %s in\n%s", account, out)
+			}
+		}
+	}
+	if redacted.Control != file.Control || redacted.Header != file.Header {
+		t.Error("expected header and control to be copied")
+	}
+}
+
+func TestRedact__Hash(t *testing.T) {
+	file := mockFilePPD()
+	a, err := file.Redact(RedactionPolicy{Names: RedactHash, HashKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := file.Redact(RedactionPolicy{Names: RedactHash, HashKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := file.Redact(RedactionPolicy{Names: RedactHash, HashKey: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := func(f *File) string { return f.Batches[0].GetEntries()[0].IndividualName }
+	if name(a) != name(b) {
+		t.Errorf("expected equal hashes: %q vs %q", name(a), name(b))
+	}
+	if name(a) == name(c) {
+		t.Errorf("expected keyed hashes to differ: %q", name(a))
+	}
+	if acct := a.Batches[0].GetEntries()[0].DFIAccountNumber; acct != file.Batches[0].GetEntries()[0].DFIAccountNumber {
+		t.Errorf("account number should not be redacted: %q", acct)
+	}
+}
+
+func TestRedact__Addenda05(t *testing.T) {
+	file := NewFile()
+	file.AddBatch(mockBatchENR())
+	dne := mockBatchDNE()
+	dne.GetEntries()[0].Addenda05[0].PaymentRelatedInformation = `    DATE OF DEATH*010218*CUSTOMERSSN*123456789*AMOUNT*0000.00\`
+	file.AddBatch(dne)
+
+	redacted, err := file.Redact(RedactionPolicy{AccountNumbers: RedactMask, Names: RedactBlank, Identifiers: RedactMask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := redacted.Batches[0].GetEntries()[0].Addenda05[0].PaymentRelatedInformation; info != `21*12200004*3*********4321******7777***1\` {
+		t.Errorf("ENR PaymentRelatedInformation=%q", info)
+	}
+	if info := redacted.Batches[1].GetEntries()[0].Addenda05[0].PaymentRelatedInformation; info != `    DATE OF DEATH*010218*CUSTOMERSSN******6789*AMOUNT*0000.00\` {
+		t.Errorf("DNE PaymentRelatedInformation=%q", info)
+	}
+	if info := file.Batches[1].GetEntries()[0].Addenda05[0].PaymentRelatedInformation; !strings.Contains(info, "123456789") {
+		t.Errorf("original addenda was redacted: %q", info)
+	}
+}
+
+func TestRedact__NOC(t *testing.T) {
+	file := NewFile()
+	file.AddBatch(mockBatchCOR())
+	file.NotificationOfChange = append(file.NotificationOfChange, file.Batches[0])
+
+	redacted, err := file.Redact(DefaultRedactionPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redacted.NotificationOfChange) != 1 || redacted.NotificationOfChange[0] != redacted.Batches[0] {
+		t.Fatal("expected NotificationOfChange to refer to the redacted batch")
+	}
+	if data := redacted.Batches[0].GetEntries()[0].Addenda98.CorrectedData; data != "******1614" {
+		t.Errorf("CorrectedData=%q", data)
+	}
+
+	policy := RedactionPolicy{AccountNumbers: RedactBlank}
+	if data := policy.redactCorrectedData("C03", "231380104   744-5678-99"); data != "231380104   " {
+		t.Errorf("C03 CorrectedData=%q", data)
+	}
+	if data := policy.redactCorrectedData("C06", "744-5678-99         27"); data != "   27" {
+		t.Errorf("C06 CorrectedData=%q", data)
+	}
+	if data := policy.redactCorrectedData("C05", "27"); data != "27" {
+		t.Errorf("C05 CorrectedData=%q", data)
+	}
+}
+
+func TestRedact__IAT(t *testing.T) {
+	file := NewFile()
+	file.AddIATBatch(mockIATBatch(t))
+
+	redacted, err := file.Redact(DefaultRedactionPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed := redacted.IATBatches[0].Entries[0]
+	if ed.DFIAccountNumber == file.IATBatches[0].Entries[0].DFIAccountNumber {
+		t.Errorf("DFIAccountNumber=%q", ed.DFIAccountNumber)
+	}
+	if ed.Addenda10.Name == "BEK Enterprises" || ed.Addenda11.OriginatorName == "BEK Solutions" {
+		t.Errorf("names not redacted: %q %q", ed.Addenda10.Name, ed.Addenda11.OriginatorName)
+	}
+	if ed.Addenda11.OriginatorStreetAddress != "" || ed.Addenda15.ReceiverStreetAddress != "" ||
+		ed.Addenda12.OriginatorCityStateProvince != "" || ed.Addenda16.ReceiverCityStateProvince != "" {
+		t.Error("addresses not blanked")
+	}
+	if ed.Addenda15.ReceiverIDNumber == "987465493213987" {
+		t.Errorf("ReceiverIDNumber=%q", ed.Addenda15.ReceiverIDNumber)
+	}
+	if ed.Addenda13 != file.IATBatches[0].Entries[0].Addenda13 {
+		t.Error("expected Addenda13 to be copied as is")
+	}
+	if orig := file.IATBatches[0].Entries[0]; orig.Addenda10.Name != "BEK Enterprises" || orig.Addenda15.ReceiverStreetAddress != "2121 Front Street" {
+		t.Error("original IAT entry was redacted")
+	}
+}
+
+func TestParseRedactionPolicy(t *testing.T) {
+	policy, err := ParseRedactionPolicy("accountNumbers=mask, names=HASH,identifiers=blank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.AccountNumbers != RedactMask || policy.Names != RedactHash || policy.Identifiers != RedactBlank || policy.Addresses != RedactNone {
+		t.Errorf("unexpected policy: %#v", policy)
+	}
+	if policy, err := ParseRedactionPolicy("default"); err != nil || policy != DefaultRedactionPolicy {
+		t.Errorf("unexpected default policy: %#v: %v", policy, err)
+	}
+	for _, s := range []string{"names", "phones=mask", "names=scramble"} {
+		if _, err := ParseRedactionPolicy(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestRedact__unknownBatch(t *testing.T) {
+	file := mockFilePPD()
+	bh := *file.Batches[0].GetHeader()
+	bh.StandardEntryClassCode = "ZZZ"
+	file.AddBatch(&Batch{Header: &bh, Entries: file.Batches[0].GetEntries()})
+
+	// batches which can't be copied fail instead of being returned unredacted
+	if redacted, err := file.Redact(DefaultRedactionPolicy); err == nil || redacted != nil {
+		t.Errorf("expected error: %v", err)
+	}
+	file.Batches = file.Batches[:1]
+	file.AddBatch(&Batch{Entries: file.Batches[0].GetEntries()})
+	if _, err := file.Redact(DefaultRedactionPolicy); err == nil || !strings.Contains(err.Error(), "missing BatchHeader") {
+		t.Errorf("expected error: %v", err)
+	}
+}
+
+func TestMaskAccountNumber(t *testing.T) {
+	for account, expected := range map[string]string{
+		"123456789":  "*****6789",
+		" 1234 ":     "****",
+		"12":         "**",
+		"":           "",
+		"744-5678-9": "******78-9",
+	} {
+		if got := MaskAccountNumber(account); got != expected {
+			t.Errorf("MaskAccountNumber(%q)=%q, expected %q", account, got, expected)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package report renders an ACH file as a human readable text or HTML report for review
+// before it is sent.
+//
+// The report shows the file header, a summary of each batch and its entries (with masked
+// account numbers, transaction code descriptions, addenda and return reasons) and the file
+// totals reconciled against the FileControl record.
+//
+// Render a file for approval
+//     if err := report.Text(os.Stdout, file); err != nil {
+//         log.Fatalf("problem rendering report: %v", err)
+//     }
+//
+// Inspect discrepancies between the entries and FileControl
+//     r := report.New(file)
+//     for _, d := range r.Discrepancies {
+//         fmt.Println(d)
+//     }
+package report
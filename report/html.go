@@ -0,0 +1,81 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/ourly/ach"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"dollars": Dollars,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ACH File Report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; }
+td.amount { text-align: right; }
+.discrepancy { color: #b00; }
+</style>
+</head>
+<body>
+<h1>ACH File Report</h1>
+<table class="header">
+<tr><th>Destination</th><td>{{.Header.ImmediateDestination}} {{.Header.ImmediateDestinationName}}</td></tr>
+<tr><th>Origin</th><td>{{.Header.ImmediateOrigin}} {{.Header.ImmediateOriginName}}</td></tr>
+<tr><th>Created</th><td>{{.Header.FileCreation}}</td></tr>
+<tr><th>File ID Modifier</th><td>{{.Header.FileIDModifier}}</td></tr>
+</table>
+{{range .Batches}}
+<h2>Batch {{.Number}}: {{.CompanyName}} ({{.CompanyIdentification}})</h2>
+<p>{{.StandardEntryClassCode}} {{.CompanyEntryDescription}}, effective {{.EffectiveEntryDate}}.
+{{.EntryCount}} entries, debits {{dollars .TotalDebit}}, credits {{dollars .TotalCredit}}</p>
+<table class="entries">
+<tr><th>Trace</th><th>Code</th><th>Description</th><th>Routing</th><th>Account</th><th>Name</th><th>ID</th><th>Amount</th><th>Addenda</th></tr>
+{{range .Entries}}<tr><td>{{.TraceNumber}}</td><td>{{.TransactionCode}}</td><td>{{.Description}}</td><td>{{.RoutingNumber}}</td><td>{{.AccountNumber}}</td><td>{{.Name}}</td><td>{{.IdentificationNumber}}</td><td class="amount">{{dollars .Amount}}</td><td>{{range .Addenda}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>File Totals</h2>
+<table class="totals">
+<tr><th></th><th>Entries</th><th>FileControl</th></tr>
+<tr><th>Batches</th><td>{{.Totals.BatchCount}}</td><td>{{.Control.BatchCount}}</td></tr>
+<tr><th>Entries and addenda</th><td>{{.Totals.EntryAddendaCount}}</td><td>{{.Control.EntryAddendaCount}}</td></tr>
+<tr><th>Entry hash</th><td>{{.Totals.EntryHash}}</td><td>{{.Control.EntryHash}}</td></tr>
+<tr><th>Debits</th><td class="amount">{{dollars .Totals.TotalDebit}}</td><td class="amount">{{dollars .Control.TotalDebit}}</td></tr>
+<tr><th>Credits</th><td class="amount">{{dollars .Totals.TotalCredit}}</td><td class="amount">{{dollars .Control.TotalCredit}}</td></tr>
+</table>
+{{if .Discrepancies}}<ul class="discrepancy">{{range .Discrepancies}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>Totals reconcile with the FileControl</p>{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the report of a file as an HTML page
+func HTML(w io.Writer, file *ach.File) error {
+	return New(file).WriteHTML(w)
+}
+
+// WriteHTML renders the report as an HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
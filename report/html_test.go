@@ -0,0 +1,63 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	file := readFile(t, "return-WEB.ach")
+	var buf bytes.Buffer
+	if err := HTML(&buf, file); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"<h2>Batch 1: CoinLion (123456789)</h2>",
+		"<td>Checking return/NOC debit</td>",
+		"Return R01 Insufficient Funds",
+		"<td class=\"amount\">$123.54</td>",
+		"Totals reconcile with the FileControl",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in\n%s", s, out)
+		}
+	}
+
+	// values are escaped
+	file.Batches[0].GetHeader().CompanyName = "<b>Co</b>"
+	buf.Reset()
+	if err := HTML(&buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<b>Co</b>") {
+		t.Error("CompanyName not escaped")
+	}
+
+	file.Control.TotalCreditEntryDollarAmountInFile = 1
+	buf.Reset()
+	if err := HTML(&buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<ul class="discrepancy">`) {
+		t.Error("missing discrepancies")
+	}
+}
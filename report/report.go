@@ -0,0 +1,351 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ourly/ach"
+)
+
+// Report is the content rendered by Text and HTML
+type Report struct {
+	Header  Header  `json:"header"`
+	Batches []Batch `json:"batches"`
+	// Totals are calculated from the file's entries
+	Totals Totals `json:"totals"`
+	// Control are the totals recorded in the FileControl
+	Control Totals `json:"control"`
+	// Discrepancies describe each total which differs from the FileControl
+	Discrepancies []string `json:"discrepancies,omitempty"`
+}
+
+// Header describes the FileHeader
+type Header struct {
+	ImmediateDestination     string `json:"immediateDestination"`
+	ImmediateDestinationName string `json:"immediateDestinationName"`
+	ImmediateOrigin          string `json:"immediateOrigin"`
+	ImmediateOriginName      string `json:"immediateOriginName"`
+	FileCreation             string `json:"fileCreation"`
+	FileIDModifier           string `json:"fileIDModifier"`
+}
+
+// Batch summarizes a batch and lists its entries
+type Batch struct {
+	Number                  int     `json:"number"`
+	CompanyName             string  `json:"companyName"`
+	CompanyIdentification   string  `json:"companyIdentification"`
+	StandardEntryClassCode  string  `json:"standardEntryClassCode"`
+	CompanyEntryDescription string  `json:"companyEntryDescription"`
+	EffectiveEntryDate      string  `json:"effectiveEntryDate"`
+	EntryCount              int     `json:"entryCount"`
+	TotalDebit              int     `json:"totalDebit"`
+	TotalCredit             int     `json:"totalCredit"`
+	Entries                 []Entry `json:"entries"`
+}
+
+// Entry describes an entry and its addenda
+type Entry struct {
+	TraceNumber     string `json:"traceNumber"`
+	TransactionCode int    `json:"transactionCode"`
+	// Description of the TransactionCode
+	Description   string `json:"description"`
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is masked to its last four characters
+	AccountNumber        string   `json:"accountNumber"`
+	Name                 string   `json:"name"`
+	IdentificationNumber string   `json:"identificationNumber"`
+	Amount               int      `json:"amount"`
+	Addenda              []string `json:"addenda,omitempty"`
+
+	// addendaRecords is the number of addenda records, which includes IAT addenda not listed
+	addendaRecords int
+}
+
+// Totals are the counts and amounts of a file
+type Totals struct {
+	BatchCount        int `json:"batchCount"`
+	EntryAddendaCount int `json:"entryAddendaCount"`
+	EntryHash         int `json:"entryHash"`
+	TotalDebit        int `json:"totalDebit"`
+	TotalCredit       int `json:"totalCredit"`
+}
+
+// New builds the report of a file
+func New(file *ach.File) *Report {
+	r := &Report{
+		Header: Header{
+			ImmediateDestination:     strings.TrimSpace(file.Header.ImmediateDestination),
+			ImmediateDestinationName: strings.TrimSpace(file.Header.ImmediateDestinationName),
+			ImmediateOrigin:          strings.TrimSpace(file.Header.ImmediateOrigin),
+			ImmediateOriginName:      strings.TrimSpace(file.Header.ImmediateOriginName),
+			FileCreation:             fileCreation(file.Header),
+			FileIDModifier:           file.Header.FileIDModifier,
+		},
+		Control: Totals{
+			BatchCount:        file.Control.BatchCount,
+			EntryAddendaCount: file.Control.EntryAddendaCount,
+			EntryHash:         file.Control.EntryHash,
+			TotalDebit:        file.Control.TotalDebitEntryDollarAmountInFile,
+			TotalCredit:       file.Control.TotalCreditEntryDollarAmountInFile,
+		},
+	}
+	for _, batch := range file.Batches {
+		r.Batches = append(r.Batches, newBatch(batch))
+	}
+	for i := range file.IATBatches {
+		r.Batches = append(r.Batches, newIATBatch(&file.IATBatches[i]))
+	}
+
+	r.Totals.BatchCount = len(r.Batches)
+	for _, b := range r.Batches {
+		r.Totals.TotalDebit += b.TotalDebit
+		r.Totals.TotalCredit += b.TotalCredit
+		for _, e := range b.Entries {
+			r.Totals.EntryAddendaCount += 1 + e.addendaRecords
+			rdfi, _ := strconv.Atoi(e.RoutingNumber[:8])
+			r.Totals.EntryHash += rdfi
+		}
+	}
+	// the entry hash is the low 10 digits of the sum
+	r.Totals.EntryHash %= 10000000000
+	r.reconcile()
+	return r
+}
+
+// reconcile compares the calculated totals with the FileControl
+func (r *Report) reconcile() {
+	check := func(name string, calculated, control int, money bool) {
+		if calculated == control {
+			return
+		}
+		if money {
+			r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("%s: entries total %s but FileControl has %s", name, Dollars(calculated), Dollars(control)))
+		} else {
+			r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("%s: entries total %d but FileControl has %d", name, calculated, control))
+		}
+	}
+	check("BatchCount", r.Totals.BatchCount, r.Control.BatchCount, false)
+	check("EntryAddendaCount", r.Totals.EntryAddendaCount, r.Control.EntryAddendaCount, false)
+	check("EntryHash", r.Totals.EntryHash, r.Control.EntryHash, false)
+	check("TotalDebitEntryDollarAmountInFile", r.Totals.TotalDebit, r.Control.TotalDebit, true)
+	check("TotalCreditEntryDollarAmountInFile", r.Totals.TotalCredit, r.Control.TotalCredit, true)
+}
+
+func newBatch(batch ach.Batcher) Batch {
+	bh := batch.GetHeader()
+	b := Batch{
+		Number:                  bh.BatchNumber,
+		CompanyName:             strings.TrimSpace(bh.CompanyName),
+		CompanyIdentification:   strings.TrimSpace(bh.CompanyIdentification),
+		StandardEntryClassCode:  bh.StandardEntryClassCode,
+		CompanyEntryDescription: strings.TrimSpace(bh.CompanyEntryDescription),
+		EffectiveEntryDate:      date(bh.EffectiveEntryDate),
+	}
+	for _, ed := range batch.GetEntries() {
+		e := Entry{
+			TraceNumber:          ed.TraceNumber,
+			TransactionCode:      ed.TransactionCode,
+			Description:          TransactionCodeDescription(ed.TransactionCode),
+			RoutingNumber:        ed.RDFIIdentificationField() + ed.CheckDigit,
+			AccountNumber:        MaskAccount(ed.DFIAccountNumber),
+			Name:                 strings.TrimSpace(ed.IndividualName),
+			IdentificationNumber: strings.TrimSpace(ed.IdentificationNumber),
+			Amount:               ed.Amount,
+		}
+		if ed.Addenda02 != nil {
+			a := ed.Addenda02
+			e.Addenda = append(e.Addenda, strings.Join(strings.Fields(fmt.Sprintf("Terminal %s %s %s %s", a.TerminalIdentificationCode, a.TerminalLocation, a.TerminalCity, a.TerminalState)), " "))
+		}
+		for _, a := range ed.Addenda05 {
+			e.Addenda = append(e.Addenda, strings.TrimSpace(a.PaymentRelatedInformation))
+		}
+		if ed.Addenda98 != nil {
+			e.Addenda = append(e.Addenda, changeDescription(ed.Addenda98))
+		}
+		if ed.Addenda99 != nil {
+			e.Addenda = append(e.Addenda, returnDescription(ed.Addenda99))
+		}
+		e.addendaRecords = len(e.Addenda)
+		b.add(e, ed.CreditOrDebit())
+	}
+	return b
+}
+
+func newIATBatch(batch *ach.IATBatch) Batch {
+	bh := batch.GetHeader()
+	b := Batch{
+		Number:                  bh.BatchNumber,
+		CompanyIdentification:   strings.TrimSpace(bh.OriginatorIdentification),
+		StandardEntryClassCode:  bh.StandardEntryClassCode,
+		CompanyEntryDescription: strings.TrimSpace(bh.CompanyEntryDescription),
+		EffectiveEntryDate:      date(bh.EffectiveEntryDate),
+	}
+	for _, ed := range batch.GetEntries() {
+		e := Entry{
+			TraceNumber:     ed.TraceNumber,
+			TransactionCode: ed.TransactionCode,
+			Description:     TransactionCodeDescription(ed.TransactionCode),
+			RoutingNumber:   ed.RDFIIdentificationField() + ed.CheckDigit,
+			AccountNumber:   MaskAccount(ed.DFIAccountNumber),
+			Amount:          ed.Amount,
+		}
+		if ed.Addenda10 != nil {
+			e.Name = strings.TrimSpace(ed.Addenda10.Name)
+		}
+		if ed.Addenda11 != nil && b.CompanyName == "" {
+			b.CompanyName = strings.TrimSpace(ed.Addenda11.OriginatorName)
+		}
+		for _, a := range ed.Addenda17 {
+			e.Addenda = append(e.Addenda, strings.TrimSpace(a.PaymentRelatedInformation))
+		}
+		if ed.Addenda99 != nil {
+			e.Addenda = append(e.Addenda, returnDescription(ed.Addenda99))
+		}
+		// Addenda10 through Addenda16 and Addenda18 are counted without being listed
+		e.addendaRecords = 7 + len(ed.Addenda17) + len(ed.Addenda18)
+		if ed.Addenda99 != nil {
+			e.addendaRecords++
+		}
+		b.add(e, creditOrDebit(ed.TransactionCode))
+	}
+	return b
+}
+
+// add appends an entry to the batch totals
+func (b *Batch) add(e Entry, creditOrDebit string) {
+	b.Entries = append(b.Entries, e)
+	b.EntryCount++
+	switch creditOrDebit {
+	case "C":
+		b.TotalCredit += e.Amount
+	case "D":
+		b.TotalDebit += e.Amount
+	}
+}
+
+// creditOrDebit returns C for credit transaction codes and D for debits
+func creditOrDebit(code int) string {
+	switch code % 10 {
+	case 1, 2, 3, 4:
+		return "C"
+	case 5, 6, 7, 8, 9:
+		return "D"
+	}
+	return ""
+}
+
+// returnDescription describes a return with its reason from the NACHA return codes
+func returnDescription(a *ach.Addenda99) string {
+	s := "Return " + a.ReturnCode
+	if code := ach.LookupReturnCode(a.ReturnCode); code != nil {
+		s += " " + code.Reason
+	}
+	return s + " (original trace " + a.OriginalTrace + ")"
+}
+
+// changeDescription describes a notification of change and the corrected data
+func changeDescription(a *ach.Addenda98) string {
+	s := "NOC " + a.ChangeCode
+	if code := ach.LookupChangeCode(a.ChangeCode); code != nil {
+		s += " " + code.Reason
+	}
+	return s + ": " + strings.TrimSpace(a.CorrectedData)
+}
+
+// MaskAccount hides all but the last four characters of an account number
+func MaskAccount(account string) string {
+	return ach.MaskAccountNumber(account)
+}
+
+// Dollars formats an amount in cents as dollars with thousands separators
+func Dollars(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	whole := fmt.Sprintf("%d", cents/100)
+	var groups []string
+	for len(whole) > 3 {
+		groups = append([]string{whole[len(whole)-3:]}, groups...)
+		whole = whole[:len(whole)-3]
+	}
+	groups = append([]string{whole}, groups...)
+	return fmt.Sprintf("%s$%s.%02d", sign, strings.Join(groups, ","), cents%100)
+}
+
+// date formats a YYMMDD date as 2006-01-02, or returns it unchanged when invalid
+func date(yymmdd string) string {
+	if t, err := time.Parse("060102", yymmdd); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return yymmdd
+}
+
+// fileCreation formats the file's creation date and time
+func fileCreation(fh ach.FileHeader) string {
+	if t, err := time.Parse("0601021504", fh.FileCreationDateField()+fh.FileCreationTimeField()); err == nil {
+		return t.Format("2006-01-02 15:04")
+	}
+	return fh.FileCreationDate
+}
+
+// transactionCodes describe each transaction code
+var transactionCodes = map[int]string{
+	ach.CheckingReturnNOCCredit:            "Checking return/NOC credit",
+	ach.CheckingCredit:                     "Checking credit",
+	ach.CheckingPrenoteCredit:              "Checking prenote credit",
+	ach.CheckingZeroDollarRemittanceCredit: "Checking zero dollar credit",
+	ach.CheckingReturnNOCDebit:             "Checking return/NOC debit",
+	ach.CheckingDebit:                      "Checking debit",
+	ach.CheckingPrenoteDebit:               "Checking prenote debit",
+	ach.CheckingZeroDollarRemittanceDebit:  "Checking zero dollar debit",
+	ach.SavingsReturnNOCCredit:             "Savings return/NOC credit",
+	ach.SavingsCredit:                      "Savings credit",
+	ach.SavingsPrenoteCredit:               "Savings prenote credit",
+	ach.SavingsZeroDollarRemittanceCredit:  "Savings zero dollar credit",
+	ach.SavingsReturnNOCDebit:              "Savings return/NOC debit",
+	ach.SavingsDebit:                       "Savings debit",
+	ach.SavingsPrenoteDebit:                "Savings prenote debit",
+	ach.SavingsZeroDollarRemittanceDebit:   "Savings zero dollar debit",
+	ach.GLReturnNOCCredit:                  "GL return/NOC credit",
+	ach.GLCredit:                           "GL credit",
+	ach.GLPrenoteCredit:                    "GL prenote credit",
+	ach.GLZeroDollarRemittanceCredit:       "GL zero dollar credit",
+	ach.GLReturnNOCDebit:                   "GL return/NOC debit",
+	ach.GLDebit:                            "GL debit",
+	ach.GLPrenoteDebit:                     "GL prenote debit",
+	ach.GLZeroDollarRemittanceDebit:        "GL zero dollar debit",
+	ach.LoanReturnNOCCredit:                "Loan return/NOC credit",
+	ach.LoanCredit:                         "Loan credit",
+	ach.LoanPrenoteCredit:                  "Loan prenote credit",
+	ach.LoanZeroDollarRemittanceCredit:     "Loan zero dollar credit",
+	ach.LoanDebit:                          "Loan debit (reversal)",
+	ach.LoanReturnNOCDebit:                 "Loan return/NOC debit",
+}
+
+// TransactionCodeDescription describes a transaction code, for example 22 is "Checking credit"
+func TransactionCodeDescription(code int) string {
+	if desc, ok := transactionCodes[code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("Unknown transaction code %d", code)
+}
@@ -0,0 +1,130 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+func readFile(t *testing.T, name string) *ach.File {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &file
+}
+
+func TestNew(t *testing.T) {
+	r := New(readFile(t, "ppd-mixedDebitCredit.ach"))
+	if len(r.Discrepancies) != 0 {
+		t.Errorf("unexpected discrepancies: %v", r.Discrepancies)
+	}
+	if r.Totals != r.Control {
+		t.Errorf("Totals=%#v Control=%#v", r.Totals, r.Control)
+	}
+	b := r.Batches[0]
+	if b.StandardEntryClassCode != ach.PPD || b.EntryCount != len(b.Entries) || b.TotalDebit == 0 || b.TotalCredit == 0 {
+		t.Errorf("unexpected batch: %#v", b)
+	}
+	for _, e := range b.Entries {
+		if e.AccountNumber[0] != '*' || e.Description == "" {
+			t.Errorf("unexpected entry: %#v", e)
+		}
+	}
+}
+
+func TestNew__returns(t *testing.T) {
+	r := New(readFile(t, "return-WEB.ach"))
+	e := r.Batches[0].Entries[0]
+	if len(e.Addenda) != 1 || e.Addenda[0] != "Return R01 Insufficient Funds (original trace 091400600000001)" {
+		t.Errorf("unexpected addenda: %v", e.Addenda)
+	}
+	if len(r.Discrepancies) != 0 {
+		t.Errorf("unexpected discrepancies: %v", r.Discrepancies)
+	}
+
+	r = New(readFile(t, "cor-example.ach"))
+	if e := r.Batches[0].Entries[0]; len(e.Addenda) != 1 || e.Addenda[0] != "NOC C01 Incorrect bank account number: 1918171614" {
+		t.Errorf("unexpected addenda: %v", e.Addenda)
+	}
+}
+
+func TestNew__IAT(t *testing.T) {
+	r := New(readFile(t, "iat-mixedDebitCredit.ach"))
+	if len(r.Discrepancies) != 0 {
+		t.Errorf("unexpected discrepancies: %v", r.Discrepancies)
+	}
+	if b := r.Batches[0]; b.CompanyName == "" || b.Entries[0].Name == "" {
+		t.Errorf("unexpected batch: %#v", b)
+	}
+}
+
+func TestNew__discrepancies(t *testing.T) {
+	file := readFile(t, "ppd-debit.ach")
+	file.Control.TotalDebitEntryDollarAmountInFile += 100
+	file.Control.EntryAddendaCount++
+	r := New(file)
+	if len(r.Discrepancies) != 2 {
+		t.Fatalf("unexpected discrepancies: %v", r.Discrepancies)
+	}
+	if r.Discrepancies[1] != "TotalDebitEntryDollarAmountInFile: entries total $1,000,000.00 but FileControl has $1,000,001.00" {
+		t.Errorf("got %q", r.Discrepancies[1])
+	}
+}
+
+func TestMaskAccount(t *testing.T) {
+	cases := map[string]string{
+		"744-5678-99      ": "*******8-99",
+		"12345678":          "****5678",
+		"1234":              "****",
+		"":                  "",
+	}
+	for account, expected := range cases {
+		if got := MaskAccount(account); got != expected {
+			t.Errorf("%q: got %q expected %q", account, got, expected)
+		}
+	}
+}
+
+func TestDollars(t *testing.T) {
+	cases := map[int]string{0: "$0.00", 5: "$0.05", 100000: "$1,000.00", 123456789: "$1,234,567.89", -2500: "-$25.00"}
+	for cents, expected := range cases {
+		if got := Dollars(cents); got != expected {
+			t.Errorf("%d: got %q expected %q", cents, got, expected)
+		}
+	}
+}
+
+func TestTransactionCodeDescription(t *testing.T) {
+	if desc := TransactionCodeDescription(ach.CheckingCredit); desc != "Checking credit" {
+		t.Errorf("got %q", desc)
+	}
+	if desc := TransactionCodeDescription(99); desc != "Unknown transaction code 99" {
+		t.Errorf("got %q", desc)
+	}
+}
@@ -0,0 +1,85 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ourly/ach"
+)
+
+// Text renders the report of a file as plain text
+func Text(w io.Writer, file *ach.File) error {
+	return New(file).WriteText(w)
+}
+
+// WriteText renders the report as plain text
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ACH File Report\n\n")
+	fmt.Fprintf(tw, "Destination:\t%s %s\n", r.Header.ImmediateDestination, r.Header.ImmediateDestinationName)
+	fmt.Fprintf(tw, "Origin:\t%s %s\n", r.Header.ImmediateOrigin, r.Header.ImmediateOriginName)
+	fmt.Fprintf(tw, "Created:\t%s\n", r.Header.FileCreation)
+	fmt.Fprintf(tw, "File ID Modifier:\t%s\n", r.Header.FileIDModifier)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, b := range r.Batches {
+		fmt.Fprintf(w, "\nBatch %d: %s (%s) %s %s, effective %s\n", b.Number, b.CompanyName, b.CompanyIdentification,
+			b.StandardEntryClassCode, b.CompanyEntryDescription, b.EffectiveEntryDate)
+		fmt.Fprintf(w, "  %d entries, debits %s, credits %s\n\n", b.EntryCount, Dollars(b.TotalDebit), Dollars(b.TotalCredit))
+
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "  Trace\tCode\tDescription\tRouting\tAccount\tName\tID\t%16s\n", "Amount")
+		for _, e := range b.Entries {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\t%s\t%s\t%16s\n", e.TraceNumber, e.TransactionCode, e.Description,
+				e.RoutingNumber, e.AccountNumber, e.Name, e.IdentificationNumber, Dollars(e.Amount))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		for _, e := range b.Entries {
+			for _, a := range e.Addenda {
+				fmt.Fprintf(w, "  %s addenda: %s\n", e.TraceNumber, a)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nFile Totals\n\n")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  \t%16s\t%16s\n", "Entries", "FileControl")
+	fmt.Fprintf(tw, "  Batches\t%16d\t%16d\n", r.Totals.BatchCount, r.Control.BatchCount)
+	fmt.Fprintf(tw, "  Entries and addenda\t%16d\t%16d\n", r.Totals.EntryAddendaCount, r.Control.EntryAddendaCount)
+	fmt.Fprintf(tw, "  Entry hash\t%16d\t%16d\n", r.Totals.EntryHash, r.Control.EntryHash)
+	fmt.Fprintf(tw, "  Debits\t%16s\t%16s\n", Dollars(r.Totals.TotalDebit), Dollars(r.Control.TotalDebit))
+	fmt.Fprintf(tw, "  Credits\t%16s\t%16s\n", Dollars(r.Totals.TotalCredit), Dollars(r.Control.TotalCredit))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Discrepancies) == 0 {
+		_, err := fmt.Fprintf(w, "\nTotals reconcile with the FileControl\n")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nDISCREPANCIES\n  %s\n", strings.Join(r.Discrepancies, "\n  "))
+	return err
+}
@@ -0,0 +1,67 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	file := readFile(t, "ppd-debit.ach")
+	var buf bytes.Buffer
+	if err := Text(&buf, file); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"Destination:       231380104 Federal Reserve Bank",
+		"Batch 1: Name on Account (121042882) PPD REG.SALARY, effective 2019-06-25",
+		"1 entries, debits $1,000,000.00, credits $0.00",
+		"Checking debit",
+		"****5678",
+		"Totals reconcile with the FileControl",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "12345678") {
+		t.Error("account number not masked")
+	}
+
+	file.Control.BatchCount = 2
+	buf.Reset()
+	if err := Text(&buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "DISCREPANCIES\n  BatchCount: entries total 1 but FileControl has 2") {
+		t.Errorf("missing discrepancy in\n%s", buf.String())
+	}
+}
+
+func TestText__addenda(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Text(&buf, readFile(t, "return-WEB.ach")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "091000017611242 addenda: Return R01 Insufficient Funds") {
+		t.Errorf("missing addenda in\n%s", buf.String())
+	}
+}
@@ -0,0 +1,441 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SECEntry is implemented by the typed entry views (POPEntry, SHREntry, CTXEntry, CIEEntry
+// and ARCEntry) which name the EntryDetail fields each Standard Entry Class overloads.
+type SECEntry interface {
+	// EntryDetail returns the EntryDetail with the view's fields written into their ACH positions.
+	EntryDetail() (*EntryDetail, error)
+}
+
+// EntryAccount holds the EntryDetail fields which have the same meaning for every Standard Entry Class.
+type EntryAccount struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id,omitempty"`
+	// TransactionCode if the receivers account is checking, savings, general ledger (GL) or loan.
+	TransactionCode int `json:"transactionCode"`
+	// RDFIIdentification is the RDFI's routing number without the last digit.
+	RDFIIdentification string `json:"RDFIIdentification"`
+	// CheckDigit the last digit of the RDFI's routing number
+	CheckDigit string `json:"checkDigit"`
+	// DFIAccountNumber is the receiver's bank account number you are crediting/debiting.
+	DFIAccountNumber string `json:"DFIAccountNumber"`
+	// Amount Number of cents you are debiting/crediting this account
+	Amount int `json:"amount"`
+	// TraceNumber assigned by the ODFI, computed by Batch.Create() when blank
+	TraceNumber string `json:"traceNumber,omitempty"`
+}
+
+func entryAccount(ed *EntryDetail) EntryAccount {
+	return EntryAccount{
+		ID:                 ed.ID,
+		TransactionCode:    ed.TransactionCode,
+		RDFIIdentification: ed.RDFIIdentification,
+		CheckDigit:         ed.CheckDigit,
+		DFIAccountNumber:   strings.TrimSpace(ed.DFIAccountNumber),
+		Amount:             ed.Amount,
+		TraceNumber:        ed.TraceNumber,
+	}
+}
+
+func (acct EntryAccount) entryDetail() *EntryDetail {
+	ed := NewEntryDetail()
+	ed.ID = acct.ID
+	ed.TransactionCode = acct.TransactionCode
+	ed.RDFIIdentification = acct.RDFIIdentification
+	ed.CheckDigit = acct.CheckDigit
+	ed.DFIAccountNumber = acct.DFIAccountNumber
+	ed.Amount = acct.Amount
+	ed.TraceNumber = acct.TraceNumber
+	ed.Category = CategoryForward
+	return ed
+}
+
+// lengthCheck is a value and the maximum length of the field it is written to
+type lengthCheck struct {
+	name  string
+	value string
+	max   int
+}
+
+// checkFieldLengths returns an error for the first value longer than its maximum length.
+func checkFieldLengths(fields ...lengthCheck) error {
+	for _, f := range fields {
+		if len(f.value) > f.max {
+			return fieldError(f.name, NewErrValidFieldLength(f.max), f.value)
+		}
+	}
+	return nil
+}
+
+// POPEntry is a Point-of-Purchase entry. The check serial number, terminal city and
+// terminal state are written into IdentificationNumber.
+type POPEntry struct {
+	EntryAccount
+	// CheckSerialNumber is the serial number of the source check, up to 9 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+	// TerminalCity is an abbreviation of the city of the electronic terminal, up to 4 characters.
+	TerminalCity string `json:"terminalCity"`
+	// TerminalState is the state of the electronic terminal.
+	TerminalState string `json:"terminalState"`
+	// IndividualName is the name of the receiver.
+	IndividualName string `json:"individualName,omitempty"`
+	// DiscretionaryData of significance only to the ODFI
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+}
+
+// POPEntryFromEntry returns the POPEntry view of a POP EntryDetail.
+func POPEntryFromEntry(ed *EntryDetail) (*POPEntry, error) {
+	if ed == nil {
+		return nil, fieldError("EntryDetail", ErrFieldInclusion)
+	}
+	padded := *ed
+	padded.IdentificationNumber = ed.IdentificationNumberField()
+	return &POPEntry{
+		EntryAccount:      entryAccount(ed),
+		CheckSerialNumber: padded.POPCheckSerialNumberField(),
+		TerminalCity:      padded.POPTerminalCityField(),
+		TerminalState:     padded.POPTerminalStateField(),
+		IndividualName:    strings.TrimSpace(ed.IndividualName),
+		DiscretionaryData: strings.TrimSpace(ed.DiscretionaryData),
+	}, nil
+}
+
+// EntryDetail returns the POP EntryDetail for e.
+func (e *POPEntry) EntryDetail() (*EntryDetail, error) {
+	err := checkFieldLengths(
+		lengthCheck{"CheckSerialNumber", e.CheckSerialNumber, 9},
+		lengthCheck{"TerminalCity", e.TerminalCity, 4},
+		lengthCheck{"TerminalState", e.TerminalState, 2},
+		lengthCheck{"IndividualName", e.IndividualName, 22},
+		lengthCheck{"DiscretionaryData", e.DiscretionaryData, 2},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ed := e.EntryAccount.entryDetail()
+	ed.SetPOPCheckSerialNumber(e.CheckSerialNumber)
+	ed.SetPOPTerminalCity(e.TerminalCity)
+	ed.SetPOPTerminalState(e.TerminalState)
+	ed.IndividualName = e.IndividualName
+	ed.DiscretionaryData = e.DiscretionaryData
+	return ed, nil
+}
+
+// SHREntry is a Shared Network Transaction entry. The card expiration date and document
+// reference number are written into IdentificationNumber and the card account number
+// into IndividualName.
+type SHREntry struct {
+	EntryAccount
+	// CardExpirationDate is the MMYY expiration date of the card.
+	CardExpirationDate string `json:"cardExpirationDate"`
+	// DocumentReferenceNumber is the 11 digit reference number of the transaction.
+	DocumentReferenceNumber string `json:"documentReferenceNumber"`
+	// IndividualCardAccountNumber is the card account number, up to 22 digits.
+	IndividualCardAccountNumber string `json:"individualCardAccountNumber"`
+	// CardTransactionTypeCode is written into DiscretionaryData.
+	CardTransactionTypeCode string `json:"cardTransactionTypeCode,omitempty"`
+	// Addenda02 holds the terminal information of the transaction.
+	Addenda02 *Addenda02 `json:"addenda02,omitempty"`
+}
+
+// SHREntryFromEntry returns the SHREntry view of a SHR EntryDetail.
+func SHREntryFromEntry(ed *EntryDetail) (*SHREntry, error) {
+	if ed == nil {
+		return nil, fieldError("EntryDetail", ErrFieldInclusion)
+	}
+	padded := *ed
+	padded.IdentificationNumber = ed.IdentificationNumberField()
+	return &SHREntry{
+		EntryAccount:                entryAccount(ed),
+		CardExpirationDate:          padded.SHRCardExpirationDateField(),
+		DocumentReferenceNumber:     padded.SHRDocumentReferenceNumberField(),
+		IndividualCardAccountNumber: ed.SHRIndividualCardAccountNumberField(),
+		CardTransactionTypeCode:     strings.TrimSpace(ed.DiscretionaryData),
+		Addenda02:                   ed.Addenda02,
+	}, nil
+}
+
+// EntryDetail returns the SHR EntryDetail for e.
+func (e *SHREntry) EntryDetail() (*EntryDetail, error) {
+	err := checkFieldLengths(
+		lengthCheck{"CardExpirationDate", e.CardExpirationDate, 4},
+		lengthCheck{"DocumentReferenceNumber", e.DocumentReferenceNumber, 11},
+		lengthCheck{"IndividualCardAccountNumber", e.IndividualCardAccountNumber, 22},
+		lengthCheck{"CardTransactionTypeCode", e.CardTransactionTypeCode, 2},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ed := e.EntryAccount.entryDetail()
+	ed.SetSHRCardExpirationDate(e.CardExpirationDate)
+	ed.SetSHRDocumentReferenceNumber(e.DocumentReferenceNumber)
+	ed.SetSHRIndividualCardAccountNumber(e.IndividualCardAccountNumber)
+	ed.DiscretionaryData = e.CardTransactionTypeCode
+	if e.Addenda02 != nil {
+		ed.Addenda02 = e.Addenda02
+		ed.AddendaRecordIndicator = 1
+	}
+	return ed, nil
+}
+
+// CTXEntry is a Corporate Trade Exchange entry. The addenda count and receiving company
+// are written into IndividualName.
+type CTXEntry struct {
+	EntryAccount
+	// IdentificationNumber is used by the originator to identify the entry.
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+	// ReceivingCompany is the name of the receiver, up to 16 characters.
+	ReceivingCompany string `json:"receivingCompany"`
+	// DiscretionaryData of significance only to the ODFI
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+	// Addenda05 holds the remittance information. Their count is written as the entry's addenda records.
+	Addenda05 []*Addenda05 `json:"addenda05,omitempty"`
+}
+
+// CTXEntryFromEntry returns the CTXEntry view of a CTX EntryDetail.
+func CTXEntryFromEntry(ed *EntryDetail) (*CTXEntry, error) {
+	if ed == nil {
+		return nil, fieldError("EntryDetail", ErrFieldInclusion)
+	}
+	padded := *ed
+	padded.IndividualName = ed.IndividualNameField()
+	return &CTXEntry{
+		EntryAccount:         entryAccount(ed),
+		IdentificationNumber: strings.TrimSpace(ed.IdentificationNumber),
+		ReceivingCompany:     padded.CATXReceivingCompanyField(),
+		DiscretionaryData:    strings.TrimSpace(ed.DiscretionaryData),
+		Addenda05:            ed.Addenda05,
+	}, nil
+}
+
+// EntryDetail returns the CTX EntryDetail for e.
+func (e *CTXEntry) EntryDetail() (*EntryDetail, error) {
+	err := checkFieldLengths(
+		lengthCheck{"IdentificationNumber", e.IdentificationNumber, 15},
+		lengthCheck{"ReceivingCompany", e.ReceivingCompany, 16},
+		lengthCheck{"DiscretionaryData", e.DiscretionaryData, 2},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(e.Addenda05); n > 9999 {
+		return nil, fieldError("Addenda05", NewErrValidFieldLength(9999), n)
+	}
+	ed := e.EntryAccount.entryDetail()
+	ed.IdentificationNumber = e.IdentificationNumber
+	ed.SetCATXAddendaRecords(len(e.Addenda05))
+	ed.SetCATXReceivingCompany(e.ReceivingCompany)
+	ed.DiscretionaryData = e.DiscretionaryData
+	for _, addenda05 := range e.Addenda05 {
+		ed.AddAddenda05(addenda05)
+	}
+	if len(ed.Addenda05) > 0 {
+		ed.AddendaRecordIndicator = 1
+	}
+	return ed, nil
+}
+
+// CIEEntry is a Customer Initiated Entry. The individual's name is written into
+// IdentificationNumber (positions 40-54) and their identification number into
+// IndividualName (positions 55-76).
+type CIEEntry struct {
+	EntryAccount
+	// IndividualName is the name of the consumer who initiated the entry, up to 15 characters.
+	IndividualName string `json:"individualName"`
+	// IndividualIdentificationNumber identifies the consumer to the receiving company, up to 22 characters.
+	IndividualIdentificationNumber string `json:"individualIdentificationNumber"`
+	// DiscretionaryData of significance only to the ODFI
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+	// Addenda05 holds the payment related information of the entry.
+	Addenda05 *Addenda05 `json:"addenda05,omitempty"`
+}
+
+// CIEEntryFromEntry returns the CIEEntry view of a CIE EntryDetail.
+func CIEEntryFromEntry(ed *EntryDetail) (*CIEEntry, error) {
+	if ed == nil {
+		return nil, fieldError("EntryDetail", ErrFieldInclusion)
+	}
+	if len(ed.Addenda05) > 1 {
+		return nil, fmt.Errorf("CIE: %v", NewErrBatchRequiredAddendaCount(len(ed.Addenda05), 1))
+	}
+	e := &CIEEntry{
+		EntryAccount:                   entryAccount(ed),
+		IndividualName:                 strings.TrimSpace(ed.IdentificationNumber),
+		IndividualIdentificationNumber: strings.TrimSpace(ed.IndividualName),
+		DiscretionaryData:              strings.TrimSpace(ed.DiscretionaryData),
+	}
+	if len(ed.Addenda05) == 1 {
+		e.Addenda05 = ed.Addenda05[0]
+	}
+	return e, nil
+}
+
+// EntryDetail returns the CIE EntryDetail for e.
+func (e *CIEEntry) EntryDetail() (*EntryDetail, error) {
+	err := checkFieldLengths(
+		lengthCheck{"IndividualName", e.IndividualName, 15},
+		lengthCheck{"IndividualIdentificationNumber", e.IndividualIdentificationNumber, 22},
+		lengthCheck{"DiscretionaryData", e.DiscretionaryData, 2},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ed := e.EntryAccount.entryDetail()
+	ed.IdentificationNumber = e.IndividualName
+	ed.IndividualName = e.IndividualIdentificationNumber
+	ed.DiscretionaryData = e.DiscretionaryData
+	if e.Addenda05 != nil {
+		ed.AddAddenda05(e.Addenda05)
+		ed.AddendaRecordIndicator = 1
+	}
+	return ed, nil
+}
+
+// ARCEntry is an Accounts Receivable entry converted from a mailed check. The check
+// serial number is written into IdentificationNumber.
+type ARCEntry struct {
+	EntryAccount
+	// CheckSerialNumber is the serial number of the source check, up to 15 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+	// IndividualName is the name of the receiver.
+	IndividualName string `json:"individualName,omitempty"`
+	// DiscretionaryData of significance only to the ODFI
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+}
+
+// ARCEntryFromEntry returns the ARCEntry view of an ARC EntryDetail.
+func ARCEntryFromEntry(ed *EntryDetail) (*ARCEntry, error) {
+	if ed == nil {
+		return nil, fieldError("EntryDetail", ErrFieldInclusion)
+	}
+	return &ARCEntry{
+		EntryAccount:      entryAccount(ed),
+		CheckSerialNumber: strings.TrimSpace(ed.IdentificationNumber),
+		IndividualName:    strings.TrimSpace(ed.IndividualName),
+		DiscretionaryData: strings.TrimSpace(ed.DiscretionaryData),
+	}, nil
+}
+
+// EntryDetail returns the ARC EntryDetail for e.
+func (e *ARCEntry) EntryDetail() (*EntryDetail, error) {
+	err := checkFieldLengths(
+		lengthCheck{"CheckSerialNumber", e.CheckSerialNumber, 15},
+		lengthCheck{"IndividualName", e.IndividualName, 22},
+		lengthCheck{"DiscretionaryData", e.DiscretionaryData, 2},
+	)
+	if err != nil {
+		return nil, err
+	}
+	ed := e.EntryAccount.entryDetail()
+	ed.SetCheckSerialNumber(e.CheckSerialNumber)
+	ed.IndividualName = e.IndividualName
+	ed.DiscretionaryData = e.DiscretionaryData
+	return ed, nil
+}
+
+// secEntriesJSON holds the typed entry views FileFromJSON accepts alongside entryDetails
+type secEntriesJSON struct {
+	Batches []struct {
+		POPEntries []*POPEntry `json:"popEntries"`
+		SHREntries []*SHREntry `json:"shrEntries"`
+		CTXEntries []*CTXEntry `json:"ctxEntries"`
+		CIEEntries []*CIEEntry `json:"cieEntries"`
+		ARCEntries []*ARCEntry `json:"arcEntries"`
+	} `json:"batches"`
+}
+
+// entryDetails converts the typed views of batch i into EntryDetail records.
+func (sec *secEntriesJSON) entryDetails(i int) ([]*EntryDetail, error) {
+	if i >= len(sec.Batches) {
+		return nil, nil
+	}
+	b := sec.Batches[i]
+	var views []SECEntry
+	for _, e := range b.POPEntries {
+		if e != nil {
+			views = append(views, e)
+		}
+	}
+	for _, e := range b.SHREntries {
+		if e != nil {
+			views = append(views, e)
+		}
+	}
+	for _, e := range b.CTXEntries {
+		if e != nil {
+			views = append(views, e)
+		}
+	}
+	for _, e := range b.CIEEntries {
+		if e != nil {
+			views = append(views, e)
+		}
+	}
+	for _, e := range b.ARCEntries {
+		if e != nil {
+			views = append(views, e)
+		}
+	}
+	var entries []*EntryDetail
+	for _, v := range views {
+		ed, err := v.EntryDetail()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ed)
+	}
+	return entries, nil
+}
+
+// SECEntriesFromBatch returns the typed views of each entry in a POP, SHR, CTX, CIE or ARC batch.
+func SECEntriesFromBatch(batch Batcher) ([]SECEntry, error) {
+	if batch == nil || batch.GetHeader() == nil {
+		return nil, fieldError("BatchHeader", ErrFieldInclusion)
+	}
+	var from func(*EntryDetail) (SECEntry, error)
+	switch sec := batch.GetHeader().StandardEntryClassCode; sec {
+	case POP:
+		from = func(ed *EntryDetail) (SECEntry, error) { return POPEntryFromEntry(ed) }
+	case SHR:
+		from = func(ed *EntryDetail) (SECEntry, error) { return SHREntryFromEntry(ed) }
+	case CTX:
+		from = func(ed *EntryDetail) (SECEntry, error) { return CTXEntryFromEntry(ed) }
+	case CIE:
+		from = func(ed *EntryDetail) (SECEntry, error) { return CIEEntryFromEntry(ed) }
+	case ARC:
+		from = func(ed *EntryDetail) (SECEntry, error) { return ARCEntryFromEntry(ed) }
+	default:
+		return nil, fieldError("StandardEntryClassCode", ErrSECCode, sec)
+	}
+	var entries []SECEntry
+	for _, ed := range batch.GetEntries() {
+		e, err := from(ed)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
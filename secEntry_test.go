@@ -0,0 +1,197 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ach
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSECEntry__roundTrip(t *testing.T) {
+	cases := []struct {
+		sec   string
+		entry *EntryDetail
+		from  func(*EntryDetail) (SECEntry, error)
+	}{
+		{POP, mockPOPEntryDetail(), func(ed *EntryDetail) (SECEntry, error) { return POPEntryFromEntry(ed) }},
+		{SHR, mockBatchSHR().GetEntries()[0], func(ed *EntryDetail) (SECEntry, error) { return SHREntryFromEntry(ed) }},
+		{CTX, mockBatchCTX().GetEntries()[0], func(ed *EntryDetail) (SECEntry, error) { return CTXEntryFromEntry(ed) }},
+		{CIE, mockBatchCIE().GetEntries()[0], func(ed *EntryDetail) (SECEntry, error) { return CIEEntryFromEntry(ed) }},
+		{ARC, mockARCEntryDetail(), func(ed *EntryDetail) (SECEntry, error) { return ARCEntryFromEntry(ed) }},
+	}
+	for _, tc := range cases {
+		view, err := tc.from(tc.entry)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.sec, err)
+		}
+
+		// views survive a trip through JSON
+		bs, err := json.Marshal(view)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.sec, err)
+		}
+		decoded := reflect.New(reflect.TypeOf(view).Elem()).Interface().(SECEntry)
+		if err := json.Unmarshal(bs, decoded); err != nil {
+			t.Fatalf("%s: %v", tc.sec, err)
+		}
+		if again, _ := json.Marshal(decoded); string(again) != string(bs) {
+			t.Errorf("%s: expected %s got %s", tc.sec, bs, again)
+		}
+
+		ed, err := decoded.EntryDetail()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.sec, err)
+		}
+		if ed.String() != tc.entry.String() {
+			t.Errorf("%s:\nexpected %s\n     got %s", tc.sec, tc.entry.String(), ed.String())
+		}
+	}
+}
+
+func TestPOPEntry(t *testing.T) {
+	e, err := POPEntryFromEntry(mockPOPEntryDetail())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CheckSerialNumber != "123456789" || e.TerminalCity != "PHIL" || e.TerminalState != "PA" {
+		t.Errorf("unexpected POPEntry: %#v", e)
+	}
+
+	e.TerminalCity = "PHILADELPHIA"
+	if _, err := e.EntryDetail(); err == nil {
+		t.Error("expected error")
+	}
+
+	// short identification numbers are padded rather than sliced out of range
+	ed := mockPOPEntryDetail()
+	ed.IdentificationNumber = "1234"
+	if e, err = POPEntryFromEntry(ed); err != nil || e.CheckSerialNumber != "1234" || e.TerminalState != "" {
+		t.Errorf("POPEntry=%#v err=%v", e, err)
+	}
+}
+
+func TestSHREntry(t *testing.T) {
+	e, err := SHREntryFromEntry(mockBatchSHR().GetEntries()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CardExpirationDate != "0722" || e.DocumentReferenceNumber != "12345678910" || e.Addenda02 == nil {
+		t.Errorf("unexpected SHREntry: %#v", e)
+	}
+}
+
+func TestCTXEntry(t *testing.T) {
+	e := &CTXEntry{
+		EntryAccount: EntryAccount{
+			TransactionCode:    CheckingCredit,
+			RDFIIdentification: "23138010",
+			CheckDigit:         "4",
+			DFIAccountNumber:   "744-5678-99",
+			Amount:             25000,
+		},
+		ReceivingCompany: "Receiver Company",
+		Addenda05:        []*Addenda05{mockAddenda05(), mockAddenda05()},
+	}
+	ed, err := e.EntryDetail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.CATXAddendaRecordsField() != "0002" || ed.CATXReceivingCompanyField() != "Receiver Company" || ed.AddendaRecordIndicator != 1 {
+		t.Errorf("IndividualName=%q AddendaRecordIndicator=%d", ed.IndividualName, ed.AddendaRecordIndicator)
+	}
+
+	e.ReceivingCompany = "Receiver Company Incorporated"
+	if _, err := e.EntryDetail(); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCIEEntry(t *testing.T) {
+	e, err := CIEEntryFromEntry(mockCIEEntryDetail())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CIE swaps the positions of the name and identification number
+	if e.IndividualName != "45689033" || e.IndividualIdentificationNumber != "Receiver Account Name" {
+		t.Errorf("unexpected CIEEntry: %#v", e)
+	}
+
+	ed := mockCIEEntryDetail()
+	ed.AddAddenda05(mockAddenda05())
+	ed.AddAddenda05(mockAddenda05())
+	if _, err := CIEEntryFromEntry(ed); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSECEntriesFromBatch(t *testing.T) {
+	entries, err := SECEntriesFromBatch(mockBatchCTX())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if e, ok := entries[0].(*CTXEntry); !ok || e.ReceivingCompany != "Receiver Company" || len(e.Addenda05) != 1 {
+		t.Errorf("unexpected entry: %#v", entries[0])
+	}
+
+	if _, err := SECEntriesFromBatch(mockBatchPPD()); !errors.Is(err, ErrSECCode) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := SECEntriesFromBatch(nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFileFromJSON__SECEntries(t *testing.T) {
+	bs, err := ioutil.ReadFile(filepath.Join("test", "testdata", "sec-entries.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := FileFromJSON(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Batches) != 2 {
+		t.Fatalf("got %d batches", len(file.Batches))
+	}
+
+	pop := file.Batches[0].GetEntries()[0]
+	if pop.IdentificationNumber != "123456789PHILPA" || pop.TraceNumber != "121042880000001" {
+		t.Errorf("IdentificationNumber=%q TraceNumber=%q", pop.IdentificationNumber, pop.TraceNumber)
+	}
+	ctx := file.Batches[1].GetEntries()[0]
+	if ctx.CATXAddendaRecordsField() != "0002" || ctx.CATXReceivingCompanyField() != "Receiver Company" {
+		t.Errorf("IndividualName=%q", ctx.IndividualName)
+	}
+	if ctx.Addenda05[1].TypeCode != "05" || ctx.Addenda05[1].EntryDetailSequenceNumber != 1 {
+		t.Errorf("unexpected Addenda05: %#v", ctx.Addenda05[1])
+	}
+
+	// invalid views are returned as errors
+	bs = []byte(`{"fileHeader": {"immediateDestination": "231380104", "immediateOrigin": "121042882"},
+"batches": [{"batchHeader": {"standardEntryClassCode": "POP"}, "popEntries": [{"terminalState": "PENN"}]}]}`)
+	if _, err := FileFromJSON(bs); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -21,9 +21,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
 	moovhttp "github.com/ourly/base/http"
 
 	"github.com/go-kit/kit/endpoint"
@@ -39,13 +43,27 @@ type createBatchRequest struct {
 }
 
 type createBatchResponse struct {
-	ID  string `json:"id"`
-	Err error  `json:"error"`
+	ID         string             `json:"id"`
+	OFAC       *ofac.Report       `json:"ofac,omitempty"`
+	Violations []limits.Violation `json:"violations,omitempty"`
+	Err        error              `json:"error"`
 }
 
 func (r createBatchResponse) error() error { return r.Err }
 
-func createBatchEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+func (r createBatchResponse) errorDetails() map[string]interface{} {
+	details := make(map[string]interface{})
+	if r.OFAC != nil {
+		details["ofac"] = r.OFAC
+	}
+	if len(r.Violations) > 0 {
+		details["violations"] = r.Violations
+	}
+	return details
+}
+
+func createBatchEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(createBatchRequest)
 		if !ok {
@@ -55,6 +73,52 @@ func createBatchEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 			}, err
 		}
 
+		// Check routing numbers against the FedACH directory
+		if cfg.fedachDirectory != nil && req.Batch != nil {
+			if err := fedach.ValidateBatch(cfg.fedachDirectory, req.Batch); err != nil {
+				return createBatchResponse{Err: fmt.Errorf("%w: %v", errUnknownRoutingNumber, err)}, nil
+			}
+		}
+
+		// Check the limits of the batch's originator
+		if cfg.limiter != nil && req.Batch != nil {
+			if violations := cfg.limiter.EvaluateBatch(req.FileID, req.Batch); len(violations) > 0 {
+				if logger != nil {
+					for _, v := range violations {
+						logger.Log("batches", "createBatch", "file", req.FileID, "requestID", req.requestID, "limit", v.String())
+					}
+				}
+				return createBatchResponse{
+					Violations: violations,
+					Err:        fmt.Errorf("%w: %d violation(s)", errLimitsExceeded, len(violations)),
+				}, nil
+			}
+		}
+
+		// Screen parties against the OFAC SDN list
+		var report *ofac.Report
+		if cfg.ofacScreener != nil && req.Batch != nil {
+			rep, err := ofac.ScreenBatch(cfg.ofacScreener, req.Batch)
+			if err != nil {
+				return createBatchResponse{Err: err}, nil
+			}
+			if !rep.Empty() {
+				ofacHits.Add(float64(len(rep.Hits)))
+				if logger != nil {
+					for _, hit := range rep.Hits {
+						logger.Log("batches", "createBatch", "file", req.FileID, "requestID", req.requestID, "ofac", fmt.Sprintf("%s %q matched SDN %s", hit.Field, hit.Value, hit.Matches[0].EntityID), "traceNumber", hit.TraceNumber)
+					}
+				}
+				if cfg.ofacBlock {
+					return createBatchResponse{
+						OFAC: rep,
+						Err:  fmt.Errorf("%w: %d hit(s)", errOFACBlocked, len(rep.Hits)),
+					}, nil
+				}
+				report = rep
+			}
+		}
+
 		id, err := s.CreateBatch(req.FileID, req.Batch)
 
 		if logger != nil {
@@ -62,8 +126,9 @@ func createBatchEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 		}
 
 		return createBatchResponse{
-			ID:  id,
-			Err: err,
+			ID:   id,
+			OFAC: report,
+			Err:  err,
 		}, nil
 	}
 }
@@ -117,7 +182,8 @@ func decodeGetBatchesRequest(_ context.Context, r *http.Request) (interface{}, e
 	return req, nil
 }
 
-func getBatchesEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+func getBatchesEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(getBatchesRequest)
 		if !ok {
@@ -130,8 +196,17 @@ func getBatchesEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 		if logger != nil {
 			logger.Log("batches", "getBatches", "file", req.fileID, "requestID", req.requestID)
 		}
+		stored := s.GetBatches(req.fileID)
+		batches := make([]ach.Batcher, 0, len(stored))
+		for _, batch := range stored {
+			batch, err := redactBatch(cfg, batch)
+			if err != nil {
+				return getBatchesResponse{Err: err}, err
+			}
+			batches = append(batches, batch)
+		}
 		return getBatchesResponse{
-			Batches: s.GetBatches(req.fileID),
+			Batches: batches,
 			Err:     nil,
 		}, nil
 	}
@@ -170,7 +245,8 @@ func decodeGetBatchRequest(_ context.Context, r *http.Request) (interface{}, err
 	return req, nil
 }
 
-func getBatchEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+func getBatchEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(getBatchRequest)
 		if !ok {
@@ -185,6 +261,12 @@ func getBatchEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 		if logger != nil {
 			logger.Log("batches", "getBatche", "file", req.fileID, "requestID", req.requestID, "error", err)
 		}
+		if err == nil {
+			// never return the batch unredacted
+			if batch, err = redactBatch(cfg, batch); err != nil {
+				return getBatchResponse{Err: err}, err
+			}
+		}
 
 		return getBatchResponse{
 			Batch: batch,
@@ -21,12 +21,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
 
 	"github.com/go-kit/kit/log"
 )
@@ -114,6 +120,162 @@ func TestFiles__createBatchEndpoint(t *testing.T) {
 	}
 }
 
+func TestFiles__createBatchEndpoint__Limits(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+	svc := NewService(repo)
+	f := ach.NewFile()
+	f.ID = "foo"
+	if err := repo.StoreFile(f); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &limits.Config{Default: &limits.CompanyLimits{EntryLimit: 1000}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := limits.NewLimiter(cfg, LimiterFiles(repo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithLimits(limiter))
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(mockBatchWEB()); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/files/foo/batches", &body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Violations []limits.Violation `json:"violations"`
+		Error      string             `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Violations) != 1 || resp.Violations[0].Limit != limits.LimitEntry || resp.Violations[0].Max != 1000 {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+	if batches := svc.GetBatches("foo"); len(batches) != 0 {
+		t.Errorf("batch was stored: %d batches", len(batches))
+	}
+}
+
+func TestFiles__createBatchEndpoint__FedACH(t *testing.T) {
+	dir, err := fedach.Open(filepath.Join("..", "test", "testdata", "FedACHdir.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+	svc := NewService(repo)
+	f := ach.NewFile()
+	f.ID = "foo"
+	if err := repo.StoreFile(f); err != nil {
+		t.Fatal(err)
+	}
+	handler := MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithFedACHDirectory(dir))
+
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	file, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := file.Batches[0]
+
+	post := func(id string) *httptest.ResponseRecorder {
+		batch.SetID(id)
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(batch); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("POST", "/files/foo/batches", &body)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+	if w := post("batch1"); w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+
+	// passes the check digit but isn't a FedACH participant
+	batch.GetEntries()[0].SetRDFI("011000028")
+	w := post("batch2")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "RDFIIdentification routing number not found") {
+		t.Errorf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	if batches := svc.GetBatches("foo"); len(batches) != 1 {
+		t.Errorf("unknown RDFI batch was stored: %d batches", len(batches))
+	}
+}
+
+func TestFiles__createBatchEndpoint__OFAC(t *testing.T) {
+	screener, err := ofac.OpenSDNScreener(
+		filepath.Join("..", "test", "testdata", "ofac-sdn.csv"),
+		filepath.Join("..", "test", "testdata", "ofac-add.csv"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := mockBatchWEB()
+	batch.GetEntries()[0].IndividualName = "Nayif Hawatmeh"
+
+	for _, block := range []bool{false, true} {
+		repo := NewRepositoryInMemory(testTTLDuration, log.NewNopLogger())
+		svc := NewService(repo)
+		f := ach.NewFile()
+		f.ID = "foo"
+		if err := repo.StoreFile(f); err != nil {
+			t.Fatal(err)
+		}
+
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(batch); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("POST", "/files/foo/batches", &body)
+		w := httptest.NewRecorder()
+		MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithOFACScreener(screener, block)).ServeHTTP(w, req)
+		w.Flush()
+
+		if block {
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), errOFACBlocked.Error()) {
+				t.Errorf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"2674"`) {
+				t.Errorf("missing OFAC hits: %s", w.Body.String())
+			}
+			if batches := svc.GetBatches("foo"); len(batches) != 0 {
+				t.Errorf("blocked batch was stored: %d batches", len(batches))
+			}
+			continue
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			ID   string       `json:"id"`
+			OFAC *ofac.Report `json:"ofac"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.ID == "" || resp.OFAC == nil || len(resp.OFAC.Hits) != 1 || resp.OFAC.Hits[0].Matches[0].EntityID != "2674" {
+			t.Errorf("unexpected response: %#v", resp)
+		}
+	}
+}
+
 func TestFiles__decodeGetBatchesRequest(t *testing.T) {
 	f := ach.NewFile()
 	f.ID = "foo"
@@ -247,6 +409,49 @@ func TestFiles__getBatchEndpoint(t *testing.T) {
 	}
 }
 
+// getRedactedBatches requests path from a server redacting with the default policy and
+// checks the account number of the stored file isn't returned
+func getRedactedBatches(t *testing.T, path func(file *ach.File) string) {
+	t.Helper()
+	logger := log.NewNopLogger()
+	repo := NewRepositoryInMemory(testTTLDuration, logger)
+	router := MakeHTTPHandler(NewService(repo), repo, logger, WithRedaction(ach.DefaultRedactionPolicy))
+
+	file, account := readRedactionFile(t)
+	file.Batches[0].SetID("batch1")
+	repo.StoreFile(file)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path(file), nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), account) {
+		t.Errorf("account number %s returned: %s", account, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), ach.MaskAccountNumber(account)) {
+		t.Errorf("expected masked account number: %s", w.Body.String())
+	}
+
+	// the stored batches are unchanged
+	if batches := repo.FindAllBatches(file.ID); strings.TrimSpace(batches[0].GetEntries()[0].DFIAccountNumber) != account {
+		t.Error("stored batch was redacted")
+	}
+}
+
+func TestFiles__getBatchesEndpointRedaction(t *testing.T) {
+	getRedactedBatches(t, func(file *ach.File) string {
+		return fmt.Sprintf("/files/%s/batches", file.ID)
+	})
+}
+
+func TestFiles__getBatchEndpointRedaction(t *testing.T) {
+	getRedactedBatches(t, func(file *ach.File) string {
+		return fmt.Sprintf("/files/%s/batches/%s", file.ID, file.Batches[0].ID())
+	})
+}
+
 func TestFiles__decodeDeleteBatchRequest(t *testing.T) {
 	f := ach.NewFile()
 	f.ID = "foo"
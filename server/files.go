@@ -26,9 +26,16 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
+	"github.com/ourly/ach/pgp"
 	"github.com/ourly/base"
 	moovhttp "github.com/ourly/base/http"
 
@@ -49,8 +56,116 @@ var (
 		Name: "ach_files_deleted",
 		Help: "The number of ACH files deleted",
 	}, nil)
+
+	ofacHits = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "ach_ofac_hits",
+		Help: "The number of OFAC screening hits on created ACH files and batches",
+	}, nil)
+
+	errOFACBlocked = errors.New("file blocked by OFAC screening")
+
+	errMissingDiffFile = errors.New("missing a or b file ID")
+
+	errInvalidWriterOptions = errors.New("invalid file contents options")
+
+	errEncryptionDisabled = errors.New("file encryption is not configured")
+
+	errDuplicateFile = errors.New("file duplicates a previously created file")
+
+	errUnknownRoutingNumber = errors.New("routing numbers not found in FedACH directory")
+
+	errLimitsExceeded = errors.New("originator limits exceeded")
+)
+
+// WithLimits evaluates every created file and batch with l and rejects those exceeding a limit,
+// returning their violations.
+func WithLimits(l *limits.Limiter) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.limiter = l
+	}
+}
+
+// LimiterFiles returns the files of r and the time each was stored for a limits.Limiter.
+// Files received from an ODFI, such as returns, and files merged from other stored files
+// aren't counted towards daily limits.
+func LimiterFiles(r Repository) limits.Files {
+	return limiterFiles{r}
+}
+
+type limiterFiles struct {
+	Repository
+}
+
+func (f limiterFiles) FindAllFiles() []*ach.File {
+	var files []*ach.File
+	for _, file := range f.Repository.FindAllFiles() {
+		if meta, err := f.FindFileMetadata(file.ID); err == nil && (meta.Received || len(meta.MergedFrom) > 0) {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func (f limiterFiles) StoredAt(id string) (time.Time, error) {
+	meta, err := f.FindFileMetadata(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return meta.StoredAt, nil
+}
+
+// WithFedACHDirectory checks the ImmediateDestination and RDFI routing numbers of every created
+// file, and the RDFI routing numbers of every batch added to a file, against dir and rejects
+// those with unknown or inactive institutions. An empty ImmediateDestinationName is filled
+// in from the directory.
+func WithFedACHDirectory(dir *fedach.Directory) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.fedachDirectory = dir
+	}
+}
+
+// DuplicateMode is how created files which duplicate a stored file are handled, see WithDuplicateDetection
+type DuplicateMode string
+
+const (
+	// DuplicateWarn stores the file and returns its duplicates
+	DuplicateWarn DuplicateMode = "warn"
+	// DuplicateReject refuses to store the file
+	DuplicateReject DuplicateMode = "reject"
+	// DuplicateAssign gives a file with a duplicate header the next unused FileIDModifier
+	// and warns of duplicate trace numbers
+	DuplicateAssign DuplicateMode = "assign"
 )
 
+// ParseDuplicateMode reads a DuplicateMode of warn, reject or assign
+func ParseDuplicateMode(s string) (DuplicateMode, error) {
+	switch mode := DuplicateMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case DuplicateWarn, DuplicateReject, DuplicateAssign:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown duplicate mode %q", s)
+}
+
+// WithDuplicateDetection checks every created file against the stored files for a duplicate
+// FileHeader (same origin, destination, creation date and FileIDModifier) or duplicate
+// trace numbers and handles them according to mode.
+func WithDuplicateDetection(mode DuplicateMode) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.duplicates = mode
+	}
+}
+
+// WithOFACScreener screens every created file and every batch added to a file with s. Hits
+// are returned with the created ID unless block is true, in which case the file or batch
+// is rejected.
+func WithOFACScreener(s ofac.Screener, block bool) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.ofacScreener = s
+		cfg.ofacBlock = block
+	}
+}
+
 type createFileRequest struct {
 	File *ach.File
 
@@ -58,13 +173,32 @@ type createFileRequest struct {
 }
 
 type createFileResponse struct {
-	ID  string `json:"id"`
-	Err error  `json:"error"`
+	ID         string             `json:"id"`
+	OFAC       *ofac.Report       `json:"ofac,omitempty"`
+	Duplicates []ach.Duplicate    `json:"duplicates,omitempty"`
+	Violations []limits.Violation `json:"violations,omitempty"`
+	Err        error              `json:"error"`
 }
 
 func (r createFileResponse) error() error { return r.Err }
 
-func createFileEndpoint(s Service, r Repository, logger log.Logger) endpoint.Endpoint {
+func (r createFileResponse) errorDetails() map[string]interface{} {
+	details := make(map[string]interface{})
+	if r.OFAC != nil {
+		details["ofac"] = r.OFAC
+	}
+	if len(r.Duplicates) > 0 {
+		details["duplicates"] = r.Duplicates
+	}
+	if len(r.Violations) > 0 {
+		details["violations"] = r.Violations
+	}
+	return details
+}
+
+func createFileEndpoint(s Service, r Repository, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
+	modifiers := ach.NewModifierAllocator()
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(createFileRequest)
 		if !ok {
@@ -74,9 +208,79 @@ func createFileEndpoint(s Service, r Repository, logger log.Logger) endpoint.End
 			}, err
 		}
 
-		// record a metric for files created
-		if req.File != nil && req.File.Header.ImmediateDestination != "" && req.File.Header.ImmediateOrigin != "" {
-			filesCreated.With("destination", req.File.Header.ImmediateDestination, "origin", req.File.Header.ImmediateOrigin).Add(1)
+		// Check routing numbers against the FedACH directory
+		if cfg.fedachDirectory != nil && req.File != nil {
+			fedach.FillDestinationName(cfg.fedachDirectory, req.File)
+			if err := fedach.ValidateFile(cfg.fedachDirectory, req.File); err != nil {
+				return createFileResponse{Err: fmt.Errorf("%w: %v", errUnknownRoutingNumber, err)}, nil
+			}
+		}
+
+		// Check the limits of each originator
+		if cfg.limiter != nil && req.File != nil {
+			if violations := cfg.limiter.EvaluateFile(req.File); len(violations) > 0 {
+				if logger != nil {
+					for _, v := range violations {
+						logger.Log("files", "createFile", "requestID", req.requestID, "limit", v.String())
+					}
+				}
+				return createFileResponse{
+					Violations: violations,
+					Err:        fmt.Errorf("%w: %d violation(s)", errLimitsExceeded, len(violations)),
+				}, nil
+			}
+		}
+
+		// Screen parties against the OFAC SDN list
+		var report *ofac.Report
+		if cfg.ofacScreener != nil {
+			rep, err := ofac.ScreenFile(cfg.ofacScreener, req.File)
+			if err != nil {
+				return createFileResponse{Err: err}, nil
+			}
+			if !rep.Empty() {
+				ofacHits.Add(float64(len(rep.Hits)))
+				if logger != nil {
+					for _, hit := range rep.Hits {
+						logger.Log("files", "createFile", "requestID", req.requestID, "ofac", fmt.Sprintf("%s %q matched SDN %s", hit.Field, hit.Value, hit.Matches[0].EntityID), "traceNumber", hit.TraceNumber)
+					}
+				}
+				if cfg.ofacBlock {
+					return createFileResponse{
+						OFAC: rep,
+						Err:  fmt.Errorf("%w: %d hit(s)", errOFACBlocked, len(rep.Hits)),
+					}, nil
+				}
+				report = rep
+			}
+		}
+
+		// Check for duplicates of stored files
+		var dups []ach.Duplicate
+		if cfg.duplicates != "" {
+			stored := r.FindAllFiles()
+			if cfg.duplicates == DuplicateAssign {
+				modifiers.Observe(stored...)
+				if modifiers.Used(req.File.Header) {
+					if err := modifiers.Assign(req.File); err != nil {
+						return createFileResponse{Err: err}, nil
+					}
+				}
+			}
+			dups = ach.FindDuplicates(req.File, stored)
+			if len(dups) > 0 {
+				if logger != nil {
+					for _, dup := range dups {
+						logger.Log("files", "createFile", "requestID", req.requestID, "duplicate", dup.String())
+					}
+				}
+				if cfg.duplicates == DuplicateReject {
+					return createFileResponse{
+						Duplicates: dups,
+						Err:        fmt.Errorf("%w: %d duplicate(s)", errDuplicateFile, len(dups)),
+					}, nil
+				}
+			}
 		}
 
 		// Create a random file ID if none was provided
@@ -89,9 +293,16 @@ func createFileEndpoint(s Service, r Repository, logger log.Logger) endpoint.End
 			logger.Log("files", "createFile", "requestID", req.requestID, "error", err)
 		}
 
+		// record a metric for files created
+		if err == nil && req.File.Header.ImmediateDestination != "" && req.File.Header.ImmediateOrigin != "" {
+			filesCreated.With("destination", req.File.Header.ImmediateDestination, "origin", req.File.Header.ImmediateOrigin).Add(1)
+		}
+
 		return createFileResponse{
-			ID:  req.File.ID,
-			Err: err,
+			ID:         req.File.ID,
+			OFAC:       report,
+			Duplicates: dups,
+			Err:        err,
 		}, nil
 	}
 }
@@ -142,10 +353,20 @@ func (r getFilesResponse) count() int { return len(r.Files) }
 
 func (r getFilesResponse) error() error { return r.Err }
 
-func getFilesEndpoint(s Service) endpoint.Endpoint {
+func getFilesEndpoint(s Service, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, _ interface{}) (interface{}, error) {
+		stored := s.GetFiles()
+		files := make([]*ach.File, 0, len(stored))
+		for _, f := range stored {
+			f, err := redactFile(cfg, f)
+			if err != nil {
+				return getFilesResponse{Err: err}, err
+			}
+			files = append(files, f)
+		}
 		return getFilesResponse{
-			Files: s.GetFiles(),
+			Files: files,
 			Err:   nil,
 		}, nil
 	}
@@ -157,6 +378,30 @@ func decodeGetFilesRequest(_ context.Context, r *http.Request) (interface{}, err
 	}, nil
 }
 
+// WithRedaction applies policy to the files, batches and differences returned by the API.
+// File contents are never redacted as they are needed for transmission.
+func WithRedaction(policy ach.RedactionPolicy) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.redaction = &policy
+	}
+}
+
+// redactFile returns f redacted by the configured policy, or f itself without a policy
+func redactFile(cfg *handlerOptions, f *ach.File) (*ach.File, error) {
+	if f == nil || cfg.redaction == nil {
+		return f, nil
+	}
+	return f.Redact(*cfg.redaction)
+}
+
+// redactBatch returns batch redacted by the configured policy, or batch itself without a policy
+func redactBatch(cfg *handlerOptions, batch ach.Batcher) (ach.Batcher, error) {
+	if batch == nil || cfg.redaction == nil {
+		return batch, nil
+	}
+	return cfg.redaction.RedactBatch(batch)
+}
+
 type getFileRequest struct {
 	ID string
 
@@ -170,7 +415,8 @@ type getFileResponse struct {
 
 func (r getFileResponse) error() error { return r.Err }
 
-func getFileEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+func getFileEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(getFileRequest)
 		if !ok {
@@ -185,6 +431,10 @@ func getFileEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 		if logger != nil {
 			logger.Log("files", "getFile", "requestID", req.requestID, "error", err)
 		}
+		// never return the file unredacted
+		if f, err = redactFile(cfg, f); err != nil {
+			return getFileResponse{Err: err}, err
+		}
 
 		return getFileResponse{
 			File: f,
@@ -254,7 +504,10 @@ func decodeDeleteFileRequest(_ context.Context, r *http.Request) (interface{}, e
 }
 
 type getFileContentsRequest struct {
-	ID string
+	ID   string
+	Opts ach.WriterOptions
+	// Encrypt is the ID of the key the contents are encrypted to
+	Encrypt string
 
 	requestID string
 }
@@ -265,7 +518,8 @@ type getFileContentsResponse struct {
 
 func (v getFileContentsResponse) error() error { return v.Err }
 
-func getFileContentsEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
+func getFileContentsEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(getFileContentsRequest)
 		if !ok {
@@ -275,10 +529,13 @@ func getFileContentsEndpoint(s Service, logger log.Logger) endpoint.Endpoint {
 			}, err
 		}
 
-		r, err := s.GetFileContents(req.ID)
+		r, err := s.GetFileContents(req.ID, req.Opts)
+		if err == nil && req.Encrypt != "" {
+			r, err = encryptContents(r, cfg, req.Encrypt)
+		}
 
 		if logger != nil {
-			logger.Log("files", "getFileContents", "requestID", req.requestID, "error", err)
+			logger.Log("files", "getFileContents", "requestID", req.requestID, "encrypt", req.Encrypt, "error", err)
 		}
 		if err != nil {
 			return getFileContentsResponse{Err: err}, nil
@@ -294,12 +551,86 @@ func decodeGetFileContentsRequest(_ context.Context, r *http.Request) (interface
 	if !ok {
 		return nil, ErrBadRouting
 	}
+	opts, err := decodeWriterOptions(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
 	return getFileContentsRequest{
 		ID:        id,
+		Opts:      opts,
+		Encrypt:   r.URL.Query().Get("encrypt"),
 		requestID: moovhttp.GetRequestID(r),
 	}, nil
 }
 
+// WithEncryption loads the keys GET /files/{id}/contents?encrypt=<keyID> encrypts file
+// contents to. Encrypted contents are signed with signer unless it is nil.
+func WithEncryption(keyring *pgp.Keyring, signer *pgp.Keyring) HandlerOption {
+	return func(cfg *handlerOptions) {
+		cfg.encryptionKeys = keyring
+		cfg.signingKey = signer
+	}
+}
+
+// encryptContents encrypts r to the key keyID of the configured keyring
+func encryptContents(r io.Reader, cfg *handlerOptions, keyID string) (io.Reader, error) {
+	if cfg.encryptionKeys == nil {
+		return nil, errEncryptionDisabled
+	}
+	recipients, err := cfg.encryptionKeys.Select(keyID)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w, err := pgp.Encrypt(&buf, recipients, cfg.signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// decodeWriterOptions reads the lineEnding, padding, blockingFactor, finalLineEnding and
+// encoding query parameters. Missing parameters keep the NACHA defaults.
+func decodeWriterOptions(q url.Values) (ach.WriterOptions, error) {
+	var opts ach.WriterOptions
+	switch v := q.Get("lineEnding"); v {
+	case "", "lf":
+	case "crlf":
+		opts.LineEnding = "\r\n"
+	default:
+		return opts, fmt.Errorf("%w: unknown lineEnding %q", errInvalidWriterOptions, v)
+	}
+	for name, omit := range map[string]*bool{"padding": &opts.NoBlockPadding, "finalLineEnding": &opts.OmitFinalLineEnding} {
+		if v := q.Get(name); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return opts, fmt.Errorf("%w: %s %q is not a boolean", errInvalidWriterOptions, name, v)
+			}
+			*omit = !enabled
+		}
+	}
+	if v := q.Get("blockingFactor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 99 {
+			return opts, fmt.Errorf("%w: blockingFactor %q is not between 1 and 99", errInvalidWriterOptions, v)
+		}
+		opts.BlockingFactor = n
+	}
+	switch v := ach.Encoding(q.Get("encoding")); v {
+	case "", ach.EncodingASCII, ach.EncodingEBCDIC:
+		opts.Encoding = v
+	default:
+		return opts, fmt.Errorf("%w: unknown encoding %q", errInvalidWriterOptions, v)
+	}
+	return opts, nil
+}
+
 type validateFileRequest struct {
 	ID string
 
@@ -514,3 +845,62 @@ func decodeFlattenBatchesRequest(_ context.Context, r *http.Request) (interface{
 		requestID: moovhttp.GetRequestID(r),
 	}, nil
 }
+
+type diffFilesRequest struct {
+	A string `json:"a"`
+	B string `json:"b"`
+
+	requestID string
+}
+
+type diffFilesResponse struct {
+	Diff *ach.FileDiff `json:"diff"`
+	Err  error         `json:"error"`
+}
+
+func (r diffFilesResponse) error() error { return r.Err }
+
+// diffFilesEndpoint describes what changed from file A to file B, e.g. when a partner resends
+// a corrected file.
+func diffFilesEndpoint(s Service, logger log.Logger, opts ...HandlerOption) endpoint.Endpoint {
+	cfg := newHandlerOptions(opts)
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(diffFilesRequest)
+		if !ok {
+			err := errors.New("invalid request")
+			return diffFilesResponse{Err: err}, err
+		}
+		a, err := s.GetFile(req.A)
+		if err != nil {
+			return diffFilesResponse{Err: err}, nil
+		}
+		b, err := s.GetFile(req.B)
+		if err != nil {
+			return diffFilesResponse{Err: err}, nil
+		}
+		// compare the redacted files so changed values are redacted as well
+		if a, err = redactFile(cfg, a); err != nil {
+			return diffFilesResponse{Err: err}, err
+		}
+		if b, err = redactFile(cfg, b); err != nil {
+			return diffFilesResponse{Err: err}, err
+		}
+		d := ach.Diff(a, b)
+		if logger != nil {
+			logger.Log("files", "diffFiles", "requestID", req.requestID, "a", req.A, "b", req.B, "batches", len(d.Batches))
+		}
+		return diffFilesResponse{Diff: d}, nil
+	}
+}
+
+func decodeDiffFilesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req diffFilesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.A == "" || req.B == "" {
+		return nil, errMissingDiffFile
+	}
+	req.requestID = moovhttp.GetRequestID(r)
+	return req, nil
+}
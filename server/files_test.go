@@ -20,6 +20,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -29,10 +30,17 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
+	"github.com/ourly/ach/pgp"
 	"github.com/ourly/base"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/go-kit/kit/log"
 	httptransport "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
@@ -86,6 +94,364 @@ func TestFiles__createFileEndpoint(t *testing.T) {
 	}
 }
 
+func TestFiles__createFileEndpoint__OFAC(t *testing.T) {
+	screener, err := ofac.OpenSDNScreener(
+		filepath.Join("..", "test", "testdata", "ofac-sdn.csv"),
+		filepath.Join("..", "test", "testdata", "ofac-add.csv"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := ach.NewFile()
+	f.Header = *mockFileHeader()
+	f.AddBatch(mockBatchWEB())
+	f.Batches[0].GetEntries()[0].IndividualName = "Nayif Hawatmeh"
+
+	for _, block := range []bool{false, true} {
+		repo := NewRepositoryInMemory(testTTLDuration, nil)
+		svc := NewService(repo)
+
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(f); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("POST", "/files/create", &body)
+		req.Header.Set("content-type", "application/json")
+
+		w := httptest.NewRecorder()
+		MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithOFACScreener(screener, block)).ServeHTTP(w, req)
+		w.Flush()
+
+		if block {
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), errOFACBlocked.Error()) {
+				t.Errorf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"2674"`) {
+				t.Errorf("missing OFAC hits: %s", w.Body.String())
+			}
+			if files := svc.GetFiles(); len(files) != 0 {
+				t.Errorf("blocked file was stored: %d files", len(files))
+			}
+			continue
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			ID   string       `json:"id"`
+			OFAC *ofac.Report `json:"ofac"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.ID == "" || len(resp.OFAC.Hits) != 1 || resp.OFAC.Hits[0].Matches[0].EntityID != "2674" {
+			t.Errorf("unexpected response: %#v", resp)
+		}
+	}
+}
+
+func TestFiles__createFileEndpoint__Duplicates(t *testing.T) {
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(handler http.Handler) (int, createFileResponse) {
+		req := httptest.NewRequest("POST", "/files/create", bytes.NewReader(bs))
+		req.Header.Set("content-type", "text/plain")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		w.Flush()
+
+		var resp struct {
+			ID         string          `json:"id"`
+			Duplicates []ach.Duplicate `json:"duplicates"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, createFileResponse{ID: resp.ID, Duplicates: resp.Duplicates}
+	}
+
+	for _, mode := range []DuplicateMode{DuplicateWarn, DuplicateReject, DuplicateAssign} {
+		repo := NewRepositoryInMemory(testTTLDuration, nil)
+		svc := NewService(repo)
+		handler := MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithDuplicateDetection(mode))
+
+		code, first := create(handler)
+		if code != http.StatusOK || len(first.Duplicates) != 0 {
+			t.Fatalf("%s: unexpected first response %d: %#v", mode, code, first)
+		}
+
+		code, second := create(handler)
+		switch mode {
+		case DuplicateWarn:
+			if code != http.StatusOK || second.ID == "" || len(second.Duplicates) != 2 {
+				t.Errorf("%s: unexpected response %d: %#v", mode, code, second)
+			}
+		case DuplicateReject:
+			if code != http.StatusBadRequest || len(second.Duplicates) != 2 {
+				t.Errorf("%s: unexpected response %d: %#v", mode, code, second)
+			}
+			if files := svc.GetFiles(); len(files) != 1 {
+				t.Errorf("%s: duplicate file was stored: %d files", mode, len(files))
+			}
+		case DuplicateAssign:
+			// only the trace number is duplicated once the header has a new modifier
+			if code != http.StatusOK || len(second.Duplicates) != 1 || second.Duplicates[0].TraceNumber == "" {
+				t.Fatalf("%s: unexpected response %d: %#v", mode, code, second)
+			}
+			file, err := svc.GetFile(second.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.Header.FileIDModifier != "B" {
+				t.Errorf("%s: FileIDModifier=%s", mode, file.Header.FileIDModifier)
+			}
+		}
+	}
+
+	if _, err := ParseDuplicateMode("Reject"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseDuplicateMode("ignore"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFiles__createFileEndpoint__FedACH(t *testing.T) {
+	dir, err := fedach.Open(filepath.Join("..", "test", "testdata", "FedACHdir.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	svc := NewService(repo)
+	handler := MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithFedACHDirectory(dir))
+
+	fd, err := os.Open(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := ach.NewReader(fd).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Header.ImmediateDestinationName = ""
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	file, _ := createAndGetContents(t, handler, "application/json", body.Bytes())
+	if file.Header.ImmediateDestinationName != "CITADEL FEDERAL CREDIT" {
+		t.Errorf("ImmediateDestinationName=%q", file.Header.ImmediateDestinationName)
+	}
+
+	// passes the check digit but isn't a FedACH participant
+	f.Header.ImmediateDestination = "011000028"
+	body.Reset()
+	if err := json.NewEncoder(&body).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/files/create", &body)
+	req.Header.Set("content-type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "ImmediateDestination routing number not found") {
+		t.Errorf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFiles__createFileEndpoint__Limits(t *testing.T) {
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	svc := NewService(repo)
+
+	cfg := &limits.Config{
+		Companies: []limits.CompanyLimits{{CompanyIdentification: "121042882", FileDebitLimit: 100}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := limits.NewLimiter(cfg, LimiterFiles(repo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := MakeHTTPHandler(svc, repo, log.NewNopLogger(), WithLimits(limiter))
+
+	req := httptest.NewRequest("POST", "/files/create", bytes.NewReader(bs))
+	req.Header.Set("content-type", "text/plain")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), errLimitsExceeded.Error()) {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Violations []limits.Violation `json:"violations"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Violations) != 1 || resp.Violations[0].Limit != limits.LimitFileDebit || resp.Violations[0].CompanyIdentification != "121042882" {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+	if files := svc.GetFiles(); len(files) != 0 {
+		t.Errorf("file was stored: %d files", len(files))
+	}
+
+	cfg.Companies[0].FileDebitLimit = 100000000
+	createAndGetContents(t, handler, "text/plain", bs)
+}
+
+func TestLimiterFiles(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	created, received := ach.NewFile(), ach.NewFile()
+	created.ID, received.ID = "created", "received"
+	repo.StoreFile(created)
+	repo.StoreFile(received)
+	repo.UpdateFileMetadata(received.ID, func(meta *FileMetadata) { meta.Received = true })
+
+	// received files, such as returns, don't count towards daily limits
+	files := LimiterFiles(repo)
+	if found := files.FindAllFiles(); len(found) != 1 || found[0].ID != created.ID {
+		t.Errorf("unexpected files: %v", found)
+	}
+	if at, err := files.StoredAt(created.ID); err != nil || at.IsZero() {
+		t.Errorf("StoredAt=%v: %v", at, err)
+	}
+}
+
+// createAndGetContents posts body to /files/create and returns the created file and its plaintext contents
+func createAndGetContents(t *testing.T, handler http.Handler, contentType string, body []byte) (*ach.File, string) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/files/create", bytes.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	var created createFileResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil || created.ID == "" {
+		t.Fatalf("unexpected response: %v (%v)", created, err)
+	}
+
+	// read the file back as JSON
+	req = httptest.NewRequest("GET", fmt.Sprintf("/files/%s", created.ID), nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	var wrapper struct {
+		File json.RawMessage `json:"file"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&wrapper); err != nil {
+		t.Fatal(err)
+	}
+	file, err := ach.FileFromJSON(wrapper.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// and as plaintext
+	req = httptest.NewRequest("GET", fmt.Sprintf("/files/%s/contents", created.ID), nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	return file, w.Body.String()
+}
+
+func TestFiles__createFileEndpoint__ADV(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	handler := MakeHTTPHandler(NewService(repo), repo, log.NewNopLogger())
+
+	// plaintext round trip
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "ach-adv-read", "adv-read.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, contents := createAndGetContents(t, handler, "text/plain", bs)
+	if !file.IsADV() || len(file.Batches[0].GetADVEntries()) != 2 {
+		t.Fatalf("expected ADV file: %#v", file)
+	}
+	read, err := ach.NewReader(bytes.NewReader(bs)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ach.NewWriter(&buf).Write(&read); err != nil {
+		t.Fatal(err)
+	}
+	if contents != buf.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", buf.String(), contents)
+	}
+
+	// JSON round trip
+	bs, err = ioutil.ReadFile(filepath.Join("..", "test", "testdata", "adv-valid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, contents = createAndGetContents(t, handler, "application/json", bs)
+	if !file.IsADV() || file.ADVControl.EntryAddendaCount != 1 {
+		t.Errorf("unexpected ADVFileControl: %#v", file.ADVControl)
+	}
+	expected, err := ach.FileFromJSON(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := ach.NewWriter(&buf).Write(expected); err != nil {
+		t.Fatal(err)
+	}
+	if contents != buf.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", buf.String(), contents)
+	}
+
+	// a built ADV file round trips through the server and reader
+	fh := ach.NewFileHeader()
+	fh.ImmediateDestination = "231380104"
+	fh.ImmediateOrigin = "121042882"
+	fh.FileCreationDate = "190220"
+	fh.ImmediateDestinationName = "Federal Reserve Bank"
+	fh.ImmediateOriginName = "My Bank Name"
+	bh := ach.NewBatchHeader()
+	bh.CompanyName = "Your Company, inc"
+	bh.CompanyIdentification = "121042882"
+	bh.CompanyEntryDescription = "Accounting"
+	bh.ODFIIdentification = "12104288"
+	built, err := ach.NewADVFileFromPostings(fh, bh, []*ach.ADVPosting{
+		{RoutingNumber: "231380104", AccountNumber: "744-5678-99", Name: "Settlement", Amount: 50000, Date: time.Date(2019, time.February, 19, 0, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := ach.NewWriter(&buf).Write(built); err != nil {
+		t.Fatal(err)
+	}
+	file, contents = createAndGetContents(t, handler, "text/plain", buf.Bytes())
+	if contents != buf.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", buf.String(), contents)
+	}
+	if entry := file.Batches[0].GetADVEntries()[0]; entry.JulianDay != 50 || entry.SequenceNumber != 1 {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+}
+
 func TestFiles__getFilesEndpoint(t *testing.T) {
 	repo := NewRepositoryInMemory(testTTLDuration, nil)
 	svc := NewService(repo)
@@ -716,3 +1082,298 @@ func TestFiles__flattenFileEndpointError(t *testing.T) {
 		t.Errorf("bogus HTTP status: %d", w.Code)
 	}
 }
+
+func TestFiles__diffFilesEndpoint(t *testing.T) {
+	logger := log.NewNopLogger()
+	repo := NewRepositoryInMemory(testTTLDuration, logger)
+	svc := NewService(repo)
+	router := MakeHTTPHandler(svc, repo, logger)
+
+	for i, name := range []string{"ppd-mixedDebitCredit.ach", "ppd-mixedDebitCredit-corrected.ach"} {
+		fd, err := os.Open(filepath.Join("..", "test", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := ach.NewReader(fd).Read()
+		fd.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.ID = fmt.Sprintf("file%d", i)
+		if err := repo.StoreFile(&file); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/files/diff", strings.NewReader(`{"a": "file0", "b": "file1"}`))
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	var resp diffFilesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Diff == nil || len(resp.Diff.Header) != 1 || len(resp.Diff.Batches) != 1 {
+		t.Errorf("unexpected diff: %#v", resp.Diff)
+	}
+
+	// unknown file
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/files/diff", strings.NewReader(`{"a": "file0", "b": "missing"}`))
+	router.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	// missing file ID
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/files/diff", strings.NewReader(`{"a": "file0"}`))
+	router.ServeHTTP(w, req)
+	w.Flush()
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+}
+
+func TestFiles__getFileEndpointRedaction(t *testing.T) {
+	logger := log.NewNopLogger()
+	repo := NewRepositoryInMemory(testTTLDuration, logger)
+	svc := NewService(repo)
+	router := MakeHTTPHandler(svc, repo, logger, WithRedaction(ach.DefaultRedactionPolicy))
+
+	file, account := readRedactionFile(t)
+	repo.StoreFile(file)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/files/%s", file.ID), nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), account) {
+		t.Errorf("account number %s returned: %s", account, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), ach.MaskAccountNumber(account)) {
+		t.Errorf("expected masked account number: %s", w.Body.String())
+	}
+
+	// contents are never redacted
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/files/%s/contents", file.ID), nil))
+	w.Flush()
+	if !strings.Contains(w.Body.String(), account) {
+		t.Errorf("expected account number %s in contents: %s", account, w.Body.String())
+	}
+
+	// the stored file is unchanged
+	if f, _ := repo.FindFile(file.ID); strings.TrimSpace(f.Batches[0].GetEntries()[0].DFIAccountNumber) != account {
+		t.Error("stored file was redacted")
+	}
+
+	// files which can't be redacted aren't returned
+	bh := *file.Batches[0].GetHeader()
+	bh.StandardEntryClassCode = "ZZZ"
+	file.AddBatch(&ach.Batch{Header: &bh, Entries: file.Batches[0].GetEntries()})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/files/%s", file.ID), nil))
+	w.Flush()
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), account) {
+		t.Errorf("bogus HTTP status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// readRedactionFile returns a file with account numbers to redact and the account
+// number of its first entry
+func readRedactionFile(t *testing.T) (*ach.File, string) {
+	t.Helper()
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "ppd-mixedDebitCredit-valid.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := ach.FileFromJSON(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, strings.TrimSpace(file.Batches[0].GetEntries()[0].DFIAccountNumber)
+}
+
+func TestFiles__getFilesEndpointRedaction(t *testing.T) {
+	logger := log.NewNopLogger()
+	repo := NewRepositoryInMemory(testTTLDuration, logger)
+	router := MakeHTTPHandler(NewService(repo), repo, logger, WithRedaction(ach.DefaultRedactionPolicy))
+
+	file, account := readRedactionFile(t)
+	repo.StoreFile(file)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/files", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), account) {
+		t.Errorf("account number %s returned: %s", account, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), ach.MaskAccountNumber(account)) {
+		t.Errorf("expected masked account number: %s", w.Body.String())
+	}
+}
+
+func TestFiles__diffFilesEndpointRedaction(t *testing.T) {
+	logger := log.NewNopLogger()
+	repo := NewRepositoryInMemory(testTTLDuration, logger)
+	router := MakeHTTPHandler(NewService(repo), repo, logger, WithRedaction(ach.DefaultRedactionPolicy))
+
+	a, account := readRedactionFile(t)
+	a.ID = "a"
+	repo.StoreFile(a)
+	b, _ := readRedactionFile(t)
+	b.ID = "b"
+	b.Batches[0].GetEntries()[0].Amount++
+	repo.StoreFile(b)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/files/diff", strings.NewReader(`{"a": "a", "b": "b"}`)))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), account) {
+		t.Errorf("account number %s returned: %s", account, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), ach.MaskAccountNumber(account)) {
+		t.Errorf("expected masked account number: %s", w.Body.String())
+	}
+}
+
+func TestFiles__getFileContentsEndpoint__Options(t *testing.T) {
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	handler := MakeHTTPHandler(NewService(repo), repo, log.NewNopLogger())
+
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, _ := createAndGetContents(t, handler, "text/plain", bs)
+
+	contents := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/files/%s/contents?%s", created.ID, query), nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+
+	w := contents("lineEnding=crlf&padding=false&finalLineEnding=false")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	lines := strings.Split(w.Body.String(), "\r\n")
+	if len(lines) != 5 || strings.HasSuffix(w.Body.String(), "\n") {
+		t.Errorf("unexpected contents: %q", w.Body.String())
+	}
+
+	w = contents("blockingFactor=1&encoding=ebcdic")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	file, err := ach.NewReader(w.Body).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Control.BlockCount != 5 {
+		t.Errorf("unexpected BlockCount %d", file.Control.BlockCount)
+	}
+	if line := file.Header.String(); line[37:39] != "01" {
+		t.Errorf("unexpected blocking factor %q", line[37:39])
+	}
+
+	// the stored file keeps its blocking factor
+	w = contents("")
+	if lines := strings.Split(w.Body.String(), "\n"); len(lines) < 1 || lines[0][37:39] != "10" {
+		t.Errorf("unexpected FileHeader: %q", lines[0])
+	}
+
+	for _, query := range []string{"lineEnding=cr", "padding=maybe", "blockingFactor=0", "encoding=utf16"} {
+		if w := contents(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus HTTP status code: %d", query, w.Code)
+		}
+	}
+}
+
+// throwawayKeyring generates a small OpenPGP key and returns keyrings of its public and private keys
+func throwawayKeyring(t *testing.T, email string) (*pgp.Keyring, *pgp.Keyring) {
+	t.Helper()
+	e, err := openpgp.NewEntity("test", "", email, &packet.Config{RSABits: 1024, DefaultHash: crypto.SHA256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SerializePrivate signs the identities including their preferred hash
+	var public, private bytes.Buffer
+	if err := e.SerializePrivate(&private, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Serialize(&public); err != nil {
+		t.Fatal(err)
+	}
+	publicKeys, err := pgp.ReadKeyring(&public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeys, err := pgp.ReadKeyring(&private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return publicKeys, privateKeys
+}
+
+func TestFiles__getFileContentsEndpoint__Encrypt(t *testing.T) {
+	odfiPublic, odfiPrivate := throwawayKeyring(t, "ach@odfi.example.com")
+	signerPublic, signerPrivate := throwawayKeyring(t, "ach@originator.example.com")
+
+	repo := NewRepositoryInMemory(testTTLDuration, nil)
+	handler := MakeHTTPHandler(NewService(repo), repo, log.NewNopLogger(), WithEncryption(odfiPublic, signerPrivate))
+
+	bs, err := ioutil.ReadFile(filepath.Join("..", "test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, _ := createAndGetContents(t, handler, "text/plain", bs)
+
+	contents := func(handler http.Handler, keyID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/files/%s/contents?encrypt=%s", created.ID, keyID), nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+
+	w := contents(handler, "ach@odfi.example.com")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status code: %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Body.String(), "-----BEGIN PGP MESSAGE-----") {
+		t.Fatalf("unexpected contents:\n%s", w.Body.String())
+	}
+	file, err := pgp.ReadFile(w.Body, odfiPrivate, signerPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Header.ImmediateOrigin != created.Header.ImmediateOrigin {
+		t.Errorf("unexpected file header: %#v", file.Header)
+	}
+
+	if w := contents(handler, "nobody@example.com"); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status code: %d", w.Code)
+	}
+	handler = MakeHTTPHandler(NewService(repo), repo, log.NewNopLogger())
+	if w := contents(handler, "ach@odfi.example.com"); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status code: %d", w.Code)
+	}
+}
@@ -20,6 +20,7 @@ package server
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -34,15 +35,64 @@ type Repository interface {
 	FindFile(id string) (*ach.File, error)
 	FindAllFiles() []*ach.File
 	DeleteFile(id string) error
+	FindFileMetadata(id string) (*FileMetadata, error)
+	UpdateFileMetadata(id string, update func(*FileMetadata)) error
 	StoreBatch(fileID string, batch ach.Batcher) error
 	FindBatch(fileID string, batchID string) (ach.Batcher, error)
 	FindAllBatches(fileID string) []ach.Batcher
 	DeleteBatch(fileID string, batchID string) error
 }
 
+// FileMetadata is recorded by the Repository alongside each stored file
+type FileMetadata struct {
+	// StoredAt is when the file was stored, unlike the FileCreationDate and
+	// FileCreationTime which are supplied by clients.
+	StoredAt time.Time `json:"storedAt"`
+
+	// Sent is where the file was delivered, e.g. the remote path of an SFTP upload,
+	// and empty until then.
+	Sent string `json:"sent,omitempty"`
+
+	// Received is true for files downloaded from an ODFI, which are never sent.
+	Received bool `json:"received,omitempty"`
+
+	// MergedInto lists the files this file was merged into when released, which are
+	// sent in its place.
+	MergedInto []string `json:"mergedInto,omitempty"`
+
+	// MergedFrom lists the files a released file was merged from. Merged files keep
+	// the FileIDModifier they were given for the day.
+	MergedFrom []string `json:"mergedFrom,omitempty"`
+}
+
+// PendingFiles returns the files of r for destination which haven't been sent, merged into
+// other files or received, oldest first.
+func PendingFiles(r Repository, destination string) []*ach.File {
+	var pending []*ach.File
+	for _, file := range r.FindAllFiles() {
+		if file.Header.ImmediateDestination != destination {
+			continue
+		}
+		meta, err := r.FindFileMetadata(file.ID)
+		if err != nil || meta.Sent != "" || meta.Received || len(meta.MergedInto) > 0 {
+			continue
+		}
+		pending = append(pending, file)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		hi, hj := pending[i].Header, pending[j].Header
+		if hi.FileCreationDate+hi.FileCreationTime != hj.FileCreationDate+hj.FileCreationTime {
+			return hi.FileCreationDate+hi.FileCreationTime < hj.FileCreationDate+hj.FileCreationTime
+		}
+		return pending[i].ID < pending[j].ID
+	})
+	return pending
+}
+
 type repositoryInMemory struct {
-	mtx   sync.RWMutex
-	files map[string]*ach.File
+	mtx      sync.RWMutex
+	files    map[string]*ach.File
+	metadata map[string]*FileMetadata
 
 	ttl time.Duration
 
@@ -52,9 +102,10 @@ type repositoryInMemory struct {
 // NewRepositoryInMemory is an in memory ach storage repository for files
 func NewRepositoryInMemory(ttl time.Duration, logger log.Logger) Repository {
 	repo := &repositoryInMemory{
-		files:  make(map[string]*ach.File),
-		ttl:    ttl,
-		logger: logger,
+		files:    make(map[string]*ach.File),
+		metadata: make(map[string]*FileMetadata),
+		ttl:      ttl,
+		logger:   logger,
 	}
 
 	if ttl <= 0*time.Second {
@@ -84,6 +135,7 @@ func (r *repositoryInMemory) StoreFile(f *ach.File) error {
 		return ErrAlreadyExists
 	}
 	r.files[f.ID] = f
+	r.metadata[f.ID] = &FileMetadata{StoredAt: time.Now()}
 	return nil
 }
 
@@ -112,6 +164,31 @@ func (r *repositoryInMemory) DeleteFile(id string) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	delete(r.files, id)
+	delete(r.metadata, id)
+	return nil
+}
+
+// FindFileMetadata returns a copy of the FileMetadata of the file with id
+func (r *repositoryInMemory) FindFileMetadata(id string) (*FileMetadata, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if meta, ok := r.metadata[id]; ok {
+		cp := *meta
+		return &cp, nil
+	}
+	return nil, ErrNotFound
+}
+
+// UpdateFileMetadata calls update with the FileMetadata of the file with id while holding
+// the lock, so read-modify-write updates aren't lost.
+func (r *repositoryInMemory) UpdateFileMetadata(id string, update func(*FileMetadata)) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	meta, ok := r.metadata[id]
+	if !ok {
+		return ErrNotFound
+	}
+	update(meta)
 	return nil
 }
 
@@ -207,6 +284,7 @@ func (r *repositoryInMemory) cleanupOldFiles() {
 		if r.files[i].Header.FileCreationDate < tooOldStr {
 			removed++
 			delete(r.files, i)
+			delete(r.metadata, i)
 		}
 	}
 
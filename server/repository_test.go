@@ -61,6 +61,48 @@ func TestRepositoryFiles(t *testing.T) {
 	}
 }
 
+func TestRepository__FileMetadata(t *testing.T) {
+	r := NewRepositoryInMemory(testTTLDuration, nil)
+
+	if _, err := r.FindFileMetadata("missing"); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := r.UpdateFileMetadata("missing", func(*FileMetadata) {}); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	f := &ach.File{
+		ID:     base.ID(),
+		Header: *mockFileHeader(),
+	}
+	start := time.Now()
+	if err := r.StoreFile(f); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := r.FindFileMetadata(f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.StoredAt.Before(start) || meta.Sent != "" || meta.Received {
+		t.Errorf("unexpected metadata: %#v", meta)
+	}
+
+	if err := r.UpdateFileMetadata(f.ID, func(m *FileMetadata) { m.Sent = "outbound/file.ach" }); err != nil {
+		t.Fatal(err)
+	}
+	meta.Sent = "ignored" // FindFileMetadata returns a copy
+	if meta, _ := r.FindFileMetadata(f.ID); meta.Sent != "outbound/file.ach" {
+		t.Errorf("sent=%q", meta.Sent)
+	}
+
+	if err := r.DeleteFile(f.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.FindFileMetadata(f.ID); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestRepositoryBatches(t *testing.T) {
 	r := NewRepositoryInMemory(testTTLDuration, nil)
 
@@ -28,6 +28,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ourly/ach"
+	"github.com/ourly/ach/fedach"
+	"github.com/ourly/ach/limits"
+	"github.com/ourly/ach/ofac"
+	"github.com/ourly/ach/pgp"
 	moovhttp "github.com/ourly/base/http"
 
 	"github.com/go-kit/kit/endpoint"
@@ -95,7 +100,40 @@ func preflightHandler(options []httptransport.ServerOption) http.Handler {
 	)
 }
 
-func MakeHTTPHandler(s Service, repo Repository, logger log.Logger) http.Handler {
+// HandlerOption configures optional behavior of the http.Handler returned by MakeHTTPHandler.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	// ofacScreener checks files on creation, see WithOFACScreener
+	ofacScreener ofac.Screener
+	ofacBlock    bool
+
+	// redaction hides account numbers and personal data of returned files, see WithRedaction
+	redaction *ach.RedactionPolicy
+
+	// encryptionKeys and signingKey encrypt file contents, see WithEncryption
+	encryptionKeys *pgp.Keyring
+	signingKey     *pgp.Keyring
+
+	// duplicates checks created files against stored files, see WithDuplicateDetection
+	duplicates DuplicateMode
+
+	// fedachDirectory checks routing numbers of created files, see WithFedACHDirectory
+	fedachDirectory *fedach.Directory
+
+	// limiter checks created files and batches against originator limits, see WithLimits
+	limiter *limits.Limiter
+}
+
+func newHandlerOptions(opts []HandlerOption) *handlerOptions {
+	cfg := &handlerOptions{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func MakeHTTPHandler(s Service, repo Repository, logger log.Logger, opts ...HandlerOption) http.Handler {
 	r := mux.NewRouter()
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
@@ -116,25 +154,31 @@ func MakeHTTPHandler(s Service, repo Repository, logger log.Logger) http.Handler
 	    os.Exit(1)
 	})
 	r.Methods("GET").Path("/files").Handler(httptransport.NewServer(
-		getFilesEndpoint(s),
+		getFilesEndpoint(s, opts...),
 		decodeGetFilesRequest,
 		encodeResponse,
 		options...,
 	))
 	r.Methods("POST").Path("/files/create").Handler(httptransport.NewServer(
-		createFileEndpoint(s, repo, logger),
+		createFileEndpoint(s, repo, logger, opts...),
 		decodeCreateFileRequest,
 		encodeResponse,
 		options...,
 	))
+	r.Methods("POST").Path("/files/diff").Handler(httptransport.NewServer(
+		diffFilesEndpoint(s, logger, opts...),
+		decodeDiffFilesRequest,
+		encodeResponse,
+		options...,
+	))
 	r.Methods("GET").Path("/files/{id}").Handler(httptransport.NewServer(
-		getFileEndpoint(s, logger),
+		getFileEndpoint(s, logger, opts...),
 		decodeGetFileRequest,
 		encodeResponse,
 		options...,
 	))
 	r.Methods("GET").Path("/files/{id}/contents").Handler(httptransport.NewServer(
-		getFileContentsEndpoint(s, logger),
+		getFileContentsEndpoint(s, logger, opts...),
 		decodeGetFileContentsRequest,
 		encodeTextResponse,
 		options...,
@@ -152,19 +196,19 @@ func MakeHTTPHandler(s Service, repo Repository, logger log.Logger) http.Handler
 		options...,
 	))
 	r.Methods("POST").Path("/files/{fileID}/batches").Handler(httptransport.NewServer(
-		createBatchEndpoint(s, logger),
+		createBatchEndpoint(s, logger, opts...),
 		decodeCreateBatchRequest,
 		encodeResponse,
 		options...,
 	))
 	r.Methods("GET").Path("/files/{fileID}/batches").Handler(httptransport.NewServer(
-		getBatchesEndpoint(s, logger),
+		getBatchesEndpoint(s, logger, opts...),
 		decodeGetBatchesRequest,
 		encodeResponse,
 		options...,
 	))
 	r.Methods("GET").Path("/files/{fileID}/batches/{batchID}").Handler(httptransport.NewServer(
-		getBatchEndpoint(s, logger),
+		getBatchEndpoint(s, logger, opts...),
 		decodeGetBatchRequest,
 		encodeResponse,
 		options...,
@@ -204,6 +248,12 @@ type errorer interface {
 	error() error
 }
 
+// errorDetailer is implemented by responses which return more than the error message when
+// they fail, e.g. the OFAC hits of a blocked file.
+type errorDetailer interface {
+	errorDetails() map[string]interface{}
+}
+
 // counter is implemented by any concrete response types that may contain
 // some arbitrary count information.
 type counter interface {
@@ -218,6 +268,13 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	if e, ok := response.(errorer); ok && e.error() != nil {
 		// Not a Go kit transport error, but a business-logic error.
 		// Provide those as HTTP errors.
+		if d, ok := response.(errorDetailer); ok {
+			body := d.errorDetails()
+			body["error"] = e.error().Error()
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(codeFrom(e.error()))
+			return json.NewEncoder(w).Encode(body)
+		}
 		encodeError(ctx, e.error(), w)
 		return nil
 	}
@@ -240,6 +297,10 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 // This method is designed text/plain content-types and expects response
 // to be an io.Reader.
 func encodeTextResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if e, ok := response.(errorer); ok && e.error() != nil {
+		encodeError(ctx, e.error(), w)
+		return nil
+	}
 	if r, ok := response.(io.Reader); ok {
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusOK)
@@ -269,6 +330,14 @@ func codeFrom(err error) int {
 		// This branch comes from validateFileEndpoint
 		return http.StatusBadRequest
 	}
+	if errors.Is(err, errOFACBlocked) || errors.Is(err, errMissingDiffFile) || errors.Is(err, errInvalidWriterOptions) ||
+		errors.Is(err, errDuplicateFile) || errors.Is(err, ach.ErrModifiersExhausted) || errors.Is(err, errUnknownRoutingNumber) ||
+		errors.Is(err, errLimitsExceeded) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, errEncryptionDisabled) || errors.Is(err, pgp.ErrKeyNotFound) {
+		return http.StatusBadRequest
+	}
 	switch err {
 	case ErrNotFound:
 		return http.StatusNotFound
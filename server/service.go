@@ -44,7 +44,8 @@ type Service interface {
 	// DeleteFile takes a file resource ID and deletes it from the store
 	DeleteFile(id string) error
 	// GetFileContents creates a valid plaintext file in memory assuming it has a FileHeader and at least one Batch record.
-	GetFileContents(id string) (io.Reader, error)
+	// The file is written with opts.
+	GetFileContents(id string, opts ach.WriterOptions) (io.Reader, error)
 	// ValidateFile
 	ValidateFile(id string) error
 	// BalanceFile will apply a given offset record to the file
@@ -114,18 +115,21 @@ func (s *service) DeleteFile(id string) error {
 	return s.store.DeleteFile(id)
 }
 
-func (s *service) GetFileContents(id string) (io.Reader, error) {
-	f, err := s.GetFile(id)
+func (s *service) GetFileContents(id string, opts ach.WriterOptions) (io.Reader, error) {
+	stored, err := s.GetFile(id)
 	if err != nil {
 		return nil, fmt.Errorf("problem reading file %s: %v", id, err)
 	}
-	if err := f.Create(); err != nil {
+	// tabulate a copy so the blocking factor of opts isn't kept in the stored FileHeader
+	f := *stored
+	if err := f.CreateWithOptions(opts); err != nil {
 		return nil, fmt.Errorf("problem creating file %s: %v", id, err)
 	}
 
 	var buf bytes.Buffer
 	w := ach.NewWriter(&buf)
-	if err := w.Write(f); err != nil {
+	w.SetOptions(opts)
+	if err := w.Write(&f); err != nil {
 		return nil, fmt.Errorf("problem writing plaintext file %s: %v", id, err)
 	}
 	if err := w.Flush(); err != nil {
@@ -120,7 +120,7 @@ func TestGetFileContents(t *testing.T) {
 	s.CreateBatch(id, batch)
 
 	// build file
-	r, err := s.GetFileContents(id)
+	r, err := s.GetFileContents(id, ach.WriterOptions{})
 	if err != nil {
 		if !strings.Contains(err.Error(), "mandatory ") {
 			t.Fatal(err.Error())
@@ -418,6 +418,20 @@ func (v *validator) isUpperAlphanumeric(s string) error {
 	return nil
 }
 
+// isTaxIdentificationNumber checks if a string is a nine digit Social Security Number
+// or Taxpayer Identification Number without separators
+func (v *validator) isTaxIdentificationNumber(s string) error {
+	if len(s) != 9 {
+		return ErrValidTaxIdentificationNumber
+	}
+	for i := range s {
+		if s[i] < '0' || s[i] > '9' {
+			return ErrValidTaxIdentificationNumber
+		}
+	}
+	return nil
+}
+
 // isAlphanumeric checks if a string only contains ASCII alphanumeric characters
 func (v *validator) isAlphanumeric(s string) error {
 	if alphanumericRegex.MatchString(s) {
@@ -19,7 +19,9 @@ package ach
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -31,6 +33,66 @@ import (
 type Writer struct {
 	w       *bufio.Writer
 	lineNum int //current line being written
+
+	opts WriterOptions
+}
+
+// WriterOptions configures optional behavior of a Writer. The zero value writes files as
+// NACHA requires them.
+type WriterOptions struct {
+	// Encoding of the file, ASCII by default. EBCDIC files are written as fixed 94 byte
+	// records without line endings unless LineEnding is set.
+	Encoding Encoding
+
+	// LineEnding ends each record, "\n" by default. Some ODFIs require "\r\n".
+	LineEnding string
+
+	// OmitFinalLineEnding leaves the last record of the file without a line ending
+	OmitFinalLineEnding bool
+
+	// NoBlockPadding doesn't fill the last block of the file with records of 9s
+	NoBlockPadding bool
+
+	// BlockingFactor is the number of records in a block, between 1 and 99, and is written
+	// into the FileHeader. The blocking factor of the FileHeader is used by default. When set,
+	// the FileControl BlockCount is written for this blocking factor, like
+	// File.CreateWithOptions computes it.
+	BlockingFactor int
+}
+
+// lineEnding returns the characters written after each record
+func (opts WriterOptions) lineEnding() string {
+	if opts.LineEnding != "" {
+		return opts.LineEnding
+	}
+	if opts.Encoding == EncodingEBCDIC {
+		return ""
+	}
+	return "\n"
+}
+
+// blockingFactor returns the number of records in a block of a file with fh
+func (opts WriterOptions) blockingFactor(fh FileHeader) int {
+	if opts.BlockingFactor > 0 {
+		return opts.BlockingFactor
+	}
+	if n, err := strconv.Atoi(fh.blockingFactor); err == nil && n > 0 {
+		return n
+	}
+	return 10
+}
+
+// header returns fh with the blocking factor of opts
+func (opts WriterOptions) header(fh FileHeader) FileHeader {
+	if opts.BlockingFactor > 0 {
+		fh.blockingFactor = fmt.Sprintf("%02d", opts.BlockingFactor)
+	}
+	return fh
+}
+
+// blockCount returns the number of blocks needed for records, counting a partial block
+func blockCount(records, blockingFactor int) int {
+	return (records + blockingFactor - 1) / blockingFactor
 }
 
 // NewWriter returns a new Writer that writes to w.
@@ -40,15 +102,41 @@ func NewWriter(w io.Writer) *Writer {
 	}
 }
 
+// SetOptions configures the Writer and must be called before Write.
+func (w *Writer) SetOptions(opts WriterOptions) {
+	w.opts = opts
+}
+
+// writeLine writes a record in the Writer's encoding, ending the previous record first
+func (w *Writer) writeLine(record string) error {
+	if w.lineNum > 0 {
+		record = w.opts.lineEnding() + record
+	}
+	return w.writeString(record)
+}
+
+func (w *Writer) writeString(s string) error {
+	if w.opts.Encoding == EncodingEBCDIC {
+		s = encodeEBCDIC(s)
+	}
+	_, err := w.w.WriteString(s)
+	return err
+}
+
 // Writer writes a single ach.file record to w
 func (w *Writer) Write(file *File) error {
 	if err := file.Validate(); err != nil {
 		return err
 	}
 
+	header := w.opts.header(file.Header)
+	if err := header.Validate(); err != nil {
+		return err
+	}
+
 	w.lineNum = 0
 	// Iterate over all records in the file
-	if _, err := w.w.WriteString(file.Header.String() + "\n"); err != nil {
+	if err := w.writeLine(header.String()); err != nil {
 		return err
 	}
 	w.lineNum++
@@ -61,20 +149,39 @@ func (w *Writer) Write(file *File) error {
 		return err
 	}
 
+	// the BlockCount of a file created with another blocking factor is written for this one
+	blocks := blockCount(w.lineNum+1, w.opts.blockingFactor(header))
 	if !file.IsADV() {
-		if _, err := w.w.WriteString(file.Control.String() + "\n"); err != nil {
+		control := file.Control
+		if w.opts.BlockingFactor > 0 {
+			control.BlockCount = blocks
+		}
+		if err := w.writeLine(control.String()); err != nil {
 			return err
 		}
 	} else {
-		if _, err := w.w.WriteString(file.ADVControl.String() + "\n"); err != nil {
+		control := file.ADVControl
+		if w.opts.BlockingFactor > 0 {
+			control.BlockCount = blocks
+		}
+		if err := w.writeLine(control.String()); err != nil {
 			return err
 		}
 	}
 	w.lineNum++
 
 	// pad the final block
-	for i := 0; i < (10-(w.lineNum%10)) && w.lineNum%10 != 0; i++ {
-		if _, err := w.w.WriteString(strings.Repeat("9", 94) + "\n"); err != nil {
+	if !w.opts.NoBlockPadding {
+		blockingFactor := w.opts.blockingFactor(file.Header)
+		for w.lineNum%blockingFactor != 0 {
+			if err := w.writeLine(strings.Repeat("9", 94)); err != nil {
+				return err
+			}
+			w.lineNum++
+		}
+	}
+	if !w.opts.OmitFinalLineEnding {
+		if err := w.writeString(w.opts.lineEnding()); err != nil {
 			return err
 		}
 	}
@@ -89,37 +196,37 @@ func (w *Writer) Flush() error {
 
 func (w *Writer) writeBatch(file *File) error {
 	for _, batch := range file.Batches {
-		if _, err := w.w.WriteString(batch.GetHeader().String() + "\n"); err != nil {
+		if err := w.writeLine(batch.GetHeader().String()); err != nil {
 			return err
 		}
 		w.lineNum++
 		if !file.IsADV() {
 			for _, entry := range batch.GetEntries() {
-				if _, err := w.w.WriteString(entry.String() + "\n"); err != nil {
+				if err := w.writeLine(entry.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 
 				if entry.Addenda02 != nil {
-					if _, err := w.w.WriteString(entry.Addenda02.String() + "\n"); err != nil {
+					if err := w.writeLine(entry.Addenda02.String()); err != nil {
 						return err
 					}
 					w.lineNum++
 				}
 				for _, addenda05 := range entry.Addenda05 {
-					if _, err := w.w.WriteString(addenda05.String() + "\n"); err != nil {
+					if err := w.writeLine(addenda05.String()); err != nil {
 						return err
 					}
 					w.lineNum++
 				}
 				if entry.Addenda98 != nil {
-					if _, err := w.w.WriteString(entry.Addenda98.String() + "\n"); err != nil {
+					if err := w.writeLine(entry.Addenda98.String()); err != nil {
 						return err
 					}
 					w.lineNum++
 				}
 				if entry.Addenda99 != nil {
-					if _, err := w.w.WriteString(entry.Addenda99.String() + "\n"); err != nil {
+					if err := w.writeLine(entry.Addenda99.String()); err != nil {
 						return err
 					}
 					w.lineNum++
@@ -127,12 +234,12 @@ func (w *Writer) writeBatch(file *File) error {
 			}
 		} else {
 			for _, entry := range batch.GetADVEntries() {
-				if _, err := w.w.WriteString(entry.String() + "\n"); err != nil {
+				if err := w.writeLine(entry.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 				if entry.Addenda99 != nil {
-					if _, err := w.w.WriteString(entry.Addenda99.String() + "\n"); err != nil {
+					if err := w.writeLine(entry.Addenda99.String()); err != nil {
 						return err
 					}
 					w.lineNum++
@@ -141,11 +248,11 @@ func (w *Writer) writeBatch(file *File) error {
 		}
 
 		if batch.GetHeader().StandardEntryClassCode != ADV {
-			if _, err := w.w.WriteString(batch.GetControl().String() + "\n"); err != nil {
+			if err := w.writeLine(batch.GetControl().String()); err != nil {
 				return err
 			}
 		} else {
-			if _, err := w.w.WriteString(batch.GetADVControl().String() + "\n"); err != nil {
+			if err := w.writeLine(batch.GetADVControl().String()); err != nil {
 				return err
 			}
 		}
@@ -156,71 +263,71 @@ func (w *Writer) writeBatch(file *File) error {
 
 func (w *Writer) writeIATBatch(file *File) error {
 	for _, iatBatch := range file.IATBatches {
-		if _, err := w.w.WriteString(iatBatch.GetHeader().String() + "\n"); err != nil {
+		if err := w.writeLine(iatBatch.GetHeader().String()); err != nil {
 			return err
 		}
 		w.lineNum++
 		for _, entry := range iatBatch.GetEntries() {
-			if _, err := w.w.WriteString(entry.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda10.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda10.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda11.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda11.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda12.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda12.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda13.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda13.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda14.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda14.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda15.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda15.String()); err != nil {
 				return err
 			}
 			w.lineNum++
-			if _, err := w.w.WriteString(entry.Addenda16.String() + "\n"); err != nil {
+			if err := w.writeLine(entry.Addenda16.String()); err != nil {
 				return err
 			}
 			w.lineNum++
 			// IAT Addenda17
 			for _, addenda17 := range entry.Addenda17 {
-				if _, err := w.w.WriteString(addenda17.String() + "\n"); err != nil {
+				if err := w.writeLine(addenda17.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 			}
 			// IAT Addenda18
 			for _, addenda18 := range entry.Addenda18 {
-				if _, err := w.w.WriteString(addenda18.String() + "\n"); err != nil {
+				if err := w.writeLine(addenda18.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 			}
 			if entry.Addenda98 != nil {
-				if _, err := w.w.WriteString(entry.Addenda98.String() + "\n"); err != nil {
+				if err := w.writeLine(entry.Addenda98.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 			}
 			if entry.Addenda99 != nil {
-				if _, err := w.w.WriteString(entry.Addenda99.String() + "\n"); err != nil {
+				if err := w.writeLine(entry.Addenda99.String()); err != nil {
 					return err
 				}
 				w.lineNum++
 			}
 		}
-		if _, err := w.w.WriteString(iatBatch.GetControl().String() + "\n"); err != nil {
+		if err := w.writeLine(iatBatch.GetControl().String()); err != nil {
 			return err
 		}
 		w.lineNum++
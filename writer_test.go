@@ -19,6 +19,8 @@ package ach
 
 import (
 	"bytes"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -681,3 +683,94 @@ func TestADVReturnWrite(t *testing.T) {
 		t.Errorf("%T: %s", err, err)
 	}
 }
+
+func TestWriter__Options(t *testing.T) {
+	file, err := readACHFilepath(filepath.Join("test", "testdata", "ppd-debit.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FileHeader, BatchHeader, entries and addenda, BatchControl and FileControl
+	records := 2 + 2 + file.Control.EntryAddendaCount
+
+	cases := []struct {
+		opts        WriterOptions
+		lines       int
+		blockCount  int
+		lineEnding  string
+		finalEnding bool
+	}{
+		{WriterOptions{}, 10, 1, "\n", true},
+		{WriterOptions{LineEnding: "\r\n"}, 10, 1, "\r\n", true},
+		{WriterOptions{NoBlockPadding: true}, records, 1, "\n", true},
+		{WriterOptions{NoBlockPadding: true, OmitFinalLineEnding: true}, records, 1, "\n", false},
+		{WriterOptions{BlockingFactor: 4}, 8, 2, "\n", true},
+		{WriterOptions{BlockingFactor: 1}, records, records, "\n", true},
+	}
+	for i, tc := range cases {
+		if err := file.CreateWithOptions(tc.opts); err != nil {
+			t.Fatal(err)
+		}
+		if file.Control.BlockCount != tc.blockCount {
+			t.Errorf("case %d: BlockCount %d, expected %d", i, file.Control.BlockCount, tc.blockCount)
+		}
+
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		w.SetOptions(tc.opts)
+		if err := w.Write(file); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		if strings.HasSuffix(out, tc.lineEnding) != tc.finalEnding {
+			t.Errorf("case %d: unexpected final line ending: %q", i, out[len(out)-4:])
+		}
+		lines := strings.Split(strings.TrimSuffix(out, tc.lineEnding), tc.lineEnding)
+		if len(lines) != tc.lines {
+			t.Errorf("case %d: got %d lines, expected %d", i, len(lines), tc.lines)
+		}
+		if factor := lines[0][37:39]; tc.opts.BlockingFactor > 0 && factor != fmt.Sprintf("%02d", tc.opts.BlockingFactor) {
+			t.Errorf("case %d: FileHeader blocking factor %q", i, factor)
+		}
+		for _, line := range lines {
+			if len(line) != RecordLength {
+				t.Errorf("case %d: unexpected line %q", i, line)
+			}
+		}
+
+		read, err := NewReader(&buf).Read()
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if read.Control.BlockCount != tc.blockCount {
+			t.Errorf("case %d: read BlockCount %d", i, read.Control.BlockCount)
+		}
+		if read.Header.blockingFactor != lines[0][37:39] {
+			t.Errorf("case %d: read blocking factor %q", i, read.Header.blockingFactor)
+		}
+	}
+
+	// the BlockCount of a file created with another blocking factor is written for the Writer's
+	if err := file.CreateWithOptions(WriterOptions{BlockingFactor: 10}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.SetOptions(WriterOptions{BlockingFactor: 2})
+	if err := w.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Control.BlockCount != 1 {
+		t.Errorf("file BlockCount changed to %d", file.Control.BlockCount)
+	}
+	read, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Header.blockingFactor != "02" || read.Control.BlockCount != 3 {
+		t.Errorf("blocking factor %q and BlockCount %d", read.Header.blockingFactor, read.Control.BlockCount)
+	}
+
+	if err := file.CreateWithOptions(WriterOptions{BlockingFactor: 100}); !base.Match(err, ErrBlockingFactor) {
+		t.Errorf("%T: %s", err, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ourly/ach"
+)
+
+const (
+	// paymentRelatedInformationLength is the length of Addenda05 PaymentRelatedInformation
+	paymentRelatedInformationLength = 80
+	// maxAddenda05 is the most Addenda05 records a CTX entry can carry
+	maxAddenda05 = 9999
+)
+
+// ErrTooManyAddenda05 is returned when X12 data does not fit into 9999 Addenda05 records
+var ErrTooManyAddenda05 = errors.New("X12 data requires more than 9999 Addenda05 records")
+
+// JoinAddenda05 reassembles the PaymentRelatedInformation of addenda in SequenceNumber order.
+func JoinAddenda05(addenda []*ach.Addenda05) string {
+	sorted := make([]*ach.Addenda05, 0, len(addenda))
+	for _, a := range addenda {
+		if a != nil {
+			sorted = append(sorted, a)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SequenceNumber < sorted[j].SequenceNumber
+	})
+
+	var buf strings.Builder
+	for _, a := range sorted {
+		buf.WriteString(a.PaymentRelatedInformation)
+	}
+	return buf.String()
+}
+
+// FromEntry parses the X12 820 carried in the Addenda05 records of a CTX entry.
+func FromEntry(ed *ach.EntryDetail) (*Remittance, error) {
+	if ed == nil {
+		return nil, errors.New("nil EntryDetail")
+	}
+	if len(ed.Addenda05) == 0 {
+		return nil, fmt.Errorf("entry %s: %w", ed.TraceNumber, ErrNoSegments)
+	}
+	r, err := Parse(JoinAddenda05(ed.Addenda05))
+	if err != nil {
+		return nil, fmt.Errorf("entry %s: %v", ed.TraceNumber, err)
+	}
+	return r, nil
+}
+
+// SplitAddenda05 chunks data into Addenda05 records with ascending SequenceNumbers.
+//
+// Addenda05 records are read with leading and trailing spaces trimmed, so records are
+// split early instead of beginning or ending with a space.
+func SplitAddenda05(data string) ([]*ach.Addenda05, error) {
+	var out []*ach.Addenda05
+	for data != "" {
+		n := len(data)
+		if n > paymentRelatedInformationLength {
+			n = paymentRelatedInformationLength
+			for n > 1 && (data[n-1] == ' ' || data[n] == ' ') {
+				n--
+			}
+			if n == 1 {
+				n = paymentRelatedInformationLength
+			}
+		}
+		if len(out) == maxAddenda05 {
+			return nil, ErrTooManyAddenda05
+		}
+		addenda := ach.NewAddenda05()
+		addenda.PaymentRelatedInformation = data[:n]
+		addenda.SequenceNumber = len(out) + 1
+		out = append(out, addenda)
+		data = data[n:]
+	}
+	return out, nil
+}
+
+// SetAddenda05 replaces the Addenda05 records of a CTX entry with r and sets the number
+// of addenda records (characters 1-4 of IndividualName).
+//
+// IndividualName is expected to be formatted with SetCATXAddendaRecords and
+// SetCATXReceivingCompany. Otherwise its contents are used as the receiving company.
+func SetAddenda05(ed *ach.EntryDetail, r *Remittance) error {
+	if ed == nil || r == nil {
+		return errors.New("nil EntryDetail or Remittance")
+	}
+	addenda, err := SplitAddenda05(r.String())
+	if err != nil {
+		return err
+	}
+
+	company := strings.TrimSpace(ed.IndividualName)
+	if len(ed.IndividualName) >= 20 {
+		company = ed.CATXReceivingCompanyField()
+	}
+	ed.SetCATXAddendaRecords(len(addenda))
+	ed.SetCATXReceivingCompany(company)
+
+	ed.Addenda05 = addenda
+	ed.AddendaRecordIndicator = 1
+	return nil
+}
@@ -0,0 +1,177 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ourly/ach"
+)
+
+// mockCTXEntry returns a CTX entry without Addenda05 records
+func mockCTXEntry() *ach.EntryDetail {
+	entry := ach.NewEntryDetail()
+	entry.TransactionCode = ach.CheckingCredit
+	entry.SetRDFI("231380104")
+	entry.DFIAccountNumber = "12345678"
+	entry.Amount = 125075
+	entry.IdentificationNumber = "45689033"
+	entry.SetCATXAddendaRecords(0)
+	entry.SetCATXReceivingCompany("Receiver Company")
+	entry.SetTraceNumber("12104288", 1)
+	return entry
+}
+
+func TestJoinAddenda05(t *testing.T) {
+	a1, a2 := ach.NewAddenda05(), ach.NewAddenda05()
+	a1.PaymentRelatedInformation, a1.SequenceNumber = "ST*820*1\\BPR*", 1
+	a2.PaymentRelatedInformation, a2.SequenceNumber = "C*1\\SE*3*1\\", 2
+	if v := JoinAddenda05([]*ach.Addenda05{a2, nil, a1}); v != `ST*820*1\BPR*C*1\SE*3*1\` {
+		t.Errorf("JoinAddenda05=%q", v)
+	}
+}
+
+func TestSplitAddenda05(t *testing.T) {
+	// spaces are never at the start or end of a record
+	data := strings.Repeat("A", 79) + " B" + strings.Repeat("C", 100)
+	addenda, err := SplitAddenda05(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addenda) != 3 || addenda[0].PaymentRelatedInformation != strings.Repeat("A", 78) || addenda[2].SequenceNumber != 3 {
+		t.Fatalf("unexpected addenda: %v", addenda)
+	}
+	for _, a := range addenda {
+		if len(a.PaymentRelatedInformation) > 80 || strings.TrimSpace(a.PaymentRelatedInformation) != a.PaymentRelatedInformation {
+			t.Errorf("unexpected PaymentRelatedInformation: %q", a.PaymentRelatedInformation)
+		}
+	}
+	if JoinAddenda05(addenda) != data {
+		t.Error("data was not reassembled")
+	}
+
+	// a run of spaces longer than a record is split anyway
+	if addenda, _ := SplitAddenda05("A" + strings.Repeat(" ", 100) + "B"); len(addenda) != 2 {
+		t.Errorf("got %d addenda", len(addenda))
+	}
+	if _, err := SplitAddenda05(strings.Repeat("A", 80*9999+1)); !errors.Is(err, ErrTooManyAddenda05) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSetAddenda05__roundTrip(t *testing.T) {
+	remit, err := Parse(mock820)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// add enough invoices to span many Addenda05 records
+	for i := 0; i < 50; i++ {
+		remit.Entities[0].Remittances = append(remit.Entities[0].Remittances, &RemittanceAdvice{
+			ReferenceIDQualifier: "IV",
+			ReferenceID:          fmt.Sprintf("INV %d", 2000+i),
+			Amount:               100,
+		})
+	}
+
+	entry := mockCTXEntry()
+	if err := SetAddenda05(entry, remit); err != nil {
+		t.Fatal(err)
+	}
+	if entry.CATXAddendaRecordsField() != fmt.Sprintf("%04d", len(entry.Addenda05)) || entry.CATXReceivingCompanyField() != "Receiver Company" {
+		t.Errorf("IndividualName=%q", entry.IndividualName)
+	}
+
+	bh := ach.NewBatchHeader()
+	bh.ServiceClassCode = ach.CreditsOnly
+	bh.CompanyName = "Name on Account"
+	bh.CompanyIdentification = "121042882"
+	bh.StandardEntryClassCode = ach.CTX
+	bh.CompanyEntryDescription = "ACH CTX"
+	bh.EffectiveEntryDate = "190221"
+	bh.ODFIIdentification = "121042882"
+	batch := ach.NewBatchCTX(bh)
+	batch.AddEntry(entry)
+	if err := batch.Create(); err != nil {
+		t.Fatal(err)
+	}
+
+	fh := ach.NewFileHeader()
+	fh.ImmediateDestination = "231380104"
+	fh.ImmediateOrigin = "121042882"
+	fh.FileCreationDate = "190220"
+	fh.ImmediateDestinationName = "Federal Reserve Bank"
+	fh.ImmediateOriginName = "My Bank Name"
+	file := ach.NewFile()
+	file.SetHeader(fh)
+	file.AddBatch(batch)
+	if err := file.Create(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ach.NewWriter(&buf).Write(file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ach.NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := FromEntry(read.Batches[0].GetEntries()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.String() != remit.String() {
+		t.Errorf("expected:\n%s\ngot:\n%s", remit.String(), parsed.String())
+	}
+	if rmr := parsed.Entities[0].Remittances[51]; rmr.ReferenceID != "INV 2049" {
+		t.Errorf("unexpected RMR: %#v", rmr)
+	}
+
+	// IndividualName which isn't CATX formatted is used as the receiving company
+	entry = mockCTXEntry()
+	entry.IndividualName = "Receiver"
+	if err := SetAddenda05(entry, remit); err != nil {
+		t.Fatal(err)
+	}
+	if entry.CATXReceivingCompanyField() != "Receiver" {
+		t.Errorf("IndividualName=%q", entry.IndividualName)
+	}
+}
+
+func TestFromEntry__errors(t *testing.T) {
+	if _, err := FromEntry(nil); err == nil {
+		t.Error("expected error")
+	}
+	entry := mockCTXEntry()
+	if _, err := FromEntry(entry); !errors.Is(err, ErrNoSegments) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	a := ach.NewAddenda05()
+	a.PaymentRelatedInformation = "Debit First Account"
+	entry.AddAddenda05(a)
+	if _, err := FromEntry(entry); err == nil {
+		t.Error("expected error")
+	}
+	if err := SetAddenda05(entry, nil); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,36 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package x12 reads and writes ANSI ASC X12 820 (Payment Order/Remittance Advice)
+// transaction sets carried in the Addenda05 records of CTX entries.
+//
+// Reassemble and parse the remittance of a CTX entry
+//     remit, err := x12.FromEntry(entry)
+//     if err != nil {
+//         log.Fatalf("problem parsing 820: %v", err)
+//     }
+//     for _, ent := range remit.Entities {
+//         for _, rmr := range ent.Remittances {
+//             fmt.Printf("invoice %s paid %d\n", rmr.ReferenceID, rmr.Amount)
+//         }
+//     }
+//
+// Write a remittance into an entry's Addenda05 records
+//     if err := x12.SetAddenda05(entry, remit); err != nil {
+//         log.Fatalf("problem writing 820: %v", err)
+//     }
+package x12
@@ -0,0 +1,412 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InterchangeHeader is the ISA segment which starts an interchange envelope
+type InterchangeHeader struct {
+	AuthorizationQualifier   string `json:"authorizationQualifier"`
+	AuthorizationInformation string `json:"authorizationInformation"`
+	SecurityQualifier        string `json:"securityQualifier"`
+	SecurityInformation      string `json:"securityInformation"`
+	SenderQualifier          string `json:"senderQualifier"`
+	SenderID                 string `json:"senderID"`
+	ReceiverQualifier        string `json:"receiverQualifier"`
+	ReceiverID               string `json:"receiverID"`
+	// Date is YYMMDD
+	Date string `json:"date"`
+	// Time is HHMM
+	Time                string `json:"time"`
+	RepetitionSeparator string `json:"repetitionSeparator"`
+	Version             string `json:"version"`
+	ControlNumber       string `json:"controlNumber"`
+	AckRequested        string `json:"ackRequested"`
+	// UsageIndicator is P for production or T for test data
+	UsageIndicator string `json:"usageIndicator"`
+}
+
+// isaWidths are the fixed widths of ISA01 through ISA15
+var isaWidths = []int{2, 10, 2, 10, 2, 15, 2, 15, 6, 4, 1, 5, 9, 1, 1}
+
+func (isa *InterchangeHeader) fields() []*string {
+	return []*string{
+		&isa.AuthorizationQualifier, &isa.AuthorizationInformation, &isa.SecurityQualifier, &isa.SecurityInformation,
+		&isa.SenderQualifier, &isa.SenderID, &isa.ReceiverQualifier, &isa.ReceiverID, &isa.Date, &isa.Time,
+		&isa.RepetitionSeparator, &isa.Version, &isa.ControlNumber, &isa.AckRequested, &isa.UsageIndicator,
+	}
+}
+
+func (isa *InterchangeHeader) parse(s Segment) {
+	for i, f := range isa.fields() {
+		*f = strings.TrimSpace(s.Element(i + 1))
+	}
+}
+
+func (isa *InterchangeHeader) segment(d Delimiters) Segment {
+	seg := newSegment("ISA")
+	for i, f := range isa.fields() {
+		v := *f
+		if len(v) < isaWidths[i] {
+			v += strings.Repeat(" ", isaWidths[i]-len(v))
+		}
+		seg.Elements = append(seg.Elements, v[:isaWidths[i]])
+	}
+	seg.Elements = append(seg.Elements, string(d.Component))
+	return seg
+}
+
+// FunctionalGroupHeader is the GS segment which starts a functional group
+type FunctionalGroupHeader struct {
+	// FunctionalIdentifierCode is RA for 820 Payment Order/Remittance Advice
+	FunctionalIdentifierCode string `json:"functionalIdentifierCode"`
+	ApplicationSenderCode    string `json:"applicationSenderCode"`
+	ApplicationReceiverCode  string `json:"applicationReceiverCode"`
+	// Date is CCYYMMDD
+	Date string `json:"date"`
+	// Time is HHMM
+	Time                     string `json:"time"`
+	ControlNumber            string `json:"controlNumber"`
+	ResponsibleAgencyCode    string `json:"responsibleAgencyCode"`
+	VersionReleaseIdentifier string `json:"versionReleaseIdentifier"`
+}
+
+func (gs *FunctionalGroupHeader) fields() []*string {
+	return []*string{
+		&gs.FunctionalIdentifierCode, &gs.ApplicationSenderCode, &gs.ApplicationReceiverCode, &gs.Date,
+		&gs.Time, &gs.ControlNumber, &gs.ResponsibleAgencyCode, &gs.VersionReleaseIdentifier,
+	}
+}
+
+// TransactionSetHeader is the ST segment which starts a transaction set
+type TransactionSetHeader struct {
+	// IdentifierCode is 820
+	IdentifierCode string `json:"identifierCode"`
+	ControlNumber  string `json:"controlNumber"`
+}
+
+func (st *TransactionSetHeader) fields() []*string {
+	return []*string{&st.IdentifierCode, &st.ControlNumber}
+}
+
+// FinancialInformation is the BPR segment describing the payment
+type FinancialInformation struct {
+	// TransactionHandlingCode is BPR01, for example C (payment accompanies remittance) or I (remittance only)
+	TransactionHandlingCode string `json:"transactionHandlingCode"`
+	// Amount is the total payment in cents (BPR02)
+	Amount int `json:"amount"`
+	// CreditDebitFlag is C or D (BPR03)
+	CreditDebitFlag string `json:"creditDebitFlag"`
+	// PaymentMethodCode is ACH, CHK, FWT, ... (BPR04)
+	PaymentMethodCode string `json:"paymentMethodCode"`
+	// PaymentFormatCode is CTX, CCP, PPD, ... (BPR05)
+	PaymentFormatCode              string `json:"paymentFormatCode"`
+	OriginatingDFIQualifier        string `json:"originatingDFIQualifier"`
+	OriginatingDFIIdentification   string `json:"originatingDFIIdentification"`
+	OriginatingAccountQualifier    string `json:"originatingAccountQualifier"`
+	OriginatingAccountNumber       string `json:"originatingAccountNumber"`
+	OriginatingCompanyIdentifier   string `json:"originatingCompanyIdentifier"`
+	OriginatingCompanySupplemental string `json:"originatingCompanySupplemental"`
+	ReceivingDFIQualifier          string `json:"receivingDFIQualifier"`
+	ReceivingDFIIdentification     string `json:"receivingDFIIdentification"`
+	ReceivingAccountQualifier      string `json:"receivingAccountQualifier"`
+	ReceivingAccountNumber         string `json:"receivingAccountNumber"`
+	// EffectiveDate is CCYYMMDD (BPR16)
+	EffectiveDate string `json:"effectiveDate"`
+}
+
+// fields returns BPR03 through BPR16
+func (bpr *FinancialInformation) fields() []*string {
+	return []*string{
+		&bpr.CreditDebitFlag, &bpr.PaymentMethodCode, &bpr.PaymentFormatCode, &bpr.OriginatingDFIQualifier,
+		&bpr.OriginatingDFIIdentification, &bpr.OriginatingAccountQualifier, &bpr.OriginatingAccountNumber,
+		&bpr.OriginatingCompanyIdentifier, &bpr.OriginatingCompanySupplemental, &bpr.ReceivingDFIQualifier,
+		&bpr.ReceivingDFIIdentification, &bpr.ReceivingAccountQualifier, &bpr.ReceivingAccountNumber, &bpr.EffectiveDate,
+	}
+}
+
+// Trace is the TRN segment which uniquely identifies the payment
+type Trace struct {
+	// TypeCode is 1 (current transaction trace numbers)
+	TypeCode    string `json:"typeCode"`
+	ReferenceID string `json:"referenceID"`
+	// OriginatingCompanyIdentifier is usually "1" followed by the Originator's TIN
+	OriginatingCompanyIdentifier string `json:"originatingCompanyIdentifier"`
+	ReferenceIDSecondary         string `json:"referenceIDSecondary"`
+}
+
+func (trn *Trace) fields() []*string {
+	return []*string{&trn.TypeCode, &trn.ReferenceID, &trn.OriginatingCompanyIdentifier, &trn.ReferenceIDSecondary}
+}
+
+// RemittanceAdvice is the RMR segment describing a single paid document, usually an invoice
+type RemittanceAdvice struct {
+	// ReferenceIDQualifier is the type of ReferenceID, for example IV (invoice) or PO (purchase order)
+	ReferenceIDQualifier string `json:"referenceIDQualifier"`
+	ReferenceID          string `json:"referenceID"`
+	// PaymentActionCode is RMR03, for example PO (payment on account)
+	PaymentActionCode string `json:"paymentActionCode"`
+	// Amount paid in cents (RMR04)
+	Amount int `json:"amount"`
+	// TotalAmount of the invoice in cents (RMR05)
+	TotalAmount int `json:"totalAmount,omitempty"`
+	// DiscountAmount taken in cents (RMR06)
+	DiscountAmount int `json:"discountAmount,omitempty"`
+	// Other segments which follow the RMR segment (e.g. REF, DTM, ADX)
+	Other []Segment `json:"other,omitempty"`
+}
+
+// Entity is an ENT segment and the remittance loop which follows it
+type Entity struct {
+	AssignedNumber              string `json:"assignedNumber"`
+	EntityIdentifierCode        string `json:"entityIdentifierCode"`
+	IdentificationCodeQualifier string `json:"identificationCodeQualifier"`
+	IdentificationCode          string `json:"identificationCode"`
+
+	// Other segments which follow the ENT segment before the first RMR segment (e.g. NM1)
+	Other []Segment `json:"other,omitempty"`
+	// Remittances paid for this entity
+	Remittances []*RemittanceAdvice `json:"remittances"`
+
+	// implicit is true when RMR segments appear without a preceding ENT segment
+	implicit bool
+}
+
+func (ent *Entity) fields() []*string {
+	return []*string{&ent.AssignedNumber, &ent.EntityIdentifierCode, &ent.IdentificationCodeQualifier, &ent.IdentificationCode}
+}
+
+// Remittance is an X12 820 Payment Order/Remittance Advice transaction set, optionally
+// enclosed in an interchange (ISA/IEA) and functional group (GS/GE) envelope.
+type Remittance struct {
+	// Delimiters used to read or write the transaction set
+	Delimiters Delimiters `json:"-"`
+
+	Interchange     *InterchangeHeader     `json:"interchange,omitempty"`
+	FunctionalGroup *FunctionalGroupHeader `json:"functionalGroup,omitempty"`
+	Header          TransactionSetHeader   `json:"header"`
+	Payment         FinancialInformation   `json:"payment"`
+	Trace           *Trace                 `json:"trace,omitempty"`
+	// Other segments which follow BPR and TRN before the first ENT or RMR segment (e.g. CUR, REF, DTM, N1)
+	Other    []Segment `json:"other,omitempty"`
+	Entities []*Entity `json:"entities"`
+}
+
+// setFields copies the elements of s into fields starting at element offset
+func setFields(s Segment, offset int, fields []*string) {
+	for i, f := range fields {
+		*f = s.Element(offset + i)
+	}
+}
+
+// getFields returns the values of fields
+func getFields(fields []*string) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = *f
+	}
+	return out
+}
+
+// Parse reads an X12 820 transaction set. Delimiters are detected from the data.
+func Parse(data string) (*Remittance, error) {
+	segments, d, err := ParseSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	r := &Remittance{Delimiters: d}
+
+	var (
+		entity  *Entity
+		rmr     *RemittanceAdvice
+		inSet   bool
+		counted int // segments from ST through SE
+		sawBPR  bool
+		sawSE   bool
+	)
+	for _, seg := range segments {
+		if inSet {
+			counted++
+		}
+		switch seg.ID {
+		case "ISA":
+			r.Interchange = &InterchangeHeader{}
+			r.Interchange.parse(seg)
+		case "GS":
+			r.FunctionalGroup = &FunctionalGroupHeader{}
+			setFields(seg, 1, r.FunctionalGroup.fields())
+		case "GE", "IEA":
+			// trailers are rebuilt when writing
+		case "ST":
+			if inSet || sawSE {
+				return nil, fmt.Errorf("%s: only one transaction set is supported", seg.ID)
+			}
+			inSet, counted = true, 1
+			setFields(seg, 1, r.Header.fields())
+			if r.Header.IdentifierCode != "820" {
+				return nil, fmt.Errorf("ST01: transaction set %q is not an 820", r.Header.IdentifierCode)
+			}
+		case "SE":
+			if !inSet {
+				return nil, fmt.Errorf("SE: found without ST")
+			}
+			inSet, sawSE = false, true
+			if n, err := strconv.Atoi(seg.Element(1)); err != nil || n != counted {
+				return nil, fmt.Errorf("SE01: segment count %q does not match %d segments", seg.Element(1), counted)
+			}
+			if seg.Element(2) != r.Header.ControlNumber {
+				return nil, fmt.Errorf("SE02: control number %q does not match ST02 %q", seg.Element(2), r.Header.ControlNumber)
+			}
+		case "BPR":
+			sawBPR = true
+			r.Payment.TransactionHandlingCode = seg.Element(1)
+			if r.Payment.Amount, err = parseAmount(seg.Element(2)); err != nil {
+				return nil, fmt.Errorf("BPR02: %v", err)
+			}
+			setFields(seg, 3, r.Payment.fields())
+		case "TRN":
+			r.Trace = &Trace{}
+			setFields(seg, 1, r.Trace.fields())
+		case "ENT":
+			entity, rmr = &Entity{}, nil
+			setFields(seg, 1, entity.fields())
+			r.Entities = append(r.Entities, entity)
+		case "RMR":
+			if entity == nil {
+				entity = &Entity{implicit: true}
+				r.Entities = append(r.Entities, entity)
+			}
+			rmr = &RemittanceAdvice{
+				ReferenceIDQualifier: seg.Element(1),
+				ReferenceID:          seg.Element(2),
+				PaymentActionCode:    seg.Element(3),
+			}
+			if rmr.Amount, err = parseAmount(seg.Element(4)); err != nil {
+				return nil, fmt.Errorf("RMR04: %v", err)
+			}
+			if rmr.TotalAmount, err = parseAmount(seg.Element(5)); err != nil {
+				return nil, fmt.Errorf("RMR05: %v", err)
+			}
+			if rmr.DiscountAmount, err = parseAmount(seg.Element(6)); err != nil {
+				return nil, fmt.Errorf("RMR06: %v", err)
+			}
+			entity.Remittances = append(entity.Remittances, rmr)
+		default:
+			if !inSet {
+				return nil, fmt.Errorf("%s: segment found outside of transaction set", seg.ID)
+			}
+			switch {
+			case rmr != nil:
+				rmr.Other = append(rmr.Other, seg)
+			case entity != nil:
+				entity.Other = append(entity.Other, seg)
+			default:
+				r.Other = append(r.Other, seg)
+			}
+		}
+	}
+	if !sawSE {
+		return nil, fmt.Errorf("missing ST/SE transaction set")
+	}
+	if !sawBPR {
+		return nil, fmt.Errorf("missing BPR segment")
+	}
+	return r, nil
+}
+
+// Segments returns every segment of the remittance in order. SE01 and the GE and IEA
+// trailers are computed.
+func (r *Remittance) Segments() []Segment {
+	var out []Segment
+	if r.Interchange != nil {
+		out = append(out, r.Interchange.segment(r.delimiters()))
+	}
+	if r.FunctionalGroup != nil {
+		out = append(out, newSegment("GS", getFields(r.FunctionalGroup.fields())...))
+	}
+	start := len(out)
+
+	out = append(out, newSegment("ST", getFields(r.Header.fields())...))
+	bpr := append([]string{r.Payment.TransactionHandlingCode, formatAmount(r.Payment.Amount)}, getFields(r.Payment.fields())...)
+	out = append(out, newSegment("BPR", bpr...))
+	if r.Trace != nil {
+		out = append(out, newSegment("TRN", getFields(r.Trace.fields())...))
+	}
+	out = append(out, r.Other...)
+	for _, ent := range r.Entities {
+		if !ent.implicit {
+			out = append(out, newSegment("ENT", getFields(ent.fields())...))
+		}
+		out = append(out, ent.Other...)
+		for _, rmr := range ent.Remittances {
+			out = append(out, newSegment("RMR",
+				rmr.ReferenceIDQualifier, rmr.ReferenceID, rmr.PaymentActionCode, formatAmount(rmr.Amount),
+				formatOptionalAmount(rmr.TotalAmount), formatOptionalAmount(rmr.DiscountAmount),
+			))
+			out = append(out, rmr.Other...)
+		}
+	}
+	out = append(out, newSegment("SE", strconv.Itoa(len(out)-start+1), r.Header.ControlNumber))
+
+	if r.FunctionalGroup != nil {
+		out = append(out, newSegment("GE", "1", r.FunctionalGroup.ControlNumber))
+	}
+	if r.Interchange != nil {
+		out = append(out, newSegment("IEA", "1", r.Interchange.ControlNumber))
+	}
+	return out
+}
+
+// delimiters returns the Delimiters of r or DefaultDelimiters if unset
+func (r *Remittance) delimiters() Delimiters {
+	if r.Delimiters.Segment == 0 || r.Delimiters.Element == 0 {
+		return DefaultDelimiters
+	}
+	if r.Delimiters.Component == 0 {
+		d := r.Delimiters
+		d.Component = DefaultDelimiters.Component
+		return d
+	}
+	return r.Delimiters
+}
+
+// String returns the X12 encoded remittance with each segment terminated
+func (r *Remittance) String() string {
+	d := r.delimiters()
+	var buf strings.Builder
+	for _, seg := range r.Segments() {
+		buf.WriteString(seg.String(d))
+		buf.WriteByte(d.Segment)
+	}
+	return buf.String()
+}
+
+// Total returns the sum of each RMR04 amount paid
+func (r *Remittance) Total() int {
+	total := 0
+	for _, ent := range r.Entities {
+		for _, rmr := range ent.Remittances {
+			total += rmr.Amount
+		}
+	}
+	return total
+}
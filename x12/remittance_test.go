@@ -0,0 +1,139 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"strings"
+	"testing"
+)
+
+// mock820 is an enveloped 820 paying two invoices
+const mock820 = `ISA*00*          *00*          *ZZ*ORIGINATOR     *ZZ*RECEIVER       *190220*1200*U*00401*000000001*0*P*>\
+GS*RA*ORIGINATOR*RECEIVER*20190220*1200*1*X*004010\
+ST*820*0001\
+BPR*C*1250.75*C*ACH*CTX*01*231380104*DA*123456789*1234567890**01*121042882*DA*987654321*20190221\
+TRN*1*0000000001*1234567890\
+REF*VV*ACME\
+ENT*1\
+RMR*IV*INV-1001**1000*1000\
+DTM*003*20190115\
+RMR*IV*INV-1002**250.75*275*24.25\
+SE*9*0001\
+GE*1*1\
+IEA*1*000000001\
+`
+
+func TestParse(t *testing.T) {
+	r, err := Parse(mock820)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Interchange.SenderID != "ORIGINATOR" || r.Interchange.ControlNumber != "000000001" || r.Interchange.UsageIndicator != "P" {
+		t.Errorf("unexpected ISA: %#v", r.Interchange)
+	}
+	if r.FunctionalGroup.FunctionalIdentifierCode != "RA" || r.FunctionalGroup.VersionReleaseIdentifier != "004010" {
+		t.Errorf("unexpected GS: %#v", r.FunctionalGroup)
+	}
+	if r.Header.ControlNumber != "0001" {
+		t.Errorf("unexpected ST: %#v", r.Header)
+	}
+	if r.Payment.Amount != 125075 || r.Payment.PaymentFormatCode != "CTX" || r.Payment.ReceivingAccountNumber != "987654321" || r.Payment.EffectiveDate != "20190221" {
+		t.Errorf("unexpected BPR: %#v", r.Payment)
+	}
+	if r.Trace.ReferenceID != "0000000001" || r.Trace.OriginatingCompanyIdentifier != "1234567890" {
+		t.Errorf("unexpected TRN: %#v", r.Trace)
+	}
+	if len(r.Other) != 1 || r.Other[0].ID != "REF" {
+		t.Errorf("unexpected header segments: %v", r.Other)
+	}
+	if len(r.Entities) != 1 || len(r.Entities[0].Remittances) != 2 {
+		t.Fatalf("unexpected entities: %v", r.Entities)
+	}
+	first, second := r.Entities[0].Remittances[0], r.Entities[0].Remittances[1]
+	if first.ReferenceID != "INV-1001" || first.Amount != 100000 || len(first.Other) != 1 || first.Other[0].ID != "DTM" {
+		t.Errorf("unexpected RMR: %#v", first)
+	}
+	if second.Amount != 25075 || second.TotalAmount != 27500 || second.DiscountAmount != 2425 {
+		t.Errorf("unexpected RMR: %#v", second)
+	}
+	if r.Total() != r.Payment.Amount {
+		t.Errorf("Total()=%d", r.Total())
+	}
+
+	// writing produces the same segments
+	if v := r.String(); v != strings.ReplaceAll(mock820, "\n", "") {
+		t.Errorf("expected:\n%s\ngot:\n%s", mock820, v)
+	}
+}
+
+func TestParse__noEnvelope(t *testing.T) {
+	r, err := Parse("ST*820*1234~BPR*I*50*C*ACH*CTX~RMR*IV*9**50~SE*4*1234~")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Interchange != nil || r.FunctionalGroup != nil || r.Trace != nil {
+		t.Errorf("unexpected envelope: %#v", r)
+	}
+	if len(r.Entities) != 1 || r.Entities[0].Remittances[0].Amount != 5000 {
+		t.Fatalf("unexpected entities: %v", r.Entities)
+	}
+	// RMR segments without an ENT are written without one
+	if v := r.String(); v != "ST*820*1234~BPR*I*50*C*ACH*CTX~RMR*IV*9**50~SE*4*1234~" {
+		t.Errorf("String()=%q", v)
+	}
+}
+
+func TestParse__errors(t *testing.T) {
+	cases := map[string]string{
+		"ST*810*1\\BPR*C*1\\SE*3*1\\":                   "not an 820",
+		"ST*820*1\\BPR*C*1\\SE*9*1\\":                   "segment count",
+		"ST*820*1\\BPR*C*1\\SE*3*2\\":                   "control number",
+		"ST*820*1\\SE*2*1\\":                            "missing BPR",
+		"ST*820*1\\BPR*C*1":                             "missing ST/SE",
+		"REF*VV*1\\ST*820*1\\BPR*C*1\\SE*3*1\\":         "outside of transaction set",
+		"SE*1*1\\":                                      "without ST",
+		"ST*820*1\\BPR*C*x\\SE*3*1\\":                   "BPR02",
+		"ST*820*1\\BPR*C*1\\RMR*IV*1**x\\SE*4*1\\":      "RMR04",
+		"ST*820*1\\BPR*C*1\\SE*3*1\\ST*820*2\\SE*2*2\\": "only one transaction set",
+	}
+	for data, msg := range cases {
+		if _, err := Parse(data); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: expected %q error, got %v", data, msg, err)
+		}
+	}
+}
+
+func TestRemittance__build(t *testing.T) {
+	r := &Remittance{
+		Header:  TransactionSetHeader{IdentifierCode: "820", ControlNumber: "0001"},
+		Payment: FinancialInformation{TransactionHandlingCode: "C", Amount: 15000, CreditDebitFlag: "C", PaymentMethodCode: "ACH", PaymentFormatCode: "CTX"},
+		Entities: []*Entity{
+			{AssignedNumber: "1", Remittances: []*RemittanceAdvice{
+				{ReferenceIDQualifier: "IV", ReferenceID: "A1", Amount: 10000},
+				{ReferenceIDQualifier: "IV", ReferenceID: "A2", Amount: 5000, DiscountAmount: 100},
+			}},
+		},
+	}
+	expected := `ST*820*0001\BPR*C*150*C*ACH*CTX\ENT*1\RMR*IV*A1**100\RMR*IV*A2**50**1\SE*6*0001\`
+	if v := r.String(); v != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, v)
+	}
+	if _, err := Parse(r.String()); err != nil {
+		t.Error(err)
+	}
+}
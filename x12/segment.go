@@ -0,0 +1,182 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// isaLength is the fixed length of an ISA segment including its segment terminator
+const isaLength = 106
+
+// Delimiters separate the segments, elements and components of X12 data
+type Delimiters struct {
+	Segment   byte
+	Element   byte
+	Component byte
+}
+
+// DefaultDelimiters are the delimiters commonly used in CTX Addenda05 records
+var DefaultDelimiters = Delimiters{
+	Segment:   '\\',
+	Element:   '*',
+	Component: '>',
+}
+
+// ErrNoSegments is returned when X12 data contains no segments
+var ErrNoSegments = errors.New("no X12 segments found")
+
+// Segment is a single X12 segment such as "RMR*IV*1001**125.00"
+type Segment struct {
+	// ID is the segment identifier (e.g. ST, BPR, RMR)
+	ID string `json:"id"`
+	// Elements are the data elements following ID
+	Elements []string `json:"elements"`
+}
+
+// Element returns the nth (1-based, following X12 reference designators such as RMR04)
+// element of the segment or an empty string if it's not present.
+func (s Segment) Element(n int) string {
+	if n < 1 || n > len(s.Elements) {
+		return ""
+	}
+	return s.Elements[n-1]
+}
+
+// String returns the segment joined by the element separator without a segment terminator
+func (s Segment) String(d Delimiters) string {
+	// trailing empty elements are omitted
+	elements := s.Elements
+	for len(elements) > 0 && elements[len(elements)-1] == "" {
+		elements = elements[:len(elements)-1]
+	}
+	return strings.Join(append([]string{s.ID}, elements...), string(d.Element))
+}
+
+// newSegment returns a Segment for id and elements
+func newSegment(id string, elements ...string) Segment {
+	return Segment{ID: id, Elements: elements}
+}
+
+// detectDelimiters returns the Delimiters used by data. An ISA segment defines all three
+// delimiters, otherwise the element separator follows the first segment ID and the
+// segment terminator is the first of ~, \ or ' found.
+func detectDelimiters(data string) (Delimiters, error) {
+	if strings.HasPrefix(data, "ISA") {
+		if len(data) < isaLength {
+			return Delimiters{}, fmt.Errorf("ISA segment is %d characters, expected %d", len(data), isaLength)
+		}
+		return Delimiters{
+			Element:   data[3],
+			Component: data[isaLength-2],
+			Segment:   data[isaLength-1],
+		}, nil
+	}
+
+	d := DefaultDelimiters
+	idx := strings.IndexFunc(data, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if idx <= 0 {
+		return d, ErrNoSegments
+	}
+	d.Element = data[idx]
+
+	d.Segment = '\n'
+	if idx := strings.IndexAny(data, "~\\'"); idx >= 0 {
+		d.Segment = data[idx]
+	}
+	return d, nil
+}
+
+// ParseSegments splits X12 data into segments, detecting the delimiters used.
+// Whitespace (such as line breaks) between segments is ignored.
+func ParseSegments(data string) ([]Segment, Delimiters, error) {
+	data = strings.TrimSpace(data)
+	d, err := detectDelimiters(data)
+	if err != nil {
+		return nil, d, err
+	}
+
+	var segments []Segment
+	for _, raw := range strings.Split(data, string(d.Segment)) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		parts := strings.Split(raw, string(d.Element))
+		segments = append(segments, Segment{
+			ID:       parts[0],
+			Elements: parts[1:],
+		})
+	}
+	if len(segments) == 0 {
+		return nil, d, ErrNoSegments
+	}
+	return segments, d, nil
+}
+
+// parseAmount converts an X12 decimal amount (e.g. "1250", "1250.5" or "-12.50") into cents
+func parseAmount(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(s, "-")
+	whole, frac := strings.TrimPrefix(s, "-"), ""
+	if idx := strings.Index(whole, "."); idx >= 0 {
+		whole, frac = whole[:idx], whole[idx+1:]
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than 2 decimal places", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	n, err := strconv.Atoi(whole + frac)
+	if err != nil || strings.ContainsAny(whole+frac, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// formatAmount converts cents into an X12 decimal amount
+func formatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	if cents%100 == 0 {
+		return fmt.Sprintf("%s%d", sign, cents/100)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// formatOptionalAmount returns an empty string for zero amounts
+func formatOptionalAmount(cents int) string {
+	if cents == 0 {
+		return ""
+	}
+	return formatAmount(cents)
+}
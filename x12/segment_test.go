@@ -0,0 +1,94 @@
+// Licensed to The Moov Authors under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. The Moov Authors licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package x12
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSegments(t *testing.T) {
+	segments, d, err := ParseSegments("ST*820*0001~\nBPR*C*100*C*ACH*CTX~\nSE*3*0001~\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Segment != '~' || d.Element != '*' {
+		t.Errorf("unexpected delimiters: %#v", d)
+	}
+	if len(segments) != 3 || segments[1].ID != "BPR" || segments[1].Element(2) != "100" {
+		t.Fatalf("unexpected segments: %v", segments)
+	}
+	if v := segments[1].Element(9); v != "" {
+		t.Errorf("missing element=%q", v)
+	}
+	if v := segments[1].String(d); v != "BPR*C*100*C*ACH*CTX" {
+		t.Errorf("String()=%q", v)
+	}
+
+	// ISA defines the delimiters
+	segments, d, err = ParseSegments(mockISA + "IEA|1|000000001^")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Segment != '^' || d.Element != '|' || d.Component != ':' || len(segments) != 2 {
+		t.Errorf("unexpected delimiters %#v for %v", d, segments)
+	}
+
+	if _, _, err := ParseSegments(""); !errors.Is(err, ErrNoSegments) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, _, err := ParseSegments("ISA*00*"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+const mockISA = "ISA|00|          |00|          |ZZ|ORIGINATOR     |ZZ|RECEIVER       |190220|1200|U|00401|000000001|0|P|:^"
+
+func TestAmounts(t *testing.T) {
+	cases := []struct {
+		in    string
+		cents int
+		out   string
+	}{
+		{"1250", 125000, "1250"},
+		{"1250.5", 125050, "1250.50"},
+		{"0.07", 7, "0.07"},
+		{".25", 25, "0.25"},
+		{"-12.50", -1250, "-12.50"},
+	}
+	for _, tc := range cases {
+		cents, err := parseAmount(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cents != tc.cents {
+			t.Errorf("parseAmount(%q)=%d expected %d", tc.in, cents, tc.cents)
+		}
+		if v := formatAmount(cents); v != tc.out {
+			t.Errorf("formatAmount(%d)=%q expected %q", cents, v, tc.out)
+		}
+	}
+	for _, in := range []string{"1.005", "abc", "1.-5", "--1"} {
+		if _, err := parseAmount(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+	if v := formatOptionalAmount(0); v != "" {
+		t.Errorf("formatOptionalAmount(0)=%q", v)
+	}
+}